@@ -0,0 +1,194 @@
+package admin
+
+import (
+	_ "embed"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+//go:embed ui.html
+var uiPage []byte
+
+// maxPayloadSize limits the size of edited JSON payloads.
+const maxPayloadSize = 1 << 20
+
+// Admin serves a small web UI and JSON API for browsing registered collections.
+type Admin struct {
+	mu          sync.RWMutex
+	collections map[string]Collection
+}
+
+// New creates an Admin with no collections.
+func New() *Admin {
+	return &Admin{collections: make(map[string]Collection)}
+}
+
+// Register makes a collection visible in the admin UI under name.
+func (a *Admin) Register(name string, collection Collection) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.collections[name] = collection
+}
+
+func (a *Admin) collection(name string) (Collection, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	c, ok := a.collections[name]
+	return c, ok
+}
+
+// Mount registers the UI and its API on group. auth must reject requests that
+// may not use the admin module, e.g. auth.Handlers.Require; it runs before the
+// middleware and every admin route. Mount panics when auth is nil, since the
+// API can read and edit every record.
+//
+//	GET   /                    the web UI
+//	GET   /api/collections     registered collection names
+//	GET   /api/records         ?collection=&q=&offset=&limit=
+//	GET   /api/record          ?collection=&key=
+//	PATCH /api/record          ?collection=&key=   body: JSON payload
+//	POST  /api/compact         ?collection=
+func (a *Admin) Mount(group *mygin.RouterGroup, auth mygin.HandlerFunc, middleware ...mygin.HandlerFunc) {
+	if auth == nil {
+		panic("admin: Mount requires an auth handler")
+	}
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{auth}, middleware...), handler)
+	}
+
+	group.GET("/", with(a.handleUI)...)
+	group.GET("/api/collections", with(a.handleCollections)...)
+	group.GET("/api/records", with(a.handleRecords)...)
+	group.GET("/api/record", with(a.handleRecord)...)
+	group.PATCH("/api/record", with(a.handleUpdateRecord)...)
+	group.POST("/api/compact", with(a.handleCompact)...)
+}
+
+func (a *Admin) handleUI(c *mygin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", uiPage)
+}
+
+func (a *Admin) handleCollections(c *mygin.Context) {
+	a.mu.RLock()
+	names := make([]string, 0, len(a.collections))
+	for name := range a.collections {
+		names = append(names, name)
+	}
+	a.mu.RUnlock()
+
+	sort.Strings(names)
+	c.JSON(http.StatusOK, mygin.H{"collections": names})
+}
+
+// lookup resolves the collection query parameter or writes a 404.
+func (a *Admin) lookup(c *mygin.Context) (Collection, bool) {
+	name := c.GetQuery("collection")
+	collection, ok := a.collection(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, mygin.H{"error": "collection not found", "collection": name})
+		return nil, false
+	}
+	return collection, true
+}
+
+func (a *Admin) handleRecords(c *mygin.Context) {
+	collection, ok := a.lookup(c)
+	if !ok {
+		return
+	}
+
+	records, err := collection.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, mygin.H{"error": err.Error()})
+		return
+	}
+
+	// جستجوی ساده روی متن JSON هر رکورد
+	if q := strings.ToLower(c.GetQuery("q")); q != "" {
+		filtered := records[:0]
+		for _, record := range records {
+			if strings.Contains(strings.ToLower(string(record)), q) {
+				filtered = append(filtered, record)
+			}
+		}
+		records = filtered
+	}
+
+	sort.Slice(records, func(i, j int) bool { return string(records[i]) < string(records[j]) })
+
+	total := len(records)
+	offset := min(max(c.GetQueryIntDefault("offset", 0), 0), total)
+	limit := c.GetQueryIntDefault("limit", 50)
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	end := min(offset+limit, total)
+
+	c.JSON(http.StatusOK, mygin.H{
+		"total":   total,
+		"offset":  offset,
+		"limit":   limit,
+		"records": records[offset:end],
+	})
+}
+
+func (a *Admin) handleRecord(c *mygin.Context) {
+	collection, ok := a.lookup(c)
+	if !ok {
+		return
+	}
+
+	record, err := collection.Get(c.GetQuery("key"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, mygin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/json", record)
+}
+
+func (a *Admin) handleUpdateRecord(c *mygin.Context) {
+	collection, ok := a.lookup(c)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, maxPayloadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": err.Error()})
+		return
+	}
+	if len(body) > maxPayloadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, mygin.H{"error": "payload too large"})
+		return
+	}
+	if !json.Valid(body) {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid JSON payload"})
+		return
+	}
+
+	record, err := collection.Put(c.GetQuery("key"), body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/json", record)
+}
+
+func (a *Admin) handleCompact(c *mygin.Context) {
+	collection, ok := a.lookup(c)
+	if !ok {
+		return
+	}
+
+	if err := collection.Compact(); err != nil {
+		c.JSON(http.StatusInternalServerError, mygin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, mygin.H{"status": "compacted"})
+}
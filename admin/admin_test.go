@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type note struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+func (n *note) SetID(id uuid.UUID) { n.ID = id }
+func (n *note) GetID() uuid.UUID   { return n.ID }
+func (n *note) GetRecordSize() int { return 256 }
+
+func TestAdmin(t *testing.T) {
+	manager, err := collection_manager_memory.NewWithRecordSize[*note](t.TempDir(), "notes", 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	var ids []string
+	for _, text := range []string{"apple", "banana", "cherry"} {
+		n, err := manager.Create(&note{Text: text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, n.ID.String())
+	}
+
+	a := New()
+	a.Register("notes", Memory[*note](manager))
+	engine := mygin.New()
+	a.Mount(engine.Group("/admin"), func(c *mygin.Context) {
+		if c.GetHeader("Authorization") != "Bearer admin" {
+			c.JSON(http.StatusUnauthorized, mygin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	})
+
+	send := func(method, target, body string, authorized bool) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if authorized {
+			req.Header.Set("Authorization", "Bearer admin")
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	// بدون احراز هویت هیچ مسیری در دسترس نیست و رکوردی تغییر نمی‌کند
+	for _, tt := range []struct{ method, target, body string }{
+		{"GET", "/admin", ""},
+		{"GET", "/admin/api/collections", ""},
+		{"GET", "/admin/api/records?collection=notes", ""},
+		{"GET", "/admin/api/record?collection=notes&key=" + ids[0], ""},
+		{"PATCH", "/admin/api/record?collection=notes&key=" + ids[0], `{"id":"` + ids[0] + `","text":"hacked"}`},
+		{"POST", "/admin/api/compact?collection=notes", ""},
+	} {
+		if w := send(tt.method, tt.target, tt.body, false); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without auth: %d", tt.method, tt.target, w.Code)
+		}
+	}
+	if n, _ := manager.Read(uuid.MustParse(ids[0])); n.Text != "apple" {
+		t.Fatalf("unauthenticated PATCH changed the record: %+v", n)
+	}
+
+	if w := send("GET", "/admin", "", true); w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("UI: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w := send("GET", "/admin/api/collections", "", true); strings.TrimSpace(w.Body.String()) != `{"collections":["notes"]}` {
+		t.Fatalf("collections: %s", w.Body)
+	}
+
+	var page struct {
+		Total   int               `json:"total"`
+		Offset  int               `json:"offset"`
+		Limit   int               `json:"limit"`
+		Records []json.RawMessage `json:"records"`
+	}
+	list := func(query string) {
+		t.Helper()
+		w := send("GET", "/admin/api/records?collection=notes"+query, "", true)
+		page.Records = nil
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("records%s: %d %s", query, w.Code, w.Body)
+		}
+	}
+	list("&offset=1&limit=1")
+	if page.Total != 3 || page.Offset != 1 || page.Limit != 1 || len(page.Records) != 1 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	list("&q=BANANA")
+	if page.Total != 1 || !strings.Contains(string(page.Records[0]), "banana") {
+		t.Fatalf("unexpected search result %+v", page)
+	}
+	if w := send("GET", "/admin/api/records?collection=missing", "", true); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown collection: %d", w.Code)
+	}
+
+	if w := send("GET", "/admin/api/record?collection=notes&key="+ids[1], "", true); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "banana") {
+		t.Fatalf("get: %d %s", w.Code, w.Body)
+	}
+	if w := send("GET", "/admin/api/record?collection=notes&key="+uuid.NewString(), "", true); w.Code != http.StatusNotFound {
+		t.Fatalf("get missing: %d", w.Code)
+	}
+
+	target := "/admin/api/record?collection=notes&key=" + ids[0]
+	if w := send("PATCH", target, `{"id":"`+ids[0]+`","text":"apricot"}`, true); w.Code != http.StatusOK {
+		t.Fatalf("patch: %d %s", w.Code, w.Body)
+	}
+	if n, _ := manager.Read(uuid.MustParse(ids[0])); n.Text != "apricot" {
+		t.Fatalf("patch was not stored: %+v", n)
+	}
+	if w := send("PATCH", target, `{"text":`, true); w.Code != http.StatusBadRequest {
+		t.Fatalf("patch with invalid JSON: %d", w.Code)
+	}
+	if w := send("PATCH", target, `{"id":"`+ids[1]+`","text":"x"}`, true); w.Code != http.StatusBadRequest {
+		t.Fatalf("patch with another id: %d", w.Code)
+	}
+
+	if w := send("POST", "/admin/api/compact?collection=notes", "", true); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"status":"compacted"}` {
+		t.Fatalf("compact: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestMountRequiresAuth(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Mount without an auth handler did not panic")
+		}
+	}()
+	New().Mount(mygin.New().Group("/admin"), nil)
+}
@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+// ErrNotSupported is returned by collections that do not implement an operation.
+var ErrNotSupported = errors.New("operation not supported by collection")
+
+// Collection is what the admin UI needs from a registered collection.
+type Collection interface {
+	// List returns every record encoded as JSON.
+	List() ([]json.RawMessage, error)
+	// Get returns a single record by its key.
+	Get(key string) (json.RawMessage, error)
+	// Put replaces the record stored under key with the given JSON payload.
+	Put(key string, data []byte) (json.RawMessage, error)
+	// Compact reclaims the space of deleted records.
+	Compact() error
+}
+
+// Item is implemented by items of collections keyed by UUID.
+type Item interface {
+	GetID() uuid.UUID
+}
+
+// Manager is the method set of collection_manager_memory.Manager used by the admin module.
+type Manager[T Item] interface {
+	ReadAll() ([]T, error)
+	Read(id uuid.UUID) (T, error)
+	Update(item T) (T, error)
+	Compact() error
+}
+
+type managerCollection[T Item] struct {
+	manager Manager[T]
+}
+
+// Memory wraps a collection_manager_memory manager (or anything with the same
+// method set) for the admin UI.
+func Memory[T Item](manager Manager[T]) Collection {
+	return &managerCollection[T]{manager: manager}
+}
+
+func (c *managerCollection[T]) List() ([]json.RawMessage, error) {
+	items, err := c.manager.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling item: %w", err)
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+func (c *managerCollection[T]) Get(key string) (json.RawMessage, error) {
+	id, err := uuid.Parse(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %w", err)
+	}
+
+	item, err := c.manager.Read(id)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(item)
+}
+
+func (c *managerCollection[T]) Put(key string, data []byte) (json.RawMessage, error) {
+	id, err := uuid.Parse(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %w", err)
+	}
+
+	item := newItem[T]()
+	if err := json.Unmarshal(data, item); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	if item.GetID() != id {
+		return nil, fmt.Errorf("payload id %s does not match %s", item.GetID(), id)
+	}
+
+	updated, err := c.manager.Update(item)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(updated)
+}
+
+func (c *managerCollection[T]) Compact() error {
+	return c.manager.Compact()
+}
+
+// newItem returns a new zero item for T, allocating the struct when T is a pointer type.
+func newItem[T any]() T {
+	var zero T
+	t := reflect.TypeOf(zero)
+	if t != nil && t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface().(T)
+	}
+	return zero
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+var fastArgon2id = Argon2id{Time: 1, Memory: 1024, Threads: 1, KeyLen: 16, SaltLen: 8}
+
+func TestVerifyPassword(t *testing.T) {
+	for _, hasher := range []Hasher{fastArgon2id, Bcrypt{Cost: 4}} {
+		hash, err := hasher.Hash("secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := VerifyPassword("secret", hash); !ok || err != nil {
+			t.Fatalf("%T: expected match, got %v %v", hasher, ok, err)
+		}
+		if ok, _ := VerifyPassword("wrong", hash); ok {
+			t.Fatalf("%T: wrong password matched", hasher)
+		}
+		if NeedsRehash(hasher, hash) {
+			t.Fatalf("%T: fresh hash should not need rehash", hasher)
+		}
+	}
+	bcryptHash, _ := Bcrypt{Cost: 4}.Hash("secret")
+	if !NeedsRehash(fastArgon2id, bcryptHash) {
+		t.Fatal("bcrypt hash should need rehash for argon2id hasher")
+	}
+}
+
+func TestLoginFlow(t *testing.T) {
+	store, err := NewStore(t.TempDir(), fastArgon2id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if _, err := store.Register("Mahdi", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Register("mahdi", "other"); err == nil {
+		t.Fatal("expected duplicate username error")
+	}
+
+	h := &Handlers{Store: store, Sessions: NewSessions(time.Hour)}
+	engine := mygin.New()
+	h.Mount(engine.Group("/auth"))
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/auth/login", `{"username":"mahdi","password":"nope"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	w := do("POST", "/auth/login", `{"username":"mahdi","password":"secret"}`, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Set-Cookie"), "HttpOnly") {
+		t.Fatalf("login failed: %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if w := do("GET", "/auth/me", "", resp.Token); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"username":"Mahdi"`) {
+		t.Fatalf("me failed: %d %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "passwordHash") {
+		t.Fatal("password hash leaked")
+	}
+	do("POST", "/auth/logout", "", resp.Token)
+	if w := do("GET", "/auth/me", "", resp.Token); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after logout, got %d", w.Code)
+	}
+
+	w = do("POST", "/auth/login", `{"username":"mahdi","password":"secret"}`, "")
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	user, _ := store.Lookup("mahdi")
+	if err := store.SetPassword(user.ID, "changed", h.Sessions); err != nil {
+		t.Fatal(err)
+	}
+	if w := do("GET", "/auth/me", "", resp.Token); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after password change, got %d", w.Code)
+	}
+	if w := do("POST", "/auth/login", `{"username":"mahdi","password":"changed"}`, ""); w.Code != http.StatusOK {
+		t.Fatalf("login with new password failed: %d %s", w.Code, w.Body)
+	}
+}
+
+// slowHasher widens the window between the username check and Create.
+type slowHasher struct{ Argon2id }
+
+func (h slowHasher) Hash(password string) (string, error) {
+	time.Sleep(20 * time.Millisecond)
+	return h.Argon2id.Hash(password)
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+	store, err := NewStore(t.TempDir(), slowHasher{fastArgon2id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	names := []string{"Mahdi", "mahdi", " MAHDI ", "mahdi", "Mahdi ", "mAhDi", "mahdi", "MAHDI"}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for _, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.Register(name, "secret"); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	users, err := store.manager.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 1 || len(users) != 1 {
+		t.Fatalf("expected one user, created %d, stored %d", created, len(users))
+	}
+}
+
+func TestDisableWhileAuthenticating(t *testing.T) {
+	store, err := NewStore(t.TempDir(), fastArgon2id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	user, err := store.Register("mahdi", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				store.Authenticate("mahdi", "secret")
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := store.SetDisabled(user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if got, _ := store.Get(user.ID); !got.Disabled {
+		t.Fatal("a login re-enabled the disabled user")
+	}
+	if _, err := store.Authenticate("mahdi", "secret"); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("expected ErrUserDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// UserKey is the Context key under which Require stores the authenticated *User.
+const UserKey = "auth.user"
+
+// Handlers are login/logout endpoints and a session middleware for mygin.
+//
+//	h := &auth.Handlers{Store: store, Sessions: auth.NewSessions(24 * time.Hour)}
+//	h.Mount(engine.Group("/auth"))
+//	engine.GET("/api/me", h.Require(), meHandler)
+type Handlers struct {
+	Store    *Store
+	Sessions *Sessions
+	// CookieName is the session cookie; defaults to "session".
+	CookieName string
+	// Secure marks the cookie as HTTPS only.
+	Secure bool
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Mount registers POST /login, POST /logout and GET /me on group.
+func (h *Handlers) Mount(group *mygin.RouterGroup) {
+	group.POST("/login", h.Login)
+	group.POST("/logout", h.Logout)
+	group.GET("/me", h.Require(), h.Me)
+}
+
+// Login checks a JSON {username, password} body, starts a session, sets the
+// session cookie and returns the token for non-browser clients.
+func (h *Handlers) Login(c *mygin.Context) {
+	var req loginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Req.Body, 1<<16)).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid request body"})
+		return
+	}
+
+	user, err := h.Store.Authenticate(req.Username, req.Password)
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, mygin.H{"error": err.Error()})
+		return
+	case errors.Is(err, ErrUserDisabled):
+		c.JSON(http.StatusForbidden, mygin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, mygin.H{"error": err.Error()})
+		return
+	}
+
+	token, expiresAt, err := h.Sessions.Create(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, mygin.H{"error": err.Error()})
+		return
+	}
+	http.SetCookie(c.Writer, h.cookie(token, expiresAt))
+	c.JSON(http.StatusOK, mygin.H{"token": token, "expiresAt": expiresAt, "user": user.Public()})
+}
+
+// Logout ends the current session and clears the cookie.
+func (h *Handlers) Logout(c *mygin.Context) {
+	if token := h.token(c); token != "" {
+		h.Sessions.Revoke(token)
+	}
+	cookie := h.cookie("", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	http.SetCookie(c.Writer, cookie)
+	c.Status(http.StatusNoContent)
+}
+
+// Me returns the authenticated user.
+func (h *Handlers) Me(c *mygin.Context) {
+	user, _ := CurrentUser(c)
+	c.JSON(http.StatusOK, user.Public())
+}
+
+// Require rejects requests without a valid session and stores the user in the
+// Context under UserKey.
+func (h *Handlers) Require() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		userID, ok := h.Sessions.Lookup(h.token(c))
+		if !ok {
+			c.JSON(http.StatusUnauthorized, mygin.H{"error": "authentication required"})
+			c.Abort()
+			return
+		}
+		user, err := h.Store.Get(userID)
+		if err != nil || user.Disabled {
+			c.JSON(http.StatusUnauthorized, mygin.H{"error": "authentication required"})
+			c.Abort()
+			return
+		}
+		c.Set(UserKey, user)
+		c.Next()
+	}
+}
+
+// CurrentUser returns the user stored by Require.
+func CurrentUser(c *mygin.Context) (*User, bool) {
+	value, ok := c.Get(UserKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := value.(*User)
+	return user, ok
+}
+
+// token reads the session token from the cookie or an "Authorization: Bearer" header.
+func (h *Handlers) token(c *mygin.Context) string {
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	if cookie, err := c.Req.Cookie(h.cookieName()); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func (h *Handlers) cookieName() string {
+	if h.CookieName == "" {
+		return "session"
+	}
+	return h.CookieName
+}
+
+func (h *Handlers) cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     h.cookieName(),
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   h.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sessions is an in-memory session store. Only the SHA-256 of each token is
+// kept, so a memory dump does not reveal usable tokens.
+type Sessions struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]session
+}
+
+type session struct {
+	userID    uuid.UUID
+	expiresAt time.Time
+}
+
+// NewSessions returns a store whose sessions expire ttl after their last use.
+func NewSessions(ttl time.Duration) *Sessions {
+	return &Sessions{ttl: ttl, now: time.Now, sessions: make(map[string]session)}
+}
+
+// Create starts a session for userID and returns its token.
+func (s *Sessions) Create(userID uuid.UUID) (token string, expiresAt time.Time, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("error generating session token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt = s.now().Add(s.ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[hashToken(token)] = session{userID: userID, expiresAt: expiresAt}
+	return token, expiresAt, nil
+}
+
+// Lookup returns the user of a valid session and extends its lifetime.
+func (s *Sessions) Lookup(token string) (uuid.UUID, bool) {
+	key := hashToken(token)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[key]
+	if !ok {
+		return uuid.Nil, false
+	}
+	if !now.Before(sess.expiresAt) {
+		delete(s.sessions, key)
+		return uuid.Nil, false
+	}
+	sess.expiresAt = now.Add(s.ttl)
+	s.sessions[key] = sess
+	return sess.userID, true
+}
+
+// Revoke ends a session.
+func (s *Sessions) Revoke(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, hashToken(token))
+}
+
+// RevokeUser ends every session of a user, e.g. after a password change.
+func (s *Sessions) RevokeUser(userID uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, sess := range s.sessions {
+		if sess.userID == userID {
+			delete(s.sessions, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Sweep removes expired sessions and returns how many were removed.
+func (s *Sessions) Sweep() int {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, sess := range s.sessions {
+		if !now.Before(sess.expiresAt) {
+			delete(s.sessions, key)
+			n++
+		}
+	}
+	return n
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUserExists         = errors.New("user already exists")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserDisabled       = errors.New("user is disabled")
+)
+
+// User is a stored credential. PasswordHash is never sent to clients.
+type User struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash"`
+	Disabled     bool      `json:"disabled"`
+	LastLoginAt  time.Time `json:"lastLoginAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (u *User) SetID(id uuid.UUID)       { u.ID = id }
+func (u *User) GetID() uuid.UUID         { return u.ID }
+func (u *User) SetCreatedAt(t time.Time) { u.CreatedAt = t }
+func (u *User) SetUpdatedAt(t time.Time) { u.UpdatedAt = t }
+func (u *User) GetRecordSize() int       { return 512 }
+
+// Public returns the user without its password hash.
+func (u *User) Public() map[string]any {
+	return map[string]any{
+		"id":          u.ID,
+		"username":    u.Username,
+		"disabled":    u.Disabled,
+		"lastLoginAt": u.LastLoginAt,
+		"createdAt":   u.CreatedAt,
+	}
+}
+
+// Store keeps user credentials in a collection_manager_memory collection.
+// Usernames are case-insensitive and unique.
+type Store struct {
+	manager *collection_manager_memory.Manager[*User]
+	hasher  Hasher
+	dummy   string // hash verified for unknown users so timing does not reveal them
+
+	mu         sync.RWMutex
+	byUsername map[string]uuid.UUID
+	stopIndex  func()
+
+	// writeMu serializes the read-modify-write of users, so the username check
+	// and Create of Register are atomic and a login cannot write back a copy
+	// read before SetPassword or SetDisabled
+	writeMu sync.Mutex
+}
+
+// NewStore opens the credential collection "users" in dir.
+func NewStore(dir string, hasher Hasher) (*Store, error) {
+	manager, err := collection_manager_memory.New[*User](dir, "users")
+	if err != nil {
+		return nil, fmt.Errorf("error opening users collection: %w", err)
+	}
+	return NewStoreWithManager(manager, hasher)
+}
+
+// NewStoreWithManager uses an already opened manager.
+func NewStoreWithManager(manager *collection_manager_memory.Manager[*User], hasher Hasher) (*Store, error) {
+	if hasher == nil {
+		hasher = DefaultArgon2id
+	}
+	dummy, err := hasher.Hash("dummy password")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{manager: manager, hasher: hasher, dummy: dummy, byUsername: make(map[string]uuid.UUID)}
+	users, err := manager.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		s.byUsername[normalize(u.Username)] = u.ID
+	}
+	// ایندکس نام کاربری با تغییرات کالکشن همگام می‌ماند
+	s.stopIndex = manager.OnChange(func(change collection_manager_memory.Change[*User]) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch change.Type {
+		case collection_manager_memory.ChangeDelete:
+			delete(s.byUsername, normalize(change.Item.Username))
+		default:
+			s.byUsername[normalize(change.Item.Username)] = change.ID
+		}
+	})
+	return s, nil
+}
+
+// Close stops tracking changes and closes the manager.
+func (s *Store) Close() error {
+	s.stopIndex()
+	return s.manager.Close()
+}
+
+// Register creates a user with the given password. Concurrent registrations of
+// the same username create one user; the others return ErrUserExists.
+func (s *Store) Register(username, password string) (*User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return nil, errors.New("username and password are required")
+	}
+	if _, err := s.Lookup(username); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserExists, username)
+	}
+
+	// hash کند است و بیرون از قفل ساخته می‌شود؛ بررسی تکراری بودن زیر قفل تکرار می‌شود
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := s.Lookup(username); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserExists, username)
+	}
+	return s.manager.Create(&User{Username: username, PasswordHash: hash})
+}
+
+// Lookup returns the user with the given username.
+func (s *Store) Lookup(username string) (*User, error) {
+	s.mu.RLock()
+	id, ok := s.byUsername[normalize(username)]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
+	}
+	return s.manager.Read(id)
+}
+
+// Get returns the user with the given ID.
+func (s *Store) Get(id uuid.UUID) (*User, error) {
+	u, err := s.manager.Read(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, id)
+	}
+	return u, nil
+}
+
+// Authenticate verifies the credentials. Unknown users and wrong passwords both
+// return ErrInvalidCredentials after the same amount of hashing work. Hashes made
+// with outdated parameters are upgraded transparently.
+func (s *Store) Authenticate(username, password string) (*User, error) {
+	user, err := s.Lookup(username)
+	if err != nil {
+		VerifyPassword(password, s.dummy)
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := VerifyPassword(password, user.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+	rehash := ""
+	if NeedsRehash(s.hasher, user.PasswordHash) {
+		rehash, _ = s.hasher.Hash(password)
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	// SetPassword یا SetDisabled ممکن است هنگام بررسی کند رمز اجرا شده باشند
+	current, err := s.Get(user.ID)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if current.PasswordHash != user.PasswordHash {
+		return nil, ErrInvalidCredentials
+	}
+	if current.Disabled {
+		return nil, ErrUserDisabled
+	}
+	updated := *current
+	updated.LastLoginAt = time.Now()
+	if rehash != "" {
+		updated.PasswordHash = rehash
+	}
+	return s.manager.Update(&updated)
+}
+
+// SetPassword replaces the password of a user and, when sessions is not nil,
+// ends the user's sessions so the old password no longer grants access.
+func (s *Store) SetPassword(id uuid.UUID, password string, sessions *Sessions) error {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	user, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	updated := *user
+	updated.PasswordHash = hash
+	if _, err := s.manager.Update(&updated); err != nil {
+		return err
+	}
+	if sessions != nil {
+		sessions.RevokeUser(id)
+	}
+	return nil
+}
+
+// SetDisabled enables or disables a user.
+func (s *Store) SetDisabled(id uuid.UUID, disabled bool) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	user, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	updated := *user
+	updated.Disabled = disabled
+	_, err = s.manager.Update(&updated)
+	return err
+}
+
+// Delete removes a user.
+func (s *Store) Delete(id uuid.UUID) error {
+	return s.manager.Delete(id)
+}
+
+func normalize(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
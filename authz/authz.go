@@ -0,0 +1,382 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+// پکیج authz کنترل دسترسی مبتنی بر نقش (RBAC) است. هر نقش مجموعه‌ای از مجوزها
+// به شکل "resource:action" دارد و کاربر نقش را یا به صورت سراسری یا برای یک
+// منبع مشخص (scope مانند "albums/<id>") می‌گیرد.
+
+var logger = logging.For("authz")
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrInvalidRole       = errors.New("invalid role name")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+// Role is a named set of permissions such as "albums:write", "albums:*" or "*".
+type Role struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (r *Role) SetID(id uuid.UUID)       { r.ID = id }
+func (r *Role) GetID() uuid.UUID         { return r.ID }
+func (r *Role) SetCreatedAt(t time.Time) { r.CreatedAt = t }
+func (r *Role) SetUpdatedAt(t time.Time) { r.UpdatedAt = t }
+func (r *Role) GetRecordSize() int       { return 2048 }
+
+// Binding assigns a role to a user. An empty Scope applies everywhere, otherwise
+// the role applies to Scope and everything below it ("albums/1" covers
+// "albums/1/photos/2").
+type Binding struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"userId"`
+	Role      string    `json:"role"`
+	Scope     string    `json:"scope,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (b *Binding) SetID(id uuid.UUID)       { b.ID = id }
+func (b *Binding) GetID() uuid.UUID         { return b.ID }
+func (b *Binding) SetCreatedAt(t time.Time) { b.CreatedAt = t }
+func (b *Binding) SetUpdatedAt(t time.Time) { b.UpdatedAt = t }
+func (b *Binding) GetRecordSize() int       { return 512 }
+
+var roleName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// ValidPermission reports whether p is "*" or "resource:action" where either part
+// may be "*".
+func ValidPermission(p string) bool {
+	if p == "*" {
+		return true
+	}
+	resource, action, ok := strings.Cut(p, ":")
+	return ok && resource != "" && action != "" && !strings.ContainsAny(resource+action, ": ")
+}
+
+// Grants reports whether the granted permission covers the required one.
+func Grants(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	gr, ga, _ := strings.Cut(granted, ":")
+	rr, ra, _ := strings.Cut(required, ":")
+	return (gr == "*" || gr == rr) && (ga == "*" || ga == ra)
+}
+
+// covers reports whether a binding with scope applies to the requested scope.
+func covers(scope, requested string) bool {
+	return scope == "" || scope == requested || strings.HasPrefix(requested, scope+"/")
+}
+
+// Authorizer stores roles and bindings in collections and answers permission
+// checks from memory.
+type Authorizer struct {
+	roles    *collection_manager_memory.Manager[*Role]
+	bindings *collection_manager_memory.Manager[*Binding]
+	stop     []func()
+	writeMu  sync.Mutex
+
+	mu     sync.RWMutex
+	byName map[string]*Role
+	byUser map[uuid.UUID]map[uuid.UUID]*Binding
+}
+
+// Open opens (or creates) the "authz_roles" and "authz_bindings" collections in dir.
+func Open(dir string) (*Authorizer, error) {
+	roles, err := collection_manager_memory.New[*Role](dir, "authz_roles")
+	if err != nil {
+		return nil, fmt.Errorf("error opening authz_roles collection: %w", err)
+	}
+	bindings, err := collection_manager_memory.New[*Binding](dir, "authz_bindings")
+	if err != nil {
+		roles.Close()
+		return nil, fmt.Errorf("error opening authz_bindings collection: %w", err)
+	}
+	a, err := New(roles, bindings)
+	if err != nil {
+		roles.Close()
+		bindings.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// New uses already opened managers; Close closes them.
+func New(roles *collection_manager_memory.Manager[*Role], bindings *collection_manager_memory.Manager[*Binding]) (*Authorizer, error) {
+	a := &Authorizer{
+		roles:    roles,
+		bindings: bindings,
+		byName:   make(map[string]*Role),
+		byUser:   make(map[uuid.UUID]map[uuid.UUID]*Binding),
+	}
+
+	allRoles, err := roles.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading roles: %w", err)
+	}
+	for _, r := range allRoles {
+		a.byName[r.Name] = r
+	}
+	allBindings, err := bindings.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading role bindings: %w", err)
+	}
+	for _, b := range allBindings {
+		a.trackBinding(b)
+	}
+
+	// ایندکس‌ها با تغییرات کالکشن‌ها همگام می‌مانند
+	a.stop = append(a.stop, roles.OnChange(func(change collection_manager_memory.Change[*Role]) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if change.Type == collection_manager_memory.ChangeDelete {
+			delete(a.byName, change.Item.Name)
+		} else {
+			a.byName[change.Item.Name] = change.Item
+		}
+	}))
+	a.stop = append(a.stop, bindings.OnChange(func(change collection_manager_memory.Change[*Binding]) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if change.Type == collection_manager_memory.ChangeDelete {
+			a.untrackBinding(change.Item)
+		} else {
+			a.trackBinding(change.Item)
+		}
+	}))
+	return a, nil
+}
+
+// Close stops tracking changes and closes both collections.
+func (a *Authorizer) Close() error {
+	for _, stop := range a.stop {
+		stop()
+	}
+	return errors.Join(a.roles.Close(), a.bindings.Close())
+}
+
+// trackBinding and untrackBinding must be called with a.mu held (or before a is shared).
+func (a *Authorizer) trackBinding(b *Binding) {
+	if a.byUser[b.UserID] == nil {
+		a.byUser[b.UserID] = make(map[uuid.UUID]*Binding)
+	}
+	a.byUser[b.UserID][b.ID] = b
+}
+
+func (a *Authorizer) untrackBinding(b *Binding) {
+	delete(a.byUser[b.UserID], b.ID)
+	if len(a.byUser[b.UserID]) == 0 {
+		delete(a.byUser, b.UserID)
+	}
+}
+
+// SetRole creates or replaces the role name.
+func (a *Authorizer) SetRole(name, description string, permissions ...string) (*Role, error) {
+	if !roleName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRole, name)
+	}
+	perms := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if !ValidPermission(p) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPermission, p)
+		}
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+	a.mu.RLock()
+	existing := a.byName[name]
+	a.mu.RUnlock()
+
+	if existing == nil {
+		return a.roles.Create(&Role{Name: name, Description: description, Permissions: perms})
+	}
+	updated := *existing
+	updated.Description = description
+	updated.Permissions = perms
+	return a.roles.Update(&updated)
+}
+
+// DeleteRole removes a role and all its bindings.
+func (a *Authorizer) DeleteRole(name string) error {
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+	a.mu.RLock()
+	role := a.byName[name]
+	var ids []uuid.UUID
+	for _, bindings := range a.byUser {
+		for _, b := range bindings {
+			if b.Role == name {
+				ids = append(ids, b.ID)
+			}
+		}
+	}
+	a.mu.RUnlock()
+	if role == nil {
+		return fmt.Errorf("%w: %s", ErrRoleNotFound, name)
+	}
+
+	for _, id := range ids {
+		if err := a.bindings.Delete(id); err != nil {
+			return fmt.Errorf("error deleting role binding: %w", err)
+		}
+	}
+	return a.roles.Delete(role.ID)
+}
+
+// Role returns the role name.
+func (a *Authorizer) Role(name string) (*Role, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	role, ok := a.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, name)
+	}
+	return role, nil
+}
+
+// Roles returns all roles sorted by name.
+func (a *Authorizer) Roles() []*Role {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	roles := make([]*Role, 0, len(a.byName))
+	for _, r := range a.byName {
+		roles = append(roles, r)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles
+}
+
+// Assign gives userID the role within scope ("" for everywhere). Assigning an
+// existing binding again is a no-op.
+func (a *Authorizer) Assign(userID uuid.UUID, role, scope string) (*Binding, error) {
+	scope = strings.Trim(scope, "/")
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+	a.mu.RLock()
+	_, roleExists := a.byName[role]
+	existing := a.findBinding(userID, role, scope)
+	a.mu.RUnlock()
+
+	if !roleExists {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, role)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	return a.bindings.Create(&Binding{UserID: userID, Role: role, Scope: scope})
+}
+
+// Revoke removes the binding of role within scope from userID. Missing bindings are ignored.
+func (a *Authorizer) Revoke(userID uuid.UUID, role, scope string) error {
+	scope = strings.Trim(scope, "/")
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+	a.mu.RLock()
+	existing := a.findBinding(userID, role, scope)
+	a.mu.RUnlock()
+	if existing == nil {
+		return nil
+	}
+	return a.bindings.Delete(existing.ID)
+}
+
+// RevokeUser removes every binding of userID, e.g. when the user is deleted.
+func (a *Authorizer) RevokeUser(userID uuid.UUID) error {
+	var errs []error
+	for _, b := range a.Bindings(userID) {
+		errs = append(errs, a.Revoke(userID, b.Role, b.Scope))
+	}
+	return errors.Join(errs...)
+}
+
+// findBinding must be called with a.mu held.
+func (a *Authorizer) findBinding(userID uuid.UUID, role, scope string) *Binding {
+	for _, b := range a.byUser[userID] {
+		if b.Role == role && b.Scope == scope {
+			return b
+		}
+	}
+	return nil
+}
+
+// Bindings returns the role bindings of userID sorted by scope and role.
+func (a *Authorizer) Bindings(userID uuid.UUID) []*Binding {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	bindings := make([]*Binding, 0, len(a.byUser[userID]))
+	for _, b := range a.byUser[userID] {
+		bindings = append(bindings, b)
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].Scope != bindings[j].Scope {
+			return bindings[i].Scope < bindings[j].Scope
+		}
+		return bindings[i].Role < bindings[j].Role
+	})
+	return bindings
+}
+
+// Can reports whether userID holds permission within scope ("" checks global bindings only).
+func (a *Authorizer) Can(userID uuid.UUID, permission, scope string) bool {
+	scope = strings.Trim(scope, "/")
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, b := range a.byUser[userID] {
+		if !covers(b.Scope, scope) {
+			continue
+		}
+		role := a.byName[b.Role]
+		if role == nil {
+			continue
+		}
+		for _, granted := range role.Permissions {
+			if Grants(granted, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Permissions returns the permissions userID holds within scope, sorted and without duplicates.
+func (a *Authorizer) Permissions(userID uuid.UUID, scope string) []string {
+	scope = strings.Trim(scope, "/")
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	set := make(map[string]bool)
+	for _, b := range a.byUser[userID] {
+		if role := a.byName[b.Role]; role != nil && covers(b.Scope, scope) {
+			for _, p := range role.Permissions {
+				set[p] = true
+			}
+		}
+	}
+	perms := make([]string, 0, len(set))
+	for p := range set {
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+	return perms
+}
@@ -0,0 +1,134 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/auth"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestGrants(t *testing.T) {
+	tests := []struct {
+		granted, required string
+		want              bool
+	}{
+		{"albums:write", "albums:write", true},
+		{"albums:*", "albums:delete", true},
+		{"*:read", "photos:read", true},
+		{"*", "anything:at-all", true},
+		{"albums:read", "albums:write", false},
+		{"photos:*", "albums:read", false},
+	}
+	for _, tt := range tests {
+		if got := Grants(tt.granted, tt.required); got != tt.want {
+			t.Errorf("Grants(%q, %q) = %v", tt.granted, tt.required, got)
+		}
+	}
+}
+
+func TestAuthorizerScopesAndPersistence(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := uuid.New(), uuid.New()
+
+	if _, err := a.SetRole("viewer", "", "albums:read", "photos:read"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetRole("editor", "", "albums:*"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetRole("Bad Name", ""); err == nil {
+		t.Error("invalid role name accepted")
+	}
+	if _, err := a.Assign(alice, "viewer", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Assign(bob, "editor", "albums/1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Assign(bob, "missing", ""); err == nil {
+		t.Error("assigned unknown role")
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	checks := []struct {
+		user              uuid.UUID
+		permission, scope string
+		want              bool
+	}{
+		{alice, "albums:read", "albums/7", true},
+		{alice, "albums:write", "", false},
+		{bob, "albums:write", "albums/1", true},
+		{bob, "albums:write", "albums/1/photos/3", true},
+		{bob, "albums:write", "albums/10", false},
+		{bob, "albums:write", "", false},
+	}
+	for _, c := range checks {
+		if got := a.Can(c.user, c.permission, c.scope); got != c.want {
+			t.Errorf("Can(%s, %s) = %v", c.permission, c.scope, got)
+		}
+	}
+
+	if err := a.DeleteRole("editor"); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Bindings(bob)) != 0 || a.Can(bob, "albums:write", "albums/1") {
+		t.Error("bindings of deleted role remain")
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	user := &auth.User{ID: uuid.New()}
+	a.SetRole("editor", "", "albums:write")
+	a.Assign(user.ID, "editor", "albums/1")
+
+	engine := mygin.New()
+	setUser := func(c *mygin.Context) {
+		if c.GetHeader("X-Test-User") != "" {
+			c.Set(auth.UserKey, user)
+		}
+		c.Next()
+	}
+	engine.PATCH("/albums/:id", setUser, a.RequirePermission("albums:write", ScopeParam("albums", "id")), func(c *mygin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, tt := range []struct {
+		path   string
+		authed bool
+		want   int
+	}{
+		{"/albums/1", true, http.StatusNoContent},
+		{"/albums/2", true, http.StatusForbidden},
+		{"/albums/1", false, http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodPatch, tt.path, nil)
+		if tt.authed {
+			req.Header.Set("X-Test-User", "1")
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s authed=%v: %d, want %d", tt.path, tt.authed, w.Code, tt.want)
+		}
+	}
+}
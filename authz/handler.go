@@ -0,0 +1,118 @@
+package authz
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type roleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type bindingRequest struct {
+	Role  string `json:"role"`
+	Scope string `json:"scope"`
+}
+
+// Mount registers the role management API on group under path. Protect it, e.g.
+// with RequirePermission("authz:admin"):
+//
+//	GET    path/roles                   list roles
+//	PUT    path/roles/:name             create or replace {description, permissions}
+//	DELETE path/roles/:name             delete a role and its bindings
+//	GET    path/users/:id/roles         bindings and effective global permissions
+//	POST   path/users/:id/roles         assign {role, scope}
+//	DELETE path/users/:id/roles/:role   revoke (?scope=)
+func (a *Authorizer) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{}, middleware...), handler)
+	}
+	group.GET(path+"/roles", with(a.listRoles)...)
+	group.PUT(path+"/roles/:name", with(a.putRole)...)
+	group.DELETE(path+"/roles/:name", with(a.deleteRole)...)
+	group.GET(path+"/users/:id/roles", with(a.userRoles)...)
+	group.POST(path+"/users/:id/roles", with(a.assign)...)
+	group.DELETE(path+"/users/:id/roles/:role", with(a.revoke)...)
+}
+
+func writeError(c *mygin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrRoleNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrInvalidPermission):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, mygin.H{"error": err.Error()})
+}
+
+func (a *Authorizer) listRoles(c *mygin.Context) {
+	c.JSON(http.StatusOK, mygin.H{"roles": a.Roles()})
+}
+
+func (a *Authorizer) putRole(c *mygin.Context) {
+	var req roleRequest
+	if err := json.NewDecoder(c.Req.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid JSON body: " + err.Error()})
+		return
+	}
+	role, err := a.SetRole(c.Param("name"), req.Description, req.Permissions...)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+func (a *Authorizer) deleteRole(c *mygin.Context) {
+	if err := a.DeleteRole(c.Param("name")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (a *Authorizer) userRoles(c *mygin.Context) {
+	id, err := c.ParamUUID("id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid id"})
+		return
+	}
+	c.JSON(http.StatusOK, mygin.H{"bindings": a.Bindings(id), "permissions": a.Permissions(id, "")})
+}
+
+func (a *Authorizer) assign(c *mygin.Context) {
+	id, err := c.ParamUUID("id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid id"})
+		return
+	}
+	var req bindingRequest
+	if err := json.NewDecoder(c.Req.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid JSON body: " + err.Error()})
+		return
+	}
+	binding, err := a.Assign(id, req.Role, req.Scope)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, binding)
+}
+
+func (a *Authorizer) revoke(c *mygin.Context) {
+	id, err := c.ParamUUID("id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid id"})
+		return
+	}
+	if err := a.Revoke(id, c.Param("role"), c.GetQuery("scope")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,55 @@
+package authz
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/auth"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// ScopeFunc returns the scope a request acts on, or "" for global checks.
+type ScopeFunc func(c *mygin.Context) string
+
+// ScopeParam scopes requests to "<resource>/<value of param>", e.g.
+// ScopeParam("albums", "id") for "/albums/:id".
+func ScopeParam(resource, param string) ScopeFunc {
+	return func(c *mygin.Context) string {
+		if value := c.Param(param); value != "" {
+			return resource + "/" + value
+		}
+		return ""
+	}
+}
+
+// RequirePermission rejects requests whose user does not hold permission with
+// 403, or 401 without a user. It must run after auth's Require middleware:
+//
+//	api.PATCH("/albums/:id", h.Require(), az.RequirePermission("albums:write", authz.ScopeParam("albums", "id")), update)
+func (a *Authorizer) RequirePermission(permission string, scopes ...ScopeFunc) mygin.HandlerFunc {
+	if !ValidPermission(permission) {
+		panic("authz: invalid permission " + permission)
+	}
+	return func(c *mygin.Context) {
+		user, ok := auth.CurrentUser(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, mygin.H{"error": "authentication required"})
+			c.Abort()
+			return
+		}
+
+		var parts []string
+		for _, scope := range scopes {
+			if s := scope(c); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		if !a.Can(user.ID, permission, strings.Join(parts, "/")) {
+			logger.Debug("permission denied", "user", user.ID, "permission", permission, "path", c.Path)
+			c.JSON(http.StatusForbidden, mygin.H{"error": "permission denied", "permission": permission})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,474 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/scheduler"
+)
+
+// پکیج backup از کالکشن‌های ثبت‌شده به صورت زمان‌بندی‌شده اسنپ‌شات می‌گیرد، هر بکاپ را
+// به صورت یک فایل tar.gz همراه با manifest.json (هش SHA-256 هر فایل) در مقصد ذخیره می‌کند
+// و بکاپ‌های قدیمی را طبق سیاست نگهداری (N روزانه، هفتگی، ماهانه) پاک می‌کند.
+//
+//	svc := backup.New(dest, backup.Policy{Daily: 7, Weekly: 4})
+//	svc.Register(backup.Collection("photos", photoManager))
+//	sched.Cron("backup", "0 3 * * *", svc.Job())
+
+var logger = logging.For("backup")
+
+const (
+	namePrefix   = "backup-"
+	nameSuffix   = ".tar.gz"
+	nameLayout   = "20060102T150405.000Z"
+	manifestName = "manifest.json"
+)
+
+// Snapshotter is implemented by collection_manager_memory.Manager.
+type Snapshotter interface {
+	Snapshot(w io.Writer) (int64, error)
+}
+
+// Source adds its files to a backup archive.
+type Source interface {
+	Name() string
+	Backup(ctx context.Context, a *Archive) error
+}
+
+type collectionSource struct {
+	name string
+	s    Snapshotter
+}
+
+// Collection backs up a collection data file as <name>.db.
+func Collection(name string, s Snapshotter) Source {
+	return collectionSource{name: name, s: s}
+}
+
+func (c collectionSource) Name() string { return c.name }
+
+func (c collectionSource) Backup(_ context.Context, a *Archive) error {
+	return a.Add(c.name+".db", func(w io.Writer) error {
+		_, err := c.s.Snapshot(w)
+		return err
+	})
+}
+
+type dirSource struct {
+	name, dir string
+}
+
+// Dir backs up every regular file below dir under <name>/, e.g. a
+// collection_manager_json directory.
+func Dir(name, dir string) Source {
+	return dirSource{name: name, dir: dir}
+}
+
+func (d dirSource) Name() string { return d.name }
+
+func (d dirSource) Backup(ctx context.Context, a *Archive) error {
+	return filepath.WalkDir(d.dir, func(p string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(d.dir, p)
+		if err != nil {
+			return err
+		}
+		return a.Add(path.Join(d.name, filepath.ToSlash(rel)), func(w io.Writer) error {
+			file, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(w, file)
+			return err
+		})
+	})
+}
+
+// File is an archive member listed in the manifest.
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest is stored as manifest.json, the last member of every archive.
+type Manifest struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Files     []File    `json:"files"`
+}
+
+// Archive is the backup being written.
+type Archive struct {
+	tw       *tar.Writer
+	tmpDir   string
+	manifest Manifest
+}
+
+// Add stores the content produced by write under name. The content is spooled to a
+// temporary file first because tar needs the size up front.
+func (a *Archive) Add(name string, write func(w io.Writer) error) error {
+	temp, err := os.CreateTemp(a.tmpDir, "member-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	hasher := sha256.New()
+	if err := write(io.MultiWriter(temp, hasher)); err != nil {
+		return fmt.Errorf("error writing %s: %w", name, err)
+	}
+	size, err := temp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	header := &tar.Header{Name: name, Mode: 0644, Size: size, ModTime: a.manifest.CreatedAt, Typeflag: tar.TypeReg}
+	if err := a.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("error writing archive header: %w", err)
+	}
+	if _, err := io.Copy(a.tw, temp); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+	a.manifest.Files = append(a.manifest.Files, File{Name: name, Size: size, SHA256: hex.EncodeToString(hasher.Sum(nil))})
+	return nil
+}
+
+// Status describes the last backup run.
+type Status struct {
+	Running      bool          `json:"running"`
+	LastRun      time.Time     `json:"lastRun"`
+	LastSuccess  time.Time     `json:"lastSuccess"`
+	LastError    string        `json:"lastError,omitempty"`
+	LastBackup   string        `json:"lastBackup,omitempty"`
+	LastSize     int64         `json:"lastSize,omitempty"`
+	LastDuration time.Duration `json:"lastDuration,omitempty"`
+	Backups      int           `json:"backups"`
+	Pruned       int           `json:"pruned"` // deleted by the last run
+}
+
+// Service runs backups of the registered sources.
+type Service struct {
+	// MaxAge makes Check fail when the last successful backup is older (0 disables).
+	MaxAge time.Duration
+	// TempDir is used to spool archives before they are stored (os.TempDir when empty).
+	TempDir string
+
+	dest   Destination
+	policy Policy
+	now    func() time.Time
+
+	mu      sync.Mutex
+	sources []Source
+
+	runMu sync.Mutex // فقط یک اجرای هم‌زمان
+
+	statusMu sync.RWMutex
+	status   Status
+}
+
+// New returns a service storing backups in dest and pruning them with policy.
+func New(dest Destination, policy Policy) *Service {
+	return &Service{dest: dest, policy: policy, now: time.Now}
+}
+
+// Register adds a source to every following backup.
+func (s *Service) Register(sources ...Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources = append(s.sources, sources...)
+}
+
+// Job returns the backup as a scheduler job.
+func (s *Service) Job() scheduler.Job {
+	return func(ctx context.Context) error {
+		_, err := s.Run(ctx)
+		return err
+	}
+}
+
+// Run creates a backup, stores it and applies the retention policy. It returns the
+// name of the stored backup.
+func (s *Service) Run(ctx context.Context) (string, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	start := s.now()
+	s.updateStatus(func(st *Status) { st.Running = true })
+
+	name, size, err := s.create(ctx, start)
+	pruned := 0
+	if err == nil {
+		pruned, err = s.prune(ctx)
+	}
+	backups, listErr := s.List(ctx)
+
+	s.updateStatus(func(st *Status) {
+		st.Running = false
+		st.LastRun = start
+		st.LastDuration = s.now().Sub(start)
+		st.Pruned = pruned
+		if listErr == nil {
+			st.Backups = len(backups)
+		}
+		if err != nil {
+			st.LastError = err.Error()
+			return
+		}
+		st.LastError = ""
+		st.LastSuccess = start
+		st.LastBackup = name
+		st.LastSize = size
+	})
+	if err != nil {
+		logger.Error("backup failed", "error", err)
+		return "", err
+	}
+	logger.Info("backup stored", "name", name, "size", size, "pruned", pruned)
+	return name, nil
+}
+
+func (s *Service) create(ctx context.Context, at time.Time) (string, int64, error) {
+	s.mu.Lock()
+	sources := append([]Source(nil), s.sources...)
+	s.mu.Unlock()
+	if len(sources) == 0 {
+		return "", 0, errors.New("no backup sources registered")
+	}
+
+	tmpDir, err := os.MkdirTemp(s.TempDir, "backup-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	file, err := os.Create(filepath.Join(tmpDir, "archive"))
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating archive: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	archive := &Archive{tw: tar.NewWriter(gz), tmpDir: tmpDir, manifest: Manifest{CreatedAt: at.UTC()}}
+	for _, src := range sources {
+		if err := ctx.Err(); err != nil {
+			return "", 0, err
+		}
+		if err := src.Backup(ctx, archive); err != nil {
+			return "", 0, fmt.Errorf("error backing up %s: %w", src.Name(), err)
+		}
+	}
+
+	manifest, err := json.MarshalIndent(archive.manifest, "", "  ")
+	if err != nil {
+		return "", 0, err
+	}
+	header := &tar.Header{Name: manifestName, Mode: 0644, Size: int64(len(manifest)), ModTime: archive.manifest.CreatedAt}
+	if err := archive.tw.WriteHeader(header); err != nil {
+		return "", 0, err
+	}
+	if _, err := archive.tw.Write(manifest); err != nil {
+		return "", 0, err
+	}
+	if err := archive.tw.Close(); err != nil {
+		return "", 0, fmt.Errorf("error finishing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return "", 0, fmt.Errorf("error finishing archive: %w", err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	name := namePrefix + at.UTC().Format(nameLayout) + nameSuffix
+	if err := s.dest.Put(ctx, name, file); err != nil {
+		return "", 0, fmt.Errorf("error storing backup: %w", err)
+	}
+	return name, size, nil
+}
+
+// Backup is a stored backup.
+type Backup struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// List returns the stored backups, newest first. Files not created by the service
+// are ignored.
+func (s *Service) List(ctx context.Context) ([]Backup, error) {
+	names, err := s.dest.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, name := range names {
+		if at, ok := parseName(name); ok {
+			backups = append(backups, Backup{Name: name, CreatedAt: at})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+func (s *Service) prune(ctx context.Context) (int, error) {
+	backups, err := s.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	times := make([]time.Time, len(backups))
+	for i, b := range backups {
+		times[i] = b.CreatedAt
+	}
+	keep := s.policy.Keep(times)
+
+	deleted := 0
+	for i, b := range backups {
+		if keep[i] {
+			continue
+		}
+		if err := s.dest.Delete(ctx, b.Name); err != nil {
+			return deleted, fmt.Errorf("error deleting backup %s: %w", b.Name, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, namePrefix) || !strings.HasSuffix(name, nameSuffix) {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(nameLayout, strings.TrimSuffix(strings.TrimPrefix(name, namePrefix), nameSuffix))
+	return at, err == nil
+}
+
+// Status returns the state of the last run.
+func (s *Service) Status() Status {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+	return s.status
+}
+
+func (s *Service) updateStatus(fn func(*Status)) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	fn(&s.status)
+}
+
+// Check reports an error when the last run failed or, with MaxAge set, when there is
+// no recent successful backup. It is meant to be registered as a health check.
+func (s *Service) Check(_ context.Context) error {
+	st := s.Status()
+	if st.LastError != "" {
+		return fmt.Errorf("last backup failed: %s", st.LastError)
+	}
+	if s.MaxAge > 0 && (st.LastSuccess.IsZero() || s.now().Sub(st.LastSuccess) > s.MaxAge) {
+		return fmt.Errorf("no successful backup in the last %s", s.MaxAge)
+	}
+	return nil
+}
+
+// Restore extracts a stored backup into dir after verifying every file against the
+// manifest. Files are written to dir/<member name>; existing files are replaced.
+func (s *Service) Restore(ctx context.Context, name, dir string) (*Manifest, error) {
+	reader, err := s.dest.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	sums := make(map[string]string)
+	var manifest *Manifest
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup: %w", err)
+		}
+		if header.Name == manifestName {
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("error reading manifest: %w", err)
+			}
+			continue
+		}
+
+		clean := path.Clean(header.Name)
+		if header.Typeflag != tar.TypeReg || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, fmt.Errorf("unsafe backup member %q", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(clean))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		out, err := os.Create(target + ".restore")
+		if err != nil {
+			return nil, err
+		}
+		hasher := sha256.New()
+		_, err = io.Copy(io.MultiWriter(out, hasher), tr)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error restoring %s: %w", clean, err)
+		}
+		sums[clean] = hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	if manifest == nil {
+		return nil, errors.New("backup has no manifest")
+	}
+	for _, f := range manifest.Files {
+		if sums[f.Name] != f.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for %s", f.Name)
+		}
+	}
+	// فایل‌ها فقط بعد از بررسی همه هش‌ها جایگزین می‌شوند
+	for _, f := range manifest.Files {
+		target := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.Rename(target+".restore", target); err != nil {
+			return nil, fmt.Errorf("error restoring %s: %w", f.Name, err)
+		}
+	}
+	return manifest, nil
+}
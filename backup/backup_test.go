@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+type note struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+}
+
+func (n *note) SetID(id uuid.UUID) { n.ID = id }
+func (n *note) GetID() uuid.UUID   { return n.ID }
+func (n *note) GetRecordSize() int { return 128 }
+
+func TestRunAndRestore(t *testing.T) {
+	dataDir := t.TempDir()
+	notes, err := collection_manager_memory.New[*note](dataDir, "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notes.Close()
+	created, _ := notes.Create(&note{Title: "سلام"})
+	os.MkdirAll(filepath.Join(dataDir, "extra"), 0755)
+	os.WriteFile(filepath.Join(dataDir, "extra", "a.txt"), []byte("a"), 0644)
+
+	dest, err := NewDirDestination(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := New(dest, Policy{Daily: 2})
+	svc.Register(Collection("notes", notes), Dir("extra", filepath.Join(dataDir, "extra")))
+
+	ctx := context.Background()
+	name, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := svc.Status(); st.LastBackup != name || st.LastError != "" || svc.Check(ctx) != nil {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	restoreDir := t.TempDir()
+	manifest, err := svc.Restore(ctx, name, restoreDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", manifest.Files)
+	}
+	restored, err := collection_manager_memory.New[*note](restoreDir, "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	if got, err := restored.Read(created.ID); err != nil || got.Title != "سلام" {
+		t.Fatalf("restore mismatch: %v %v", got, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(restoreDir, "extra", "a.txt")); string(data) != "a" {
+		t.Fatalf("dir source not restored: %q", data)
+	}
+}
+
+func TestPolicyKeep(t *testing.T) {
+	base := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := 0; i < 30; i++ {
+		times = append(times, base.AddDate(0, 0, -i))
+	}
+	keep := Policy{Daily: 3, Weekly: 2}.Keep(times)
+	var kept []int
+	for i, k := range keep {
+		if k {
+			kept = append(kept, i)
+		}
+	}
+	// سه روز آخر، و آخرین بکاپ هفته قبل (یکشنبه 11 اکتبر)
+	want := []int{0, 1, 2, 5}
+	if len(kept) != len(want) {
+		t.Fatalf("kept %v, want %v", kept, want)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Fatalf("kept %v, want %v", kept, want)
+		}
+	}
+}
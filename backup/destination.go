@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNotFound = errors.New("backup not found")
+
+// Destination stores backup archives. Implementations for remote storage (e.g. S3)
+// only need these four methods.
+type Destination interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// DirDestination stores backups as files in a local directory.
+type DirDestination struct {
+	dir string
+}
+
+// NewDirDestination returns a destination writing into dir, creating it if needed.
+func NewDirDestination(dir string) (*DirDestination, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating backup directory: %w", err)
+	}
+	return &DirDestination{dir: dir}, nil
+}
+
+func (d *DirDestination) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid backup name %q", name)
+	}
+	return filepath.Join(d.dir, name), nil
+}
+
+// Put writes the archive to a temporary file and renames it, so a listed backup is
+// always complete.
+func (d *DirDestination) Put(_ context.Context, name string, r io.Reader) error {
+	target, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(d.dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, r); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), target)
+}
+
+func (d *DirDestination) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	target, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return file, err
+}
+
+func (d *DirDestination) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (d *DirDestination) Delete(_ context.Context, name string) error {
+	target, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Handler serves the backup status and the stored backups; it answers 503 when
+// Check fails so it can be polled by monitoring:
+//
+//	GET /admin/backup
+//
+//	{"status":{"lastSuccess":"...","lastBackup":"backup-...tar.gz",...},"backups":[...]}
+func (s *Service) Handler() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		backups, err := s.List(c.Req.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, mygin.H{"error": err.Error()})
+			return
+		}
+		status := http.StatusOK
+		body := mygin.H{"status": s.Status(), "backups": backups}
+		if err := s.Check(c.Req.Context()); err != nil {
+			status = http.StatusServiceUnavailable
+			body["error"] = err.Error()
+		}
+		c.JSON(status, body)
+	}
+}
+
+// Mount registers Handler on group as GET path.
+func (s *Service) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	handlers := append(append([]mygin.HandlerFunc{}, middleware...), s.Handler())
+	group.GET(path, handlers...)
+}
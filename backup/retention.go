@@ -0,0 +1,48 @@
+package backup
+
+import "time"
+
+// Policy decides which backups are kept: the newest backup of each of the last Daily
+// days, Weekly ISO weeks and Monthly months. The newest backup is always kept and a
+// zero policy keeps everything.
+type Policy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+// Keep returns which of times (sorted newest first) are retained.
+func (p Policy) Keep(times []time.Time) []bool {
+	keep := make([]bool, len(times))
+	if len(times) == 0 {
+		return keep
+	}
+	if p.Daily <= 0 && p.Weekly <= 0 && p.Monthly <= 0 {
+		for i := range keep {
+			keep[i] = true
+		}
+		return keep
+	}
+	keep[0] = true
+
+	period := func(limit int, key func(time.Time) [2]int) {
+		seen := make(map[[2]int]bool)
+		for i, t := range times {
+			if len(seen) >= limit {
+				return
+			}
+			k := key(t.UTC())
+			if !seen[k] {
+				seen[k] = true
+				keep[i] = true
+			}
+		}
+	}
+	period(p.Daily, func(t time.Time) [2]int { return [2]int{t.Year(), t.YearDay()} })
+	period(p.Weekly, func(t time.Time) [2]int {
+		year, week := t.ISOWeek()
+		return [2]int{year, week}
+	})
+	period(p.Monthly, func(t time.Time) [2]int { return [2]int{t.Year(), int(t.Month())} })
+	return keep
+}
@@ -0,0 +1,43 @@
+package benchmarks
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWorkloadsOnEveryEngine(t *testing.T) {
+	var reports []Report
+	for _, engine := range EngineNames() {
+		for _, workload := range []Workload{ReadHeavy, WriteHeavy, Mixed, LargeRecords} {
+			workload = workload.Scale(0.01)
+			report, err := Run(context.Background(), engine, workload, Options{Dir: t.TempDir()})
+			if err != nil {
+				t.Fatalf("%s/%s: %v", engine, workload.Name, err)
+			}
+			if report.Ops != workload.Ops || report.Errors != 0 {
+				t.Fatalf("%s/%s: %d ops, %d errors: %+v", engine, workload.Name, report.Ops, report.Errors, report.PerOp)
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteText(&out, reports); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "large-records") {
+		t.Fatalf("missing workload in report:\n%s", out.String())
+	}
+}
+
+func TestPlanIsReproducible(t *testing.T) {
+	ops, weights := mix(Mixed.Mix)
+	a, b := planFor(Mixed, ops, weights), planFor(Mixed, ops, weights)
+	for i := range a {
+		if strings.Join(a[i], ",") != strings.Join(b[i], ",") {
+			t.Fatalf("plans differ at %d", i)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package benchmarks
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+)
+
+// WriteText writes reports as one table row per engine, workload and op so runs on
+// different engines can be compared side by side.
+func WriteText(w io.Writer, reports []Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "workload\tengine\top\tcount\tops/s\tp50\tp90\tp99\tmax\terrors\talloc/op\t")
+	for _, r := range reports {
+		perOp := "-"
+		if r.Ops > 0 {
+			perOp = fmt.Sprintf("%dB", r.AllocBytes/uint64(r.Ops))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.0f\t\t\t\t\t%d\t%s\t\n", r.Workload, r.Engine, "all", r.Ops, r.Throughput, r.Errors, perOp)
+
+		ops := make([]string, 0, len(r.PerOp))
+		for op := range r.PerOp {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+		for _, op := range ops {
+			s := r.PerOp[op]
+			fmt.Fprintf(tw, "\t\t%s\t%d\t\t%v\t%v\t%v\t%v\t%d\t\t\n", op, s.Count, s.P50, s.P90, s.P99, s.Max, s.Errors)
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes reports as a JSON array, e.g. to keep a baseline for later runs.
+func WriteJSON(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
@@ -0,0 +1,170 @@
+package benchmarks
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_bolt"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_index"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_json"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_sqlite"
+)
+
+// itemRecordSize is returned by Item.GetRecordSize. Managers that read the record size
+// from the zero value of T (index) pick it up when they are opened, so stores with
+// different record sizes must not be opened concurrently.
+var itemRecordSize = 256
+
+// Item is the record written by the workloads.
+type Item struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name" index:"true"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (b *Item) SetID(id uuid.UUID)       { b.ID = id }
+func (b *Item) GetID() uuid.UUID         { return b.ID }
+func (b *Item) SetCreatedAt(t time.Time) { b.CreatedAt = t }
+func (b *Item) SetUpdatedAt(t time.Time) { b.UpdatedAt = t }
+func (b *Item) GetRecordSize() int       { return itemRecordSize }
+
+// indexItem is the index record used with collection_manager_index.
+type indexItem struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+func (b *indexItem) SetID(id uuid.UUID)     { b.ID = id }
+func (b *indexItem) GetID() uuid.UUID       { return b.ID }
+func (b *indexItem) SetCreatedAt(time.Time) {}
+func (b *indexItem) SetUpdatedAt(time.Time) {}
+func (b *indexItem) GetRecordSize() int     { return 128 }
+
+// Store adapts the different managers to the operations the workloads need.
+type Store interface {
+	Create(item *Item) (uuid.UUID, error)
+	Read(id uuid.UUID) error
+	Update(item *Item) error
+	Delete(id uuid.UUID) error
+	Close() error
+}
+
+// crud is the method set shared by the managers keyed by UUID.
+type crud interface {
+	Create(item *Item) (*Item, error)
+	Read(id uuid.UUID) (*Item, error)
+	Update(item *Item) (*Item, error)
+	Delete(id uuid.UUID) error
+}
+
+type managerStore struct {
+	m     crud
+	close func() error
+}
+
+func (s managerStore) Create(item *Item) (uuid.UUID, error) {
+	created, err := s.m.Create(item)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return created.GetID(), nil
+}
+func (s managerStore) Read(id uuid.UUID) error { _, err := s.m.Read(id); return err }
+func (s managerStore) Update(item *Item) error {
+	_, err := s.m.Update(item)
+	return err
+}
+func (s managerStore) Delete(id uuid.UUID) error { return s.m.Delete(id) }
+func (s managerStore) Close() error              { return s.close() }
+
+type jsonStore struct {
+	m *collection_manager_json.Manager[*Item]
+}
+
+func (s jsonStore) Create(item *Item) (uuid.UUID, error) {
+	// collection_manager_json expects the caller to assign the ID
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	item.SetID(id)
+	if _, err := s.m.Create(item); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+func (s jsonStore) Read(id uuid.UUID) error { _, err := s.m.Read(id); return err }
+func (s jsonStore) Update(item *Item) error {
+	_, err := s.m.Update(item)
+	return err
+}
+func (s jsonStore) Delete(id uuid.UUID) error { return s.m.Delete(id) }
+func (s jsonStore) Close() error              { return nil }
+
+// Opener opens a store of one engine in an empty directory.
+type Opener func(dir string, recordSize int) (Store, error)
+
+// Engines are the backends a workload can run against.
+var Engines = map[string]Opener{
+	"memory": func(dir string, recordSize int) (Store, error) {
+		m, err := collection_manager_memory.NewWithRecordSize[*Item](dir, "bench", recordSize)
+		if err != nil {
+			return nil, err
+		}
+		return managerStore{m: m, close: m.Close}, nil
+	},
+	"index": func(dir string, recordSize int) (Store, error) {
+		itemRecordSize = recordSize
+		m, err := collection_manager_index.New[*Item, *indexItem](dir)
+		if err != nil {
+			return nil, err
+		}
+		return managerStore{m: m, close: m.Close}, nil
+	},
+	"json": func(dir string, _ int) (Store, error) {
+		m, err := collection_manager_json.New[*Item](dir)
+		if err != nil {
+			return nil, err
+		}
+		return jsonStore{m: m}, nil
+	},
+	"sqlite": func(dir string, _ int) (Store, error) {
+		m, err := collection_manager_sqlite.New[*Item](filepath.Join(dir, "bench.sqlite"), "bench")
+		if err != nil {
+			return nil, err
+		}
+		return managerStore{m: m, close: m.Close}, nil
+	},
+	"bolt": func(dir string, _ int) (Store, error) {
+		m, err := collection_manager_bolt.New[*Item](filepath.Join(dir, "bench.bolt"), "bench")
+		if err != nil {
+			return nil, err
+		}
+		return managerStore{m: m, close: m.Close}, nil
+	},
+}
+
+// EngineNames returns the names of Engines in sorted order.
+func EngineNames() []string {
+	names := make([]string, 0, len(Engines))
+	for name := range Engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens engine in dir.
+func Open(engine, dir string, recordSize int) (Store, error) {
+	open, ok := Engines[engine]
+	if !ok {
+		return nil, fmt.Errorf("unknown engine %q (%v)", engine, EngineNames())
+	}
+	return open(dir, recordSize)
+}
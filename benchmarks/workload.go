@@ -0,0 +1,349 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// پکیج benchmarks بارهای کاری تکرارپذیر (خواندن‌محور، نوشتن‌محور، ترکیبی، رکورد بزرگ)
+// را روی هر موتور ذخیره‌سازی اجرا می‌کند و گزارش‌های قابل مقایسه می‌سازد:
+//
+//	report, err := benchmarks.Run(ctx, "memory", benchmarks.Mixed, benchmarks.Options{})
+//	benchmarks.WriteText(os.Stdout, []benchmarks.Report{report})
+//
+// دنباله عملیات هر worker از Seed ساخته می‌شود و هر worker فقط کلیدهای خودش را می‌بیند،
+// پس دو اجرا با تنظیمات یکسان دقیقاً همان عملیات را انجام می‌دهند.
+
+// Op names used in workload mixes and reports.
+const (
+	OpCreate = "create"
+	OpRead   = "read"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Workload describes a reproducible benchmark run.
+type Workload struct {
+	Name string
+	// Preload items are created before measuring; they are not part of the report.
+	Preload int
+	// Ops is the number of measured operations.
+	Ops int
+	// Mix gives the relative weight of each op name.
+	Mix map[string]int
+	// RecordSize is the record size of the stores; PayloadSize bytes of it are payload.
+	RecordSize  int
+	PayloadSize int
+	Concurrency int
+	Seed        int64
+}
+
+var (
+	ReadHeavy = Workload{Name: "read-heavy", Preload: 2000, Ops: 20000,
+		Mix: map[string]int{OpRead: 95, OpUpdate: 5}, RecordSize: 512, PayloadSize: 100, Concurrency: 4, Seed: 1}
+	WriteHeavy = Workload{Name: "write-heavy", Preload: 1000, Ops: 5000,
+		Mix: map[string]int{OpCreate: 40, OpUpdate: 40, OpDelete: 10, OpRead: 10}, RecordSize: 512, PayloadSize: 100, Concurrency: 4, Seed: 1}
+	Mixed = Workload{Name: "mixed", Preload: 2000, Ops: 10000,
+		Mix: map[string]int{OpRead: 50, OpUpdate: 25, OpCreate: 15, OpDelete: 10}, RecordSize: 512, PayloadSize: 100, Concurrency: 4, Seed: 1}
+	LargeRecords = Workload{Name: "large-records", Preload: 500, Ops: 2000,
+		Mix: map[string]int{OpRead: 50, OpUpdate: 30, OpCreate: 20}, RecordSize: 16384, PayloadSize: 12000, Concurrency: 4, Seed: 1}
+)
+
+// Workloads are the predefined workloads by name.
+var Workloads = map[string]Workload{
+	ReadHeavy.Name:    ReadHeavy,
+	WriteHeavy.Name:   WriteHeavy,
+	Mixed.Name:        Mixed,
+	LargeRecords.Name: LargeRecords,
+}
+
+// Scale returns w with Preload and Ops multiplied by f, e.g. for quick runs.
+func (w Workload) Scale(f float64) Workload {
+	w.Preload = max(1, int(float64(w.Preload)*f))
+	w.Ops = max(1, int(float64(w.Ops)*f))
+	return w
+}
+
+// Options controls a run.
+type Options struct {
+	// Dir is the data directory (default: a temporary directory that is removed).
+	Dir string
+	// CPUProfile and MemProfile write pprof profiles of the measured phase.
+	CPUProfile string
+	MemProfile string
+}
+
+// OpStats summarizes the latencies of one op name.
+type OpStats struct {
+	Count  int           `json:"count"`
+	Errors int           `json:"errors"`
+	P50    time.Duration `json:"p50"`
+	P90    time.Duration `json:"p90"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+}
+
+// Report is the result of one workload on one engine.
+type Report struct {
+	Engine     string             `json:"engine"`
+	Workload   string             `json:"workload"`
+	Ops        int                `json:"ops"`
+	Errors     int                `json:"errors"`
+	Elapsed    time.Duration      `json:"elapsed"`
+	Throughput float64            `json:"throughput"` // ops per second
+	AllocBytes uint64             `json:"allocBytes"` // allocated during the measured phase
+	Allocs     uint64             `json:"allocs"`
+	PerOp      map[string]OpStats `json:"perOp"`
+}
+
+type step struct {
+	op  string
+	key int // index into the worker's live keys
+}
+
+type sample struct {
+	op      string
+	latency time.Duration
+	err     bool
+}
+
+// worker owns a disjoint part of the key space so concurrent deletes never race with
+// reads of other workers.
+type worker struct {
+	store   Store
+	payload string
+	items   []*Item
+	rng     *rand.Rand
+	created int
+}
+
+func (w *worker) create() error {
+	item := &Item{Name: fmt.Sprintf("item-%d", w.created), Payload: w.payload}
+	w.created++
+	if _, err := w.store.Create(item); err != nil {
+		return err
+	}
+	w.items = append(w.items, item)
+	return nil
+}
+
+func (w *worker) do(op string) error {
+	if op != OpCreate && len(w.items) == 0 {
+		op = OpCreate
+	}
+	switch op {
+	case OpCreate:
+		return w.create()
+	case OpRead:
+		return w.store.Read(w.items[w.rng.Intn(len(w.items))].ID)
+	case OpUpdate:
+		updated := *w.items[w.rng.Intn(len(w.items))]
+		updated.Name += "'"
+		return w.store.Update(&updated)
+	case OpDelete:
+		i := w.rng.Intn(len(w.items))
+		id := w.items[i].ID
+		w.items[i] = w.items[len(w.items)-1]
+		w.items = w.items[:len(w.items)-1]
+		return w.store.Delete(id)
+	default:
+		return fmt.Errorf("unknown op %q", op)
+	}
+}
+
+// Run executes workload against engine and returns its report.
+func Run(ctx context.Context, engine string, workload Workload, opts Options) (Report, error) {
+	report := Report{Engine: engine, Workload: workload.Name, PerOp: make(map[string]OpStats)}
+	if workload.Concurrency <= 0 {
+		workload.Concurrency = 1
+	}
+	if workload.RecordSize <= 0 {
+		workload.RecordSize = 256
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "iris-bench-")
+		if err != nil {
+			return report, err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+	store, err := Open(engine, dir, workload.RecordSize)
+	if err != nil {
+		return report, err
+	}
+	defer store.Close()
+
+	ops, weights := mix(workload.Mix)
+	if len(ops) == 0 {
+		return report, fmt.Errorf("workload %s has no ops", workload.Name)
+	}
+
+	payload := strings.Repeat("x", workload.PayloadSize)
+	plans := planFor(workload, ops, weights)
+	workers := make([]*worker, workload.Concurrency)
+	for i := range workers {
+		// کلیدها با منبع تصادفی جدا از برنامه انتخاب می‌شوند
+		rng := rand.New(rand.NewSource(workload.Seed*7919 + int64(i) + 1))
+		workers[i] = &worker{store: store, payload: payload, rng: rng}
+	}
+
+	// پیش‌بارگذاری خارج از اندازه‌گیری
+	for n := 0; n < workload.Preload; n++ {
+		if err := workers[n%len(workers)].create(); err != nil {
+			return report, fmt.Errorf("error preloading: %w", err)
+		}
+	}
+
+	stopProfile, err := startProfile(opts)
+	if err != nil {
+		return report, err
+	}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	samples := make([][]sample, len(workers))
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, op := range plans[i] {
+				if ctx.Err() != nil {
+					return
+				}
+				opStart := time.Now()
+				err := w.do(op)
+				samples[i] = append(samples[i], sample{op: op, latency: time.Since(opStart), err: err != nil})
+			}
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+
+	runtime.ReadMemStats(&after)
+	if err := stopProfile(); err != nil {
+		return report, err
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	report.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	report.Allocs = after.Mallocs - before.Mallocs
+	latencies := make(map[string][]time.Duration)
+	for _, list := range samples {
+		for _, s := range list {
+			latencies[s.op] = append(latencies[s.op], s.latency)
+			stats := report.PerOp[s.op]
+			if s.err {
+				stats.Errors++
+				report.Errors++
+			}
+			report.PerOp[s.op] = stats
+			report.Ops++
+		}
+	}
+	for op, list := range latencies {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+		stats := report.PerOp[op]
+		stats.Count = len(list)
+		stats.P50, stats.P90, stats.P99, stats.Max = percentile(list, 0.50), percentile(list, 0.90), percentile(list, 0.99), list[len(list)-1]
+		report.PerOp[op] = stats
+	}
+	if report.Elapsed > 0 {
+		report.Throughput = float64(report.Ops) / report.Elapsed.Seconds()
+	}
+	return report, nil
+}
+
+// planFor returns the op sequence of every worker; it only depends on the workload.
+func planFor(workload Workload, ops []string, weights []int) [][]string {
+	plans := make([][]string, workload.Concurrency)
+	for i := range plans {
+		rng := rand.New(rand.NewSource(workload.Seed + int64(i)))
+		for n := i; n < workload.Ops; n += workload.Concurrency {
+			plans[i] = append(plans[i], pick(rng, ops, weights))
+		}
+	}
+	return plans
+}
+
+// mix returns the op names in a fixed order with their weights.
+func mix(m map[string]int) ([]string, []int) {
+	var ops []string
+	for op, weight := range m {
+		if weight > 0 {
+			ops = append(ops, op)
+		}
+	}
+	sort.Strings(ops)
+	weights := make([]int, len(ops))
+	for i, op := range ops {
+		weights[i] = m[op]
+	}
+	return ops, weights
+}
+
+func pick(rng *rand.Rand, ops []string, weights []int) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	n := rng.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return ops[i]
+		}
+		n -= w
+	}
+	return ops[len(ops)-1]
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+func startProfile(opts Options) (stop func() error, err error) {
+	var cpu *os.File
+	if opts.CPUProfile != "" {
+		if cpu, err = os.Create(opts.CPUProfile); err != nil {
+			return nil, fmt.Errorf("error creating CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			return nil, fmt.Errorf("error starting CPU profile: %w", err)
+		}
+	}
+	return func() error {
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			cpu.Close()
+		}
+		if opts.MemProfile == "" {
+			return nil
+		}
+		file, err := os.Create(opts.MemProfile)
+		if err != nil {
+			return fmt.Errorf("error creating memory profile: %w", err)
+		}
+		defer file.Close()
+		runtime.GC()
+		return pprof.WriteHeapProfile(file)
+	}, nil
+}
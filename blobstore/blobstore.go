@@ -0,0 +1,185 @@
+package blobstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// پکیج blobstore بایت‌های فایل‌ها (مثلاً خود عکس‌ها) را بر اساس هش SHA-256 محتوا ذخیره
+// می‌کند. فایل‌های تکراری یک بار ذخیره می‌شوند، ارجاع رکوردهای کالکشن به هر blob شمرده
+// می‌شود و GC بلاب‌های بدون ارجاع را پاک می‌کند.
+//
+// ساختار پوشه:
+//
+//	<dir>/objects/ab/abcdef...   محتوای blob با نام هش
+//	<dir>/tmp/                   فایل‌های در حال نوشتن
+//	<dir>/blob_refs.db           ارجاع‌ها (collection_manager_memory)
+
+var (
+	ErrNotFound    = errors.New("blob not found")
+	ErrInvalidHash = errors.New("invalid blob hash")
+)
+
+// Blob describes a stored blob.
+type Blob struct {
+	Hash    string    `json:"hash"` // hex SHA-256
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// Put writes the content of r and returns its blob. Identical content is stored once.
+func (s *Store) Put(r io.Reader) (Blob, error) {
+	temp, err := os.CreateTemp(s.tmpDir(), "put-*")
+	if err != nil {
+		return Blob{}, fmt.Errorf("error creating temp file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(temp, hasher), r)
+	if err != nil {
+		temp.Close()
+		return Blob{}, fmt.Errorf("error writing blob: %w", err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return Blob{}, fmt.Errorf("error syncing blob: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return Blob{}, fmt.Errorf("error closing blob: %w", err)
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	path := s.objectPath(hash)
+	if info, err := os.Stat(path); err == nil {
+		// محتوای تکراری؛ زمان را به‌روز می‌کنیم تا GC آن را تازه در نظر بگیرد
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return Blob{Hash: hash, Size: info.Size(), ModTime: now}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Blob{}, fmt.Errorf("error creating object directory: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return Blob{}, fmt.Errorf("error storing blob: %w", err)
+	}
+	os.Chmod(path, 0444)
+	return Blob{Hash: hash, Size: size, ModTime: time.Now()}, nil
+}
+
+// PutBytes stores data.
+func (s *Store) PutBytes(data []byte) (Blob, error) {
+	return s.Put(bytes.NewReader(data))
+}
+
+// Open returns a reader for a blob. The caller must close it.
+func (s *Store) Open(hash string) (*os.File, error) {
+	if !ValidHash(hash) {
+		return nil, ErrInvalidHash
+	}
+	file, err := os.Open(s.objectPath(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, hash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening blob: %w", err)
+	}
+	return file, nil
+}
+
+// Stat returns the blob metadata.
+func (s *Store) Stat(hash string) (Blob, error) {
+	if !ValidHash(hash) {
+		return Blob{}, ErrInvalidHash
+	}
+	info, err := os.Stat(s.objectPath(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return Blob{}, fmt.Errorf("%w: %s", ErrNotFound, hash)
+	}
+	if err != nil {
+		return Blob{}, fmt.Errorf("error reading blob info: %w", err)
+	}
+	return Blob{Hash: hash, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Exists reports whether a blob is stored.
+func (s *Store) Exists(hash string) bool {
+	_, err := s.Stat(hash)
+	return err == nil
+}
+
+// Verify re-hashes a stored blob and reports whether its content is intact.
+func (s *Store) Verify(hash string) (bool, error) {
+	file, err := s.Open(hash)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return false, fmt.Errorf("error reading blob: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)) == hash, nil
+}
+
+// Walk calls fn for every stored blob.
+func (s *Store) Walk(fn func(Blob) error) error {
+	root := filepath.Join(s.dir, "objects")
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) && path == root {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		hash := d.Name()
+		if !ValidHash(hash) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(Blob{Hash: hash, Size: info.Size(), ModTime: info.ModTime()})
+	})
+}
+
+// ValidHash reports whether hash is a lowercase hex SHA-256.
+func ValidHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for i := 0; i < len(hash); i++ {
+		c := hash[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash[:2], hash)
+}
+
+func (s *Store) tmpDir() string {
+	return filepath.Join(s.dir, "tmp")
+}
+
+func (s *Store) remove(hash string) error {
+	path := s.objectPath(hash)
+	os.Chmod(path, 0644)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing blob: %w", err)
+	}
+	os.Remove(filepath.Dir(path)) // فقط اگر خالی باشد حذف می‌شود
+	return nil
+}
@@ -0,0 +1,77 @@
+package blobstore
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+type photo struct {
+	ID       uuid.UUID `json:"id"`
+	BlobHash string    `json:"blobHash"`
+}
+
+func (p *photo) SetID(id uuid.UUID) { p.ID = id }
+func (p *photo) GetID() uuid.UUID   { return p.ID }
+func (p *photo) GetRecordSize() int { return 200 }
+
+func TestPutDedupAndGC(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	a, err := store.PutBytes([]byte("jpeg bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := store.PutBytes([]byte("jpeg bytes"))
+	if a.Hash != b.Hash || a.Size != 10 {
+		t.Fatalf("expected deduplicated blob, got %+v %+v", a, b)
+	}
+
+	file, err := store.Open(a.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != "jpeg bytes" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	photos, err := collection_manager_memory.New[*photo](t.TempDir(), "photos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer photos.Close()
+	stop := Track(store, "photos", photos, func(p *photo) []string { return []string{p.BlobHash} })
+	defer stop()
+
+	p, err := photos.Create(&photo{BlobHash: a.Hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.RefCount(a.Hash) != 1 {
+		t.Fatalf("expected 1 reference, got %d", store.RefCount(a.Hash))
+	}
+
+	orphan, _ := store.PutBytes([]byte("orphan"))
+	if result, err := store.GC(0); err != nil || result.Removed != 1 || store.Exists(orphan.Hash) || !store.Exists(a.Hash) {
+		t.Fatalf("unexpected GC result %+v %v", result, err)
+	}
+
+	if err := photos.Delete(p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := store.GC(time.Hour); result.Removed != 0 {
+		t.Fatal("grace period should protect recent blobs")
+	}
+	if result, _ := store.GC(0); result.Removed != 1 || store.Exists(a.Hash) {
+		t.Fatalf("expected unreferenced blob to be collected, got %+v", result)
+	}
+}
@@ -0,0 +1,245 @@
+package blobstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+var logger = logging.For("blobstore")
+
+// Ref records that owner (e.g. "photos/<id>") uses a blob.
+type Ref struct {
+	ID        uuid.UUID `json:"id"`
+	Hash      string    `json:"hash"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (r *Ref) SetID(id uuid.UUID)       { r.ID = id }
+func (r *Ref) GetID() uuid.UUID         { return r.ID }
+func (r *Ref) SetCreatedAt(t time.Time) { r.CreatedAt = t }
+func (r *Ref) SetUpdatedAt(t time.Time) { r.UpdatedAt = t }
+func (r *Ref) GetRecordSize() int       { return 512 }
+
+// Store is a content-addressable blob store with reference counting.
+type Store struct {
+	dir  string
+	refs *collection_manager_memory.Manager[*Ref]
+
+	mu      sync.Mutex
+	byOwner map[string]map[string]uuid.UUID // owner -> hash -> ref id
+	counts  map[string]int                  // hash -> number of owners
+}
+
+// Open opens (or creates) a blob store in dir.
+func Open(dir string) (*Store, error) {
+	s := &Store{dir: dir, byOwner: make(map[string]map[string]uuid.UUID), counts: make(map[string]int)}
+	if err := os.MkdirAll(s.tmpDir(), 0755); err != nil {
+		return nil, fmt.Errorf("error creating blob store directory: %w", err)
+	}
+
+	refs, err := collection_manager_memory.New[*Ref](dir, "blob_refs")
+	if err != nil {
+		return nil, fmt.Errorf("error opening blob_refs collection: %w", err)
+	}
+	all, err := refs.ReadAll()
+	if err != nil {
+		refs.Close()
+		return nil, err
+	}
+	for _, ref := range all {
+		s.track(ref)
+	}
+	s.refs = refs
+	return s, nil
+}
+
+// Close closes the reference collection.
+func (s *Store) Close() error {
+	return s.refs.Close()
+}
+
+// AddRef records that owner uses hash. Adding the same reference twice is a no-op.
+func (s *Store) AddRef(hash, owner string) error {
+	if !ValidHash(hash) {
+		return ErrInvalidHash
+	}
+	if !s.Exists(hash) {
+		return fmt.Errorf("%w: %s", ErrNotFound, hash)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byOwner[owner][hash]; ok {
+		return nil
+	}
+	ref, err := s.refs.Create(&Ref{Hash: hash, Owner: owner})
+	if err != nil {
+		return fmt.Errorf("error storing blob reference: %w", err)
+	}
+	s.track(ref)
+	return nil
+}
+
+// RemoveRef drops the reference of owner to hash. The blob itself is removed by GC.
+func (s *Store) RemoveRef(hash, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.byOwner[owner][hash]
+	if !ok {
+		return nil
+	}
+	if err := s.refs.Delete(id); err != nil {
+		return fmt.Errorf("error removing blob reference: %w", err)
+	}
+	s.untrack(owner, hash)
+	return nil
+}
+
+// SetRefs makes hashes the exact set of blobs referenced by owner.
+func (s *Store) SetRefs(owner string, hashes ...string) error {
+	want := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		if hash != "" {
+			want[hash] = true
+		}
+	}
+
+	s.mu.Lock()
+	var stale []string
+	for hash := range s.byOwner[owner] {
+		if !want[hash] {
+			stale = append(stale, hash)
+		}
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, hash := range stale {
+		errs = append(errs, s.RemoveRef(hash, owner))
+	}
+	for hash := range want {
+		errs = append(errs, s.AddRef(hash, owner))
+	}
+	return errors.Join(errs...)
+}
+
+// RemoveOwner drops every reference of owner, e.g. when its record is deleted.
+func (s *Store) RemoveOwner(owner string) error {
+	return s.SetRefs(owner)
+}
+
+// RefCount returns the number of owners referencing hash.
+func (s *Store) RefCount(hash string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[hash]
+}
+
+// Refs returns the blobs referenced by owner.
+func (s *Store) Refs(owner string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hashes := make([]string, 0, len(s.byOwner[owner]))
+	for hash := range s.byOwner[owner] {
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+// GCResult reports what a garbage collection removed.
+type GCResult struct {
+	Scanned int
+	Removed int
+	Freed   int64
+}
+
+// GC removes blobs without references that were last written more than grace
+// ago; the grace period protects uploads whose record has not been saved yet.
+// Stale temp files older than grace are removed too.
+func (s *Store) GC(grace time.Duration) (GCResult, error) {
+	var result GCResult
+	cutoff := time.Now().Add(-grace)
+
+	var candidates []Blob
+	err := s.Walk(func(b Blob) error {
+		result.Scanned++
+		if b.ModTime.Before(cutoff) && s.RefCount(b.Hash) == 0 {
+			candidates = append(candidates, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("error scanning blobs: %w", err)
+	}
+
+	for _, b := range candidates {
+		s.mu.Lock()
+		referenced := s.counts[b.Hash] > 0
+		if !referenced {
+			if err := s.remove(b.Hash); err != nil {
+				s.mu.Unlock()
+				return result, err
+			}
+			result.Removed++
+			result.Freed += b.Size
+		}
+		s.mu.Unlock()
+	}
+
+	if entries, err := os.ReadDir(s.tmpDir()); err == nil {
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(filepath.Join(s.tmpDir(), entry.Name()))
+			}
+		}
+	}
+	return result, nil
+}
+
+// track and untrack must be called with s.mu held (or before the store is shared).
+func (s *Store) track(ref *Ref) {
+	if s.byOwner[ref.Owner] == nil {
+		s.byOwner[ref.Owner] = make(map[string]uuid.UUID)
+	}
+	s.byOwner[ref.Owner][ref.Hash] = ref.ID
+	s.counts[ref.Hash]++
+}
+
+func (s *Store) untrack(owner, hash string) {
+	delete(s.byOwner[owner], hash)
+	if len(s.byOwner[owner]) == 0 {
+		delete(s.byOwner, owner)
+	}
+	if s.counts[hash]--; s.counts[hash] <= 0 {
+		delete(s.counts, hash)
+	}
+}
+
+// Track keeps the references of a collection in sync with its records: every
+// change of manager sets the refs of "<collection>/<id>" to hashes(item).
+// Hooks run under the manager lock, so hashes must not call back into manager.
+// The returned function stops tracking.
+func Track[T collection_manager_memory.CollectionItem](s *Store, collection string, manager *collection_manager_memory.Manager[T], hashes func(T) []string) (stop func()) {
+	return manager.OnChange(func(change collection_manager_memory.Change[T]) {
+		owner := collection + "/" + change.ID.String()
+		var err error
+		if change.Type == collection_manager_memory.ChangeDelete {
+			err = s.RemoveOwner(owner)
+		} else {
+			err = s.SetRefs(owner, hashes(change.Item)...)
+		}
+		if err != nil {
+			logger.Error("error syncing blob references", "owner", owner, "error", err)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// پکیج cache یک کش عمومی درون‌حافظه‌ای با انقضای TTL، حذف LRU و بارگذاری
+// singleflight است (چند درخواست هم‌زمان برای یک کلید فقط یک بار loader را اجرا می‌کنند).
+// برای میان‌افزار کش پاسخ، حالت بارگذاری تنبل ذخیره‌سازی و کد برنامه قابل استفاده است.
+
+// Options configures a cache.
+type Options[K comparable, V any] struct {
+	// Size is the maximum number of entries; the least recently used entry is
+	// evicted when it is exceeded. Required.
+	Size int
+	// TTL is the default lifetime of an entry (0 = no expiry).
+	TTL time.Duration
+	// OnEvict is called when an entry is evicted for space, expired or deleted.
+	OnEvict func(key K, value V)
+	// Now returns the current time; defaults to time.Now.
+	Now func() time.Time
+}
+
+// Stats are the counters of a cache.
+type Stats struct {
+	Hits       uint64
+	Misses     uint64
+	Evictions  uint64 // removed to make room for new entries
+	Expired    uint64
+	Loads      uint64
+	LoadErrors uint64
+	Size       int
+}
+
+// HitRatio returns hits / (hits + misses), or 0 without lookups.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time // zero = never
+}
+
+// call is an in-flight load shared by concurrent GetOrLoad callers.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Cache is a size-bounded LRU cache with per-entry TTL. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	lru     *lru.Cache[K, entry[V]]
+	ttl     time.Duration
+	now     func() time.Time
+	onEvict func(K, V)
+
+	loadMu   sync.Mutex
+	inflight map[K]*call[V]
+
+	hits, misses, evictions, expired, loads, loadErrors atomic.Uint64
+}
+
+// New returns an empty cache.
+func New[K comparable, V any](opts Options[K, V]) (*Cache[K, V], error) {
+	if opts.Size <= 0 {
+		return nil, fmt.Errorf("cache size must be positive, got %d", opts.Size)
+	}
+	c := &Cache[K, V]{
+		ttl:      opts.TTL,
+		now:      opts.Now,
+		onEvict:  opts.OnEvict,
+		inflight: make(map[K]*call[V]),
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+
+	l, err := lru.NewWithEvict(opts.Size, func(key K, e entry[V]) {
+		if c.onEvict != nil {
+			c.onEvict(key, e.value)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating LRU: %w", err)
+	}
+	c.lru = l
+	return c, nil
+}
+
+// Get returns the value stored under key if it exists and has not expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	e, ok := c.lru.Get(key)
+	if ok && c.isExpired(e) {
+		c.remove(key)
+		c.expired.Add(1)
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+	c.hits.Add(1)
+	return e.value, true
+}
+
+// Set stores value with the default TTL.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores value with a specific TTL (0 = no expiry).
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	e := entry[V]{value: value}
+	if ttl > 0 {
+		e.expiresAt = c.now().Add(ttl)
+	}
+	if c.lru.Add(key, e) {
+		c.evictions.Add(1)
+	}
+}
+
+// Delete removes key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	c.remove(key)
+}
+
+// Len returns the number of entries, including expired ones not yet removed.
+func (c *Cache[K, V]) Len() int {
+	return c.lru.Len()
+}
+
+// Resize changes the maximum number of entries and returns how many of the least
+// recently used entries were evicted to fit the new size.
+func (c *Cache[K, V]) Resize(size int) (int, error) {
+	if size <= 0 {
+		return 0, fmt.Errorf("cache size must be positive, got %d", size)
+	}
+	evicted := c.lru.Resize(size)
+	c.evictions.Add(uint64(evicted))
+	return evicted, nil
+}
+
+// Purge removes every entry.
+func (c *Cache[K, V]) Purge() {
+	c.lru.Purge()
+}
+
+// PurgeExpired removes every expired entry and returns how many were removed.
+// Expired entries are otherwise removed lazily by Get.
+func (c *Cache[K, V]) PurgeExpired() int {
+	removed := 0
+	for _, key := range c.lru.Keys() {
+		if e, ok := c.lru.Peek(key); ok && c.isExpired(e) {
+			c.remove(key)
+			removed++
+		}
+	}
+	c.expired.Add(uint64(removed))
+	return removed
+}
+
+// GetOrLoad returns the cached value or calls load to produce it. Concurrent
+// calls for the same missing key share a single load. Errors are not cached.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context, key K) (V, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	c.loadMu.Lock()
+	if inflight, ok := c.inflight[key]; ok {
+		c.loadMu.Unlock()
+		select {
+		case <-inflight.done:
+			return inflight.value, inflight.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	current := &call[V]{done: make(chan struct{})}
+	c.inflight[key] = current
+	c.loadMu.Unlock()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				current.err = fmt.Errorf("cache loader panicked: %v", r)
+			}
+		}()
+		current.value, current.err = load(ctx, key)
+	}()
+
+	c.loads.Add(1)
+	if current.err != nil {
+		c.loadErrors.Add(1)
+	} else {
+		c.Set(key, current.value)
+	}
+
+	c.loadMu.Lock()
+	delete(c.inflight, key)
+	c.loadMu.Unlock()
+	close(current.done)
+
+	return current.value, current.err
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Evictions:  c.evictions.Load(),
+		Expired:    c.expired.Load(),
+		Loads:      c.loads.Load(),
+		LoadErrors: c.loadErrors.Load(),
+		Size:       c.lru.Len(),
+	}
+}
+
+func (c *Cache[K, V]) isExpired(e entry[V]) bool {
+	return !e.expiresAt.IsZero() && !c.now().Before(e.expiresAt)
+}
+
+func (c *Cache[K, V]) remove(key K) {
+	c.lru.Remove(key)
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTTLAndLRU(t *testing.T) {
+	now := time.Unix(0, 0)
+	c, err := New(Options[string, int]{Size: 2, TTL: time.Minute, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")    // a بیشتر از b استفاده شده است
+	c.Set("c", 3) // b حذف می‌شود
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatal("expected a to survive eviction")
+	}
+
+	c.SetWithTTL("c", 3, 0)
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected a to expire")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("expected c without TTL to remain")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 3 || stats.Misses != 2 || stats.Evictions != 1 || stats.Expired != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	c, err := New(Options[string, string]{Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context, key string) (string, error) {
+		calls.Add(1)
+		<-release
+		return "value:" + key, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.GetOrLoad(context.Background(), "k", load)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single load, got %d", calls.Load())
+	}
+	for _, r := range results {
+		if r != "value:k" {
+			t.Fatalf("unexpected result %q", r)
+		}
+	}
+	if v, ok := c.Get("k"); !ok || v != "value:k" {
+		t.Fatal("expected loaded value to be cached")
+	}
+}
@@ -0,0 +1,459 @@
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/openapi"
+)
+
+// پکیج clientgen از روی سند OpenAPI مسیرها (یا مستقیماً Engine.Routes) یک پکیج
+// کلاینت Go با تابع برای هر مسیر و struct برای هر اسکیمای درخواست و پاسخ می‌سازد.
+// با go:generate یا دستور "iristool gen-client" می‌توان کلاینت را با سرور همگام نگه داشت:
+//
+//	//go:generate iristool gen-client -spec http://localhost:8080/openapi.json -package albumsclient -out client.go
+
+// Options configures the generated package.
+type Options struct {
+	Package string // package name, default "client"
+	// Header is written at the top of the file; default is the standard
+	// "Code generated ... DO NOT EDIT." line.
+	Header string
+}
+
+// FromRoutes generates a client for routes, see Generate.
+func FromRoutes(routes mygin.RoutesInfo, opts Options) ([]byte, error) {
+	spec, err := json.Marshal(openapi.Generate(routes, openapi.Info{Title: opts.Package, Version: "generated"}))
+	if err != nil {
+		return nil, fmt.Errorf("error encoding openapi document: %w", err)
+	}
+	return Generate(spec, opts)
+}
+
+// spec is the part of an OpenAPI document the generator reads.
+type spec struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string `json:"operationId"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	Deprecated  bool   `json:"deprecated"`
+	Parameters  []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+
+	path, method string
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 any                `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+}
+
+// types returns the JSON schema types of s without "null" and whether null is allowed.
+func (s *schema) types() ([]string, bool) {
+	var all []string
+	switch t := s.Type.(type) {
+	case string:
+		all = []string{t}
+	case []any:
+		for _, v := range t {
+			if str, ok := v.(string); ok {
+				all = append(all, str)
+			}
+		}
+	}
+	var types []string
+	nullable := false
+	for _, t := range all {
+		if t == "null" {
+			nullable = true
+		} else {
+			types = append(types, t)
+		}
+	}
+	return types, nullable
+}
+
+// Generate returns the formatted source of a client package for the OpenAPI
+// document spec. Every operation becomes a method of Client named after its
+// operationId; path parameters become string arguments, JSON request bodies a
+// typed argument and the first 2xx JSON response the result.
+func Generate(specJSON []byte, opts Options) ([]byte, error) {
+	var s spec
+	if err := json.Unmarshal(specJSON, &s); err != nil {
+		return nil, fmt.Errorf("error parsing openapi document: %w", err)
+	}
+	if opts.Package == "" {
+		opts.Package = "client"
+	}
+	if opts.Header == "" {
+		opts.Header = "// Code generated by clientgen. DO NOT EDIT."
+	}
+
+	g := &generator{components: s.Components.Schemas, names: make(map[string]string), used: make(map[string]bool), imports: make(map[string]bool)}
+	componentNames := make([]string, 0, len(s.Components.Schemas))
+	for name := range s.Components.Schemas {
+		componentNames = append(componentNames, name)
+	}
+	sort.Strings(componentNames)
+	for _, name := range componentNames {
+		g.component(name)
+	}
+
+	var ops []*operation
+	for path, methods := range s.Paths {
+		for method, op := range methods {
+			op.path, op.method = path, strings.ToUpper(method)
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+		return ops[i].method < ops[j].method
+	})
+	methods := make(map[string]bool)
+	for _, op := range ops {
+		if err := g.operation(op, methods); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s\n\npackage %s\n\nimport (\n", opts.Header, opts.Package)
+	imports := []string{"bytes", "context", "fmt", "io", "net/http", "strings", "github.com/goccy/go-json"}
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(runtimeSource)
+	out.Write(g.types.Bytes())
+	out.Write(g.funcs.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated client: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+type generator struct {
+	components map[string]*schema
+	names      map[string]string // component name -> Go type name
+	used       map[string]bool
+	imports    map[string]bool
+	types      bytes.Buffer
+	funcs      bytes.Buffer
+}
+
+// unique returns name, or name with a number appended if it is taken.
+func (g *generator) unique(name string) string {
+	candidate := name
+	for i := 2; g.used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	g.used[candidate] = true
+	return candidate
+}
+
+// component declares the Go type of a component schema and returns its name.
+func (g *generator) component(name string) string {
+	if goName, ok := g.names[name]; ok {
+		return goName
+	}
+	goName := g.unique(exportedName(name))
+	g.names[name] = goName // قبل از تولید بدنه ثبت می‌شود تا ارجاع به خود کار کند
+	s := g.components[name]
+	if s == nil {
+		fmt.Fprintf(&g.types, "type %s = any\n\n", goName)
+		return goName
+	}
+	g.declare(goName, s)
+	return goName
+}
+
+// declare writes "type name <definition>".
+func (g *generator) declare(name string, s *schema) {
+	types, _ := s.types()
+	if len(types) == 1 && types[0] == "object" && s.Properties != nil {
+		var body bytes.Buffer
+		required := make(map[string]bool)
+		for _, r := range s.Required {
+			required[r] = true
+		}
+		props := make([]string, 0, len(s.Properties))
+		for prop := range s.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+		usedFields := make(map[string]bool)
+		for _, prop := range props {
+			field := exportedName(prop)
+			for i := 2; usedFields[field]; i++ {
+				field = exportedName(prop) + strconv.Itoa(i)
+			}
+			usedFields[field] = true
+			typ := g.goType(s.Properties[prop], name+field)
+			tag := prop
+			if !required[prop] {
+				tag += ",omitempty"
+				if isStructType(typ) {
+					typ = "*" + typ
+				}
+			}
+			if d := s.Properties[prop].Description; d != "" {
+				fmt.Fprintf(&body, "\t// %s\n", oneLine(d))
+			}
+			fmt.Fprintf(&body, "\t%s %s `json:%q`\n", field, typ, tag)
+		}
+		if s.Description != "" {
+			fmt.Fprintf(&g.types, "// %s %s\n", name, oneLine(s.Description))
+		}
+		fmt.Fprintf(&g.types, "type %s struct {\n%s}\n\n", name, body.String())
+		return
+	}
+	fmt.Fprintf(&g.types, "type %s %s\n\n", name, g.goType(s, name+"Item"))
+}
+
+// isStructType reports whether typ is a generated named type. Such types are used
+// through pointers for optional fields, bodies and results.
+func isStructType(typ string) bool {
+	return typ != "" && unicode.IsUpper(rune(typ[0])) && !strings.Contains(typ, ".")
+}
+
+// goType returns the Go type of s; inline objects are declared as hint.
+func (g *generator) goType(s *schema, hint string) string {
+	if s == nil {
+		return "any"
+	}
+	if s.Ref != "" {
+		return g.component(strings.TrimPrefix(s.Ref, "#/components/schemas/"))
+	}
+	types, nullable := s.types()
+	if len(types) != 1 {
+		return "any"
+	}
+
+	var typ string
+	switch types[0] {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.imports["time"] = true
+			typ = "time.Time"
+		case "uuid":
+			g.imports["github.com/google/uuid"] = true
+			typ = "uuid.UUID"
+		default:
+			typ = "string"
+		}
+	case "integer":
+		typ = "int64"
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	case "array":
+		return "[]" + g.goType(s.Items, hint+"Item")
+	case "object":
+		if s.Properties != nil {
+			name := g.unique(hint)
+			g.declare(name, s)
+			typ = name
+		} else {
+			value := "any"
+			var extra schema
+			if len(s.AdditionalProperties) > 0 && json.Unmarshal(s.AdditionalProperties, &extra) == nil {
+				value = g.goType(&extra, hint+"Value")
+			}
+			return "map[string]" + value
+		}
+	default:
+		return "any"
+	}
+	if nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// operation writes the Client method of op.
+func (g *generator) operation(op *operation, methods map[string]bool) error {
+	name := exportedName(op.OperationID)
+	if name == "" {
+		name = exportedName(strings.ToLower(op.method) + " " + op.path)
+	}
+	for i := 2; methods[name]; i++ {
+		name = exportedName(op.OperationID) + strconv.Itoa(i)
+	}
+	methods[name] = true
+
+	var params []string
+	pathExpr := strconv.Quote(op.path)
+	for _, p := range op.Parameters {
+		if p.In != "path" {
+			continue
+		}
+		g.imports["net/url"] = true
+		arg := paramName(p.Name)
+		params = append(params, arg+" string")
+		pathExpr = fmt.Sprintf("strings.Replace(%s, %q, url.PathEscape(%s), 1)", pathExpr, "{"+p.Name+"}", arg)
+	}
+
+	bodyArg := "nil"
+	if op.RequestBody != nil {
+		if content, ok := op.RequestBody.Content["application/json"]; ok {
+			typ := g.goType(content.Schema, name+"Request")
+			if isStructType(typ) {
+				typ = "*" + typ
+			}
+			params = append(params, "body "+typ)
+			bodyArg = "body"
+		}
+	}
+
+	result := ""
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if content, ok := op.Responses[code].Content["application/json"]; ok {
+			result = g.goType(content.Schema, name+"Response")
+			break
+		}
+	}
+
+	summary := op.Summary
+	if summary == "" {
+		summary = "calls " + op.method + " " + op.path + "."
+	}
+	fmt.Fprintf(&g.funcs, "// %s %s\n", name, oneLine(lowerFirst(summary)))
+	if op.Deprecated {
+		fmt.Fprintf(&g.funcs, "//\n// Deprecated: the endpoint is deprecated.\n")
+	}
+	args := strings.Join(append([]string{"ctx context.Context"}, params...), ", ")
+	if result == "" {
+		fmt.Fprintf(&g.funcs, "func (c *Client) %s(%s) error {\n\treturn c.do(ctx, %q, %s, %s, nil)\n}\n\n", name, args, op.method, pathExpr, bodyArg)
+		return nil
+	}
+	ret := result
+	if isStructType(result) {
+		ret = "*" + result
+	}
+	fmt.Fprintf(&g.funcs, "func (c *Client) %s(%s) (%s, error) {\n\tvar out %s\n", name, args, ret, result)
+	fmt.Fprintf(&g.funcs, "\tif err := c.do(ctx, %q, %s, %s, &out); err != nil {\n", op.method, pathExpr, bodyArg)
+	if isStructType(result) {
+		g.funcs.WriteString("\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n\n")
+	} else {
+		g.funcs.WriteString("\t\treturn out, err\n\t}\n\treturn out, nil\n}\n\n")
+	}
+	return nil
+}
+
+var initialisms = map[string]string{"id": "ID", "url": "URL", "uri": "URI", "uuid": "UUID", "api": "API", "http": "HTTP", "json": "JSON", "html": "HTML", "ip": "IP", "sql": "SQL"}
+
+// exportedName turns "createdAt", "owner_id" or "get /albums/{id}" into CreatedAt, OwnerID and GetAlbumsID.
+func exportedName(s string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if up, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "T" + name
+	}
+	return name
+}
+
+// paramName returns a lowerCamel argument name that is not a Go keyword.
+func paramName(s string) string {
+	name := lowerFirst(exportedName(s))
+	if strings.HasPrefix(name, "iD") {
+		name = "id" + name[2:]
+	}
+	switch name {
+	case "type", "func", "var", "range", "map", "chan", "go", "select", "case", "default", "package", "import", "interface", "struct", "ctx", "body", "c":
+		return name + "Param"
+	}
+	return name
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	if up, ok := initialisms[strings.ToLower(s)]; ok && up == s {
+		return strings.ToLower(s)
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
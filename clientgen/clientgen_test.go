@@ -0,0 +1,87 @@
+package clientgen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/openapi"
+	"github.com/mahdi-cpp/iris-tools/request_schema"
+)
+
+type albumInput struct {
+	Title string   `json:"title" validate:"required"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type album struct {
+	ID        uuid.UUID         `json:"id"`
+	Title     string            `json:"title"`
+	Cover     *album            `json:"cover,omitempty"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+func TestGenerate(t *testing.T) {
+	engine := mygin.New()
+	h := func(c *mygin.Context) {}
+	request_schema.Response(request_schema.Body(engine.POST("/albums", h), request_schema.Of[albumInput]()), http.StatusCreated, request_schema.Of[album]())
+	openapi.Describe(request_schema.Response(engine.GET("/albums/:id", h), http.StatusOK, request_schema.Of[album]()), openapi.Docs{Summary: "Returns an album."})
+	request_schema.Response(engine.GET("/albums", h), http.StatusOK, request_schema.Of[[]album]())
+	engine.DELETE("/albums/:id/photos/:photoId", h)
+	request_schema.Body(engine.PATCH("/settings", h), request_schema.MustFromJSON(`{"type":"object","properties":{"theme":{"type":"string"},"limits":{"type":"object","properties":{"max":{"type":"integer"}}}}}`))
+
+	src, err := FromRoutes(engine.Routes(), Options{Package: "albumsclient"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(src)
+	for _, want := range []string{
+		"package albumsclient",
+		"func (c *Client) PostAlbums(ctx context.Context, body *AlbumInput) (*Album, error)",
+		"func (c *Client) GetAlbumsByID(ctx context.Context, id string) (*Album, error)",
+		"func (c *Client) GetAlbums(ctx context.Context) ([]Album, error)",
+		"func (c *Client) DeleteAlbumsByIDPhotosByPhotoID(ctx context.Context, id string, photoID string) error",
+		"// GetAlbumsByID returns an album.",
+		"Cover     *Album",
+		"ID        uuid.UUID",
+		"Limits *PatchSettingsRequestLimits",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %q", want)
+		}
+	}
+
+	// کد تولیدشده باید کامپایل شود
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("albumsclient", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("generated code does not type-check: %v\n%s", err, src)
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	for in, want := range map[string]string{
+		"createdAt":        "CreatedAt",
+		"owner_id":         "OwnerID",
+		"getApiAlbumsById": "GetAPIAlbumsByID",
+		"HTTPServer":       "HTTPServer",
+		"2fa":              "T2fa",
+	} {
+		if got := exportedName(in); got != want {
+			t.Errorf("exportedName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
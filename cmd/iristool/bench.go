@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/benchmarks"
+)
+
+// phaseResult holds the latencies of one workload phase.
+type phaseResult struct {
+	name      string
+	latencies []time.Duration
+	errors    int
+	elapsed   time.Duration
+}
+
+func (r phaseResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	idx := int(float64(len(r.latencies)-1) * p)
+	return r.latencies[idx]
+}
+
+// runPhase executes op for every index in [0, count) using the given number of workers.
+func runPhase(name string, count, concurrency int, op func(i int) error) phaseResult {
+	result := phaseResult{name: name, latencies: make([]time.Duration, count)}
+	failed := make([]bool, count)
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	start := time.Now()
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				opStart := time.Now()
+				err := op(i)
+				result.latencies[i] = time.Since(opStart)
+				failed[i] = err != nil
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	result.elapsed = time.Since(start)
+	for _, f := range failed {
+		if f {
+			result.errors++
+		}
+	}
+	sort.Slice(result.latencies, func(i, j int) bool { return result.latencies[i] < result.latencies[j] })
+	return result
+}
+
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	engine := fs.String("engine", "memory", "storage engine: "+strings.Join(benchmarks.EngineNames(), ", ")+" (all with -workload)")
+	count := fs.Int("count", 1000, "number of items")
+	concurrency := fs.Int("concurrency", 4, "number of concurrent workers")
+	recordSize := fs.Int("record-size", 256, "record size in bytes")
+	ops := fs.String("ops", "create,read,update,delete", "comma separated phases to run")
+	dir := fs.String("dir", "", "data directory (default: a temporary directory)")
+	keep := fs.Bool("keep", false, "keep the data directory after the run")
+	seed := fs.Int64("seed", 1, "random seed for read order")
+	workload := fs.String("workload", "", "run a predefined workload instead of phases: "+strings.Join(workloadNames(), ", ")+" or all")
+	scale := fs.Float64("scale", 1, "multiply the preload and op counts of -workload")
+	jsonOut := fs.Bool("json", false, "print -workload reports as JSON")
+	cpuProfile := fs.String("cpuprofile", "", "write a CPU profile of the measured phase (-workload)")
+	memProfile := fs.String("memprofile", "", "write a heap profile after the measured phase (-workload)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *count <= 0 || *concurrency <= 0 {
+		fmt.Fprintln(os.Stderr, "iristool bench: -count and -concurrency must be positive")
+		return 2
+	}
+	if *workload != "" {
+		return runWorkloads(*workload, *engine, *scale, *jsonOut, benchmarks.Options{Dir: *dir, CPUProfile: *cpuProfile, MemProfile: *memProfile})
+	}
+
+	dataDir := *dir
+	if dataDir == "" {
+		tmp, err := os.MkdirTemp("", "iristool-bench-")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+			return 1
+		}
+		dataDir = tmp
+	}
+	if !*keep {
+		defer os.RemoveAll(dataDir)
+	}
+
+	store, err := benchmarks.Open(*engine, dataDir, *recordSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	// payload را طوری پر می‌کنیم که رکورد تقریباً نصف اندازه مجاز را بگیرد
+	payload := strings.Repeat("x", max(0, *recordSize/2-120))
+	items := make([]*benchmarks.Item, *count)
+	ids := make([]uuid.UUID, *count)
+
+	var results []phaseResult
+	for _, phase := range strings.Split(*ops, ",") {
+		switch strings.TrimSpace(phase) {
+		case "create":
+			results = append(results, runPhase("create", *count, *concurrency, func(i int) error {
+				items[i] = &benchmarks.Item{Name: fmt.Sprintf("item-%d", i), Payload: payload}
+				id, err := store.Create(items[i])
+				ids[i] = id
+				return err
+			}))
+		case "read":
+			order := rand.New(rand.NewSource(*seed)).Perm(*count)
+			results = append(results, runPhase("read", *count, *concurrency, func(i int) error {
+				return store.Read(ids[order[i]])
+			}))
+		case "update":
+			results = append(results, runPhase("update", *count, *concurrency, func(i int) error {
+				if items[i] == nil {
+					return fmt.Errorf("item %d was not created", i)
+				}
+				updated := *items[i]
+				updated.Name = fmt.Sprintf("item-%d-updated", i)
+				return store.Update(&updated)
+			}))
+		case "delete":
+			results = append(results, runPhase("delete", *count, *concurrency, func(i int) error {
+				return store.Delete(ids[i])
+			}))
+		default:
+			fmt.Fprintf(os.Stderr, "iristool bench: unknown phase %q\n", phase)
+			return 2
+		}
+	}
+
+	fmt.Printf("engine=%s count=%d concurrency=%d record-size=%d\n\n", *engine, *count, *concurrency, *recordSize)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "phase\tops/s\tp50\tp90\tp99\tmax\terrors\t")
+	exitCode := 0
+	for _, r := range results {
+		throughput := float64(len(r.latencies)) / r.elapsed.Seconds()
+		fmt.Fprintf(w, "%s\t%.0f\t%v\t%v\t%v\t%v\t%d\t\n", r.name, throughput,
+			r.percentile(0.50), r.percentile(0.90), r.percentile(0.99), r.percentile(1), r.errors)
+		if r.errors > 0 {
+			exitCode = 1
+		}
+	}
+	w.Flush()
+
+	return exitCode
+}
+
+func workloadNames() []string {
+	names := make([]string, 0, len(benchmarks.Workloads))
+	for name := range benchmarks.Workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runWorkloads runs predefined workloads against one or all engines and prints one
+// comparable report.
+func runWorkloads(workload, engine string, scale float64, jsonOut bool, opts benchmarks.Options) int {
+	workloads := workloadNames()
+	if workload != "all" {
+		if _, ok := benchmarks.Workloads[workload]; !ok {
+			fmt.Fprintf(os.Stderr, "iristool bench: unknown workload %q\n", workload)
+			return 2
+		}
+		workloads = []string{workload}
+	}
+	engines := benchmarks.EngineNames()
+	if engine != "all" {
+		engines = []string{engine}
+	}
+	if opts.Dir != "" && len(engines)*len(workloads) > 1 {
+		fmt.Fprintln(os.Stderr, "iristool bench: -dir can only be used with a single engine and workload")
+		return 2
+	}
+
+	var reports []benchmarks.Report
+	exitCode := 0
+	for _, name := range workloads {
+		for _, engine := range engines {
+			report, err := benchmarks.Run(context.Background(), engine, benchmarks.Workloads[name].Scale(scale), opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s/%s: %v\n", engine, name, err)
+				exitCode = 1
+				continue
+			}
+			if report.Errors > 0 {
+				exitCode = 1
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	if jsonOut {
+		benchmarks.WriteJSON(os.Stdout, reports)
+	} else {
+		benchmarks.WriteText(os.Stdout, reports)
+	}
+	return exitCode
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/collection_file"
+)
+
+func runDB(args []string) int {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool db <inspect|compact|verify|repair|to-sqlite> [flags] <dir>")
+		return 2
+	}
+
+	switch args[0] {
+	case "inspect":
+		return runDBInspect(args[1:])
+	case "compact":
+		return runDBCompact(args[1:])
+	case "verify":
+		return runDBVerify(args[1:])
+	case "repair":
+		return runDBRepair(args[1:])
+	case "to-sqlite":
+		return runDBToSQLite(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "iristool db: unknown subcommand %q\n", args[0])
+		return 2
+	}
+}
+
+// dataFiles returns the .db files of a collection directory, or the path itself when it is a file.
+// The binary index file of collection_manager_index is skipped.
+func dataFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(path, "*.db"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := matches[:0]
+	for _, match := range matches {
+		if filepath.Base(match) != collection_file.IndexFileName {
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// progressPrinter writes scan progress to stderr unless quiet is set.
+func progressPrinter(file string, quiet bool) collection_file.Progress {
+	if quiet {
+		return nil
+	}
+	return func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\r%s: %d/%d records", file, done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
+
+// resolveRecordSize returns the configured record size or detects it from the file.
+func resolveRecordSize(path string, recordSize int) (int, error) {
+	if recordSize > 0 {
+		return recordSize, nil
+	}
+	return collection_file.DetectRecordSize(path)
+}
+
+func runDBInspect(args []string) int {
+	fs := flag.NewFlagSet("db inspect", flag.ContinueOnError)
+	recordSize := fs.Int("record-size", 0, "record size in bytes (0 = detect from file)")
+	dump := fs.Bool("dump", false, "dump decoded JSON of each active record")
+	all := fs.Bool("all", false, "with -dump, also list deleted and corrupt records")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool db inspect [-record-size N] [-dump] [-all] <dir>")
+		return 2
+	}
+
+	files, err := dataFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "iristool: no .db files found in %s\n", fs.Arg(0))
+		return 1
+	}
+
+	exitCode := 0
+	for _, file := range files {
+		size, err := resolveRecordSize(file, *recordSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+
+		var onRecord func(collection_file.Record) error
+		if *dump {
+			onRecord = func(rec collection_file.Record) error {
+				return dumpRecord(rec, *all)
+			}
+		}
+
+		stats, err := collection_file.Scan(file, size, onRecord)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		printStats(stats)
+	}
+
+	return exitCode
+}
+
+func dumpRecord(rec collection_file.Record, all bool) error {
+	if rec.State != collection_file.StateActive {
+		if all && rec.State != collection_file.StateEmpty {
+			reason := ""
+			if rec.Err != nil {
+				reason = ": " + rec.Err.Error()
+			}
+			fmt.Printf("# offset %d: %s%s\n", rec.Offset, rec.State, reason)
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, rec.Data, "", "  "); err != nil {
+		out.Reset()
+		out.Write(rec.Data)
+	}
+	fmt.Printf("# offset %d\n%s\n", rec.Offset, out.String())
+	return nil
+}
+
+func printStats(stats collection_file.Stats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "file:\t%s\n", stats.Path)
+	fmt.Fprintf(w, "file size:\t%d bytes\n", stats.FileSize)
+	fmt.Fprintf(w, "record size:\t%d bytes\n", stats.RecordSize)
+	fmt.Fprintf(w, "records:\t%d\n", stats.Records)
+	fmt.Fprintf(w, "  active:\t%d\n", stats.Active)
+	fmt.Fprintf(w, "  deleted:\t%d\n", stats.Deleted)
+	fmt.Fprintf(w, "  empty:\t%d\n", stats.Empty)
+	fmt.Fprintf(w, "  corrupt:\t%d\n", stats.Corrupt)
+	if stats.TrailingBytes > 0 {
+		fmt.Fprintf(w, "trailing bytes:\t%d\n", stats.TrailingBytes)
+	}
+	if stats.Active > 0 {
+		fmt.Fprintf(w, "avg payload:\t%d bytes\n", stats.PayloadBytes/int64(stats.Active))
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+func runDBCompact(args []string) int {
+	fs := flag.NewFlagSet("db compact", flag.ContinueOnError)
+	recordSize := fs.Int("record-size", 0, "record size in bytes (0 = detect from file)")
+	quiet := fs.Bool("quiet", false, "only print errors")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool db compact [-record-size N] [-quiet] <dir>")
+		return 2
+	}
+
+	files, err := dataFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+
+	exitCode := 0
+	compacted := false
+	for _, file := range files {
+		size, err := resolveRecordSize(file, *recordSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+
+		result, err := collection_file.Compact(file, size, progressPrinter(file, *quiet))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		compacted = true
+
+		if !*quiet {
+			fmt.Printf("%s: kept %d of %d records, freed %d bytes\n",
+				file, result.Kept, result.Before.Records, result.Freed)
+		}
+	}
+
+	if info, err := os.Stat(fs.Arg(0)); compacted && err == nil && info.IsDir() {
+		reset, err := collection_file.ResetIndex(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+			exitCode = 1
+		} else if reset && !*quiet {
+			fmt.Printf("%s: index reset, it will be rebuilt on next open\n", fs.Arg(0))
+		}
+	}
+
+	return exitCode
+}
+
+func runDBVerify(args []string) int {
+	fs := flag.NewFlagSet("db verify", flag.ContinueOnError)
+	recordSize := fs.Int("record-size", 0, "record size in bytes (0 = detect from file)")
+	quiet := fs.Bool("quiet", false, "only print problems")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool db verify [-record-size N] [-quiet] <dir>")
+		return 2
+	}
+
+	files, err := dataFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+
+	exitCode := 0
+	for _, file := range files {
+		size, err := resolveRecordSize(file, *recordSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+
+		report, err := collection_file.Verify(file, size, progressPrinter(file, *quiet))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+
+		for _, problem := range report.Problems {
+			fmt.Printf("%s: offset %d: %s\n", file, problem.Offset, problem.Reason)
+		}
+		if !report.OK() {
+			exitCode = 1
+		} else if !*quiet {
+			fmt.Printf("%s: OK (%d active records)\n", file, report.Stats.Active)
+		}
+	}
+
+	return exitCode
+}
+
+func runDBRepair(args []string) int {
+	fs := flag.NewFlagSet("db repair", flag.ContinueOnError)
+	recordSize := fs.Int("record-size", 0, "record size in bytes (0 = detect from file)")
+	dryRun := fs.Bool("dry-run", false, "print the recovery report without changing files")
+	quiet := fs.Bool("quiet", false, "only print errors")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool db repair [-record-size N] [-dry-run] [-quiet] <dir>")
+		return 2
+	}
+
+	files, err := dataFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+
+	exitCode := 0
+	repaired := false
+	for _, file := range files {
+		size, err := resolveRecordSize(file, *recordSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v (use -record-size for damaged files)\n", file, err)
+			exitCode = 1
+			continue
+		}
+
+		report, err := collection_file.Repair(file, size, collection_file.RepairOptions{
+			DryRun:   *dryRun,
+			Progress: progressPrinter(file, *quiet),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		if !*dryRun {
+			repaired = true
+		}
+		if !*quiet {
+			printRepairReport(file, report)
+		}
+	}
+
+	if info, err := os.Stat(fs.Arg(0)); repaired && err == nil && info.IsDir() {
+		if _, err := collection_file.ResetIndex(fs.Arg(0)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+			exitCode = 1
+		}
+	}
+
+	return exitCode
+}
+
+func printRepairReport(file string, report collection_file.RepairReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "file:\t%s\n", file)
+	fmt.Fprintf(w, "slots scanned:\t%d\n", report.Stats.Records)
+	fmt.Fprintf(w, "salvaged:\t%d\n", report.Salvaged)
+	fmt.Fprintf(w, "recovered:\t%d\n", report.Recovered)
+	fmt.Fprintf(w, "dropped:\t%d\n", report.Dropped)
+	fmt.Fprintf(w, "quarantined:\t%d\n", len(report.Quarantined))
+	for _, q := range report.Quarantined {
+		fmt.Fprintf(w, "  offset %d:\t%s\n", q.Offset, q.Reason)
+	}
+	if report.BackupPath != "" {
+		fmt.Fprintf(w, "backup:\t%s\n", report.BackupPath)
+	}
+	if report.QuarantinePath != "" {
+		fmt.Fprintf(w, "quarantine:\t%s\n", report.QuarantinePath)
+	}
+	w.Flush()
+	fmt.Println()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/clientgen"
+)
+
+// readSpec reads an OpenAPI document from a file or an http(s) URL.
+func readSpec(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", source, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func runGenClient(args []string) int {
+	fs := flag.NewFlagSet("gen-client", flag.ContinueOnError)
+	specSource := fs.String("spec", "", "OpenAPI document: file or http(s) URL such as http://localhost:8080/openapi.json")
+	pkg := fs.String("package", "client", "package name of the generated code")
+	out := fs.String("out", "", "output file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *specSource == "" || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool gen-client -spec <file or URL> [-package name] [-out file.go]")
+		return 2
+	}
+
+	spec, err := readSpec(*specSource)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+	src, err := clientgen.Generate(spec, clientgen.Options{Package: *pkg})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return 0
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// iristool ابزار خط فرمان برای کار آفلاین با داده‌های کالکشن‌ها است.
+//
+//	iristool db inspect [-record-size N] [-dump] <dir>
+//	iristool db compact [-record-size N] [-quiet] <dir>
+//	iristool db verify  [-record-size N] [-quiet] <dir>
+//	iristool db repair  [-record-size N] [-dry-run] [-quiet] <dir>
+//	iristool db to-sqlite [-record-size N] [-quiet] <dir> <database.sqlite>
+//	iristool bench [-engine memory|index|json|sqlite|bolt] [-count N] [-concurrency N] [-record-size N]
+//	iristool bench -workload read-heavy|write-heavy|mixed|large-records|all [-engine name|all] [-scale F] [-json]
+//	iristool seed -dir <data dir> [-record-size N] [-size name=N] <fixtures>...
+//	iristool gen-client -spec <openapi.json or URL> [-package name] [-out file.go]
+//	iristool mock [-addr :8080] <fixture file or dir>...
+//
+// کد خروج: 0 موفق، 1 خطا یا مشکل در داده، 2 استفاده نادرست
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: iristool <command> [arguments]
+
+Commands:
+  db inspect    print record counts, statuses and sizes of collection .db files
+  db compact    rewrite .db files without deleted and corrupt records
+  db verify     check .db files for corrupt records and duplicate ids
+  db repair     salvage intact records of damaged .db files and quarantine the rest
+  db to-sqlite  copy the records of .db files into tables of a SQLite database
+  bench         run create/read/update/delete workloads against a storage engine
+  seed          load JSON/YAML fixture files into collection .db files
+  gen-client    generate a typed Go client package from an OpenAPI document
+  mock          serve recorded request/response fixtures as a mock API`)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches the command line and returns the process exit code.
+func run(args []string) int {
+	if len(args) < 1 {
+		usage()
+		return 2
+	}
+
+	switch args[0] {
+	case "db":
+		return runDB(args[1:])
+	case "bench":
+		return runBench(args[1:])
+	case "seed":
+		return runSeed(args[1:])
+	case "gen-client":
+		return runGenClient(args[1:])
+	case "mock":
+		return runMock(args[1:])
+	case "help", "-h", "--help":
+		usage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "iristool: unknown command %q\n", args[0])
+		usage()
+		return 2
+	}
+}
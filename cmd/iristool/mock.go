@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/mahdi-cpp/iris-tools/mock_server"
+)
+
+func runMock(args []string) int {
+	fs := flag.NewFlagSet("mock", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "listen address")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool mock [-addr :8080] <fixture file or dir>...")
+		return 2
+	}
+
+	var exchanges []*mock_server.Exchange
+	for _, source := range fs.Args() {
+		info, err := os.Stat(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+			return 1
+		}
+		load := mock_server.LoadFile
+		if info.IsDir() {
+			load = mock_server.LoadDir
+		}
+		loaded, err := load(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+			return 1
+		}
+		exchanges = append(exchanges, loaded...)
+	}
+
+	server := mock_server.New(exchanges...)
+	fmt.Fprintf(os.Stderr, "serving %d recorded exchanges on %s\n", server.Len(), *addr)
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/collection_manager_sqlite"
+)
+
+// runDBToSQLite copies every .db file of a collection directory into a table of a
+// SQLite database, named after the file (photos.db -> photos).
+func runDBToSQLite(args []string) int {
+	fs := flag.NewFlagSet("db to-sqlite", flag.ContinueOnError)
+	recordSize := fs.Int("record-size", 0, "record size in bytes (0 = detect from file)")
+	quiet := fs.Bool("quiet", false, "only print errors")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: iristool db to-sqlite [-record-size N] [-quiet] <dir> <database.sqlite>")
+		return 2
+	}
+
+	files, err := dataFiles(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+	db, err := collection_manager_sqlite.Open(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iristool: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	exitCode := 0
+	for _, file := range files {
+		size, err := resolveRecordSize(file, *recordSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+
+		table := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		result, err := collection_manager_sqlite.Import(context.Background(), db, table, file, size)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			exitCode = 1
+			continue
+		}
+		if !*quiet {
+			fmt.Printf("%s: imported %d records into %s, skipped %d\n", file, result.Imported, table, result.Skipped)
+		}
+	}
+	return exitCode
+}
@@ -1,20 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/mahdi-cpp/iris-tools/mygin"
 )
 
-// Logger Middleware
-func Logger() mygin.HandlerFunc {
-	return func(c *mygin.Context) {
-		log.Printf("[%s] %s - Request received", c.Method, c.Path)
-	}
-}
-
 // IndexHandler Handler for the home page
 func IndexHandler(c *mygin.Context) {
 	c.Writer.WriteHeader(http.StatusOK)
@@ -32,7 +29,7 @@ func main() {
 	r := mygin.New()
 
 	// Global Middleware
-	r.Use(Logger())
+	r.Use(mygin.Logger(), mygin.Recovery())
 
 	// Static Route
 	r.GET("/", IndexHandler)
@@ -62,7 +59,11 @@ func main() {
 		c.Writer.Write([]byte("Photos added to album!"))
 	})
 
-	// Start the server
+	// Start the server; Ctrl+C or SIGTERM shuts it down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	fmt.Println("Server is running on http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", r))
+	if err := r.RunWithContext(ctx, ":8080"); err != nil {
+		log.Fatal(err)
+	}
 }
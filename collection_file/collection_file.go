@@ -0,0 +1,201 @@
+package collection_file
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goccy/go-json"
+)
+
+// این پکیج ابزارهای آفلاین برای کار با فایل‌های .db مدیریت‌کننده‌های کالکشن را فراهم می‌کند.
+// فرمت فایل: رکوردهای با اندازه ثابت، بایت اول وضعیت رکورد و بقیه داده JSON که با صفر پر شده است.
+
+const (
+	recordStatusSize = 1
+)
+
+const (
+	StatusActive  = 0x00
+	StatusDeleted = 0x01
+)
+
+// RecordState describes what a scan found at a record slot.
+type RecordState int
+
+const (
+	StateActive RecordState = iota
+	StateDeleted
+	StateEmpty
+	StateCorrupt
+)
+
+func (s RecordState) String() string {
+	switch s {
+	case StateActive:
+		return "active"
+	case StateDeleted:
+		return "deleted"
+	case StateEmpty:
+		return "empty"
+	case StateCorrupt:
+		return "corrupt"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// Record is a single slot read from a data file.
+type Record struct {
+	Offset int64
+	Status byte
+	State  RecordState
+	Data   []byte // payload without status byte and zero padding
+	Err    error  // reason when State is StateCorrupt
+}
+
+// Stats summarizes a scanned data file.
+type Stats struct {
+	Path          string
+	FileSize      int64
+	RecordSize    int
+	Records       int
+	Active        int
+	Deleted       int
+	Empty         int
+	Corrupt       int
+	TrailingBytes int64 // bytes after the last complete record
+	PayloadBytes  int64 // total payload bytes of active records
+}
+
+// ErrRecordSizeUnknown is returned when the record size cannot be detected from the file content.
+var ErrRecordSizeUnknown = errors.New("unable to detect record size")
+
+// DecodeRecord classifies a raw record buffer read at offset.
+func DecodeRecord(offset int64, buf []byte) Record {
+	rec := Record{Offset: offset}
+	if len(buf) == 0 {
+		rec.State = StateEmpty
+		return rec
+	}
+
+	rec.Status = buf[0]
+	dataLength := bytes.IndexByte(buf[recordStatusSize:], 0)
+	if dataLength == -1 {
+		dataLength = len(buf) - recordStatusSize
+	}
+	rec.Data = buf[recordStatusSize : recordStatusSize+dataLength]
+
+	switch {
+	case rec.Status == StatusDeleted:
+		rec.State = StateDeleted
+	case rec.Status != StatusActive:
+		rec.State = StateCorrupt
+		rec.Err = fmt.Errorf("unknown status byte 0x%02x", rec.Status)
+	case dataLength == 0:
+		rec.State = StateEmpty
+	case !json.Valid(rec.Data):
+		rec.State = StateCorrupt
+		rec.Err = fmt.Errorf("invalid JSON payload")
+	default:
+		rec.State = StateActive
+	}
+
+	return rec
+}
+
+// Scan reads every record slot of the data file and calls fn for each one.
+// If fn returns an error the scan stops and that error is returned.
+func Scan(path string, recordSize int, fn func(Record) error) (Stats, error) {
+	stats := Stats{Path: path, RecordSize: recordSize}
+
+	if recordSize <= recordStatusSize {
+		return stats, fmt.Errorf("invalid record size: %d", recordSize)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return stats, fmt.Errorf("error opening data file: %w", err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return stats, fmt.Errorf("error getting data file info: %w", err)
+	}
+	stats.FileSize = fileInfo.Size()
+	stats.TrailingBytes = stats.FileSize % int64(recordSize)
+
+	recordBuffer := make([]byte, recordSize)
+	for offset := int64(0); offset+int64(recordSize) <= stats.FileSize; offset += int64(recordSize) {
+		if _, err := file.ReadAt(recordBuffer, offset); err != nil && err != io.EOF {
+			return stats, fmt.Errorf("error reading record at offset %d: %w", offset, err)
+		}
+
+		rec := DecodeRecord(offset, recordBuffer)
+		stats.Records++
+		switch rec.State {
+		case StateActive:
+			stats.Active++
+			stats.PayloadBytes += int64(len(rec.Data))
+		case StateDeleted:
+			stats.Deleted++
+		case StateEmpty:
+			stats.Empty++
+		case StateCorrupt:
+			stats.Corrupt++
+		}
+
+		if fn != nil {
+			// داده را کپی می‌کنیم چون بافر در دور بعدی بازنویسی می‌شود
+			rec.Data = append([]byte(nil), rec.Data...)
+			if err := fn(rec); err != nil {
+				return stats, err
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// DetectRecordSize guesses the fixed record size of a data file by finding the
+// smallest size for which every slot starts with a valid status byte followed by JSON.
+func DetectRecordSize(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("error reading data file: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, ErrRecordSizeUnknown
+	}
+
+	for size := recordStatusSize + 2; size <= len(data); size++ {
+		if len(data)%size != 0 {
+			continue
+		}
+		if looksLikeRecordSize(data, size) {
+			return size, nil
+		}
+	}
+
+	return 0, ErrRecordSizeUnknown
+}
+
+func looksLikeRecordSize(data []byte, size int) bool {
+	for offset := 0; offset < len(data); offset += size {
+		status := data[offset]
+		if status != StatusActive && status != StatusDeleted {
+			return false
+		}
+		if data[offset+1] != '{' {
+			return false
+		}
+		// رکورد بعدی باید درست بعد از padding صفر شروع شود
+		if offset+size < len(data) && data[offset+size-1] != 0 && data[offset+size-1] != '}' {
+			return false
+		}
+	}
+	return true
+}
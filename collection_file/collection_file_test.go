@@ -0,0 +1,178 @@
+package collection_file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRecords(t *testing.T, path string, recordSize int, records ...[]byte) {
+	var buf []byte
+	for _, r := range records {
+		record := make([]byte, recordSize)
+		copy(record, r)
+		buf = append(buf, record...)
+	}
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.db")
+	writeRecords(t, path, 64,
+		append([]byte{StatusActive}, `{"id":"a","name":"one"}`...),
+		append([]byte{StatusDeleted}, `{"id":"b","name":"two"}`...),
+		append([]byte{StatusActive}, `{"id":"c",`...),
+		append([]byte{StatusActive}, `{"id":"d"}`...),
+	)
+
+	var active []string
+	stats, err := Scan(path, 64, func(rec Record) error {
+		if rec.State == StateActive {
+			active = append(active, string(rec.Data))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Records != 4 || stats.Active != 2 || stats.Deleted != 1 || stats.Corrupt != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(active) != 2 || active[1] != `{"id":"d"}` {
+		t.Fatalf("unexpected active records: %v", active)
+	}
+}
+
+func TestDetectRecordSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.db")
+	writeRecords(t, path, 250,
+		append([]byte{StatusActive}, `{"id":"a"}`...),
+		append([]byte{StatusDeleted}, `{"id":"b"}`...),
+	)
+
+	size, err := DetectRecordSize(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 250 {
+		t.Fatalf("expected record size 250, got %d", size)
+	}
+}
+
+func TestCompactAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.db")
+	writeRecords(t, path, 64,
+		append([]byte{StatusActive}, `{"id":"a"}`...),
+		append([]byte{StatusDeleted}, `{"id":"b"}`...),
+		append([]byte{StatusActive}, `{"id":"a"}`...),
+		append([]byte{0x07}, `{"id":"c"}`...),
+	)
+
+	report, err := Verify(path, 64, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 2 {
+		t.Fatalf("expected 2 problems, got %v", report.Problems)
+	}
+
+	result, err := Compact(path, 64, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Kept != 2 || result.Freed != 128 {
+		t.Fatalf("unexpected compact result: %+v", result)
+	}
+
+	stats, err := Scan(path, 64, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Records != 2 || stats.Active != 2 {
+		t.Fatalf("unexpected stats after compaction: %+v", stats)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.db")
+	writeRecords(t, path, 64,
+		append([]byte{StatusActive}, `{"id":"a","v":1}`...),
+		append([]byte{StatusDeleted}, `{"id":"d"}`...),
+		append([]byte{StatusActive}, `{"id":"b"}garbage`...),
+		append([]byte{0x07}, `{"id":"c"}`...),
+		append([]byte{StatusActive}, `{"id":`...),
+		append([]byte{StatusActive}, `{"id":"a","v":2}`...),
+	)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.Write([]byte("partial"))
+	f.Close()
+	original, _ := os.ReadFile(path)
+
+	report, err := Repair(path, 64, RepairOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Salvaged != 2 || report.Recovered != 1 || report.Dropped != 1 || len(report.Quarantined) != 4 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(original) || report.BackupPath != "" {
+		t.Fatal("dry run changed the data file")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("dry run created files: %v", entries)
+	}
+
+	report, err = Repair(path, 64, RepairOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := map[int64]string{}
+	for _, q := range report.Quarantined {
+		reasons[q.Offset] = q.Reason
+	}
+	if !strings.Contains(reasons[0], "duplicate of id a") || !strings.Contains(reasons[192], "unknown status byte 0x07") ||
+		!strings.Contains(reasons[256], "invalid JSON") || !strings.Contains(reasons[384], "partial") {
+		t.Fatalf("unexpected quarantine reasons: %v", reasons)
+	}
+
+	var kept []string
+	stats, err := Scan(path, 64, func(rec Record) error {
+		kept = append(kept, string(rec.Data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Active != 2 || stats.Corrupt != 0 || stats.TrailingBytes != 0 ||
+		strings.Join(kept, " ") != `{"id":"b"} {"id":"a","v":2}` {
+		t.Fatalf("unexpected repaired file: %+v %v", stats, kept)
+	}
+
+	if !strings.HasPrefix(report.BackupPath, path+".bak-") || !strings.HasPrefix(report.QuarantinePath, path+".quarantine-") {
+		t.Fatalf("unexpected paths: %s %s", report.BackupPath, report.QuarantinePath)
+	}
+	if data, _ := os.ReadFile(report.BackupPath); string(data) != string(original) {
+		t.Fatal("backup differs from the original file")
+	}
+	lines, _ := os.ReadFile(report.QuarantinePath)
+	if n := strings.Count(string(lines), "\n"); n != 4 {
+		t.Fatalf("expected 4 quarantined lines, got %d", n)
+	}
+
+	// a second repair must keep the first backup
+	second, err := Repair(path, 64, RepairOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.BackupPath == report.BackupPath || second.QuarantinePath != "" {
+		t.Fatalf("unexpected paths of second repair: %s %s", second.BackupPath, second.QuarantinePath)
+	}
+	if data, _ := os.ReadFile(report.BackupPath); string(data) != string(original) {
+		t.Fatal("second repair overwrote the first backup")
+	}
+}
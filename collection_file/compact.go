@@ -0,0 +1,169 @@
+package collection_file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-json"
+)
+
+// IndexFileName is the secondary index file written by collection_manager_index next to data.db.
+// Its offsets point into the data file, so it must be rebuilt after compaction.
+const IndexFileName = "index.db"
+
+// Progress is called periodically with the number of processed and total record slots.
+type Progress func(done, total int)
+
+// CompactResult reports what a compaction did.
+type CompactResult struct {
+	Before Stats
+	Kept   int
+	Freed  int64 // bytes removed from the file
+}
+
+// Compact rewrites the data file keeping only active records, dropping deleted,
+// empty and corrupt slots. The new file is written next to the original and
+// renamed over it, so a failed compaction leaves the original untouched.
+// The file must not be open by a running manager.
+func Compact(path string, recordSize int, progress Progress) (CompactResult, error) {
+	var result CompactResult
+
+	tempPath := path + ".compact"
+	out, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return result, fmt.Errorf("error creating compacted file: %w", err)
+	}
+	defer os.Remove(tempPath)
+
+	total := 0
+	if info, err := os.Stat(path); err == nil {
+		total = int(info.Size() / int64(recordSize))
+	}
+
+	recordBuffer := make([]byte, recordSize)
+	done := 0
+	stats, err := Scan(path, recordSize, func(rec Record) error {
+		done++
+		if progress != nil && (done%1000 == 0 || done == total) {
+			progress(done, total)
+		}
+		if rec.State != StateActive {
+			return nil
+		}
+
+		clear(recordBuffer)
+		recordBuffer[0] = StatusActive
+		copy(recordBuffer[recordStatusSize:], rec.Data)
+		if _, err := out.Write(recordBuffer); err != nil {
+			return fmt.Errorf("error writing compacted record: %w", err)
+		}
+		result.Kept++
+		return nil
+	})
+	result.Before = stats
+	if err != nil {
+		out.Close()
+		return result, err
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return result, fmt.Errorf("error syncing compacted file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return result, fmt.Errorf("error closing compacted file: %w", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		return result, fmt.Errorf("error replacing data file: %w", err)
+	}
+
+	result.Freed = stats.FileSize - int64(result.Kept*recordSize)
+	return result, nil
+}
+
+// ResetIndex truncates the index file in dir, if there is one, so that
+// collection_manager_index rebuilds it from the data file on the next open.
+func ResetIndex(dir string) (bool, error) {
+	indexPath := filepath.Join(dir, IndexFileName)
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		return false, nil
+	}
+	if err := os.Truncate(indexPath, 0); err != nil {
+		return false, fmt.Errorf("error truncating index file: %w", err)
+	}
+	return true, nil
+}
+
+// Problem is a single issue found by Verify.
+type Problem struct {
+	Offset int64
+	Reason string
+}
+
+// VerifyReport is the result of verifying a data file.
+type VerifyReport struct {
+	Stats    Stats
+	Problems []Problem
+}
+
+// OK reports whether the file passed verification.
+func (r VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify checks the structural integrity of a data file: every slot has a known
+// status byte, active payloads are valid JSON, no partial record trails the file
+// and no two active records share the same "id".
+func Verify(path string, recordSize int, progress Progress) (VerifyReport, error) {
+	var report VerifyReport
+
+	total := 0
+	if info, err := os.Stat(path); err == nil {
+		total = int(info.Size() / int64(recordSize))
+	}
+
+	seen := make(map[string]int64)
+	done := 0
+	stats, err := Scan(path, recordSize, func(rec Record) error {
+		done++
+		if progress != nil && (done%1000 == 0 || done == total) {
+			progress(done, total)
+		}
+
+		switch rec.State {
+		case StateCorrupt:
+			report.Problems = append(report.Problems, Problem{Offset: rec.Offset, Reason: rec.Err.Error()})
+		case StateActive:
+			var item struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(rec.Data, &item); err != nil || item.ID == "" {
+				return nil
+			}
+			if first, ok := seen[item.ID]; ok {
+				report.Problems = append(report.Problems, Problem{
+					Offset: rec.Offset,
+					Reason: fmt.Sprintf("duplicate id %s (first at offset %d)", item.ID, first),
+				})
+				return nil
+			}
+			seen[item.ID] = rec.Offset
+		}
+		return nil
+	})
+	report.Stats = stats
+	if err != nil {
+		return report, err
+	}
+
+	if stats.TrailingBytes > 0 {
+		report.Problems = append(report.Problems, Problem{
+			Offset: stats.FileSize - stats.TrailingBytes,
+			Reason: fmt.Sprintf("%d trailing bytes after last complete record", stats.TrailingBytes),
+		})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,262 @@
+package collection_file
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// RepairOptions controls Repair.
+type RepairOptions struct {
+	DryRun   bool // only build the report, do not touch any file
+	Progress Progress
+}
+
+// QuarantinedRecord is a record slot that could not be salvaged. Quarantine files
+// contain one JSON object per line in this shape.
+type QuarantinedRecord struct {
+	Offset int64  `json:"offset"`
+	Reason string `json:"reason"`
+	Raw    string `json:"raw"` // base64 of the full slot
+}
+
+// RepairReport describes the outcome of a repair.
+type RepairReport struct {
+	Stats          Stats
+	Salvaged       int // intact active records copied as-is
+	Recovered      int // damaged records that were fixed and kept
+	Dropped        int // deleted and empty slots that were removed
+	Quarantined    []QuarantinedRecord
+	BackupPath     string
+	QuarantinePath string
+}
+
+// Repair scans a damaged data file, copies every intact record into a fresh
+// file and quarantines the slots it cannot read. Active records with trailing
+// garbage after the JSON payload are recovered; slots with a status byte other
+// than active or deleted are quarantined, since the record may have been
+// deleted and should not come back. When an id
+// appears more than once the last record wins, matching how the managers load
+// their cache; earlier copies are quarantined.
+//
+// Unless DryRun is set the original file is kept as <path>.bak-<time>, the
+// quarantined slots are written to <path>.quarantine-<time> and the repaired
+// file replaces path. <time> is the UTC time of the repair, with a counter
+// appended when files of an earlier repair have the same name, so repeated
+// repairs never overwrite a backup.
+func Repair(path string, recordSize int, opts RepairOptions) (RepairReport, error) {
+	var report RepairReport
+
+	type candidate struct {
+		offset int64
+		data   []byte
+		raw    []byte
+		id     string
+	}
+
+	var candidates []candidate
+	lastByID := make(map[string]int)
+
+	total := 0
+	if info, err := os.Stat(path); err == nil {
+		total = int(info.Size() / int64(recordSize))
+	}
+
+	done := 0
+	recordBuffer := make([]byte, recordSize)
+	stats, err := Scan(path, recordSize, func(rec Record) error {
+		done++
+		if opts.Progress != nil && (done%1000 == 0 || done == total) {
+			opts.Progress(done, total)
+		}
+
+		switch rec.State {
+		case StateDeleted, StateEmpty:
+			report.Dropped++
+			return nil
+		}
+
+		data := rec.Data
+		if rec.State == StateCorrupt {
+			recovered, ok := salvagePayload(data)
+			// وضعیت ناشناخته ممکن است یک رکورد حذف‌شده باشد، پس بازیابی نمی‌شود
+			if !ok || rec.Status != StatusActive {
+				clear(recordBuffer)
+				recordBuffer[0] = rec.Status
+				copy(recordBuffer[recordStatusSize:], rec.Data)
+				report.Quarantined = append(report.Quarantined, QuarantinedRecord{
+					Offset: rec.Offset,
+					Reason: rec.Err.Error(),
+					Raw:    base64.StdEncoding.EncodeToString(recordBuffer),
+				})
+				return nil
+			}
+			data = recovered
+			report.Recovered++
+		} else {
+			report.Salvaged++
+		}
+
+		var item struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(data, &item)
+
+		candidates = append(candidates, candidate{offset: rec.Offset, data: data, raw: rec.Data, id: item.ID})
+		if item.ID != "" {
+			lastByID[item.ID] = len(candidates) - 1
+		}
+		return nil
+	})
+	report.Stats = stats
+	if err != nil {
+		return report, err
+	}
+
+	if stats.TrailingBytes > 0 {
+		trailing, err := readTail(path, stats.TrailingBytes)
+		if err != nil {
+			return report, err
+		}
+		report.Quarantined = append(report.Quarantined, QuarantinedRecord{
+			Offset: stats.FileSize - stats.TrailingBytes,
+			Reason: "partial record after last complete slot",
+			Raw:    base64.StdEncoding.EncodeToString(trailing),
+		})
+	}
+
+	var repaired bytes.Buffer
+	for i, c := range candidates {
+		if c.id != "" && lastByID[c.id] != i {
+			report.Quarantined = append(report.Quarantined, QuarantinedRecord{
+				Offset: c.offset,
+				Reason: fmt.Sprintf("superseded duplicate of id %s", c.id),
+				Raw:    base64.StdEncoding.EncodeToString(append([]byte{StatusActive}, c.raw...)),
+			})
+			continue
+		}
+
+		clear(recordBuffer)
+		recordBuffer[0] = StatusActive
+		copy(recordBuffer[recordStatusSize:], c.data)
+		repaired.Write(recordBuffer)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	report.BackupPath, report.QuarantinePath = repairPaths(path, time.Now())
+
+	if len(report.Quarantined) > 0 {
+		var lines bytes.Buffer
+		for _, q := range report.Quarantined {
+			line, err := json.Marshal(q)
+			if err != nil {
+				return report, fmt.Errorf("error marshaling quarantined record: %w", err)
+			}
+			lines.Write(line)
+			lines.WriteByte('\n')
+		}
+		if err := writeNewFile(report.QuarantinePath, lines.Bytes()); err != nil {
+			return report, fmt.Errorf("error writing quarantine file: %w", err)
+		}
+	} else {
+		report.QuarantinePath = ""
+	}
+
+	tempPath := path + ".repair"
+	if err := os.WriteFile(tempPath, repaired.Bytes(), 0644); err != nil {
+		return report, fmt.Errorf("error writing repaired file: %w", err)
+	}
+	// os.Rename جایگزین فایل موجود می‌شود؛ پشتیبان قبلی هرگز نباید از بین برود
+	if _, err := os.Lstat(report.BackupPath); !errors.Is(err, fs.ErrNotExist) {
+		os.Remove(tempPath)
+		return report, fmt.Errorf("error backing up data file: refusing to overwrite %s", report.BackupPath)
+	}
+	if err := os.Rename(path, report.BackupPath); err != nil {
+		os.Remove(tempPath)
+		return report, fmt.Errorf("error backing up data file: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return report, fmt.Errorf("error replacing data file: %w", err)
+	}
+
+	return report, nil
+}
+
+// repairPaths returns backup and quarantine paths for a repair of path at now
+// that do not exist yet.
+func repairPaths(path string, now time.Time) (backup, quarantine string) {
+	stamp := now.UTC().Format("20060102T150405Z")
+	for n := 1; ; n++ {
+		suffix := stamp
+		if n > 1 {
+			suffix = fmt.Sprintf("%s-%d", stamp, n)
+		}
+		backup, quarantine = path+".bak-"+suffix, path+".quarantine-"+suffix
+		if !exists(backup) && !exists(quarantine) {
+			return backup, quarantine
+		}
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// writeNewFile writes data to path, failing when path already exists.
+func writeNewFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// salvagePayload tries to recover a JSON object from a damaged payload by
+// trimming trailing garbage after the last closing brace.
+func salvagePayload(data []byte) ([]byte, bool) {
+	if len(data) == 0 || data[0] != '{' {
+		return nil, false
+	}
+	if json.Valid(data) {
+		return data, true
+	}
+	for end := bytes.LastIndexByte(data, '}'); end > 0; end = bytes.LastIndexByte(data[:end], '}') {
+		if json.Valid(data[:end+1]) {
+			return data[:end+1], true
+		}
+	}
+	return nil, false
+}
+
+func readTail(path string, n int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening data file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error getting data file info: %w", err)
+	}
+
+	buf := make([]byte, n)
+	if _, err := file.ReadAt(buf, info.Size()-n); err != nil {
+		return nil, fmt.Errorf("error reading trailing bytes: %w", err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,329 @@
+package collection_manager_bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/validation"
+	bolt "go.etcd.io/bbolt"
+)
+
+// پکیج collection_manager_bolt همان API مدیریت‌کننده‌های حافظه و join را روی bbolt پیاده
+// می‌کند. هر کالکشن یک bucket است و هر آیتم یک کلید؛ نوشتن‌ها تراکنشی و با fsync هستند،
+// اندازه رکورد محدودیتی ندارد و داده‌ها در رم نگه داشته نمی‌شوند. چند کالکشن می‌توانند یک
+// فایل را با Open و NewWithDB به اشتراک بگذارند و با Tx چند تغییر را اتمیک انجام دهند.
+
+var logger = logging.For("collection_manager_bolt")
+
+// CollectionItem همان اینترفیس collection_manager_memory است تا مدل‌ها بدون تغییر استفاده شوند.
+type CollectionItem = collection_manager_memory.CollectionItem
+
+type Change[T CollectionItem] = collection_manager_memory.Change[T]
+
+var ErrNotFound = errors.New("item not found")
+
+// Open فایل bbolt را در path باز می‌کند یا می‌سازد.
+func Open(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening bolt database: %w", err)
+	}
+	return db, nil
+}
+
+// Manager یک کالکشن با کلید UUID روی یک bucket است. کلیدها ۱۶ بایت UUID هستند، پس
+// ترتیب bucket برای UUID v7 همان ترتیب ساخت است.
+type Manager[T CollectionItem] struct {
+	db     *bolt.DB
+	bucket []byte
+	ownsDB bool
+
+	hooksMu    sync.RWMutex
+	hooks      map[int]func(Change[T])
+	nextHookID int
+}
+
+// New کالکشن name را در فایل path باز می‌کند؛ Close فایل را هم می‌بندد.
+func New[T CollectionItem](path string, name string) (*Manager[T], error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewWithDB[T](db, name)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	m.ownsDB = true
+	return m, nil
+}
+
+// NewWithDB کالکشن name را در یک فایل مشترک باز می‌کند؛ Close آن را نمی‌بندد.
+func NewWithDB[T CollectionItem](db *bolt.DB, name string) (*Manager[T], error) {
+	if err := createBucket(db, name); err != nil {
+		return nil, err
+	}
+	return &Manager[T]{db: db, bucket: []byte(name)}, nil
+}
+
+func createBucket(db *bolt.DB, name string) error {
+	if name == "" {
+		return fmt.Errorf("collection name is required")
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+			return fmt.Errorf("error creating bucket %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// Close Manager را می‌بندد.
+func (m *Manager[T]) Close() error {
+	if m.ownsDB {
+		return m.db.Close()
+	}
+	return nil
+}
+
+// DB فایل bbolt زیرین را برمی‌گرداند.
+func (m *Manager[T]) DB() *bolt.DB {
+	return m.db
+}
+
+func (m *Manager[T]) Create(item T) (T, error) {
+	return m.CreateContext(context.Background(), item)
+}
+
+// CreateContext مثل collection_manager_memory یک UUID v7 به آیتم می‌دهد.
+func (m *Manager[T]) CreateContext(_ context.Context, item T) (created T, err error) {
+	err = m.Tx(func(tx *Tx[T]) error {
+		created, err = tx.Create(item)
+		return err
+	})
+	return created, err
+}
+
+func (m *Manager[T]) Read(id uuid.UUID) (T, error) {
+	return m.ReadContext(context.Background(), id)
+}
+
+func (m *Manager[T]) ReadContext(_ context.Context, id uuid.UUID) (item T, err error) {
+	err = m.View(func(tx *Tx[T]) error {
+		item, err = tx.Read(id)
+		return err
+	})
+	return item, err
+}
+
+func (m *Manager[T]) ReadAll() ([]T, error) {
+	return m.ReadAllContext(context.Background())
+}
+
+// ReadAllContext آیتم‌ها را به ترتیب شناسه برمی‌گرداند.
+func (m *Manager[T]) ReadAllContext(_ context.Context) (items []T, err error) {
+	err = m.View(func(tx *Tx[T]) error {
+		items, err = tx.ReadAll()
+		return err
+	})
+	return items, err
+}
+
+func (m *Manager[T]) Update(item T) (T, error) {
+	return m.UpdateContext(context.Background(), item)
+}
+
+func (m *Manager[T]) UpdateContext(_ context.Context, item T) (updated T, err error) {
+	err = m.Tx(func(tx *Tx[T]) error {
+		updated, err = tx.Update(item)
+		return err
+	})
+	return updated, err
+}
+
+func (m *Manager[T]) Delete(id uuid.UUID) error {
+	return m.DeleteContext(context.Background(), id)
+}
+
+func (m *Manager[T]) DeleteContext(_ context.Context, id uuid.UUID) error {
+	return m.Tx(func(tx *Tx[T]) error {
+		return tx.Delete(id)
+	})
+}
+
+// Count تعداد آیتم‌ها را برمی‌گرداند.
+func (m *Manager[T]) Count() int {
+	n := 0
+	m.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(m.bucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// OnChange مثل collection_manager_memory بعد از هر تغییر fn را صدا می‌زند؛ تغییرات یک
+// تراکنش فقط بعد از commit و به ترتیب اعلام می‌شوند.
+func (m *Manager[T]) OnChange(fn func(Change[T])) (remove func()) {
+	m.hooksMu.Lock()
+	defer m.hooksMu.Unlock()
+	if m.hooks == nil {
+		m.hooks = make(map[int]func(Change[T]))
+	}
+	id := m.nextHookID
+	m.nextHookID++
+	m.hooks[id] = fn
+	return func() {
+		m.hooksMu.Lock()
+		defer m.hooksMu.Unlock()
+		delete(m.hooks, id)
+	}
+}
+
+func (m *Manager[T]) notify(change Change[T]) {
+	m.hooksMu.RLock()
+	defer m.hooksMu.RUnlock()
+	for _, fn := range m.hooks {
+		fn(change)
+	}
+}
+
+// Tx fn را در یک تراکنش نوشتنی اجرا می‌کند؛ اگر fn خطا برگرداند هیچ تغییری ذخیره نمی‌شود:
+//
+//	err := albums.Tx(func(tx *collection_manager_bolt.Tx[*Album]) error {
+//		if _, err := tx.Create(a); err != nil {
+//			return err
+//		}
+//		return tx.Delete(old.ID)
+//	})
+func (m *Manager[T]) Tx(fn func(tx *Tx[T]) error) error {
+	var changes []Change[T]
+	err := m.db.Update(func(btx *bolt.Tx) error {
+		tx := &Tx[T]{bucket: btx.Bucket(m.bucket), changes: &changes}
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		m.notify(change)
+	}
+	return nil
+}
+
+// View fn را در یک تراکنش فقط‌خواندنی با دید ثابت از داده‌ها اجرا می‌کند.
+func (m *Manager[T]) View(fn func(tx *Tx[T]) error) error {
+	return m.db.View(func(btx *bolt.Tx) error {
+		return fn(&Tx[T]{bucket: btx.Bucket(m.bucket)})
+	})
+}
+
+// Tx عملیات یک کالکشن داخل یک تراکنش bbolt است و بیرون از fn معتبر نیست.
+type Tx[T CollectionItem] struct {
+	bucket  *bolt.Bucket
+	changes *[]Change[T]
+}
+
+func (tx *Tx[T]) Create(item T) (T, error) {
+	var zero T
+	id, err := uuid.NewV7()
+	if err != nil {
+		return zero, fmt.Errorf("error generating UUID v7: %w", err)
+	}
+	item.SetID(id)
+
+	if tsItem, ok := any(item).(collection_manager_memory.Timestampable); ok {
+		now := time.Now()
+		tsItem.SetCreatedAt(now)
+		tsItem.SetUpdatedAt(now)
+	}
+
+	if err := put(tx.bucket, id[:], item); err != nil {
+		return zero, err
+	}
+	*tx.changes = append(*tx.changes, Change[T]{Type: collection_manager_memory.ChangeCreate, ID: id, Item: item})
+	return item, nil
+}
+
+func (tx *Tx[T]) Read(id uuid.UUID) (T, error) {
+	var zero T
+	data := tx.bucket.Get(id[:])
+	if data == nil {
+		return zero, fmt.Errorf("%w with ID: %s", ErrNotFound, id)
+	}
+	return decode[T](data)
+}
+
+func (tx *Tx[T]) ReadAll() ([]T, error) {
+	var items []T
+	err := tx.bucket.ForEach(func(k, v []byte) error {
+		item, err := decode[T](v)
+		if err != nil {
+			logger.Error("error unmarshaling item", "key", fmt.Sprintf("%x", k), "error", err)
+			return nil
+		}
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+func (tx *Tx[T]) Update(item T) (T, error) {
+	var zero T
+	id := item.GetID()
+	if tx.bucket.Get(id[:]) == nil {
+		return zero, fmt.Errorf("item with ID %s does not exist", id)
+	}
+	if tsItem, ok := any(item).(collection_manager_memory.Timestampable); ok {
+		tsItem.SetUpdatedAt(time.Now())
+	}
+
+	if err := put(tx.bucket, id[:], item); err != nil {
+		return zero, err
+	}
+	*tx.changes = append(*tx.changes, Change[T]{Type: collection_manager_memory.ChangeUpdate, ID: id, Item: item})
+	return item, nil
+}
+
+func (tx *Tx[T]) Delete(id uuid.UUID) error {
+	data := tx.bucket.Get(id[:])
+	if data == nil {
+		return fmt.Errorf("item with ID %s not found", id)
+	}
+	item, _ := decode[T](data)
+	if err := tx.bucket.Delete(id[:]); err != nil {
+		return fmt.Errorf("error deleting item: %w", err)
+	}
+	*tx.changes = append(*tx.changes, Change[T]{Type: collection_manager_memory.ChangeDelete, ID: id, Item: item})
+	return nil
+}
+
+func put(bucket *bolt.Bucket, key []byte, item any) error {
+	if v, ok := item.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("error marshaling item: %w", err)
+	}
+	if err := bucket.Put(key, data); err != nil {
+		return fmt.Errorf("error writing item: %w", err)
+	}
+	return nil
+}
+
+func decode[T any](data []byte) (T, error) {
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return item, fmt.Errorf("error unmarshaling item: %w", err)
+	}
+	return item, nil
+}
@@ -0,0 +1,95 @@
+package collection_manager_bolt
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+type album struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+}
+
+func (a *album) SetID(id uuid.UUID) { a.ID = id }
+func (a *album) GetID() uuid.UUID   { return a.ID }
+func (a *album) GetRecordSize() int { return 128 }
+
+type albumPhoto struct {
+	AlbumID uuid.UUID `json:"albumId"`
+	PhotoID uuid.UUID `json:"photoId"`
+}
+
+func (p *albumPhoto) GetCompositeKey() string { return p.AlbumID.String() + ":" + p.PhotoID.String() }
+func (p *albumPhoto) GetRecordSize() int      { return 128 }
+
+func TestManagerAndTx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iris.bolt")
+	albums, err := New[*album](path, "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []collection_manager_memory.ChangeType
+	albums.OnChange(func(c collection_manager_memory.Change[*album]) { changes = append(changes, c.Type) })
+
+	first, _ := albums.Create(&album{Title: "اول"})
+	second, _ := albums.Create(&album{Title: "دوم"})
+	first.Title = "اول!"
+	if _, err := albums.Update(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// تراکنش ناموفق هیچ اثری ندارد
+	boom := errors.New("boom")
+	err = albums.Tx(func(tx *Tx[*album]) error {
+		if err := tx.Delete(second.ID); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) || albums.Count() != 2 || len(changes) != 3 {
+		t.Fatalf("rolled back tx leaked: %v count=%d changes=%v", err, albums.Count(), changes)
+	}
+
+	all, err := albums.ReadAll()
+	if err != nil || len(all) != 2 || all[0].ID != first.ID {
+		t.Fatalf("read all: %v %v", all, err)
+	}
+	albums.Close()
+
+	albums, err = New[*album](path, "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer albums.Close()
+	if got, err := albums.Read(first.ID); err != nil || got.Title != "اول!" {
+		t.Fatalf("reopen: %v %v", got, err)
+	}
+	if err := albums.Delete(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := albums.Read(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	photos, err := NewJoinWithDB[*albumPhoto](albums.DB(), "album_photos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := photos.Create(&albumPhoto{AlbumID: second.ID, PhotoID: uuid.New()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link, _ := photos.Create(&albumPhoto{AlbumID: uuid.New(), PhotoID: uuid.New()})
+	if _, err := photos.Create(link); err == nil {
+		t.Fatal("expected duplicate key error")
+	}
+	if items, err := photos.GetByParentID(second.ID); err != nil || len(items) != 3 {
+		t.Fatalf("by parent: %d %v", len(items), err)
+	}
+}
@@ -0,0 +1,164 @@
+package collection_manager_bolt
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_join"
+	bolt "go.etcd.io/bbolt"
+)
+
+// JoinManager همان API collection_manager_join است: کلید هر آیتم کلید ترکیبی
+// "<parentID>:<...>" آن است و GetByParentID با یک prefix scan روی کلیدهای مرتب bucket
+// انجام می‌شود.
+type JoinManager[T collection_manager_join.JoinItem] struct {
+	db     *bolt.DB
+	bucket []byte
+	ownsDB bool
+}
+
+// NewJoin کالکشن name را در فایل path باز می‌کند؛ Close فایل را هم می‌بندد.
+func NewJoin[T collection_manager_join.JoinItem](path string, name string) (*JoinManager[T], error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewJoinWithDB[T](db, name)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	m.ownsDB = true
+	return m, nil
+}
+
+// NewJoinWithDB کالکشن name را در یک فایل مشترک باز می‌کند.
+func NewJoinWithDB[T collection_manager_join.JoinItem](db *bolt.DB, name string) (*JoinManager[T], error) {
+	if err := createBucket(db, name); err != nil {
+		return nil, err
+	}
+	return &JoinManager[T]{db: db, bucket: []byte(name)}, nil
+}
+
+func (m *JoinManager[T]) Close() error {
+	if m.ownsDB {
+		return m.db.Close()
+	}
+	return nil
+}
+
+func (m *JoinManager[T]) Create(item T) (T, error) {
+	var zero T
+	key := item.GetCompositeKey()
+	if tsItem, ok := any(item).(collection_manager_join.Timestampable); ok {
+		now := time.Now()
+		tsItem.SetCreatedAt(now)
+		tsItem.SetUpdatedAt(now)
+	}
+	if key == "" {
+		return zero, fmt.Errorf("composite key is empty")
+	}
+
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.bucket)
+		if bucket.Get([]byte(key)) != nil {
+			return fmt.Errorf("item with key %s already exists", key)
+		}
+		return put(bucket, []byte(key), item)
+	})
+	if err != nil {
+		return zero, err
+	}
+	return item, nil
+}
+
+func (m *JoinManager[T]) Read(key string) (T, error) {
+	var item T
+	err := m.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(m.bucket).Get([]byte(key))
+		if data == nil {
+			return fmt.Errorf("%w with key: %s", ErrNotFound, key)
+		}
+		var err error
+		item, err = decode[T](data)
+		return err
+	})
+	return item, err
+}
+
+func (m *JoinManager[T]) ReadAll() ([]T, error) {
+	return m.scan(nil)
+}
+
+func (m *JoinManager[T]) Update(item T) (T, error) {
+	var zero T
+	key := item.GetCompositeKey()
+	if tsItem, ok := any(item).(collection_manager_join.Timestampable); ok {
+		tsItem.SetUpdatedAt(time.Now())
+	}
+	if key == "" {
+		return zero, fmt.Errorf("composite key is empty")
+	}
+
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.bucket)
+		if bucket.Get([]byte(key)) == nil {
+			return fmt.Errorf("item with key %s does not exist", key)
+		}
+		return put(bucket, []byte(key), item)
+	})
+	if err != nil {
+		return zero, err
+	}
+	return item, nil
+}
+
+func (m *JoinManager[T]) Delete(key string) error {
+	return m.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(m.bucket)
+		if bucket.Get([]byte(key)) == nil {
+			return fmt.Errorf("item with key %s not found", key)
+		}
+		return bucket.Delete([]byte(key))
+	})
+}
+
+func (m *JoinManager[T]) Count() int {
+	n := 0
+	m.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(m.bucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// GetByParentID تمام آیتم‌های مربوط به یک کلید والد را برمی‌گرداند.
+func (m *JoinManager[T]) GetByParentID(parentID uuid.UUID) ([]T, error) {
+	items, err := m.scan([]byte(parentID.String() + ":"))
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w for parent ID: %s", collection_manager_join.ErrNoItems, parentID)
+	}
+	return items, nil
+}
+
+func (m *JoinManager[T]) scan(prefix []byte) ([]T, error) {
+	var items []T
+	err := m.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(m.bucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			item, err := decode[T](v)
+			if err != nil {
+				logger.Error("error unmarshaling item", "key", string(k), "error", err)
+				continue
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -14,8 +13,12 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/validation"
 )
 
+var logger = logging.For("collection_manager_index")
+
 const (
 	recordStatusSize = 1
 )
@@ -302,7 +305,7 @@ func (m *Manager[T, I]) loadPrimaryIndex() error {
 
 	m.primaryIndex = indexMap
 
-	log.Printf("Loaded %d entries from primary index", len(m.primaryIndex))
+	logger.Info("loaded primary index", "count", len(m.primaryIndex))
 	return nil
 }
 
@@ -340,7 +343,7 @@ func (m *Manager[T, I]) readAllIndexRecords() (map[uuid.UUID]IndexEntry[I], erro
 
 		id, err := uuid.FromBytes(record[0:16])
 		if err != nil {
-			log.Printf("Error parsing UUID at offset %d: %v", currentOffset, err)
+			logger.Error("error parsing index UUID", "offset", currentOffset, "error", err)
 			currentOffset += int64(m.fh.indexRecordSize)
 			continue
 		}
@@ -352,7 +355,7 @@ func (m *Manager[T, I]) readAllIndexRecords() (map[uuid.UUID]IndexEntry[I], erro
 
 		var indexData I
 		if err := json.Unmarshal(data, &indexData); err != nil {
-			log.Printf("Error unmarshaling index data for ID %s at offset %d: %v", id, currentOffset, err)
+			logger.Error("error unmarshaling index entry", "id", id, "offset", currentOffset, "error", err)
 			currentOffset += int64(m.fh.indexRecordSize)
 			continue
 		}
@@ -378,7 +381,7 @@ func (m *Manager[T, I]) rebuildIndex() error {
 	fileSize := fileInfo.Size()
 
 	if fileSize == 0 {
-		log.Println("Data file is empty, no index to rebuild")
+		logger.Info("data file is empty, no index to rebuild")
 		return nil
 	}
 
@@ -393,7 +396,7 @@ func (m *Manager[T, I]) rebuildIndex() error {
 		recordBuffer := make([]byte, m.fh.recordSize)
 		n, err := m.fh.dataFile.ReadAt(recordBuffer, offset)
 		if err != nil && err != io.EOF {
-			log.Printf("Error reading record at offset %d: %v", offset, err)
+			logger.Error("error reading record", "offset", offset, "error", err)
 			continue
 		}
 
@@ -418,13 +421,13 @@ func (m *Manager[T, I]) rebuildIndex() error {
 
 		var dataItem T
 		if err := json.Unmarshal(data, &dataItem); err != nil {
-			log.Printf("Error unmarshaling data at offset %d: %v", offset, err)
+			logger.Error("error unmarshaling record", "offset", offset, "error", err)
 			continue
 		}
 
 		indexItem, err := createIndexItem[T, I](dataItem)
 		if err != nil {
-			log.Printf("Error creating index item from data at offset %d: %v", offset, err)
+			logger.Error("error creating index item", "offset", offset, "error", err)
 			continue
 		}
 
@@ -432,13 +435,13 @@ func (m *Manager[T, I]) rebuildIndex() error {
 		if id != uuid.Nil {
 			indexData, err := json.Marshal(indexItem)
 			if err != nil {
-				log.Printf("Error marshaling index item for ID %s: %v", id, err)
+				logger.Error("error marshaling index item", "id", id, "error", err)
 				continue
 			}
 
 			indexOffset, err := m.fh.WriteIndexRecord(id, offset, indexData)
 			if err != nil {
-				log.Printf("Error writing index record for ID %s: %v", id, err)
+				logger.Error("error writing index record", "id", id, "error", err)
 				continue
 			}
 
@@ -450,7 +453,7 @@ func (m *Manager[T, I]) rebuildIndex() error {
 		}
 	}
 
-	log.Printf("Rebuilt primary index with %d entries", len(m.primaryIndex))
+	logger.Info("rebuilt primary index", "count", len(m.primaryIndex))
 	return nil
 }
 
@@ -516,8 +519,8 @@ func createIndexItem[T, I any](dataItem T) (I, error) {
 				dataFieldValue.Type().AssignableTo(indexField.Type) {
 				indexFieldValue.Set(dataFieldValue)
 			} else {
-				log.Printf("Warning: Cannot assign field %s from type %v to type %v",
-					dataField.Name, dataFieldValue.Type(), indexField.Type)
+				logger.Warn("cannot assign index field",
+					"field", dataField.Name, "from", dataFieldValue.Type(), "to", indexField.Type)
 			}
 		}
 	}
@@ -545,6 +548,10 @@ func (m *Manager[T, I]) Create(item T) (T, error) {
 	item.SetCreatedAt(time.Now())
 	item.SetUpdatedAt(time.Now())
 
+	if err := validateItem(item); err != nil {
+		return zero, err
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return zero, fmt.Errorf("error marshaling item: %w", err)
@@ -600,6 +607,10 @@ func (m *Manager[T, I]) Update(item T) (T, error) {
 		return zero, fmt.Errorf("failed to create index item: %w", err)
 	}
 
+	if err := validateItem(item); err != nil {
+		return zero, err
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return zero, fmt.Errorf("error marshaling item: %w", err)
@@ -709,9 +720,9 @@ func (m *Manager[T, I]) DebugInfo() map[string]interface{} {
 
 func (m *Manager[T, I]) PrintDebugInfo() {
 	info := m.DebugInfo()
-	log.Printf("Debug Info - Primary Index: %d, Closed: %v",
-		info["primary_index_size"],
-		info["is_closed"])
+	logger.Debug("debug info",
+		"primary_index_size", info["primary_index_size"],
+		"is_closed", info["is_closed"])
 }
 
 func (m *Manager[T, I]) GetIndexEntry(id uuid.UUID) (IndexEntry[I], error) {
@@ -750,3 +761,13 @@ func (m *Manager[T, I]) GetAllIndexes() []I {
 
 	return result
 }
+
+// validateItem هوک Validatable را قبل از نوشتن آیتم اجرا می‌کند (در صورت پیاده‌سازی).
+func validateItem(item any) error {
+	if v, ok := item.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	return nil
+}
@@ -2,9 +2,9 @@ package collection_manager_join
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,8 +13,15 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/validation"
 )
 
+var logger = logging.For("collection_manager_join")
+
+// ErrNoItems is returned by GetByParentID when a parent has no items.
+var ErrNoItems = errors.New("no items found")
+
 const (
 	recordStatusSize = 1
 )
@@ -203,7 +210,7 @@ func (m *Manager[T]) loadAllDataToCache() error {
 
 		var loadedItem T
 		if err := json.Unmarshal(data, &loadedItem); err != nil {
-			log.Printf("Error unmarshaling data at offset %d: %v", offset, err)
+			logger.Error("error unmarshaling record", "offset", offset, "error", err)
 			continue
 		}
 
@@ -216,7 +223,7 @@ func (m *Manager[T]) loadAllDataToCache() error {
 			m.parentCache[parentID] = append(m.parentCache[parentID], loadedItem)
 		}
 	}
-	log.Printf("Loaded %d items into cache from data.db", len(m.dataCache))
+	logger.Info("loaded items into cache", "count", len(m.dataCache))
 	return nil
 }
 
@@ -256,6 +263,10 @@ func (m *Manager[T]) Create(item T) (T, error) {
 		tsItem.SetUpdatedAt(now)
 	}
 
+	if err := validateItem(item); err != nil {
+		return zero, err
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return zero, fmt.Errorf("error marshaling item: %w", err)
@@ -321,6 +332,10 @@ func (m *Manager[T]) Update(item T) (T, error) {
 		return zero, err
 	}
 
+	if err := validateItem(item); err != nil {
+		return zero, err
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return zero, fmt.Errorf("error marshaling item: %w", err)
@@ -392,7 +407,7 @@ func (m *Manager[T]) findRecordOffset(key string) (int64, error) {
 
 		var loadedItem T
 		if err := json.Unmarshal(data, &loadedItem); err != nil {
-			log.Printf("Error unmarshaling data at offset %d: %v", offset, err)
+			logger.Error("error unmarshaling record", "offset", offset, "error", err)
 			continue
 		}
 
@@ -413,8 +428,18 @@ func (m *Manager[T]) GetByParentID(parentID uuid.UUID) ([]T, error) {
 
 	items, ok := m.parentCache[parentIDStr]
 	if !ok || len(items) == 0 {
-		return nil, fmt.Errorf("no items found for parent ID: %s", parentIDStr)
+		return nil, fmt.Errorf("%w for parent ID: %s", ErrNoItems, parentIDStr)
 	}
 
 	return items, nil
 }
+
+// validateItem هوک Validatable را قبل از نوشتن آیتم اجرا می‌کند (در صورت پیاده‌سازی).
+func validateItem(item any) error {
+	if v, ok := item.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	return nil
+}
@@ -11,8 +11,12 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/validation"
 )
 
+var logger = logging.For("collection_manager_json")
+
 // collectionItem is the interface that every item in the collection must implement.
 // The ID is of type uuid.UUID for type safety.
 type collectionItem interface {
@@ -176,13 +180,13 @@ func (m *Manager[T]) readAllItemsFromDisk() ([]T, error) {
 
 		filename := strings.TrimSuffix(entry.Name(), ".json")
 		if _, err := uuid.Parse(filename); err != nil {
-			fmt.Printf("collection_manager_json: skipping file with invalid UUID filename: %s, error: %v\n", entry.Name(), err)
+			logger.Warn("skipping file with invalid UUID filename", "file", entry.Name(), "error", err)
 			continue
 		}
 
 		item, err := m.readItemFromDisk(filename)
 		if err != nil {
-			fmt.Printf("collection_manager_json: error reading item %s: %v\n", filename, err)
+			logger.Error("error reading item", "file", filename, "error", err)
 			continue
 		}
 		items = append(items, item)
@@ -242,6 +246,11 @@ func (m *Manager[T]) Create(newItem T) (T, error) {
 		return zero, fmt.Errorf("item with ID %s already exists", newItem.GetID().String())
 	}
 
+	if err := validateItem(newItem); err != nil {
+		var zero T
+		return zero, err
+	}
+
 	if err := m.writeItemToDisk(newItem); err != nil {
 		var zero T
 		return zero, err
@@ -285,6 +294,11 @@ func (m *Manager[T]) Update(updatedItem T) (T, error) {
 		return zero, fmt.Errorf("item with ID %s does not exist", updatedItem.GetID().String())
 	}
 
+	if err := validateItem(updatedItem); err != nil {
+		var zero T
+		return zero, err
+	}
+
 	if err := m.writeItemToDisk(updatedItem); err != nil {
 		var zero T
 		return zero, err
@@ -318,3 +332,13 @@ func (m *Manager[T]) Delete(id uuid.UUID) error {
 func (m *Manager[T]) Count() int {
 	return m.items.count()
 }
+
+// validateItem هوک Validatable را قبل از نوشتن آیتم اجرا می‌کند (در صورت پیاده‌سازی).
+func validateItem(item any) error {
+	if v, ok := item.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	return nil
+}
@@ -2,9 +2,9 @@ package collection_manager_memory
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"sync"
@@ -12,8 +12,12 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/validation"
 )
 
+var logger = logging.For("collection_manager_memory")
+
 const (
 	recordStatusSize = 1
 )
@@ -36,11 +40,10 @@ type Timestampable interface {
 	SetUpdatedAt(t time.Time)
 }
 
-// FileHandler همان ساختار قبلی را حفظ می‌کند
+// FileHandler رکوردهای با اندازه ثابت را روی یک Storage می‌خواند و می‌نویسد.
 type FileHandler struct {
-	dataFile   *os.File
+	data       Storage
 	mu         sync.RWMutex
-	dirName    string
 	recordSize int
 }
 
@@ -50,25 +53,42 @@ func NewFileHandler(dirName string, fileName string, recordSize int) (*FileHandl
 		return nil, fmt.Errorf("error creating directory %s: %w", dirName, err)
 	}
 
-	dataFileName := filepath.Join(dirName, fileName+".db")
-
-	dataFile, err := os.OpenFile(dataFileName, os.O_RDWR|os.O_CREATE, 0644)
+	storage, err := OpenFileStorage(filepath.Join(dirName, fileName+".db"))
 	if err != nil {
-		return nil, fmt.Errorf("error opening data file: %w", err)
+		return nil, err
 	}
+	return NewStorageHandler(storage, recordSize), nil
+}
 
+// NewStorageHandler یک FileHandler روی storage دلخواه (مثلاً object storage) می‌سازد.
+func NewStorageHandler(storage Storage, recordSize int) *FileHandler {
 	return &FileHandler{
-		dataFile:   dataFile,
-		dirName:    dirName,
+		data:       storage,
 		recordSize: recordSize,
-	}, nil
+	}
 }
 
 func (h *FileHandler) Close() error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	return h.dataFile.Close()
+	return h.data.Close()
+}
+
+// Sync داده‌های بافرشده storage را ماندگار می‌کند.
+func (h *FileHandler) Sync() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.data.Sync()
+}
+
+// Size اندازه فعلی داده‌ها را برمی‌گرداند.
+func (h *FileHandler) Size() (int64, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.data.Size()
 }
 
 func (h *FileHandler) WriteRecord(data []byte) (int64, error) {
@@ -79,16 +99,16 @@ func (h *FileHandler) WriteRecord(data []byte) (int64, error) {
 		return -1, fmt.Errorf("data size is larger than max record size (%d bytes)", h.recordSize-recordStatusSize)
 	}
 
-	offset, err := h.dataFile.Seek(0, io.SeekEnd)
+	offset, err := h.data.Size()
 	if err != nil {
-		return -1, fmt.Errorf("error seeking to end of data file: %w", err)
+		return -1, err
 	}
 
 	recordBuffer := make([]byte, h.recordSize)
 	recordBuffer[0] = StatusActive
 	copy(recordBuffer[recordStatusSize:], data)
 
-	if _, err := h.dataFile.Write(recordBuffer); err != nil {
+	if _, err := h.data.WriteAt(recordBuffer, offset); err != nil {
 		return -1, fmt.Errorf("error writing record: %w", err)
 	}
 
@@ -104,7 +124,7 @@ func (h *FileHandler) ReadRecord(offset int64) ([]byte, error) {
 	}
 
 	recordBuffer := make([]byte, h.recordSize)
-	n, err := h.dataFile.ReadAt(recordBuffer, offset)
+	n, err := h.data.ReadAt(recordBuffer, offset)
 	if err != nil && err != io.EOF {
 		return nil, fmt.Errorf("error reading block from data file at offset %d: %w", offset, err)
 	}
@@ -139,7 +159,7 @@ func (h *FileHandler) UpdateRecord(offset int64, data []byte) error {
 	recordBuffer[0] = StatusActive
 	copy(recordBuffer[recordStatusSize:], data)
 
-	if _, err := h.dataFile.WriteAt(recordBuffer, offset); err != nil {
+	if _, err := h.data.WriteAt(recordBuffer, offset); err != nil {
 		return fmt.Errorf("error updating record in data file: %w", err)
 	}
 
@@ -150,18 +170,65 @@ func (h *FileHandler) DeleteRecord(offset int64) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if _, err := h.dataFile.WriteAt([]byte{StatusDeleted}, offset); err != nil {
+	if _, err := h.data.WriteAt([]byte{StatusDeleted}, offset); err != nil {
 		return fmt.Errorf("error marking record as deleted: %w", err)
 	}
 	return nil
 }
 
+// Rewrite فایل داده را با رکوردهای داده شده از نو می‌سازد و به صورت اتمیک جایگزین
+// محتوای فعلی می‌کند (Storage.Replace).
+func (h *FileHandler) Rewrite(records [][]byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var buf bytes.Buffer
+	buf.Grow(len(records) * h.recordSize)
+	recordBuffer := make([]byte, h.recordSize)
+	for _, data := range records {
+		if len(data) > h.recordSize-recordStatusSize {
+			return fmt.Errorf("data size is larger than max record size (%d bytes)", h.recordSize-recordStatusSize)
+		}
+
+		clear(recordBuffer)
+		recordBuffer[0] = StatusActive
+		copy(recordBuffer[recordStatusSize:], data)
+		buf.Write(recordBuffer)
+	}
+
+	return h.data.Replace(&buf)
+}
+
+// ChangeType نوع تغییر یک آیتم را مشخص می‌کند.
+type ChangeType string
+
+const (
+	ChangeCreate ChangeType = "create"
+	ChangeUpdate ChangeType = "update"
+	ChangeDelete ChangeType = "delete"
+	// ChangeOverflow فقط در کانال Watch دیده می‌شود و یعنی Dropped تغییر دور ریخته شده‌اند
+	ChangeOverflow ChangeType = "overflow"
+)
+
+// Change یک تغییر انجام‌شده روی کالکشن است. برای حذف، Item مقدار قبل از حذف است.
+type Change[T CollectionItem] struct {
+	Type    ChangeType
+	ID      uuid.UUID
+	Item    T
+	Dropped uint64 // تعداد تغییرات از دست رفته، فقط برای ChangeOverflow
+}
+
 // Manager جدید با قابلیت کشینگ در رم
 type Manager[T CollectionItem] struct {
 	fh        *FileHandler
 	mu        sync.RWMutex
 	dataCache map[uuid.UUID]T // کش برای ذخیره تمام آیتم‌ها در رم
 	closed    bool
+
+	hooksMu    sync.RWMutex
+	hooks      map[int]func(Change[T])
+	nextHookID int
+	observers  map[int]OpObserver
 }
 
 func NewWithRecordSize[T CollectionItem](dirName string, fileName string, recordSize int) (*Manager[T], error) {
@@ -170,29 +237,22 @@ func NewWithRecordSize[T CollectionItem](dirName string, fileName string, record
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file handler: %w", err)
 	}
-
-	manager := &Manager[T]{
-		fh:        fh,
-		dataCache: make(map[uuid.UUID]T),
-	}
-
-	// لود کردن تمام داده‌ها در زمان شروع
-	if err := manager.loadAllDataToCache(); err != nil {
-		return nil, fmt.Errorf("failed to load data to cache: %w", err)
-	}
-
-	return manager, nil
+	return newManager[T](fh)
 }
 
 func New[T CollectionItem](dirName string, fileName string) (*Manager[T], error) {
 	var dataItem T
-	recordSize := dataItem.GetRecordSize()
+	return NewWithRecordSize[T](dirName, fileName, dataItem.GetRecordSize())
+}
 
-	fh, err := NewFileHandler(dirName, fileName, recordSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create file handler: %w", err)
-	}
+// NewWithStorage یک Manager روی storage دلخواه می‌سازد، مثلاً object_storage برای
+// نگه‌داشتن کالکشن روی S3. با بسته شدن Manager، storage هم بسته می‌شود.
+func NewWithStorage[T CollectionItem](storage Storage) (*Manager[T], error) {
+	var dataItem T
+	return newManager[T](NewStorageHandler(storage, dataItem.GetRecordSize()))
+}
 
+func newManager[T CollectionItem](fh *FileHandler) (*Manager[T], error) {
 	manager := &Manager[T]{
 		fh:        fh,
 		dataCache: make(map[uuid.UUID]T),
@@ -200,6 +260,7 @@ func New[T CollectionItem](dirName string, fileName string) (*Manager[T], error)
 
 	// لود کردن تمام داده‌ها در زمان شروع
 	if err := manager.loadAllDataToCache(); err != nil {
+		fh.Close()
 		return nil, fmt.Errorf("failed to load data to cache: %w", err)
 	}
 
@@ -211,11 +272,10 @@ func (m *Manager[T]) loadAllDataToCache() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	fileInfo, err := m.fh.dataFile.Stat()
+	fileSize, err := m.fh.Size()
 	if err != nil {
-		return fmt.Errorf("error getting data file info: %w", err)
+		return err
 	}
-	fileSize := fileInfo.Size()
 
 	for offset := int64(0); offset < fileSize; offset += int64(m.fh.recordSize) {
 		data, err := m.fh.ReadRecord(offset)
@@ -226,13 +286,13 @@ func (m *Manager[T]) loadAllDataToCache() error {
 
 		var loadedItem T
 		if err := json.Unmarshal(data, &loadedItem); err != nil {
-			log.Printf("Error unmarshaling data at offset %d: %v", offset, err)
+			logger.Error("error unmarshaling record", "offset", offset, "error", err)
 			continue
 		}
 
 		m.dataCache[loadedItem.GetID()] = loadedItem
 	}
-	log.Printf("Loaded %d items into cache from data.db", len(m.dataCache))
+	logger.Info("loaded items into cache", "count", len(m.dataCache))
 	return nil
 }
 
@@ -254,6 +314,14 @@ func (m *Manager[T]) Close() error {
 
 // Create یک آیتم جدید را به کش اضافه کرده و در فایل می‌نویسد.
 func (m *Manager[T]) Create(item T) (T, error) {
+	return m.CreateContext(context.Background(), item)
+}
+
+// CreateContext همان Create است؛ ctx فقط به observerها (مثلاً برای tracing) داده می‌شود.
+func (m *Manager[T]) CreateContext(ctx context.Context, item T) (_ T, err error) {
+	op := m.startOp(ctx, "create")
+	defer m.finishOp(op, &err)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -267,6 +335,7 @@ func (m *Manager[T]) Create(item T) (T, error) {
 		return zero, fmt.Errorf("error generating UUID v7: %w", err)
 	}
 	item.SetID(id)
+	op.ID = id
 
 	if tsItem, ok := any(item).(Timestampable); ok {
 		now := time.Now()
@@ -274,10 +343,15 @@ func (m *Manager[T]) Create(item T) (T, error) {
 		tsItem.SetUpdatedAt(now)
 	}
 
+	if err := validateItem(item); err != nil {
+		return zero, err
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return zero, fmt.Errorf("error marshaling item: %w", err)
 	}
+	op.Bytes = len(data)
 
 	_, err = m.fh.WriteRecord(data)
 	if err != nil {
@@ -285,17 +359,28 @@ func (m *Manager[T]) Create(item T) (T, error) {
 	}
 
 	m.dataCache[id] = item
+	m.notify(Change[T]{Type: ChangeCreate, ID: id, Item: item})
 
 	return item, nil
 }
 
 // Read یک آیتم را از کش برمی‌گرداند.
 func (m *Manager[T]) Read(id uuid.UUID) (T, error) {
+	return m.ReadContext(context.Background(), id)
+}
+
+// ReadContext همان Read است با ctx برای observerها.
+func (m *Manager[T]) ReadContext(ctx context.Context, id uuid.UUID) (_ T, err error) {
+	op := m.startOp(ctx, "read")
+	op.ID = id
+	defer m.finishOp(op, &err)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var zero T
 	item, ok := m.dataCache[id]
+	op.CacheHit = ok
 	if !ok {
 		return zero, fmt.Errorf("item not found with ID: %s", id)
 	}
@@ -303,6 +388,14 @@ func (m *Manager[T]) Read(id uuid.UUID) (T, error) {
 }
 
 func (m *Manager[T]) ReadAll() ([]T, error) {
+	return m.ReadAllContext(context.Background())
+}
+
+// ReadAllContext همان ReadAll است با ctx برای observerها.
+func (m *Manager[T]) ReadAllContext(ctx context.Context) (_ []T, err error) {
+	op := m.startOp(ctx, "read_all")
+	defer m.finishOp(op, &err)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -310,11 +403,21 @@ func (m *Manager[T]) ReadAll() ([]T, error) {
 	for _, item := range m.dataCache {
 		items = append(items, item)
 	}
+	op.Items = len(items)
 	return items, nil
 }
 
 // Update یک آیتم را در کش و فایل به‌روزرسانی می‌کند.
 func (m *Manager[T]) Update(item T) (T, error) {
+	return m.UpdateContext(context.Background(), item)
+}
+
+// UpdateContext همان Update است با ctx برای observerها.
+func (m *Manager[T]) UpdateContext(ctx context.Context, item T) (_ T, err error) {
+	op := m.startOp(ctx, "update")
+	op.ID = item.GetID()
+	defer m.finishOp(op, &err)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -335,26 +438,42 @@ func (m *Manager[T]) Update(item T) (T, error) {
 		return zero, err
 	}
 
+	if err := validateItem(item); err != nil {
+		return zero, err
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return zero, fmt.Errorf("error marshaling item: %w", err)
 	}
+	op.Bytes = len(data)
 
 	if err := m.fh.UpdateRecord(offset, data); err != nil {
 		return zero, fmt.Errorf("error updating record on disk: %w", err)
 	}
 
 	m.dataCache[id] = item
+	m.notify(Change[T]{Type: ChangeUpdate, ID: id, Item: item})
 
 	return item, nil
 }
 
 // Delete یک آیتم را از کش و فایل حذف می‌کند.
 func (m *Manager[T]) Delete(id uuid.UUID) error {
+	return m.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext همان Delete است با ctx برای observerها.
+func (m *Manager[T]) DeleteContext(ctx context.Context, id uuid.UUID) (err error) {
+	op := m.startOp(ctx, "delete")
+	op.ID = id
+	defer m.finishOp(op, &err)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, ok := m.dataCache[id]; !ok {
+	item, ok := m.dataCache[id]
+	if !ok {
 		return fmt.Errorf("item with ID %s not found", id)
 	}
 
@@ -369,6 +488,7 @@ func (m *Manager[T]) Delete(id uuid.UUID) error {
 	}
 
 	delete(m.dataCache, id)
+	m.notify(Change[T]{Type: ChangeDelete, ID: id, Item: item})
 
 	return nil
 }
@@ -380,13 +500,229 @@ func (m *Manager[T]) Count() int {
 	return len(m.dataCache)
 }
 
+// Compact فایل داده را فقط با آیتم‌های فعال موجود در کش بازنویسی می‌کند
+// و فضای رکوردهای حذف‌شده را آزاد می‌کند.
+func (m *Manager[T]) Compact() error {
+	return m.CompactContext(context.Background())
+}
+
+// CompactContext همان Compact است با ctx برای observerها.
+func (m *Manager[T]) CompactContext(ctx context.Context) (err error) {
+	op := m.startOp(ctx, "compact")
+	defer m.finishOp(op, &err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return fmt.Errorf("manager is closed")
+	}
+
+	records := make([][]byte, 0, len(m.dataCache))
+	for _, item := range m.dataCache {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("error marshaling item: %w", err)
+		}
+		records = append(records, data)
+	}
+
+	op.Items = len(records)
+	if err := m.fh.Rewrite(records); err != nil {
+		return fmt.Errorf("error compacting data file: %w", err)
+	}
+
+	return nil
+}
+
+// OnChange تابعی را ثبت می‌کند که بعد از هر Create، Update، Copy و Delete موفق صدا زده می‌شود.
+// هوک‌ها به ترتیب تغییرات و در حالی که قفل Manager گرفته شده اجرا می‌شوند، پس باید سریع
+// باشند و نباید دوباره متدهای Manager را صدا بزنند. تابع برگشتی هوک را حذف می‌کند.
+func (m *Manager[T]) OnChange(fn func(Change[T])) (remove func()) {
+	m.hooksMu.Lock()
+	defer m.hooksMu.Unlock()
+
+	if m.hooks == nil {
+		m.hooks = make(map[int]func(Change[T]))
+	}
+	id := m.nextHookID
+	m.nextHookID++
+	m.hooks[id] = fn
+
+	return func() {
+		m.hooksMu.Lock()
+		defer m.hooksMu.Unlock()
+		delete(m.hooks, id)
+	}
+}
+
+// Watch یک کانال از تغییرات کالکشن برمی‌گرداند. اگر گیرنده عقب بماند و بافر پر شود
+// تغییرات جدید برای آن گیرنده دور ریخته می‌شوند و به جای آن‌ها یک Change از نوع
+// ChangeOverflow با تعداد تغییرات از دست رفته در کانال قرار می‌گیرد؛ گیرنده باید
+// در این حالت دوباره از روی کالکشن همگام شود. آخرین خانه بافر برای این پیام نگه
+// داشته می‌شود، پس buffer حداقل ۲ است. cancel کانال را می‌بندد.
+func (m *Manager[T]) Watch(buffer int) (changes <-chan Change[T], cancel func()) {
+	ch := make(chan Change[T], max(buffer, 2))
+	var once sync.Once
+	var mu sync.Mutex
+	closed := false
+	var dropped uint64 // تغییرات دور ریخته‌شده‌ای که هنوز گزارش نشده‌اند
+
+	// report یک ChangeOverflow می‌فرستد اگر جایی در بافر باشد
+	report := func() {
+		select {
+		case ch <- Change[T]{Type: ChangeOverflow, Dropped: dropped}:
+			dropped = 0
+		default:
+		}
+	}
+
+	remove := m.OnChange(func(change Change[T]) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		if dropped > 0 {
+			report()
+		}
+		// فقط همین هوک در کانال می‌نویسد، پس جای خالی زیر mu کم نمی‌شود
+		if dropped == 0 && len(ch) < cap(ch)-1 {
+			ch <- change
+			return
+		}
+		dropped++
+		report()
+	})
+
+	return ch, func() {
+		once.Do(func() {
+			remove()
+			mu.Lock()
+			closed = true
+			close(ch)
+			mu.Unlock()
+		})
+	}
+}
+
+// Op توصیف یک عملیات انجام‌شده روی Manager است که به observerها داده می‌شود.
+type Op struct {
+	Context    context.Context // ctx متدهای ...Context، در غیر این صورت context.Background()
+	Name       string          // create، read، read_all، update، delete، copy یا compact
+	ID         uuid.UUID       // شناسه آیتم برای عملیات تک‌آیتمی
+	Start      time.Time
+	Duration   time.Duration // شامل زمان انتظار برای قفل
+	Err        error
+	RecordSize int  // اندازه رکورد فایل داده
+	Bytes      int  // اندازه JSON نوشته‌شده برای create، update و copy
+	CacheHit   bool // برای read: آیتم در کش رم پیدا شد
+	Items      int  // برای read_all و compact: تعداد آیتم‌ها
+}
+
+// OpObserver بعد از هر عملیات و بعد از آزاد شدن قفل Manager صدا زده می‌شود.
+type OpObserver func(op Op)
+
+// Observe یک observer عملیات اضافه می‌کند (مثلاً برای متریک یا tracing). تابع برگشتی آن را حذف می‌کند.
+func (m *Manager[T]) Observe(fn OpObserver) (remove func()) {
+	m.hooksMu.Lock()
+	defer m.hooksMu.Unlock()
+
+	if m.observers == nil {
+		m.observers = make(map[int]OpObserver)
+	}
+	id := m.nextHookID
+	m.nextHookID++
+	m.observers[id] = fn
+
+	return func() {
+		m.hooksMu.Lock()
+		defer m.hooksMu.Unlock()
+		delete(m.observers, id)
+	}
+}
+
+func (m *Manager[T]) startOp(ctx context.Context, name string) *Op {
+	return &Op{Context: ctx, Name: name, Start: time.Now(), RecordSize: m.fh.recordSize}
+}
+
+func (m *Manager[T]) finishOp(op *Op, err *error) {
+	m.hooksMu.RLock()
+	defer m.hooksMu.RUnlock()
+	if len(m.observers) == 0 {
+		return
+	}
+	op.Duration = time.Since(op.Start)
+	op.Err = *err
+	for _, fn := range m.observers {
+		fn(*op)
+	}
+}
+
+// Snapshot یک کپی سازگار از فایل داده را در w می‌نویسد (مثلاً برای بکاپ). در طول کپی
+// نوشتن‌ها منتظر می‌مانند ولی خواندن‌ها ادامه دارند. خروجی با New قابل باز کردن است.
+func (m *Manager[T]) Snapshot(w io.Writer) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return 0, fmt.Errorf("manager is closed")
+	}
+
+	m.fh.mu.RLock()
+	defer m.fh.mu.RUnlock()
+	size, err := m.fh.data.Size()
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(w, io.NewSectionReader(m.fh.data, 0, size))
+	if err != nil {
+		return n, fmt.Errorf("error copying data file: %w", err)
+	}
+	return n, nil
+}
+
+// Ping بررسی می‌کند که Manager بسته نشده و فایل داده در دسترس است؛ برای health checkها.
+func (m *Manager[T]) Ping() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return fmt.Errorf("manager is closed")
+	}
+	_, err := m.fh.Size()
+	return err
+}
+
+// FileSize اندازه فعلی فایل داده را برمی‌گرداند (شامل رکوردهای حذف‌شده تا Compact بعدی).
+func (m *Manager[T]) FileSize() (int64, error) {
+	return m.fh.Size()
+}
+
+// Sync داده‌های نوشته‌شده را ماندگار می‌کند؛ برای storageهایی مثل object_storage که
+// نوشتن‌ها را بافر می‌کنند، بعد از Sync تغییرات روی مقصد هستند.
+func (m *Manager[T]) Sync() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return fmt.Errorf("manager is closed")
+	}
+	return m.fh.Sync()
+}
+
+func (m *Manager[T]) notify(change Change[T]) {
+	m.hooksMu.RLock()
+	defer m.hooksMu.RUnlock()
+
+	for _, fn := range m.hooks {
+		fn(change)
+	}
+}
+
 // findRecordOffset به صورت خطی در فایل برای پیدا کردن آفست جستجو می‌کند.
 func (m *Manager[T]) findRecordOffset(id uuid.UUID) (int64, error) {
-	fileInfo, err := m.fh.dataFile.Stat()
+	fileSize, err := m.fh.Size()
 	if err != nil {
-		return -1, fmt.Errorf("error getting data file info: %w", err)
+		return -1, err
 	}
-	fileSize := fileInfo.Size()
 
 	for offset := int64(0); offset < fileSize; offset += int64(m.fh.recordSize) {
 		data, err := m.fh.ReadRecord(offset)
@@ -396,7 +732,7 @@ func (m *Manager[T]) findRecordOffset(id uuid.UUID) (int64, error) {
 
 		var loadedItem T
 		if err := json.Unmarshal(data, &loadedItem); err != nil {
-			log.Printf("Error unmarshaling data at offset %d: %v", offset, err)
+			logger.Error("error unmarshaling record", "offset", offset, "error", err)
 			continue
 		}
 
@@ -409,6 +745,15 @@ func (m *Manager[T]) findRecordOffset(id uuid.UUID) (int64, error) {
 }
 
 func (m *Manager[T]) Copy(item T) (T, error) {
+	return m.CopyContext(context.Background(), item)
+}
+
+// CopyContext همان Copy است با ctx برای observerها.
+func (m *Manager[T]) CopyContext(ctx context.Context, item T) (_ T, err error) {
+	op := m.startOp(ctx, "copy")
+	op.ID = item.GetID()
+	defer m.finishOp(op, &err)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -417,17 +762,34 @@ func (m *Manager[T]) Copy(item T) (T, error) {
 		return zero, fmt.Errorf("manager is closed")
 	}
 
+	if err := validateItem(item); err != nil {
+		return zero, err
+	}
+
 	data, err := json.Marshal(item)
 	if err != nil {
 		return zero, fmt.Errorf("error marshaling item: %w", err)
 	}
 
+	op.Bytes = len(data)
+
 	_, err = m.fh.WriteRecord(data)
 	if err != nil {
 		return zero, fmt.Errorf("error writing record to disk: %w", err)
 	}
 
 	m.dataCache[item.GetID()] = item
+	m.notify(Change[T]{Type: ChangeCreate, ID: item.GetID(), Item: item})
 
 	return item, nil
 }
+
+// validateItem هوک Validatable را قبل از نوشتن آیتم اجرا می‌کند (در صورت پیاده‌سازی).
+func validateItem(item any) error {
+	if v, ok := item.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/validation"
 )
 
 func (a *Model) SetID(id uuid.UUID)       { a.ID = id }
@@ -27,6 +28,7 @@ func (a *PhotoAlbums) GetID() uuid.UUID   { return a.ID }
 func (a *PhotoAlbums) GetRecordSize() int { return 150 }
 
 type PhotoAlbums struct {
+	ID      uuid.UUID `json:"id"`
 	AlbumID uuid.UUID `json:"albumId"`
 	PhotoID uuid.UUID `json:"photoID"`
 }
@@ -57,3 +59,131 @@ func TestNew(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestCompact(t *testing.T) {
+	dir := t.TempDir()
+
+	modelCollection, err := New[*Model](dir, "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var kept *Model
+	for i := 0; i < 3; i++ {
+		m, err := modelCollection.Create(&Model{Name: "album", Count: i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			kept = m
+			continue
+		}
+		if err := modelCollection.Delete(m.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := modelCollection.Compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := modelCollection.Create(&Model{Name: "after compact"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := modelCollection.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := New[*Model](dir, "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if reopened.Count() != 2 {
+		t.Fatalf("expected 2 items after compaction, got %d", reopened.Count())
+	}
+	if _, err := reopened.Read(kept.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type checkedItem struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name" validate:"required,max=20"`
+}
+
+func (c *checkedItem) SetID(id uuid.UUID) { c.ID = id }
+func (c *checkedItem) GetID() uuid.UUID   { return c.ID }
+func (c *checkedItem) GetRecordSize() int { return 128 }
+func (c *checkedItem) Validate() error    { return validation.Struct(c) }
+
+func TestValidatable(t *testing.T) {
+	manager, err := NewWithRecordSize[*checkedItem](t.TempDir(), "checked", 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	_, err = manager.Create(&checkedItem{})
+	if fields, ok := validation.Fields(err); !ok || fields["name"] == "" {
+		t.Fatalf("expected name validation error, got %v", err)
+	}
+	if manager.Count() != 0 {
+		t.Fatal("invalid item was stored")
+	}
+
+	item, err := manager.Create(&checkedItem{Name: "ok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Name = ""
+	if _, err := manager.Update(item); err == nil {
+		t.Fatal("expected update validation error")
+	}
+}
+
+func TestWatchOverflow(t *testing.T) {
+	m, err := New[*Model](t.TempDir(), "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	changes, cancel := m.Watch(4)
+	defer cancel()
+
+	// گیرنده چیزی نمی‌خواند: سه تغییر در بافر می‌مانند و بقیه گزارش می‌شوند
+	for i := 0; i < 10; i++ {
+		if _, err := m.Create(&Model{Name: "m"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var created, dropped uint64
+	drain := func() {
+		for len(changes) > 0 {
+			change := <-changes
+			switch change.Type {
+			case ChangeCreate:
+				created++
+			case ChangeOverflow:
+				dropped += change.Dropped
+			default:
+				t.Fatalf("unexpected change %+v", change)
+			}
+		}
+	}
+	drain()
+	if created != 3 || dropped != 1 {
+		t.Fatalf("expected 3 created and 1 dropped, got %d/%d", created, dropped)
+	}
+
+	// تغییرات بعدی پس از گزارش باقی‌مانده از دست رفته‌ها دوباره تحویل می‌شوند
+	if _, err := m.Create(&Model{Name: "m"}); err != nil {
+		t.Fatal(err)
+	}
+	drain()
+	if created != 4 || dropped != 7 {
+		t.Fatalf("expected 4 created and 7 dropped, got %d/%d", created, dropped)
+	}
+}
@@ -0,0 +1,76 @@
+package collection_manager_memory
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Storage بایت‌های فایل داده یک کالکشن را نگه می‌دارد. پیاده‌سازی پیش‌فرض یک فایل محلی
+// است (FileStorage)؛ پکیج object_storage همین اینترفیس را روی S3 پیاده می‌کند.
+type Storage interface {
+	io.ReaderAt
+	io.WriterAt
+	// Size اندازه فعلی داده‌ها است؛ رکورد جدید در این آفست نوشته می‌شود.
+	Size() (int64, error)
+	// Replace کل محتوا را به صورت اتمیک با r جایگزین می‌کند (برای Compact).
+	Replace(r io.Reader) error
+	Sync() error
+	Close() error
+}
+
+// FileStorage یک Storage روی فایل محلی است.
+type FileStorage struct {
+	file *os.File
+	path string
+}
+
+// OpenFileStorage فایل را باز می‌کند یا در صورت نبودن می‌سازد.
+func OpenFileStorage(path string) (*FileStorage, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening data file: %w", err)
+	}
+	return &FileStorage{file: file, path: path}, nil
+}
+
+func (s *FileStorage) ReadAt(p []byte, off int64) (int, error)  { return s.file.ReadAt(p, off) }
+func (s *FileStorage) WriteAt(p []byte, off int64) (int, error) { return s.file.WriteAt(p, off) }
+func (s *FileStorage) Sync() error                              { return s.file.Sync() }
+func (s *FileStorage) Close() error                             { return s.file.Close() }
+
+func (s *FileStorage) Size() (int64, error) {
+	info, err := s.file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("error getting data file info: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// Replace فایل جدید را ابتدا کنار فایل اصلی می‌نویسد تا در صورت خطا داده‌ها از بین نروند.
+func (s *FileStorage) Replace(r io.Reader) error {
+	tempFileName := s.path + ".compact"
+	tempFile, err := os.OpenFile(tempFileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating compacted file: %w", err)
+	}
+	fail := func(format string, err error) error {
+		tempFile.Close()
+		os.Remove(tempFileName)
+		return fmt.Errorf(format, err)
+	}
+
+	if _, err := io.Copy(tempFile, r); err != nil {
+		return fail("error writing compacted file: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		return fail("error syncing compacted file: %w", err)
+	}
+	if err := os.Rename(tempFileName, s.path); err != nil {
+		return fail("error replacing data file: %w", err)
+	}
+
+	s.file.Close()
+	s.file = tempFile
+	return nil
+}
@@ -0,0 +1,293 @@
+package collection_manager_sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/validation"
+	_ "modernc.org/sqlite"
+)
+
+// پکیج collection_manager_sqlite همان API مدیریت‌کننده حافظه (Create/Read/ReadAll/Update/
+// Delete و نسخه‌های Context) را روی SQLite پیاده می‌کند. هر کالکشن یک جدول با دو ستون
+// id و data (JSON آیتم) است، پس محدودیت اندازه رکورد وجود ندارد و داده‌ها در رم نگه
+// داشته نمی‌شوند. برای انتقال داده‌های موجود از Import استفاده کنید.
+
+var logger = logging.For("collection_manager_sqlite")
+
+// CollectionItem همان اینترفیس collection_manager_memory است تا مدل‌ها بدون تغییر استفاده شوند.
+type CollectionItem = collection_manager_memory.CollectionItem
+
+type (
+	Change[T CollectionItem] = collection_manager_memory.Change[T]
+	ChangeType               = collection_manager_memory.ChangeType
+)
+
+var ErrNotFound = errors.New("item not found")
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Manager یک کالکشن روی یک جدول SQLite است.
+type Manager[T CollectionItem] struct {
+	db     *sql.DB
+	table  string
+	ownsDB bool
+
+	hooksMu    sync.RWMutex
+	hooks      map[int]func(Change[T])
+	nextHookID int
+}
+
+// Open پایگاه داده SQLite را در path باز می‌کند (با WAL و busy timeout).
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("error opening sqlite database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error opening sqlite database: %w", err)
+	}
+	return db, nil
+}
+
+// New کالکشن name را در فایل path باز می‌کند؛ Close پایگاه داده را هم می‌بندد.
+func New[T CollectionItem](path string, name string) (*Manager[T], error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewWithDB[T](db, name)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	m.ownsDB = true
+	return m, nil
+}
+
+// NewWithDB کالکشن name را در یک پایگاه داده مشترک باز می‌کند؛ Close آن را نمی‌بندد.
+func NewWithDB[T CollectionItem](db *sql.DB, name string) (*Manager[T], error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid collection name %q", name)
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q (id TEXT PRIMARY KEY, data BLOB NOT NULL) WITHOUT ROWID`, name)
+	if _, err := db.Exec(query); err != nil {
+		return nil, fmt.Errorf("error creating table %s: %w", name, err)
+	}
+	return &Manager[T]{db: db, table: name}, nil
+}
+
+// Close Manager را می‌بندد.
+func (m *Manager[T]) Close() error {
+	if m.ownsDB {
+		return m.db.Close()
+	}
+	return nil
+}
+
+// DB پایگاه داده زیرین را برای کوئری‌های دلخواه برمی‌گرداند.
+func (m *Manager[T]) DB() *sql.DB {
+	return m.db
+}
+
+func (m *Manager[T]) Create(item T) (T, error) {
+	return m.CreateContext(context.Background(), item)
+}
+
+// CreateContext مثل collection_manager_memory یک UUID v7 به آیتم می‌دهد.
+func (m *Manager[T]) CreateContext(ctx context.Context, item T) (T, error) {
+	var zero T
+	id, err := uuid.NewV7()
+	if err != nil {
+		return zero, fmt.Errorf("error generating UUID v7: %w", err)
+	}
+	item.SetID(id)
+
+	if tsItem, ok := any(item).(collection_manager_memory.Timestampable); ok {
+		now := time.Now()
+		tsItem.SetCreatedAt(now)
+		tsItem.SetUpdatedAt(now)
+	}
+
+	data, err := encode(item)
+	if err != nil {
+		return zero, err
+	}
+	query := fmt.Sprintf(`INSERT INTO %q (id, data) VALUES (?, ?)`, m.table)
+	if _, err := m.db.ExecContext(ctx, query, id.String(), data); err != nil {
+		return zero, fmt.Errorf("error inserting item: %w", err)
+	}
+
+	m.notify(Change[T]{Type: collection_manager_memory.ChangeCreate, ID: id, Item: item})
+	return item, nil
+}
+
+func (m *Manager[T]) Read(id uuid.UUID) (T, error) {
+	return m.ReadContext(context.Background(), id)
+}
+
+func (m *Manager[T]) ReadContext(ctx context.Context, id uuid.UUID) (T, error) {
+	var zero T
+	var data []byte
+	query := fmt.Sprintf(`SELECT data FROM %q WHERE id = ?`, m.table)
+	err := m.db.QueryRowContext(ctx, query, id.String()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return zero, fmt.Errorf("%w with ID: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("error reading item: %w", err)
+	}
+	return decode[T](data)
+}
+
+func (m *Manager[T]) ReadAll() ([]T, error) {
+	return m.ReadAllContext(context.Background())
+}
+
+// ReadAllContext آیتم‌ها را به ترتیب شناسه (یعنی زمان ساخت برای UUID v7) برمی‌گرداند.
+func (m *Manager[T]) ReadAllContext(ctx context.Context) ([]T, error) {
+	return m.query(ctx, fmt.Sprintf(`SELECT data FROM %q ORDER BY id`, m.table))
+}
+
+// Query آیتم‌هایی را برمی‌گرداند که با شرط SQL روی ستون data (JSON) می‌خوانند، مثلاً:
+//
+//	albums.Query(ctx, "json_extract(data, '$.title') LIKE ?", "%سفر%")
+func (m *Manager[T]) Query(ctx context.Context, where string, args ...any) ([]T, error) {
+	return m.query(ctx, fmt.Sprintf(`SELECT data FROM %q WHERE %s ORDER BY id`, m.table, where), args...)
+}
+
+func (m *Manager[T]) query(ctx context.Context, query string, args ...any) ([]T, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("error reading item: %w", err)
+		}
+		item, err := decode[T](data)
+		if err != nil {
+			logger.Error("error unmarshaling item", "table", m.table, "error", err)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (m *Manager[T]) Update(item T) (T, error) {
+	return m.UpdateContext(context.Background(), item)
+}
+
+func (m *Manager[T]) UpdateContext(ctx context.Context, item T) (T, error) {
+	var zero T
+	if tsItem, ok := any(item).(collection_manager_memory.Timestampable); ok {
+		tsItem.SetUpdatedAt(time.Now())
+	}
+
+	data, err := encode(item)
+	if err != nil {
+		return zero, err
+	}
+	id := item.GetID()
+	query := fmt.Sprintf(`UPDATE %q SET data = ? WHERE id = ?`, m.table)
+	result, err := m.db.ExecContext(ctx, query, data, id.String())
+	if err != nil {
+		return zero, fmt.Errorf("error updating item: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return zero, fmt.Errorf("item with ID %s does not exist", id)
+	}
+
+	m.notify(Change[T]{Type: collection_manager_memory.ChangeUpdate, ID: id, Item: item})
+	return item, nil
+}
+
+func (m *Manager[T]) Delete(id uuid.UUID) error {
+	return m.DeleteContext(context.Background(), id)
+}
+
+func (m *Manager[T]) DeleteContext(ctx context.Context, id uuid.UUID) error {
+	var data []byte
+	query := fmt.Sprintf(`DELETE FROM %q WHERE id = ? RETURNING data`, m.table)
+	err := m.db.QueryRowContext(ctx, query, id.String()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("item with ID %s not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting item: %w", err)
+	}
+
+	item, _ := decode[T](data)
+	m.notify(Change[T]{Type: collection_manager_memory.ChangeDelete, ID: id, Item: item})
+	return nil
+}
+
+// Count تعداد آیتم‌ها را برمی‌گرداند (در صورت خطا 0).
+func (m *Manager[T]) Count() int {
+	var n int
+	if err := m.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %q`, m.table)).Scan(&n); err != nil {
+		logger.Error("error counting items", "table", m.table, "error", err)
+	}
+	return n
+}
+
+// OnChange مثل collection_manager_memory بعد از هر تغییر موفق fn را صدا می‌زند.
+func (m *Manager[T]) OnChange(fn func(Change[T])) (remove func()) {
+	m.hooksMu.Lock()
+	defer m.hooksMu.Unlock()
+	if m.hooks == nil {
+		m.hooks = make(map[int]func(Change[T]))
+	}
+	id := m.nextHookID
+	m.nextHookID++
+	m.hooks[id] = fn
+	return func() {
+		m.hooksMu.Lock()
+		defer m.hooksMu.Unlock()
+		delete(m.hooks, id)
+	}
+}
+
+func (m *Manager[T]) notify(change Change[T]) {
+	m.hooksMu.RLock()
+	defer m.hooksMu.RUnlock()
+	for _, fn := range m.hooks {
+		fn(change)
+	}
+}
+
+func encode(item any) ([]byte, error) {
+	if v, ok := item.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid item: %w", err)
+		}
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling item: %w", err)
+	}
+	return data, nil
+}
+
+func decode[T CollectionItem](data []byte) (T, error) {
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return item, fmt.Errorf("error unmarshaling item: %w", err)
+	}
+	return item, nil
+}
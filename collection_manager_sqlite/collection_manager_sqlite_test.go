@@ -0,0 +1,87 @@
+package collection_manager_sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+type album struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+}
+
+func (a *album) SetID(id uuid.UUID) { a.ID = id }
+func (a *album) GetID() uuid.UUID   { return a.ID }
+func (a *album) GetRecordSize() int { return 128 }
+
+func TestManager(t *testing.T) {
+	albums, err := New[*album](filepath.Join(t.TempDir(), "iris.sqlite"), "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer albums.Close()
+
+	var changes int
+	albums.OnChange(func(collection_manager_memory.Change[*album]) { changes++ })
+
+	created, err := albums.Create(&album{Title: "سفر"})
+	if err != nil || created.ID == uuid.Nil {
+		t.Fatalf("create: %v %v", created, err)
+	}
+	albums.Create(&album{Title: "خانه"})
+
+	created.Title = "سفر شمال"
+	if _, err := albums.Update(created); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := albums.Read(created.ID); err != nil || got.Title != "سفر شمال" {
+		t.Fatalf("read: %v %v", got, err)
+	}
+	found, err := albums.Query(context.Background(), "json_extract(data, '$.title') LIKE ?", "سفر%")
+	if err != nil || len(found) != 1 {
+		t.Fatalf("query: %v %v", found, err)
+	}
+	if err := albums.Delete(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := albums.Read(created.ID); err == nil {
+		t.Fatal("expected not found after delete")
+	}
+	if albums.Count() != 1 || changes != 4 {
+		t.Fatalf("count %d, changes %d", albums.Count(), changes)
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	memory, err := collection_manager_memory.New[*album](dir, "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, _ := memory.Create(&album{Title: "kept"})
+	removed, _ := memory.Create(&album{Title: "removed"})
+	memory.Delete(removed.ID)
+	memory.Close()
+
+	db, err := Open(filepath.Join(dir, "iris.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	result, err := Import(context.Background(), db, "albums", filepath.Join(dir, "albums.db"), 128)
+	if err != nil || result.Imported != 1 || result.Skipped != 1 {
+		t.Fatalf("import: %+v %v", result, err)
+	}
+
+	albums, err := NewWithDB[*album](db, "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := albums.Read(kept.ID); err != nil || got.Title != "kept" {
+		t.Fatalf("imported item: %v %v", got, err)
+	}
+}
@@ -0,0 +1,75 @@
+package collection_manager_sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_file"
+)
+
+// ImportResult reports what Import copied from a data file.
+type ImportResult struct {
+	Imported int
+	Skipped  int // deleted, corrupt or records without a valid id
+}
+
+// Import copies the active records of a collection_manager_memory data file into table
+// of db without knowing the Go type of the items; ids are kept so references between
+// collections stay valid. Existing rows with the same id are replaced, so an import
+// can be repeated. The whole file is imported in one transaction.
+func Import(ctx context.Context, db *sql.DB, table string, path string, recordSize int) (ImportResult, error) {
+	var result ImportResult
+	if !validName.MatchString(table) {
+		return result, fmt.Errorf("invalid collection name %q", table)
+	}
+	create := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q (id TEXT PRIMARY KEY, data BLOB NOT NULL) WITHOUT ROWID`, table)
+	if _, err := db.ExecContext(ctx, create); err != nil {
+		return result, fmt.Errorf("error creating table %s: %w", table, err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT OR REPLACE INTO %q (id, data) VALUES (?, ?)`, table))
+	if err != nil {
+		return result, err
+	}
+	defer insert.Close()
+
+	_, err = collection_file.Scan(path, recordSize, func(rec collection_file.Record) error {
+		if rec.State != collection_file.StateActive {
+			result.Skipped++
+			return nil
+		}
+		var fields struct {
+			ID string `json:"id"`
+		}
+		id, err := uuid.Nil, json.Unmarshal(rec.Data, &fields)
+		if err == nil {
+			id, err = uuid.Parse(fields.ID)
+		}
+		if err != nil {
+			logger.Warn("skipping record without valid id", "path", path, "offset", rec.Offset)
+			result.Skipped++
+			return nil
+		}
+		if _, err := insert.ExecContext(ctx, id.String(), rec.Data); err != nil {
+			return fmt.Errorf("error inserting record at offset %d: %w", rec.Offset, err)
+		}
+		result.Imported++
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("error committing import: %w", err)
+	}
+	return result, nil
+}
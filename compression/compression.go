@@ -0,0 +1,332 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// پکیج compression بدنه پاسخ‌ها را بر اساس Accept-Encoding فشرده می‌کند. gzip و
+// deflate داخلی هستند و کدگذاری‌های دیگر مثل br با Register اضافه می‌شوند.
+
+var logger = logging.For("compression")
+
+// DefaultMinSize is the smallest body Middleware compresses (1 KiB); smaller
+// bodies do not get smaller enough to pay for the CPU.
+const DefaultMinSize = 1024
+
+// NewWriter returns a writer that compresses into w at level, where 0 means the
+// encoder's default level.
+type NewWriter func(w io.Writer, level int) (io.WriteCloser, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]NewWriter{
+		"gzip": func(w io.Writer, level int) (io.WriteCloser, error) {
+			if level == 0 {
+				level = gzip.DefaultCompression
+			}
+			return gzip.NewWriterLevel(w, level)
+		},
+		"deflate": func(w io.Writer, level int) (io.WriteCloser, error) {
+			if level == 0 {
+				level = flate.DefaultCompression
+			}
+			return flate.NewWriter(w, level)
+		},
+	}
+)
+
+// Register adds a content coding, e.g. brotli:
+//
+//	compression.Register("br", func(w io.Writer, level int) (io.WriteCloser, error) {
+//		if level == 0 {
+//			level = brotli.DefaultCompression
+//		}
+//		return brotli.NewWriterLevel(w, level), nil
+//	})
+//
+// Writers that implement Flush() error are flushed when the handler flushes.
+func Register(encoding string, newWriter NewWriter) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(encoding)] = newWriter
+}
+
+func encoder(encoding string) NewWriter {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	return encoders[encoding]
+}
+
+// DefaultExcludedContentTypes are media types that are already compressed.
+var DefaultExcludedContentTypes = []string{
+	"image/*", "video/*", "audio/*", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/pdf",
+	"application/x-protobuf", "text/event-stream",
+}
+
+// Options configures Middleware.
+type Options struct {
+	// Encodings are the content codings in the order the server prefers them when
+	// the client accepts several equally (default br, zstd, gzip, deflate). Codings
+	// that are not registered are skipped.
+	Encodings []string
+	// Level is the compression level passed to the encoder; 0 uses its default.
+	Level int
+	// MinSize is the smallest body that is compressed (default DefaultMinSize); a
+	// negative value compresses every body.
+	MinSize int
+	// ExcludedPaths are path prefixes that are never compressed, e.g. "/metrics".
+	ExcludedPaths []string
+	// ExcludedContentTypes are media types, or type/* patterns, that are never
+	// compressed (default DefaultExcludedContentTypes).
+	ExcludedContentTypes []string
+}
+
+func (o *Options) defaults() {
+	if len(o.Encodings) == 0 {
+		o.Encodings = []string{"br", "zstd", "gzip", "deflate"}
+	}
+	if o.MinSize == 0 {
+		o.MinSize = DefaultMinSize
+	}
+	if o.ExcludedContentTypes == nil {
+		o.ExcludedContentTypes = DefaultExcludedContentTypes
+	}
+}
+
+// Middleware compresses response bodies with the content coding the client
+// prefers according to Accept-Encoding:
+//
+//	engine.Use(compression.Middleware(compression.Options{ExcludedPaths: []string{"/metrics"}}))
+//
+// Responses are sent uncompressed when they are smaller than MinSize, have an
+// excluded content type, already have a Content-Encoding, or answer HEAD, Range
+// or upgrade requests. Compressed responses lose their Content-Length.
+func Middleware(opts Options) mygin.HandlerFunc {
+	opts.defaults()
+	return func(c *mygin.Context) {
+		if !compressible(c.Req, opts.ExcludedPaths) {
+			c.Next()
+			return
+		}
+		encoding := negotiate(c.GetHeader("Accept-Encoding"), opts.Encodings)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, opts: &opts, encoding: encoding}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if err := w.close(); err != nil {
+				logger.Warn("error finishing compressed response", "path", c.Path, "encoding", encoding, "error", err)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// compressible reports whether the response to req may be compressed at all.
+func compressible(req *http.Request, excludedPaths []string) bool {
+	if req.Method == http.MethodHead || req.Header.Get("Range") != "" || req.Header.Get("Upgrade") != "" {
+		return false
+	}
+	for _, prefix := range excludedPaths {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// negotiate returns the registered coding among preferred with the highest
+// quality in the Accept-Encoding header, or "" to send the body as it is.
+func negotiate(header string, preferred []string) string {
+	if header == "" {
+		return ""
+	}
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range preferred {
+		q, ok := qualities[encoding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ && encoder(encoding) != nil {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of the body until it knows whether the
+// response is worth compressing, then writes through the encoder or directly.
+type compressWriter struct {
+	http.ResponseWriter
+	opts     *Options
+	encoding string
+
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	enc     io.WriteCloser // nil when the body is sent uncompressed
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if code < http.StatusOK {
+		// پاسخ‌های 1xx مثل 103 Early Hints مستقیم فرستاده می‌شوند
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		w.buf.Write(b)
+		if w.buf.Len() < w.opts.MinSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends what was written so far, compressing it when the response is
+// eligible regardless of MinSize, since a streamed body's size is unknown.
+func (w *compressWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			logger.Warn("error flushing compressed response", "encoding", w.encoding, "error", err)
+			return
+		}
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			logger.Warn("error flushing compressed response", "encoding", w.encoding, "error", err)
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide sends the header, compressed when big is true and the response is
+// eligible, followed by the buffered body.
+func (w *compressWriter) decide(big bool) error {
+	w.decided = true
+	header := w.Header()
+	if big && w.eligible() {
+		enc, err := encoder(w.encoding)(w.ResponseWriter, w.opts.Level)
+		if err != nil {
+			// بدون فشرده‌سازی ادامه می‌دهیم تا پاسخ از دست نرود
+			logger.Error("error creating compressed writer", "encoding", w.encoding, "error", err)
+		} else {
+			w.enc = enc
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			header.Add("Vary", "Accept-Encoding")
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	if err != nil {
+		return fmt.Errorf("error writing response body: %w", err)
+	}
+	return nil
+}
+
+// eligible reports whether the status and headers allow compressing the body.
+func (w *compressWriter) eligible() bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buf.Bytes())
+		header.Set("Content-Type", contentType)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	typ, _, _ := strings.Cut(mediaType, "/")
+	for _, excluded := range w.opts.ExcludedContentTypes {
+		if excluded == mediaType || excluded == typ+"/*" {
+			return false
+		}
+	}
+	return true
+}
+
+// close finishes the response after the handlers returned.
+func (w *compressWriter) close() error {
+	if !w.decided {
+		if w.status == 0 {
+			return nil
+		}
+		return w.decide(w.buf.Len() > 0 && (w.opts.MinSize < 0 || w.buf.Len() >= w.opts.MinSize))
+	}
+	if w.enc != nil {
+		return w.enc.Close()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package compression
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestMiddleware(t *testing.T) {
+	big := strings.Repeat("compress me ", 200)
+	router := mygin.New()
+	router.Use(Middleware(Options{ExcludedPaths: []string{"/metrics"}}))
+	router.GET("/big", func(c *mygin.Context) { c.Data(http.StatusOK, "text/plain", []byte(big)) })
+	router.GET("/small", func(c *mygin.Context) { c.JSON(http.StatusOK, mygin.H{"ok": true}) })
+	router.GET("/png", func(c *mygin.Context) { c.Data(http.StatusOK, "image/png", []byte(big)) })
+	router.GET("/metrics", func(c *mygin.Context) { c.Data(http.StatusOK, "text/plain", []byte(big)) })
+	router.GET("/stream", func(c *mygin.Context) {
+		c.Writer.Header().Set("Content-Type", "text/plain")
+		c.Writer.Write([]byte("part"))
+		c.Writer.(http.Flusher).Flush()
+		c.Writer.Write([]byte(" two"))
+	})
+
+	send := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("/big", "gzip, deflate")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("big: %v", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != big {
+		t.Fatalf("gzip body does not round-trip: %d bytes", len(body))
+	}
+
+	w = send("/big", "gzip;q=0.5, deflate")
+	if w.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("q values: %v", w.Header())
+	}
+	if body, _ := io.ReadAll(flate.NewReader(w.Body)); string(body) != big {
+		t.Fatal("deflate body does not round-trip")
+	}
+
+	for _, tt := range []struct{ path, acceptEncoding string }{
+		{"/small", "gzip"},
+		{"/png", "gzip"},
+		{"/metrics", "gzip"},
+		{"/big", "br"},
+		{"/big", ""},
+		{"/big", "gzip;q=0"},
+	} {
+		w := send(tt.path, tt.acceptEncoding)
+		if w.Header().Get("Content-Encoding") != "" || w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("%s with %q was compressed: %v", tt.path, tt.acceptEncoding, w.Header())
+		}
+	}
+
+	w = send("/stream", "gzip")
+	zr, err = gzip.NewReader(w.Body)
+	if err != nil || !w.Flushed {
+		t.Fatalf("stream: %v flushed=%v", err, w.Flushed)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != "part two" {
+		t.Fatalf("stream body %q", body)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("identity-test", func(w io.Writer, level int) (io.WriteCloser, error) {
+		return nopWriteCloser{w}, nil
+	})
+	if got := negotiate("identity-test, gzip", []string{"identity-test", "gzip"}); got != "identity-test" {
+		t.Fatalf("negotiate = %q", got)
+	}
+	if got := negotiate("*", []string{"br", "gzip"}); got != "gzip" {
+		t.Fatalf("negotiate(*) skipped unregistered br: %q", got)
+	}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
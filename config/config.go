@@ -0,0 +1,303 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"gopkg.in/yaml.v3"
+)
+
+// پکیج config تنظیمات تایپ‌شده را از مقادیر پیش‌فرض، فایل JSON/YAML و متغیرهای محیطی
+// (به همین ترتیب اولویت) بارگذاری می‌کند تا تنظیمات سرور، ذخیره‌سازی و میان‌افزارها
+// از یک جا بیایند.
+//
+// تگ‌های پشتیبانی‌شده روی فیلدهای struct:
+//
+//	json:"port"        نام کلید در فایل (برای YAML هم همین نام استفاده می‌شود)
+//	default:"8080"     مقدار پیش‌فرض
+//	env:"PORT"         نام بخش متغیر محیطی (پیش‌فرض: نام فیلد با حروف بزرگ)
+//	required:"true"    بعد از بارگذاری نباید مقدار صفر باشد
+//
+// نام متغیر محیطی از پیشوند و مسیر فیلدها ساخته می‌شود، مثلاً IRIS_SERVER_PORT.
+
+// Validator is implemented by configuration structs that check themselves after loading.
+type Validator interface {
+	Validate() error
+}
+
+// Loader loads a configuration struct of type T and keeps the last valid value.
+type Loader[T any] struct {
+	// Path is the optional JSON or YAML file. A missing file is not an error.
+	Path string
+	// EnvPrefix prefixes every environment variable name, e.g. "IRIS".
+	EnvPrefix string
+	// LookupEnv reads environment variables; defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+
+	mu      sync.RWMutex
+	current T
+	loaded  bool
+	modTime time.Time
+	size    int64
+
+	subsMu sync.Mutex
+	subs   map[int]func(T)
+	nextID int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a loader for the given file and environment prefix.
+func New[T any](path, envPrefix string) *Loader[T] {
+	return &Loader[T]{Path: path, EnvPrefix: envPrefix}
+}
+
+// Load reads the configuration once without changing the loader state.
+func (l *Loader[T]) Load() (T, error) {
+	var cfg T
+	value := reflect.ValueOf(&cfg).Elem()
+	if value.Kind() != reflect.Struct {
+		return cfg, fmt.Errorf("config type must be a struct, got %s", value.Type())
+	}
+
+	if err := applyDefaults(value); err != nil {
+		return cfg, fmt.Errorf("error applying defaults: %w", err)
+	}
+	if l.Path != "" {
+		if err := loadFile(l.Path, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+	lookup := l.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	if err := applyEnv(value, l.EnvPrefix, lookup); err != nil {
+		return cfg, fmt.Errorf("error applying environment: %w", err)
+	}
+	if err := checkRequired(value, ""); err != nil {
+		return cfg, err
+	}
+	if v, ok := any(&cfg).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return cfg, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// Reload loads the configuration and, if it is valid, makes it current and
+// notifies subscribers when it changed. On error the previous value is kept.
+func (l *Loader[T]) Reload() error {
+	modTime, size := l.stat()
+	cfg, err := l.Load()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	changed := !l.loaded || !reflect.DeepEqual(l.current, cfg)
+	l.current = cfg
+	l.loaded = true
+	l.modTime, l.size = modTime, size
+	l.mu.Unlock()
+
+	if changed {
+		l.notify(cfg)
+	}
+	return nil
+}
+
+// Set makes cfg current after checking required fields and Validate, and notifies
+// subscribers when it changed. It is meant for changes made at runtime, e.g. by an
+// admin endpoint; they last until the next reload of a changed file.
+func (l *Loader[T]) Set(cfg T) error {
+	value := reflect.ValueOf(&cfg).Elem()
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("config type must be a struct, got %s", value.Type())
+	}
+	if err := checkRequired(value, ""); err != nil {
+		return err
+	}
+	if v, ok := any(&cfg).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
+	l.mu.Lock()
+	changed := !l.loaded || !reflect.DeepEqual(l.current, cfg)
+	l.current = cfg
+	l.loaded = true
+	l.mu.Unlock()
+
+	if changed {
+		l.notify(cfg)
+	}
+	return nil
+}
+
+// Current returns the last successfully loaded configuration.
+func (l *Loader[T]) Current() T {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Subscribe registers fn to be called with every new configuration after a
+// successful reload that changed it.
+func (l *Loader[T]) Subscribe(fn func(T)) (unsubscribe func()) {
+	l.subsMu.Lock()
+	defer l.subsMu.Unlock()
+	if l.subs == nil {
+		l.subs = make(map[int]func(T))
+	}
+	id := l.nextID
+	l.nextID++
+	l.subs[id] = fn
+	return func() {
+		l.subsMu.Lock()
+		defer l.subsMu.Unlock()
+		delete(l.subs, id)
+	}
+}
+
+func (l *Loader[T]) notify(cfg T) {
+	l.subsMu.Lock()
+	subs := make([]func(T), 0, len(l.subs))
+	for _, fn := range l.subs {
+		subs = append(subs, fn)
+	}
+	l.subsMu.Unlock()
+
+	for _, fn := range subs {
+		fn(cfg)
+	}
+}
+
+// Watch polls the file every interval and reloads it when its modification time
+// or size changes. Reload errors are passed to onError (which may be nil) and the
+// previous configuration stays current. Watch returns after the first load; call
+// Stop to end watching.
+func (l *Loader[T]) Watch(interval time.Duration, onError func(error)) error {
+	if err := l.Reload(); err != nil {
+		return err
+	}
+	if l.Path == "" {
+		return nil
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	l.mu.Lock()
+	if l.stop != nil {
+		l.mu.Unlock()
+		return nil
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	l.stop, l.done = stop, done
+	l.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				modTime, size := l.stat()
+				l.mu.RLock()
+				unchanged := modTime.Equal(l.modTime) && size == l.size
+				l.mu.RUnlock()
+				if unchanged {
+					continue
+				}
+				if err := l.Reload(); err != nil {
+					// برای جلوگیری از تکرار خطا روی همان نسخه فایل، وضعیت آن را ثبت می‌کنیم
+					l.mu.Lock()
+					l.modTime, l.size = modTime, size
+					l.mu.Unlock()
+					if onError != nil {
+						onError(err)
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop ends watching started by Watch.
+func (l *Loader[T]) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	stop, done := l.stop, l.done
+	l.stop, l.done = nil, nil
+	l.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Loader[T]) stat() (time.Time, int64) {
+	if l.Path == "" {
+		return time.Time{}, 0
+	}
+	info, err := os.Stat(l.Path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
+
+// loadFile decodes a JSON or YAML file over cfg. YAML is converted to JSON first
+// so that json tags name the keys for both formats.
+func loadFile(path string, cfg any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var raw any
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("error parsing YAML config: %w", err)
+		}
+		if raw == nil {
+			return nil
+		}
+		if data, err = json.Marshal(raw); err != nil {
+			return fmt.Errorf("error converting YAML config: %w", err)
+		}
+	case ".json", "":
+	default:
+		return fmt.Errorf("unsupported config file type: %s", filepath.Ext(path))
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("error parsing config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type serverConfig struct {
+	Host    string        `json:"host" default:"0.0.0.0"`
+	Port    int           `json:"port" default:"8080"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+}
+
+type testConfig struct {
+	Server  serverConfig `json:"server"`
+	DataDir string       `json:"data_dir" env:"DATA_DIR" required:"true"`
+	Origins []string     `json:"origins"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Server.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 9000\ndata_dir: /var/iris\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"IRIS_SERVER_HOST": "127.0.0.1", "IRIS_ORIGINS": "a.com, b.com"}
+	loader := New[testConfig](path, "IRIS")
+	loader.LookupEnv = func(key string) (string, bool) { v, ok := env[key]; return v, ok }
+
+	changes := 0
+	loader.Subscribe(func(testConfig) { changes++ })
+	if err := loader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := loader.Current()
+	if cfg.Server.Port != 9000 || cfg.Server.Host != "127.0.0.1" || cfg.Server.Timeout != 5*time.Second {
+		t.Fatalf("unexpected server config %+v", cfg.Server)
+	}
+	if cfg.DataDir != "/var/iris" || len(cfg.Origins) != 2 || cfg.Origins[1] != "b.com" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	// مقدار نامعتبر رد می‌شود و تنظیمات قبلی باقی می‌ماند
+	env["IRIS_SERVER_PORT"] = "-1"
+	if err := loader.Reload(); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if loader.Current().Server.Port != 9000 {
+		t.Fatal("invalid reload replaced the current config")
+	}
+
+	delete(env, "IRIS_SERVER_PORT")
+	env["IRIS_DATA_DIR"] = "/srv/iris"
+	if err := loader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if changes != 2 || loader.Current().DataDir != "/srv/iris" {
+		t.Fatalf("expected 2 notifications and env override, got %d %+v", changes, loader.Current())
+	}
+
+	os.WriteFile(path, []byte("server:\n  port: 9000\n"), 0644)
+	delete(env, "IRIS_DATA_DIR")
+	if err := loader.Reload(); err == nil {
+		t.Fatal("expected missing required value error")
+	}
+}
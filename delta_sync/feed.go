@@ -0,0 +1,319 @@
+package delta_sync
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/metadata"
+	"github.com/mahdi-cpp/iris-tools/uuidutil"
+)
+
+// پکیج delta_sync برای هر کالکشن یک فید تغییرات نگه می‌دارد تا کلاینت‌ها (مثلاً اپ
+// آفلاین عکس‌ها) فقط رکوردهایی را که از آخرین همگام‌سازی تغییر کرده‌اند بگیرند.
+//
+// هر تغییر یک رکورد در <name>_changes.db با شناسه UUIDv7 می‌گیرد و ترتیب شناسه‌ها ترتیب
+// تغییرات است. برای هر آیتم فقط آخرین تغییرش نگه داشته می‌شود، پس لاگ هیچ‌وقت بزرگ‌تر از
+// تعداد آیتم‌ها به‌علاوه حذف‌ها نمی‌شود و همگام‌سازی کامل همان خواندن لاگ از ابتدا است.
+// توکن همگام‌سازی شناسه آخرین تغییر تحویل‌داده‌شده است (st_<short>).
+
+var logger = logging.For("delta_sync")
+
+var (
+	ErrInvalidToken = errors.New("invalid sync token")
+	// ErrTokenExpired means deletions after the token were pruned; the client must
+	// drop its local copy and sync again from an empty token.
+	ErrTokenExpired = errors.New("sync token expired")
+)
+
+const tokenPrefix uuidutil.Prefix = "st"
+
+// Op is the kind of a change entry.
+type Op string
+
+const (
+	OpUpsert Op = "upsert"
+	OpDelete Op = "delete"
+)
+
+// LogEntry is a record of the change log. ID orders the log.
+type LogEntry struct {
+	ID      uuid.UUID `json:"id"`
+	ItemID  uuid.UUID `json:"itemId"`
+	Deleted bool      `json:"deleted,omitempty"`
+}
+
+func (e *LogEntry) SetID(id uuid.UUID) { e.ID = id }
+func (e *LogEntry) GetID() uuid.UUID   { return e.ID }
+func (e *LogEntry) GetRecordSize() int { return 160 }
+
+// Entry is one change returned to clients. Item is the current value for upserts.
+type Entry[T any] struct {
+	Op   Op        `json:"op"`
+	ID   uuid.UUID `json:"id"`
+	Item T         `json:"item,omitempty"`
+}
+
+// Page is a batch of changes. Token is passed to the next Changes call; More reports
+// whether more changes are available right away.
+type Page[T any] struct {
+	Changes []Entry[T] `json:"changes"`
+	Token   string     `json:"token"`
+	More    bool       `json:"more"`
+}
+
+type state struct {
+	Horizon uuid.UUID `json:"horizon"` // tokens before this are expired
+}
+
+// Feed tracks the changes of a collection_manager_memory collection.
+type Feed[T collection_manager_memory.CollectionItem] struct {
+	manager *collection_manager_memory.Manager[T]
+	log     *collection_manager_memory.Manager[*LogEntry]
+	state   *metadata.Control[state]
+
+	mu      sync.RWMutex
+	entries []*LogEntry             // sorted by ID
+	latest  map[uuid.UUID]*LogEntry // item id -> its entry
+	horizon uuid.UUID
+	stop    func()
+}
+
+// NewFeed opens the change log dir/<name>_changes.db for manager. It should be
+// created at startup before the collection is written to; items changed while no
+// feed was attached are reconciled by existence only.
+func NewFeed[T collection_manager_memory.CollectionItem](dir, name string, manager *collection_manager_memory.Manager[T]) (*Feed[T], error) {
+	log, err := collection_manager_memory.New[*LogEntry](dir, name+"_changes")
+	if err != nil {
+		return nil, fmt.Errorf("error opening change log: %w", err)
+	}
+	f := &Feed[T]{
+		manager: manager,
+		log:     log,
+		state:   metadata.NewMetadataControl[state](filepath.Join(dir, name+"_sync.json")),
+		latest:  make(map[uuid.UUID]*LogEntry),
+	}
+
+	st, err := f.state.Read(false)
+	if err != nil {
+		log.Close()
+		return nil, fmt.Errorf("error reading sync state: %w", err)
+	}
+	f.horizon = st.Horizon
+
+	entries, err := log.ReadAll()
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return less(entries[i].ID, entries[j].ID) })
+	for _, e := range entries {
+		if old, ok := f.latest[e.ItemID]; ok {
+			f.dropLocked(old) // از اجرای ناقص قبلی باقی مانده
+		}
+		f.entries = append(f.entries, e)
+		f.latest[e.ItemID] = e
+	}
+
+	f.stop = manager.OnChange(func(change collection_manager_memory.Change[T]) {
+		f.record(change.ID, change.Type == collection_manager_memory.ChangeDelete)
+	})
+	if err := f.reconcile(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// reconcile adds upserts for items missing from the log and deletes for logged
+// items that no longer exist.
+func (f *Feed[T]) reconcile() error {
+	items, err := f.manager.ReadAll()
+	if err != nil {
+		return err
+	}
+	exists := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		exists[item.GetID()] = true
+	}
+
+	f.mu.RLock()
+	var missing, gone []uuid.UUID
+	for id := range exists {
+		if e, ok := f.latest[id]; !ok || e.Deleted {
+			missing = append(missing, id)
+		}
+	}
+	for id, e := range f.latest {
+		if !e.Deleted && !exists[id] {
+			gone = append(gone, id)
+		}
+	}
+	f.mu.RUnlock()
+
+	for _, id := range missing {
+		f.record(id, false)
+	}
+	for _, id := range gone {
+		f.record(id, true)
+	}
+	return nil
+}
+
+// record appends a change for itemID and drops the item's previous entry.
+func (f *Feed[T]) record(itemID uuid.UUID, deleted bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, err := f.log.Create(&LogEntry{ItemID: itemID, Deleted: deleted})
+	if err != nil {
+		logger.Error("error writing change log", "item", itemID, "error", err)
+		return
+	}
+	if old, ok := f.latest[itemID]; ok {
+		f.dropLocked(old)
+	}
+	f.entries = append(f.entries, entry)
+	f.latest[itemID] = entry
+}
+
+func (f *Feed[T]) dropLocked(e *LogEntry) {
+	if err := f.log.Delete(e.ID); err != nil {
+		logger.Error("error removing change log entry", "id", e.ID, "error", err)
+	}
+	i := sort.Search(len(f.entries), func(i int) bool { return !less(f.entries[i].ID, e.ID) })
+	if i < len(f.entries) && f.entries[i].ID == e.ID {
+		f.entries = append(f.entries[:i], f.entries[i+1:]...)
+	}
+	if f.latest[e.ItemID] == e {
+		delete(f.latest, e.ItemID)
+	}
+}
+
+// Token returns the token of the newest change, e.g. to hand out after a full export.
+func (f *Feed[T]) Token() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.entries) == 0 {
+		return f.formatToken(f.horizon)
+	}
+	return f.formatToken(f.entries[len(f.entries)-1].ID)
+}
+
+// Changes returns up to limit changes after token. An empty token starts from the
+// beginning and yields every existing item.
+func (f *Feed[T]) Changes(token string, limit int) (Page[T], error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	since, err := parseToken(token)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	f.mu.RLock()
+	if token != "" && less(since, f.horizon) {
+		f.mu.RUnlock()
+		return Page[T]{}, ErrTokenExpired
+	}
+	start := sort.Search(len(f.entries), func(i int) bool { return less(since, f.entries[i].ID) })
+	end := min(start+limit, len(f.entries))
+	window := make([]LogEntry, 0, end-start)
+	for _, e := range f.entries[start:end] {
+		window = append(window, *e)
+	}
+	more := end < len(f.entries)
+	f.mu.RUnlock()
+
+	// آیتم‌ها بیرون از قفل فید خوانده می‌شوند؛ هوک‌های Manager با قفل Manager قفل فید را می‌گیرند
+	page := Page[T]{Changes: make([]Entry[T], 0, len(window)), More: more, Token: token}
+	for _, e := range window {
+		page.Token = f.formatToken(e.ID)
+		if e.Deleted {
+			page.Changes = append(page.Changes, Entry[T]{Op: OpDelete, ID: e.ItemID})
+			continue
+		}
+		item, err := f.manager.Read(e.ItemID)
+		if err != nil {
+			continue // بین خواندن لاگ و آیتم حذف شده؛ تغییر حذف بعداً می‌آید
+		}
+		page.Changes = append(page.Changes, Entry[T]{Op: OpUpsert, ID: e.ItemID, Item: item})
+	}
+	if page.Token == "" {
+		page.Token = f.Token()
+	}
+	return page, nil
+}
+
+// Prune removes deletions older than age and compacts the log. Clients whose token
+// is older than the newest pruned deletion get ErrTokenExpired.
+func (f *Feed[T]) Prune(age time.Duration) (int, error) {
+	cutoff := time.Now().Add(-age)
+
+	f.mu.Lock()
+	var pruned []*LogEntry
+	for _, e := range f.entries {
+		if at, err := uuidutil.Time(e.ID); err != nil || at.After(cutoff) {
+			break
+		}
+		if e.Deleted {
+			pruned = append(pruned, e)
+		}
+	}
+	for _, e := range pruned {
+		f.dropLocked(e)
+	}
+	var err error
+	if len(pruned) > 0 {
+		f.horizon = pruned[len(pruned)-1].ID
+		err = f.state.Write(&state{Horizon: f.horizon})
+	}
+	f.mu.Unlock()
+
+	if err != nil {
+		return len(pruned), fmt.Errorf("error saving sync state: %w", err)
+	}
+	if err := f.log.Compact(); err != nil {
+		return len(pruned), fmt.Errorf("error compacting change log: %w", err)
+	}
+	return len(pruned), nil
+}
+
+// Close detaches the feed from the collection and closes the log.
+func (f *Feed[T]) Close() error {
+	f.stop()
+	return f.log.Close()
+}
+
+func (f *Feed[T]) formatToken(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return tokenPrefix.Format(id)
+}
+
+func parseToken(token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, nil
+	}
+	id, err := tokenPrefix.Parse(token)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return id, nil
+}
+
+func less(a, b uuid.UUID) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
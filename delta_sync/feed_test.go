@@ -0,0 +1,101 @@
+package delta_sync
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type photo struct {
+	ID      uuid.UUID `json:"id"`
+	Caption string    `json:"caption"`
+}
+
+func (p *photo) SetID(id uuid.UUID) { p.ID = id }
+func (p *photo) GetID() uuid.UUID   { return p.ID }
+func (p *photo) GetRecordSize() int { return 256 }
+
+func TestFeed(t *testing.T) {
+	dir := t.TempDir()
+	photos, err := collection_manager_memory.New[*photo](dir, "photos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer photos.Close()
+
+	// آیتمی که قبل از وصل شدن فید ساخته شده با reconcile وارد لاگ می‌شود
+	early, _ := photos.Create(&photo{Caption: "early"})
+
+	feed, err := NewFeed(dir, "photos", photos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := photos.Create(&photo{Caption: "a"})
+	b, _ := photos.Create(&photo{Caption: "b"})
+
+	page, err := feed.Changes("", 2)
+	if err != nil || len(page.Changes) != 2 || !page.More || page.Changes[0].ID != early.ID {
+		t.Fatalf("unexpected first page: %+v %v", page, err)
+	}
+	page, _ = feed.Changes(page.Token, 2)
+	if len(page.Changes) != 1 || page.More || page.Changes[0].ID != b.ID {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+	token := page.Token
+
+	a.Caption = "a2"
+	photos.Update(a)
+	photos.Delete(b.ID)
+	page, _ = feed.Changes(token, 10)
+	if len(page.Changes) != 2 || page.Changes[0].Item.Caption != "a2" || page.Changes[1].Op != OpDelete {
+		t.Fatalf("unexpected delta: %+v", page)
+	}
+	if page, _ := feed.Changes(page.Token, 10); len(page.Changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", page)
+	}
+
+	// لاگ فقط آخرین تغییر هر آیتم را نگه می‌دارد
+	if full, _ := feed.Changes("", 100); len(full.Changes) != 3 {
+		t.Fatalf("expected compacted log of 3 entries, got %+v", full)
+	}
+
+	if n, err := feed.Prune(0); err != nil || n != 1 {
+		t.Fatalf("expected one pruned deletion, got %d %v", n, err)
+	}
+	if _, err := feed.Changes(token, 10); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired, got %v", err)
+	}
+	if _, err := feed.Changes("nope", 10); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+	feed.Close()
+
+	// بعد از باز کردن دوباره، لاگ و horizon از دیسک خوانده می‌شوند
+	feed, err = NewFeed(dir, "photos", photos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer feed.Close()
+	if _, err := feed.Changes(token, 10); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("horizon not persisted: %v", err)
+	}
+
+	engine := mygin.New()
+	feed.Mount(engine.Group("/sync"), "/photos")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/sync/photos?limit=1", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"more":true`) {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body)
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/sync/photos?token="+token, nil))
+	if w.Code != http.StatusGone {
+		t.Fatalf("expected 410, got %d", w.Code)
+	}
+}
@@ -0,0 +1,46 @@
+package delta_sync
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Handler serves the feed to polling clients:
+//
+//	GET /sync/photos?token=st_...&limit=100
+//
+//	{"changes":[{"op":"upsert","id":"...","item":{...}},{"op":"delete","id":"..."}],
+//	 "token":"st_...","more":false}
+//
+// A client starts with an empty token, applies the changes, stores the returned
+// token and polls again (immediately while more is true). An expired token is
+// answered with 410 Gone and the client must sync again from scratch.
+func (f *Feed[T]) Handler(maxLimit int) mygin.HandlerFunc {
+	if maxLimit <= 0 {
+		maxLimit = 500
+	}
+	return func(c *mygin.Context) {
+		limit := min(c.GetQueryIntDefault("limit", 100), maxLimit)
+		page, err := f.Changes(c.GetQuery("token"), limit)
+		switch {
+		case errors.Is(err, ErrTokenExpired):
+			c.JSON(http.StatusGone, mygin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrInvalidToken):
+			c.JSON(http.StatusBadRequest, mygin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, mygin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// Mount registers Handler on group as GET path.
+func (f *Feed[T]) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	handlers := append(append([]mygin.HandlerFunc{}, middleware...), f.Handler(0))
+	group.GET(path, handlers...)
+}
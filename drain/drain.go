@@ -0,0 +1,233 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+var logger = logging.For("drain")
+
+// ErrDraining is returned by Drain when a drain is already running or finished.
+var ErrDraining = errors.New("server is already draining")
+
+// Options configures a Drainer.
+type Options struct {
+	// ReadinessDelay is how long Drain keeps accepting connections after readiness
+	// starts failing, so load balancers stop sending new traffic first (default 0).
+	ReadinessDelay time.Duration
+}
+
+// Status reports the progress of a drain.
+type Status struct {
+	Draining  bool      `json:"draining"`
+	Done      bool      `json:"done"`
+	InFlight  int       `json:"inFlight"`
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	Elapsed   string    `json:"elapsed,omitempty"`
+}
+
+// Drainer takes a server out of rotation for zero-downtime deploys. Drain makes
+// readiness fail, waits ReadinessDelay, closes the listeners passed to Listener,
+// disables keep-alives and waits for the requests tracked by Middleware:
+//
+//	d := drain.New(drain.Options{ReadinessDelay: 5 * time.Second})
+//	engine.Use(d.Middleware())
+//	engine.GET("/ready", d.ReadyHandler())
+//	d.Mount(adminGroup, "/drain", authMiddleware)
+//	srv := &http.Server{Handler: engine}
+//	d.TrackServer(srv)
+//	srv.Serve(d.Listener(listener))
+type Drainer struct {
+	opts Options
+
+	mu        sync.Mutex
+	inFlight  int
+	idle      chan struct{} // closed when inFlight drops to zero while draining
+	draining  bool
+	done      bool
+	startedAt time.Time
+	listeners []net.Listener
+	servers   []*http.Server
+}
+
+// New creates a Drainer.
+func New(opts Options) *Drainer {
+	return &Drainer{opts: opts}
+}
+
+// Listener registers l to be closed by Drain and returns it.
+func (d *Drainer) Listener(l net.Listener) net.Listener {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners = append(d.listeners, l)
+	if d.draining {
+		l.Close()
+	}
+	return l
+}
+
+// TrackServer makes Drain disable keep-alives of srv, so idle connections are
+// closed instead of carrying new requests.
+func (d *Drainer) TrackServer(srv *http.Server) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.servers = append(d.servers, srv)
+}
+
+// Ready reports whether the server should receive new traffic.
+func (d *Drainer) Ready() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.draining
+}
+
+// Status returns the drain progress.
+func (d *Drainer) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := Status{Draining: d.draining, Done: d.done, InFlight: d.inFlight}
+	if d.draining {
+		s.StartedAt = d.startedAt
+		s.Elapsed = time.Since(d.startedAt).Round(time.Millisecond).String()
+	}
+	return s
+}
+
+// Middleware tracks in-flight requests. Responses sent while draining carry
+// "Connection: close".
+func (d *Drainer) Middleware() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		d.mu.Lock()
+		d.inFlight++
+		draining := d.draining
+		d.mu.Unlock()
+		defer d.release()
+
+		if draining {
+			c.Writer.Header().Set("Connection", "close")
+		}
+		c.Next()
+	}
+}
+
+func (d *Drainer) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inFlight--
+	if d.inFlight == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+// Drain takes the server out of rotation and waits until no tracked request is in
+// flight or ctx ends. It returns ErrDraining when called a second time.
+func (d *Drainer) Drain(ctx context.Context) error {
+	d.mu.Lock()
+	if d.draining {
+		d.mu.Unlock()
+		return ErrDraining
+	}
+	d.draining = true
+	d.startedAt = time.Now()
+	d.mu.Unlock()
+	logger.Info("drain started", "readinessDelay", d.opts.ReadinessDelay)
+
+	if d.opts.ReadinessDelay > 0 {
+		timer := time.NewTimer(d.opts.ReadinessDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	d.mu.Lock()
+	for _, l := range d.listeners {
+		if err := l.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			logger.Warn("error closing listener", "addr", l.Addr(), "error", err)
+		}
+	}
+	for _, srv := range d.servers {
+		srv.SetKeepAlivesEnabled(false)
+	}
+	idle := make(chan struct{})
+	if d.inFlight == 0 {
+		close(idle)
+	} else {
+		d.idle = idle
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		status := d.Status()
+		logger.Warn("drain deadline reached", "inFlight", status.InFlight, "elapsed", status.Elapsed)
+		return ctx.Err()
+	}
+
+	d.mu.Lock()
+	d.done = true
+	d.mu.Unlock()
+	logger.Info("drain completed", "elapsed", d.Status().Elapsed)
+	return nil
+}
+
+// ReadyHandler answers readiness probes: 200 while serving, 503 while draining.
+func (d *Drainer) ReadyHandler() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		if !d.Ready() {
+			c.JSON(http.StatusServiceUnavailable, mygin.H{"status": "draining"})
+			return
+		}
+		c.JSON(http.StatusOK, mygin.H{"status": "ready"})
+	}
+}
+
+// Mount registers the drain endpoint on group. The middleware must protect it.
+//
+//	GET  path   drain progress
+//	POST path   start draining (?timeout=30s, default 30s); answers 202 immediately
+func (d *Drainer) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{}, middleware...), handler)
+	}
+
+	group.GET(path, with(func(c *mygin.Context) {
+		c.JSON(http.StatusOK, d.Status())
+	})...)
+	group.POST(path, with(d.handleDrain)...)
+}
+
+func (d *Drainer) handleDrain(c *mygin.Context) {
+	timeout := 30 * time.Second
+	if raw := c.GetQuery("timeout"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid timeout"})
+			return
+		}
+		timeout = parsed
+	}
+	if !d.Ready() {
+		c.JSON(http.StatusConflict, mygin.H{"error": ErrDraining.Error(), "status": d.Status()})
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := d.Drain(ctx); err != nil && !errors.Is(err, ErrDraining) {
+			logger.Error("drain failed", "error", err)
+		}
+	}()
+	c.JSON(http.StatusAccepted, mygin.H{"status": "draining"})
+}
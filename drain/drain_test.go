@@ -0,0 +1,81 @@
+package drain
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestDrainWaitsForInFlightRequests(t *testing.T) {
+	d := New(Options{})
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	engine := mygin.New()
+	engine.Use(d.Middleware())
+	engine.GET("/ready", d.ReadyHandler())
+	engine.GET("/slow", func(c *mygin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: engine}
+	d.TrackServer(srv)
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(d.Listener(listener)) }()
+
+	go http.Get("http://" + listener.Addr().String() + "/slow")
+	<-started
+
+	drained := make(chan error, 1)
+	go func() { drained <- d.Drain(context.Background()) }()
+
+	// Serve پس از بسته شدن listener برمی‌گردد ولی درخواست در جریان ادامه دارد
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Fatal("listener was not closed")
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Connection") != "close" {
+		t.Fatalf("ready while draining: %d %v", w.Code, w.Header())
+	}
+	if s := d.Status(); !s.Draining || s.Done || s.InFlight != 1 {
+		t.Fatalf("status while waiting: %+v", s)
+	}
+
+	close(release)
+	if err := <-drained; err != nil {
+		t.Fatal(err)
+	}
+	if s := d.Status(); !s.Done || s.InFlight != 0 {
+		t.Fatalf("status after drain: %+v", s)
+	}
+	if err := d.Drain(context.Background()); err != ErrDraining {
+		t.Fatalf("second drain: %v", err)
+	}
+}
+
+func TestDrainDeadline(t *testing.T) {
+	d := New(Options{})
+	d.mu.Lock()
+	d.inFlight = 1
+	d.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+}
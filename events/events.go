@@ -0,0 +1,179 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// پکیج events یک گذرگاه رویداد درون‌پردازه‌ای است که هوک‌های تغییر ذخیره‌سازی،
+// پوش HTTP (SSE/WebSocket) و کارهای پس‌زمینه را بدون کانال‌های پراکنده به هم وصل می‌کند.
+//
+// نام topic ها با نقطه جدا می‌شوند (مثلاً "photos.create"). در الگوی اشتراک
+// "*" دقیقاً یک بخش و ">" (فقط در انتها) یک یا چند بخش باقی‌مانده را پوشش می‌دهد.
+
+// DefaultBuffer is the subscription buffer used when Subscribe is given a non-positive size.
+const DefaultBuffer = 64
+
+// Event is a message delivered to subscribers.
+type Event struct {
+	Topic   string
+	Payload any
+	Time    time.Time
+}
+
+// Subscription receives the events matching its pattern on C until Unsubscribe
+// is called or the bus is closed. Delivery never blocks the publisher: when C is
+// full the event is dropped for this subscriber and counted in Dropped.
+type Subscription struct {
+	C <-chan Event
+
+	bus     *Bus
+	id      int
+	pattern string
+	ch      chan Event
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Pattern returns the topic pattern of the subscription.
+func (s *Subscription) Pattern() string { return s.pattern }
+
+// Dropped returns how many events were dropped because the buffer was full.
+func (s *Subscription) Dropped() uint64 { return s.dropped.Load() }
+
+// Unsubscribe stops delivery and closes C. It is safe to call more than once.
+func (s *Subscription) Unsubscribe() {
+	s.bus.remove(s)
+}
+
+// Bus is an in-process publish/subscribe event bus. The zero value is not usable; use New.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[int]*Subscription
+	nextID int
+	closed bool
+}
+
+// New returns an empty bus.
+func New() *Bus {
+	return &Bus{subs: make(map[int]*Subscription)}
+}
+
+// Subscribe registers a buffered subscription for the topics matching pattern.
+// On a closed bus the returned subscription is already closed.
+func (b *Bus) Subscribe(pattern string, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, bus: b, pattern: pattern, ch: ch}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.once.Do(func() { close(ch) })
+		return sub
+	}
+	sub.id = b.nextID
+	b.nextID++
+	b.subs[sub.id] = sub
+	return sub
+}
+
+// SubscribeFunc calls fn from a dedicated goroutine for every matching event.
+// The returned function unsubscribes and waits for the goroutine to finish.
+func (b *Bus) SubscribeFunc(pattern string, buffer int, fn func(Event)) (unsubscribe func()) {
+	sub := b.Subscribe(pattern, buffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range sub.C {
+			fn(event)
+		}
+	}()
+	return func() {
+		sub.Unsubscribe()
+		<-done
+	}
+}
+
+// Publish delivers payload to every subscription matching topic and returns the
+// number of subscribers that received it. It never blocks.
+func (b *Bus) Publish(topic string, payload any) int {
+	event := Event{Topic: topic, Payload: payload, Time: time.Now()}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return 0
+	}
+
+	delivered := 0
+	for _, sub := range b.subs {
+		if !Match(sub.pattern, topic) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+			delivered++
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+	return delivered
+}
+
+// Close closes every subscription; later publishes are ignored.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for id, sub := range b.subs {
+		delete(b.subs, id)
+		sub.once.Do(func() { close(sub.ch) })
+	}
+}
+
+// Len returns the number of active subscriptions.
+func (b *Bus) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+func (b *Bus) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[sub.id] == sub {
+		delete(b.subs, sub.id)
+	}
+	sub.once.Do(func() { close(sub.ch) })
+}
+
+// Match reports whether topic matches pattern. "*" matches exactly one segment
+// and a trailing ">" matches one or more remaining segments.
+func Match(pattern, topic string) bool {
+	if pattern == topic {
+		return true
+	}
+	patternParts := strings.Split(pattern, ".")
+	topicParts := strings.Split(topic, ".")
+
+	for i, part := range patternParts {
+		if part == ">" && i == len(patternParts)-1 {
+			return len(topicParts) > i
+		}
+		if i >= len(topicParts) {
+			return false
+		}
+		if part != "*" && part != topicParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(topicParts)
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"photos.create", "photos.create", true},
+		{"photos.*", "photos.create", true},
+		{"photos.*", "photos.create.thumb", false},
+		{"photos.>", "photos.create.thumb", true},
+		{"photos.>", "photos", false},
+		{"*.delete", "albums.delete", true},
+		{">", "anything.at.all", true},
+		{"photos.create", "photos.update", false},
+	}
+	for _, c := range cases {
+		if got := Match(c.pattern, c.topic); got != c.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", c.pattern, c.topic, got, c.want)
+		}
+	}
+}
+
+func TestBus(t *testing.T) {
+	bus := New()
+	defer bus.Close()
+
+	all := bus.Subscribe("photos.>", 4)
+	small := bus.Subscribe("photos.create", 1)
+
+	if n := bus.Publish("photos.create", 1); n != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", n)
+	}
+	bus.Publish("photos.create", 2) // small is full and drops this one
+	bus.Publish("albums.create", 3)
+
+	if small.Dropped() != 1 {
+		t.Fatalf("expected 1 dropped event, got %d", small.Dropped())
+	}
+	if event := <-all.C; event.Payload != 1 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if event := <-all.C; event.Payload != 2 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	small.Unsubscribe()
+	if _, ok := <-small.C; !ok {
+		t.Fatal("expected buffered event before close")
+	}
+	if _, ok := <-small.C; ok {
+		t.Fatal("expected closed channel after Unsubscribe")
+	}
+
+	counts := NewTopic[int]("counts")
+	received := make(chan int, 1)
+	stop := counts.Subscribe(bus, 1, func(v int) { received <- v })
+	defer stop()
+	counts.Publish(bus, 42)
+	select {
+	case v := <-received:
+		if v != 42 {
+			t.Fatalf("unexpected value %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("typed subscriber did not receive the event")
+	}
+}
@@ -0,0 +1,14 @@
+package events
+
+import "github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+
+// ForwardChanges publishes every change of a memory manager on bus under
+// "<prefix>.<create|update|delete>" with the collection_manager_memory.Change as
+// payload, so a subscription to "<prefix>.>" follows the whole collection.
+// The returned function stops forwarding.
+func ForwardChanges[T collection_manager_memory.CollectionItem](bus *Bus, prefix string, manager *collection_manager_memory.Manager[T]) (stop func()) {
+	// Publish هرگز بلاک نمی‌شود، پس اجرای آن داخل هوک (زیر قفل Manager) امن است
+	return manager.OnChange(func(change collection_manager_memory.Change[T]) {
+		bus.Publish(prefix+"."+string(change.Type), change)
+	})
+}
@@ -0,0 +1,30 @@
+package events
+
+// Topic is a typed topic name. Publishing through a Topic guarantees that its
+// subscribers always receive payloads of type T.
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic returns a typed topic with the given name.
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name returns the topic name.
+func (t Topic[T]) Name() string { return t.name }
+
+// Publish publishes value on bus under the topic name.
+func (t Topic[T]) Publish(bus *Bus, value T) int {
+	return bus.Publish(t.name, value)
+}
+
+// Subscribe calls fn for every value published on the topic. Events on the same
+// name with a payload of another type are ignored.
+func (t Topic[T]) Subscribe(bus *Bus, buffer int, fn func(T)) (unsubscribe func()) {
+	return bus.SubscribeFunc(t.name, buffer, func(event Event) {
+		if value, ok := event.Payload.(T); ok {
+			fn(value)
+		}
+	})
+}
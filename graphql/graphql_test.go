@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_join"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+type Album struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+}
+
+func (a *Album) SetID(id uuid.UUID) { a.ID = id }
+func (a *Album) GetID() uuid.UUID   { return a.ID }
+func (a *Album) GetRecordSize() int { return 200 }
+
+type Photo struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+func (p *Photo) SetID(id uuid.UUID) { p.ID = id }
+func (p *Photo) GetID() uuid.UUID   { return p.ID }
+func (p *Photo) GetRecordSize() int { return 200 }
+
+type PhotoAlbum struct {
+	AlbumID uuid.UUID `json:"albumId"`
+	PhotoID uuid.UUID `json:"photoId"`
+}
+
+func (pa *PhotoAlbum) GetRecordSize() int { return 100 }
+func (pa *PhotoAlbum) GetCompositeKey() string {
+	return fmt.Sprintf("%s:%s", pa.AlbumID.String(), pa.PhotoID.String())
+}
+
+func TestExecute(t *testing.T) {
+	dir := t.TempDir()
+	albums, _ := collection_manager_memory.New[*Album](dir, "albums")
+	photos, _ := collection_manager_memory.New[*Photo](dir, "photos")
+	photoAlbums, _ := collection_manager_join.New[*PhotoAlbum](dir, "photo_albums")
+	defer albums.Close()
+	defer photos.Close()
+	defer photoAlbums.Close()
+
+	album, _ := albums.Create(&Album{Title: "Summer"})
+	albums.Create(&Album{Title: "Winter"})
+	photo, _ := photos.Create(&Photo{Name: "beach.jpg"})
+	photoAlbums.Create(&PhotoAlbum{AlbumID: album.ID, PhotoID: photo.ID})
+
+	schema := NewSchema()
+	schema.Collection("albums", Memory[*Album](albums))
+	schema.Collection("photos", Memory[*Photo](photos))
+	schema.Relation("albums", "photos", "photos", JoinRelation[*PhotoAlbum](photoAlbums,
+		func(pa *PhotoAlbum) uuid.UUID { return pa.PhotoID }))
+
+	result := schema.Execute(`query Albums($title: String = "Summer") {
+		albums(title: $title) { title pictures: photos { name } }
+	}`, nil)
+	if len(result.Errors) > 0 {
+		t.Fatal(result.Errors)
+	}
+
+	got, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"albums":[{"title":"Summer","pictures":[{"name":"beach.jpg"}]}]}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	var vars map[string]any
+	json.Unmarshal([]byte(`{"n":1,"skip":1}`), &vars) // numbers are float64, as in Handler
+	result = schema.Execute(`query($n: Int, $skip: Int){ albums(limit: $n, offset: $skip) { title } }`, vars)
+	if got, _ := json.Marshal(result.Data); len(result.Errors) > 0 || string(got) != `{"albums":[{"title":"Winter"}]}` {
+		t.Fatalf("limit from variables: %s %v", got, result.Errors)
+	}
+	result = schema.Execute(`query($n: Int){ albums(limit: $n) { title } }`, map[string]any{"n": 1.5})
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected an error for a fractional limit, got %v", result.Errors)
+	}
+
+	result = schema.Execute(`{ unknown { id } }`, nil)
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected one error, got %v", result.Errors)
+	}
+}
+
+type failingLookup struct{ err error }
+
+func (l failingLookup) GetByParentID(uuid.UUID) ([]*PhotoAlbum, error) { return nil, l.err }
+
+func TestJoinRelationErrors(t *testing.T) {
+	childID := func(pa *PhotoAlbum) uuid.UUID { return pa.PhotoID }
+	empty := JoinRelation[*PhotoAlbum](failingLookup{fmt.Errorf("%w for parent ID: x", collection_manager_join.ErrNoItems)}, childID)
+	if ids, err := empty(uuid.New()); err != nil || len(ids) != 0 {
+		t.Fatalf("parent without items: %v %v", ids, err)
+	}
+	broken := JoinRelation[*PhotoAlbum](failingLookup{errors.New("disk failure")}, childID)
+	if _, err := broken(uuid.New()); err == nil {
+		t.Fatal("expected the read error")
+	}
+}
@@ -0,0 +1,465 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// این فایل زیرمجموعه‌ای از زبان GraphQL را تجزیه می‌کند: query با متغیرها، alias،
+// آرگومان‌ها و selection set تو در تو. fragment و mutation پشتیبانی نمی‌شوند.
+
+// Field is a selected field of a query.
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]any
+	Selections []Field
+}
+
+// ResponseKey is the key under which the field appears in the result.
+func (f Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Document is a parsed query.
+type Document struct {
+	Name       string
+	Variables  map[string]any // default values of declared variables
+	Selections []Field
+}
+
+// variable is a $name reference inside arguments, resolved at execution time.
+type variable string
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenString
+	tokenInt
+	tokenFloat
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	// فاصله‌ها، ویرگول‌ها و کامنت‌ها نادیده گرفته می‌شوند
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		}
+		if c == ',' || unicode.IsSpace(rune(c)) {
+			l.pos++
+			continue
+		}
+		break
+	}
+
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("{}():$!=[]", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), pos: start}, nil
+	case c == '"':
+		return l.readString()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return l.readNumber()
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || unicode.IsLetter(rune(l.src[l.pos])) || unicode.IsDigit(rune(l.src[l.pos]))) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], pos: start}, nil
+	default:
+		return token{}, fmt.Errorf("unexpected character %q at %d", c, start)
+	}
+}
+
+func (l *lexer) readString() (token, error) {
+	start := l.pos
+	l.pos++ // opening quote
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return token{kind: tokenString, value: sb.String(), pos: start}, nil
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, fmt.Errorf("unterminated string at %d", start)
+			}
+			escaped := l.src[l.pos+1]
+			switch escaped {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '/':
+				sb.WriteByte(escaped)
+			default:
+				return token{}, fmt.Errorf("unsupported escape \\%c at %d", escaped, l.pos)
+			}
+			l.pos += 2
+		default:
+			sb.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string at %d", start)
+}
+
+func (l *lexer) readNumber() (token, error) {
+	start := l.pos
+	kind := tokenInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c >= '0' && c <= '9' {
+			l.pos++
+			continue
+		}
+		if c == '.' || c == 'e' || c == 'E' || c == '+' || (c == '-' && kind == tokenFloat) {
+			kind = tokenFloat
+			l.pos++
+			continue
+		}
+		break
+	}
+	return token{kind: kind, value: l.src[start:l.pos], pos: start}, nil
+}
+
+type parser struct {
+	lex  *lexer
+	tok  token
+	vars map[string]any
+}
+
+// Parse parses a query document.
+func Parse(query string) (*Document, error) {
+	p := &parser{lex: &lexer{src: query}, vars: make(map[string]any)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	if p.tok.kind == tokenName {
+		switch p.tok.value {
+		case "query":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.tok.kind == tokenName {
+				doc.Name = p.tok.value
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			}
+			if p.is("(") {
+				if err := p.parseVariableDefinitions(); err != nil {
+					return nil, err
+				}
+			}
+		case "mutation", "subscription":
+			return nil, fmt.Errorf("%s operations are not supported", p.tok.value)
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", p.tok.value, p.tok.pos)
+		}
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", p.tok.value, p.tok.pos)
+	}
+
+	doc.Selections = selections
+	doc.Variables = p.vars
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		if p.tok.kind == tokenEOF {
+			return fmt.Errorf("expected %q but query ended", punct)
+		}
+		return fmt.Errorf("expected %q at %d, got %q", punct, p.tok.pos, p.tok.value)
+	}
+	return p.advance()
+}
+
+func (p *parser) parseVariableDefinitions() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.is(")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		if p.tok.kind != tokenName {
+			return fmt.Errorf("expected variable name at %d", p.tok.pos)
+		}
+		name := p.tok.value
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if p.is("=") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return err
+			}
+			p.vars[name] = value
+		} else {
+			p.vars[name] = nil
+		}
+	}
+	return p.advance()
+}
+
+// skipType consumes a type reference such as ID!, [String] or Int.
+func (p *parser) skipType() error {
+	if p.is("[") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else {
+		if p.tok.kind != tokenName {
+			return fmt.Errorf("expected type at %d", p.tok.pos)
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if p.is("!") {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) parseSelectionSet() ([]Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	for !p.is("}") {
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty selection set at %d", p.tok.pos)
+	}
+	return fields, p.advance()
+}
+
+func (p *parser) parseField() (Field, error) {
+	var field Field
+	if p.tok.kind != tokenName {
+		if p.tok.kind == tokenEOF {
+			return field, fmt.Errorf("expected field name but query ended")
+		}
+		return field, fmt.Errorf("expected field name at %d, got %q", p.tok.pos, p.tok.value)
+	}
+	field.Name = p.tok.value
+	if err := p.advance(); err != nil {
+		return field, err
+	}
+
+	if p.is(":") {
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+		if p.tok.kind != tokenName {
+			return field, fmt.Errorf("expected field name after alias at %d", p.tok.pos)
+		}
+		field.Alias = field.Name
+		field.Name = p.tok.value
+		if err := p.advance(); err != nil {
+			return field, err
+		}
+	}
+
+	if p.is("(") {
+		args, err := p.parseArguments()
+		if err != nil {
+			return field, err
+		}
+		field.Arguments = args
+	}
+
+	if p.is("{") {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return field, err
+		}
+		field.Selections = selections
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseArguments() (map[string]any, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := make(map[string]any)
+	for !p.is(")") {
+		if p.tok.kind != tokenName {
+			return nil, fmt.Errorf("expected argument name at %d", p.tok.pos)
+		}
+		name := p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		args[name] = value
+	}
+	return args, p.advance()
+}
+
+func (p *parser) parseValue() (any, error) {
+	tok := p.tok
+	switch {
+	case p.is("$"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenName {
+			return nil, fmt.Errorf("expected variable name at %d", p.tok.pos)
+		}
+		name := p.tok.value
+		return variable(name), p.advance()
+	case p.is("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var list []any
+		for !p.is("]") {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, p.advance()
+	case p.is("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		object := make(map[string]any)
+		for !p.is("}") {
+			if p.tok.kind != tokenName {
+				return nil, fmt.Errorf("expected object field at %d", p.tok.pos)
+			}
+			name := p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			object[name] = value
+		}
+		return object, p.advance()
+	case tok.kind == tokenString:
+		return tok.value, p.advance()
+	case tok.kind == tokenInt:
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int %q at %d", tok.value, tok.pos)
+		}
+		return n, p.advance()
+	case tok.kind == tokenFloat:
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q at %d", tok.value, tok.pos)
+		}
+		return f, p.advance()
+	case tok.kind == tokenName:
+		var value any
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = tok.value // enum value
+		}
+		return value, p.advance()
+	case tok.kind == tokenEOF:
+		return nil, fmt.Errorf("expected value but query ended")
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", tok.value, tok.pos)
+	}
+}
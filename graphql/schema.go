@@ -0,0 +1,494 @@
+package graphql
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_join"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Source provides the records of a collection.
+type Source interface {
+	List() ([]any, error)
+	Get(id uuid.UUID) (any, error)
+}
+
+// Item is implemented by items of collections keyed by UUID.
+type Item interface {
+	GetID() uuid.UUID
+}
+
+// Reader is the read method set of collection_manager_memory and collection_manager_json managers.
+type Reader[T Item] interface {
+	ReadAll() ([]T, error)
+	Read(id uuid.UUID) (T, error)
+}
+
+type readerSource[T Item] struct {
+	reader Reader[T]
+}
+
+// Memory exposes a manager's in-memory cache as a Source.
+func Memory[T Item](reader Reader[T]) Source {
+	return readerSource[T]{reader: reader}
+}
+
+func (s readerSource[T]) List() ([]any, error) {
+	items, err := s.reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result, nil
+}
+
+func (s readerSource[T]) Get(id uuid.UUID) (any, error) {
+	return s.reader.Read(id)
+}
+
+// RelationResolver returns the IDs of the records related to a parent record.
+type RelationResolver func(parentID uuid.UUID) ([]uuid.UUID, error)
+
+// ParentLookup is the GetByParentID method of collection_manager_join managers.
+type ParentLookup[T any] interface {
+	GetByParentID(parentID uuid.UUID) ([]T, error)
+}
+
+// JoinRelation resolves a relation through a join collection; childID picks the
+// related record's ID out of each join item. A parent without items, reported
+// with collection_manager_join.ErrNoItems, is an empty relation; other errors
+// are returned.
+func JoinRelation[T any](join ParentLookup[T], childID func(T) uuid.UUID) RelationResolver {
+	return func(parentID uuid.UUID) ([]uuid.UUID, error) {
+		items, err := join.GetByParentID(parentID)
+		if errors.Is(err, collection_manager_join.ErrNoItems) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading relation of %s: %w", parentID, err)
+		}
+		ids := make([]uuid.UUID, len(items))
+		for i, item := range items {
+			ids[i] = childID(item)
+		}
+		return ids, nil
+	}
+}
+
+type relation struct {
+	target  string
+	resolve RelationResolver
+}
+
+// Schema exposes registered collections as root query fields. Every root field
+// returns a list and accepts id, limit and offset arguments; any other argument
+// filters on the record field of the same name:
+//
+//	{ albums(limit: 10) { id title photos { id } } }
+type Schema struct {
+	mu          sync.RWMutex
+	collections map[string]Source
+	relations   map[string]map[string]relation
+}
+
+// NewSchema creates an empty Schema.
+func NewSchema() *Schema {
+	return &Schema{
+		collections: make(map[string]Source),
+		relations:   make(map[string]map[string]relation),
+	}
+}
+
+// Collection registers a root query field backed by source.
+func (s *Schema) Collection(name string, source Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[name] = source
+}
+
+// Relation adds field to the records of collection, returning the records of
+// target whose IDs resolve returns for the parent record.
+func (s *Schema) Relation(collection, field, target string, resolve RelationResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.relations[collection] == nil {
+		s.relations[collection] = make(map[string]relation)
+	}
+	s.relations[collection][field] = relation{target: target, resolve: resolve}
+}
+
+// Error is a GraphQL error entry.
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Result is a GraphQL response.
+type Result struct {
+	Data   any     `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// object keeps the selection order of fields when encoded, as GraphQL requires.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]any)}
+}
+
+func (o *object) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type execution struct {
+	schema    *Schema
+	variables map[string]any
+	errors    []Error
+}
+
+func (e *execution) fail(path []any, format string, args ...any) {
+	e.errors = append(e.errors, Error{Message: fmt.Sprintf(format, args...), Path: append([]any(nil), path...)})
+}
+
+// Execute runs a query against the schema.
+func (s *Schema) Execute(query string, variables map[string]any) Result {
+	doc, err := Parse(query)
+	if err != nil {
+		return Result{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	vars := make(map[string]any, len(doc.Variables)+len(variables))
+	for k, v := range doc.Variables {
+		vars[k] = v
+	}
+	for k, v := range variables {
+		vars[k] = v
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e := &execution{schema: s, variables: vars}
+	data := newObject()
+	for _, field := range doc.Selections {
+		key := field.ResponseKey()
+		if field.Name == "__typename" {
+			data.set(key, "Query")
+			continue
+		}
+		data.set(key, e.resolveRoot(field, []any{key}))
+	}
+
+	return Result{Data: data, Errors: e.errors}
+}
+
+func (e *execution) argument(field Field, name string) (any, bool) {
+	value, ok := field.Arguments[name]
+	if !ok {
+		return nil, false
+	}
+	if v, isVar := value.(variable); isVar {
+		value, ok = e.variables[string(v)]
+		return value, ok && value != nil
+	}
+	return value, true
+}
+
+func (e *execution) resolveRoot(field Field, path []any) any {
+	source, ok := e.schema.collections[field.Name]
+	if !ok {
+		e.fail(path, "unknown field %q on Query", field.Name)
+		return nil
+	}
+	if len(field.Selections) == 0 {
+		e.fail(path, "field %q must have a selection set", field.Name)
+		return nil
+	}
+
+	var records []map[string]any
+	if idArg, ok := e.argument(field, "id"); ok {
+		id, err := uuid.Parse(fmt.Sprint(idArg))
+		if err != nil {
+			e.fail(path, "invalid id: %v", err)
+			return nil
+		}
+		item, err := source.Get(id)
+		if err == nil {
+			record, err := toRecord(item)
+			if err != nil {
+				e.fail(path, "%v", err)
+				return nil
+			}
+			records = append(records, record)
+		}
+	} else {
+		items, err := source.List()
+		if err != nil {
+			e.fail(path, "%v", err)
+			return nil
+		}
+		for _, item := range items {
+			record, err := toRecord(item)
+			if err != nil {
+				e.fail(path, "%v", err)
+				return nil
+			}
+			records = append(records, record)
+		}
+		// ترتیب ثابت بر اساس شناسه (برای UUIDv7 همان ترتیب ساخت است)
+		sort.Slice(records, func(i, j int) bool {
+			return fmt.Sprint(records[i]["id"]) < fmt.Sprint(records[j]["id"])
+		})
+	}
+
+	records, ok = e.filter(field, records, path)
+	if !ok {
+		return nil
+	}
+	return e.resolveList(field.Name, field, records, path)
+}
+
+// filter applies equality arguments and offset/limit pagination. It reports
+// false after failing at path when offset or limit is not an integer.
+func (e *execution) filter(field Field, records []map[string]any, path []any) ([]map[string]any, bool) {
+	for name := range field.Arguments {
+		switch name {
+		case "id", "limit", "offset":
+			continue
+		}
+		want, ok := e.argument(field, name)
+		if !ok {
+			continue
+		}
+		filtered := records[:0]
+		for _, record := range records {
+			if fmt.Sprint(record[name]) == fmt.Sprint(want) {
+				filtered = append(filtered, record)
+			}
+		}
+		records = filtered
+	}
+
+	offset, hasOffset, err := e.intArgument(field, "offset")
+	if err != nil {
+		e.fail(path, "%v", err)
+		return nil, false
+	}
+	limit, hasLimit, err := e.intArgument(field, "limit")
+	if err != nil {
+		e.fail(path, "%v", err)
+		return nil, false
+	}
+	if hasOffset && offset > 0 {
+		records = records[min(offset, len(records)):]
+	}
+	if hasLimit && limit >= 0 && limit < len(records) {
+		records = records[:limit]
+	}
+	return records, true
+}
+
+// intArgument returns an integer argument. Literals are parsed as int, while
+// variables decoded from JSON are float64 or json.Number.
+func (e *execution) intArgument(field Field, name string) (int, bool, error) {
+	value, ok := e.argument(field, name)
+	if !ok {
+		return 0, false, nil
+	}
+	switch v := value.(type) {
+	case int:
+		return v, true, nil
+	case int64:
+		if v == int64(int(v)) {
+			return int(v), true, nil
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+			return int(v), true, nil
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil && n == int64(int(n)) {
+			return int(n), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("argument %q must be an integer, got %v", name, value)
+}
+
+func (e *execution) resolveList(collection string, field Field, records []map[string]any, path []any) []any {
+	result := make([]any, len(records))
+	for i, record := range records {
+		result[i] = e.resolveObject(collection, field.Selections, record, append(path, i))
+	}
+	return result
+}
+
+func (e *execution) resolveObject(collection string, selections []Field, record map[string]any, path []any) *object {
+	out := newObject()
+	for _, field := range selections {
+		key := field.ResponseKey()
+		fieldPath := append(path, key)
+
+		if field.Name == "__typename" {
+			out.set(key, collection)
+			continue
+		}
+
+		if rel, ok := e.schema.relations[collection][field.Name]; ok {
+			out.set(key, e.resolveRelation(rel, field, record, fieldPath))
+			continue
+		}
+
+		value := record[field.Name]
+		if len(field.Selections) > 0 {
+			nested, ok := value.(map[string]any)
+			if !ok {
+				out.set(key, nil)
+				continue
+			}
+			out.set(key, e.resolveObject(field.Name, field.Selections, nested, fieldPath))
+			continue
+		}
+		out.set(key, value)
+	}
+	return out
+}
+
+func (e *execution) resolveRelation(rel relation, field Field, parent map[string]any, path []any) any {
+	if len(field.Selections) == 0 {
+		e.fail(path, "field %q must have a selection set", field.Name)
+		return nil
+	}
+
+	parentID, err := uuid.Parse(fmt.Sprint(parent["id"]))
+	if err != nil {
+		e.fail(path, "parent record has no valid id")
+		return nil
+	}
+
+	ids, err := rel.resolve(parentID)
+	if err != nil {
+		e.fail(path, "%v", err)
+		return nil
+	}
+
+	target := e.schema.collections[rel.target]
+	if target == nil {
+		e.fail(path, "relation target %q is not registered", rel.target)
+		return nil
+	}
+
+	records := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		item, err := target.Get(id)
+		if err != nil {
+			continue // رکورد مرتبط حذف شده است
+		}
+		record, err := toRecord(item)
+		if err != nil {
+			e.fail(path, "%v", err)
+			return nil
+		}
+		records = append(records, record)
+	}
+
+	records, ok := e.filter(field, records, path)
+	if !ok {
+		return nil
+	}
+	return e.resolveList(rel.target, field, records, path)
+}
+
+// toRecord converts a stored item to its JSON field map.
+func toRecord(item any) (map[string]any, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling item: %w", err)
+	}
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("error decoding item: %w", err)
+	}
+	return record, nil
+}
+
+// request is the standard GraphQL-over-HTTP request body.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Handler serves the schema over HTTP: GET with ?query= or POST with a JSON body.
+func (s *Schema) Handler() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		var req request
+		switch c.Method {
+		case http.MethodGet:
+			req.Query = c.GetQuery("query")
+			if vars := c.GetQuery("variables"); vars != "" {
+				if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+					c.JSON(http.StatusBadRequest, Result{Errors: []Error{{Message: "invalid variables: " + err.Error()}}})
+					return
+				}
+			}
+		default:
+			if err := json.NewDecoder(c.Req.Body).Decode(&req); err != nil {
+				c.JSON(http.StatusBadRequest, Result{Errors: []Error{{Message: "invalid request body: " + err.Error()}}})
+				return
+			}
+		}
+
+		if strings.TrimSpace(req.Query) == "" {
+			c.JSON(http.StatusBadRequest, Result{Errors: []Error{{Message: "missing query"}}})
+			return
+		}
+
+		result := s.Execute(req.Query, req.Variables)
+		if result.Data == nil {
+			c.JSON(http.StatusBadRequest, result)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,121 @@
+package grpc_service
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type note struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+func (n *note) SetID(id uuid.UUID) { n.ID = id }
+func (n *note) GetID() uuid.UUID   { return n.ID }
+func (n *note) GetRecordSize() int { return 256 }
+
+func TestCollections(t *testing.T) {
+	manager, err := collection_manager_memory.NewWithRecordSize[*note](t.TempDir(), "notes", 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	NewServer().Register("notes", Memory(manager)).Attach(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := NewCollectionsClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	watch, err := client.Watch(ctx, &WatchRequest{Collection: "notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watch.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := client.Create(ctx, &CreateRequest{Collection: "notes", Data: []byte(`{"text":"hello"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := watch.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != "create" || event.Id != created.Id {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	if _, err := client.Update(ctx, &UpdateRequest{Collection: "notes", Id: created.Id, Data: []byte(`{"text":"updated"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.Get(ctx, &GetRequest{Collection: "notes", Id: created.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n note
+	if err := json.Unmarshal(got.Data, &n); err != nil || n.Text != "updated" {
+		t.Fatalf("unexpected record %s (%v)", got.Data, err)
+	}
+
+	if _, err := client.Create(ctx, &CreateRequest{Collection: "notes", Data: []byte(`{"text":"second"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := client.List(ctx, &ListRequest{Collection: "notes", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for {
+		_, err := list.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 records, got %d", count)
+	}
+
+	if _, err := client.Delete(ctx, &DeleteRequest{Collection: "notes", Id: created.Id}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Get(ctx, &GetRequest{Collection: "notes", Id: created.Id})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+	_, err = client.Get(ctx, &GetRequest{Collection: "missing", Id: created.Id})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for unknown collection, got %v", err)
+	}
+}
@@ -0,0 +1,373 @@
+package grpc_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// پیام‌ها و stubها از collections.proto تولید می‌شوند:
+//
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative grpc_service/collections.proto
+
+var (
+	// ErrNotFound is returned by a Collection when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrInvalid is returned by a Collection when the request payload cannot be used.
+	ErrInvalid = errors.New("invalid record")
+)
+
+// Event is a change reported by Collection.Watch. An "overflow" event without
+// ID and Data means changes were dropped and the client should resync.
+type Event struct {
+	Type string
+	ID   string
+	Data json.RawMessage
+}
+
+// Collection is what the gRPC service needs from a registered collection.
+// Records are exchanged as JSON so any manager can be exposed.
+type Collection interface {
+	Create(data []byte) (id string, record json.RawMessage, err error)
+	Get(id string) (json.RawMessage, error)
+	// List returns every record ordered by id.
+	List() ([]*Record, error)
+	Update(id string, data []byte) (json.RawMessage, error)
+	Delete(id string) error
+	// Watch streams changes until cancel is called.
+	Watch(buffer int) (events <-chan Event, cancel func())
+}
+
+// Server implements CollectionsServer over registered collections. The same
+// manager instances can be shared with the HTTP handlers of the application.
+type Server struct {
+	UnimplementedCollectionsServer
+
+	mu          sync.RWMutex
+	collections map[string]Collection
+
+	// WatchBuffer is the per-stream buffer of Watch; events are dropped when a
+	// slow client lets it fill up.
+	WatchBuffer int
+}
+
+// NewServer returns an empty server.
+func NewServer() *Server {
+	return &Server{
+		collections: make(map[string]Collection),
+		WatchBuffer: 64,
+	}
+}
+
+// Register exposes a collection under the given name.
+func (s *Server) Register(name string, collection Collection) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collections[name] = collection
+	return s
+}
+
+// Attach registers the Collections service on a gRPC server.
+func (s *Server) Attach(registrar grpc.ServiceRegistrar) {
+	RegisterCollectionsServer(registrar, s)
+}
+
+func (s *Server) collection(name string) (Collection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.collections[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown collection %q", name)
+	}
+	return c, nil
+}
+
+func (s *Server) Create(_ context.Context, req *CreateRequest) (*Record, error) {
+	c, err := s.collection(req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	id, data, err := c.Create(req.Data)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &Record{Collection: req.Collection, Id: id, Data: data}, nil
+}
+
+func (s *Server) Get(_ context.Context, req *GetRequest) (*Record, error) {
+	c, err := s.collection(req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.Get(req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &Record{Collection: req.Collection, Id: req.Id, Data: data}, nil
+}
+
+func (s *Server) List(req *ListRequest, stream grpc.ServerStreamingServer[Record]) error {
+	c, err := s.collection(req.Collection)
+	if err != nil {
+		return err
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return status.Error(codes.InvalidArgument, "offset and limit must not be negative")
+	}
+
+	records, err := c.List()
+	if err != nil {
+		return toStatus(err)
+	}
+
+	start := min(int(req.Offset), len(records))
+	end := len(records)
+	if req.Limit > 0 {
+		end = min(start+int(req.Limit), end)
+	}
+	for _, record := range records[start:end] {
+		record.Collection = req.Collection
+		if err := stream.Send(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) Update(_ context.Context, req *UpdateRequest) (*Record, error) {
+	c, err := s.collection(req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.Update(req.Id, req.Data)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &Record{Collection: req.Collection, Id: req.Id, Data: data}, nil
+}
+
+func (s *Server) Delete(_ context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	c, err := s.collection(req.Collection)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Delete(req.Id); err != nil {
+		return nil, toStatus(err)
+	}
+	return &DeleteResponse{}, nil
+}
+
+func (s *Server) Watch(req *WatchRequest, stream grpc.ServerStreamingServer[ChangeEvent]) error {
+	c, err := s.collection(req.Collection)
+	if err != nil {
+		return err
+	}
+
+	events, cancel := c.Watch(s.WatchBuffer)
+	defer cancel()
+
+	// هدر را فوراً می‌فرستیم تا کلاینت بداند اشتراک برقرار شده است
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&ChangeEvent{
+				Collection: req.Collection,
+				Type:       event.Type,
+				Id:         event.ID,
+				Data:       event.Data,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// memoryCollection adapts a collection_manager_memory manager to Collection.
+type memoryCollection[T collection_manager_memory.CollectionItem] struct {
+	manager *collection_manager_memory.Manager[T]
+}
+
+// Memory exposes a collection_manager_memory manager over gRPC.
+func Memory[T collection_manager_memory.CollectionItem](manager *collection_manager_memory.Manager[T]) Collection {
+	return &memoryCollection[T]{manager: manager}
+}
+
+func (c *memoryCollection[T]) parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: bad id %q", ErrInvalid, id)
+	}
+	return parsed, nil
+}
+
+func (c *memoryCollection[T]) decode(data []byte) (T, error) {
+	item := newItem[T]()
+	if err := json.Unmarshal(data, item); err != nil {
+		var zero T
+		return zero, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return item, nil
+}
+
+func (c *memoryCollection[T]) Create(data []byte) (string, json.RawMessage, error) {
+	item, err := c.decode(data)
+	if err != nil {
+		return "", nil, err
+	}
+	created, err := c.manager.Create(item)
+	if err != nil {
+		return "", nil, err
+	}
+	encoded, err := json.Marshal(created)
+	if err != nil {
+		return "", nil, fmt.Errorf("error marshaling item: %w", err)
+	}
+	return created.GetID().String(), encoded, nil
+}
+
+func (c *memoryCollection[T]) Get(id string) (json.RawMessage, error) {
+	parsed, err := c.parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	item, err := c.manager.Read(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return json.Marshal(item)
+}
+
+func (c *memoryCollection[T]) List() ([]*Record, error) {
+	items, err := c.manager.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	// شناسه‌ها UUID v7 هستند، پس مرتب‌سازی بر اساس شناسه ترتیب ایجاد را حفظ می‌کند
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].GetID().String() < items[j].GetID().String()
+	})
+
+	records := make([]*Record, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling item: %w", err)
+		}
+		records = append(records, &Record{Id: item.GetID().String(), Data: data})
+	}
+	return records, nil
+}
+
+func (c *memoryCollection[T]) Update(id string, data []byte) (json.RawMessage, error) {
+	parsed, err := c.parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.manager.Read(parsed); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	item, err := c.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	item.SetID(parsed)
+
+	updated, err := c.manager.Update(item)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(updated)
+}
+
+func (c *memoryCollection[T]) Delete(id string) error {
+	parsed, err := c.parseID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := c.manager.Read(parsed); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return c.manager.Delete(parsed)
+}
+
+func (c *memoryCollection[T]) Watch(buffer int) (<-chan Event, func()) {
+	changes, cancelChanges := c.manager.Watch(buffer)
+	events := make(chan Event, buffer)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case <-done:
+				return
+			case change, ok := <-changes:
+				if !ok {
+					return
+				}
+				event := Event{Type: string(change.Type)}
+				if change.Type != collection_manager_memory.ChangeOverflow {
+					data, err := json.Marshal(change.Item)
+					if err != nil {
+						continue
+					}
+					event.ID, event.Data = change.ID.String(), data
+				}
+				select {
+				case events <- event:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			cancelChanges()
+			close(done)
+		})
+	}
+}
+
+// newItem returns a new zero item for T, allocating the struct when T is a pointer type.
+func newItem[T any]() T {
+	var zero T
+	t := reflect.TypeOf(zero)
+	if t != nil && t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface().(T)
+	}
+	return zero
+}
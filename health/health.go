@@ -0,0 +1,294 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// پکیج health بررسی‌های سلامت نام‌دار (دیسک، باز بودن collectionها، سرویس‌های پایین‌دستی)
+// را نگه می‌دارد و نتیجه آن‌ها را در /healthz و /readyz برای load balancer و
+// kubernetes گزارش می‌کند.
+
+var logger = logging.For("health")
+
+// Statuses of checks and reports.
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // only optional checks fail
+	StatusFail     = "fail"
+)
+
+// CheckFunc reports a problem with a dependency. It should return when ctx ends.
+type CheckFunc func(ctx context.Context) error
+
+// Options configures a Health.
+type Options struct {
+	// Timeout bounds each check (default 5s).
+	Timeout time.Duration
+}
+
+func (o *Options) defaults() {
+	if o.Timeout <= 0 {
+		o.Timeout = 5 * time.Second
+	}
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report aggregates the results of a probe.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Health runs named checks for liveness and readiness probes:
+//
+//	h := health.New(health.Options{Timeout: 2 * time.Second})
+//	h.AddLiveness("data_dir", health.Writable("/var/lib/photos"))
+//	h.AddReadiness("albums", health.Ping(albums))
+//	h.AddReadiness("drain", func(context.Context) error {
+//		if !d.Ready() {
+//			return drain.ErrDraining
+//		}
+//		return nil
+//	})
+//	h.AddOptional("thumbnails", health.HTTP(nil, "http://thumbnails:8080/healthz"))
+//	h.Mount(engine.RouterGroup)
+//
+// Liveness checks are part of both probes; readiness checks only of /readyz.
+// Checks run concurrently on every probe, and changes of a check's status are
+// logged.
+type Health struct {
+	opts Options
+
+	mu     sync.Mutex
+	checks []*check
+	names  map[string]bool
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	liveness bool
+	optional bool
+	failing  bool // last status, guarded by Health.mu
+}
+
+// New creates a Health.
+func New(opts Options) *Health {
+	opts.defaults()
+	return &Health{opts: opts, names: make(map[string]bool)}
+}
+
+// AddLiveness adds a check that restarts the process when it fails, such as a
+// deadlock or unusable data directory. It panics when name is already used.
+func (h *Health) AddLiveness(name string, fn CheckFunc) {
+	h.add(&check{name: name, fn: fn, liveness: true})
+}
+
+// AddReadiness adds a check that takes the server out of rotation while it fails.
+func (h *Health) AddReadiness(name string, fn CheckFunc) {
+	h.add(&check{name: name, fn: fn})
+}
+
+// AddOptional adds a readiness check whose failure is reported, with status
+// degraded, but keeps the server ready.
+func (h *Health) AddOptional(name string, fn CheckFunc) {
+	h.add(&check{name: name, fn: fn, optional: true})
+}
+
+func (h *Health) add(c *check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if c.name == "" || h.names[c.name] {
+		panic(fmt.Sprintf("health: duplicate or empty check name %q", c.name))
+	}
+	h.names[c.name] = true
+	h.checks = append(h.checks, c)
+}
+
+// Live runs the liveness checks.
+func (h *Health) Live(ctx context.Context) Report {
+	return h.run(ctx, true)
+}
+
+// Ready runs every check.
+func (h *Health) Ready(ctx context.Context) Report {
+	return h.run(ctx, false)
+}
+
+func (h *Health) run(ctx context.Context, livenessOnly bool) Report {
+	h.mu.Lock()
+	var checks []*check
+	for _, c := range h.checks {
+		if c.liveness || !livenessOnly {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.Unlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range checks {
+		result := results[i]
+		report.Checks[c.name] = result
+		failing := result.Status == StatusFail
+		if failing != c.failing {
+			c.failing = failing
+			if failing {
+				logger.Warn("health check failing", "check", c.name, "optional", c.optional, "error", result.Error)
+			} else {
+				logger.Info("health check recovered", "check", c.name)
+			}
+		}
+		switch {
+		case !failing:
+		case c.optional:
+			if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		default:
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (h *Health) runCheck(ctx context.Context, c *check) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, h.opts.Timeout)
+	defer cancel()
+	start := time.Now()
+	defer func() {
+		// یک check خراب نباید کل probe را از کار بیندازد
+		if r := recover(); r != nil {
+			result.Status, result.Error = StatusFail, fmt.Sprint("panic: ", r)
+		}
+		result.Optional = c.optional
+		result.Duration = time.Since(start).Round(time.Microsecond).String()
+	}()
+
+	if err := c.fn(ctx); err != nil {
+		return Result{Status: StatusFail, Error: err.Error()}
+	}
+	return Result{Status: StatusOK}
+}
+
+// LiveHandler answers liveness probes with the report: 200, or 503 when a
+// liveness check fails.
+func (h *Health) LiveHandler() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		respond(c, h.Live(c.Req.Context()))
+	}
+}
+
+// ReadyHandler answers readiness probes with the report: 200, or 503 when a
+// check that is not optional fails.
+func (h *Health) ReadyHandler() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		respond(c, h.Ready(c.Req.Context()))
+	}
+}
+
+func respond(c *mygin.Context, report Report) {
+	status := http.StatusOK
+	if report.Status == StatusFail {
+		status = http.StatusServiceUnavailable
+	}
+	c.Writer.Header().Set("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
+
+// Mount registers GET /healthz and /readyz on group. Reports include error
+// messages, so public servers may pass middleware restricting access.
+func (h *Health) Mount(group *mygin.RouterGroup, middleware ...mygin.HandlerFunc) {
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{}, middleware...), handler)
+	}
+
+	group.GET("/healthz", with(h.LiveHandler())...)
+	group.GET("/readyz", with(h.ReadyHandler())...)
+}
+
+// Pinger is implemented by collection managers that can report whether they
+// are open, such as collection_manager_memory.Manager.
+type Pinger interface {
+	Ping() error
+}
+
+// Ping returns a check that fails while p.Ping fails.
+func Ping(p Pinger) CheckFunc {
+	return func(context.Context) error {
+		return p.Ping()
+	}
+}
+
+// Writable returns a check that fails when a file cannot be created in dir,
+// e.g. because the disk is full or was remounted read-only.
+func Writable(dir string) CheckFunc {
+	return func(context.Context) error {
+		f, err := os.CreateTemp(dir, ".health-*")
+		if err != nil {
+			return fmt.Errorf("error creating file: %w", err)
+		}
+		name := f.Name()
+		_, err = f.Write([]byte("ok"))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if removeErr := os.Remove(name); err == nil {
+			err = removeErr
+		}
+		if err != nil {
+			return fmt.Errorf("error writing file: %w", err)
+		}
+		return nil
+	}
+}
+
+// HTTP returns a check that fails unless a GET of url answers with 2xx. A nil
+// client uses http.DefaultClient.
+func HTTP(client *http.Client, url string) CheckFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("error requesting %s: %w", url, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("%s answered %s", url, resp.Status)
+		}
+		return nil
+	}
+}
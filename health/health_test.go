@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestProbes(t *testing.T) {
+	var dbErr, cdnErr error
+	h := New(Options{})
+	h.AddLiveness("data_dir", Writable(t.TempDir()))
+	h.AddReadiness("db", func(context.Context) error { return dbErr })
+	h.AddOptional("cdn", func(context.Context) error { return cdnErr })
+
+	engine := mygin.New()
+	h.Mount(engine.RouterGroup)
+	probe := func(path string) (int, Report) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var report Report
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s: %v %q", path, err, w.Body.String())
+		}
+		return w.Code, report
+	}
+
+	code, report := probe("/readyz")
+	if code != http.StatusOK || report.Status != StatusOK || len(report.Checks) != 3 {
+		t.Fatalf("ready: %d %+v", code, report)
+	}
+
+	cdnErr = errors.New("connection refused")
+	code, report = probe("/readyz")
+	if code != http.StatusOK || report.Status != StatusDegraded || report.Checks["cdn"].Error != "connection refused" || !report.Checks["cdn"].Optional {
+		t.Fatalf("degraded: %d %+v", code, report)
+	}
+
+	dbErr = errors.New("manager is closed")
+	code, report = probe("/readyz")
+	if code != http.StatusServiceUnavailable || report.Status != StatusFail || report.Checks["db"].Status != StatusFail {
+		t.Fatalf("not ready: %d %+v", code, report)
+	}
+
+	// liveness فقط checkهای liveness را اجرا می‌کند
+	code, report = probe("/healthz")
+	if code != http.StatusOK || report.Status != StatusOK || len(report.Checks) != 1 || report.Checks["data_dir"].Status != StatusOK {
+		t.Fatalf("live: %d %+v", code, report)
+	}
+}
+
+func TestCheckTimeoutAndPanic(t *testing.T) {
+	h := New(Options{Timeout: 10 * time.Millisecond})
+	h.AddReadiness("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	h.AddReadiness("broken", func(context.Context) error { panic("nil map") })
+
+	report := h.Ready(context.Background())
+	if report.Status != StatusFail {
+		t.Fatalf("status: %+v", report)
+	}
+	if r := report.Checks["slow"]; r.Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("slow: %+v", r)
+	}
+	if r := report.Checks["broken"]; r.Status != StatusFail || r.Error != "panic: nil map" {
+		t.Fatalf("broken: %+v", r)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate name did not panic")
+		}
+	}()
+	h.AddOptional("slow", nil)
+}
+
+func TestWritable(t *testing.T) {
+	dir := t.TempDir()
+	if err := Writable(dir)(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("left files behind: %v", entries)
+	}
+	if err := Writable(filepath.Join(dir, "missing"))(context.Background()); err == nil {
+		t.Fatal("missing directory is writable")
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	check := HTTP(nil, srv.URL)
+	if err := check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusBadGateway
+	if err := check(context.Background()); err == nil {
+		t.Fatal("502 passed the check")
+	}
+}
@@ -0,0 +1,334 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+// پکیج i18n رشته‌های ترجمه را برای هر locale در یک کالکشن نگه می‌دارد. تغییرات
+// کالکشن بلافاصله در جستجوها دیده می‌شوند، پس ترجمه‌ها بدون راه‌اندازی مجدد
+// سرویس قابل به‌روزرسانی هستند.
+
+var logger = logging.For("i18n")
+
+// Entry is one translated string.
+type Entry struct {
+	ID        uuid.UUID `json:"id"`
+	Locale    string    `json:"locale"`
+	Key       string    `json:"key"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (e *Entry) SetID(id uuid.UUID)       { e.ID = id }
+func (e *Entry) GetID() uuid.UUID         { return e.ID }
+func (e *Entry) SetCreatedAt(t time.Time) { e.CreatedAt = t }
+func (e *Entry) SetUpdatedAt(t time.Time) { e.UpdatedAt = t }
+func (e *Entry) GetRecordSize() int       { return 2048 }
+
+// ErrInvalidLocale is returned for empty or malformed locale tags.
+var ErrInvalidLocale = errors.New("i18n: invalid locale")
+
+// Translator returns the message key in locale formatted with args. *Catalog
+// implements it; the i18n middleware resolves c.T() through a Translator.
+type Translator interface {
+	T(locale, key string, args ...any) string
+}
+
+type entryKey struct{ locale, key string }
+
+// Catalog serves translations stored in a collection. Lookups fall back from
+// "fa-IR" to "fa" and then to DefaultLocale.
+type Catalog struct {
+	DefaultLocale string
+
+	manager     *collection_manager_memory.Manager[*Entry]
+	ownsManager bool
+	stopHook    func()
+	writeMu     sync.Mutex // Set های همزمان یک کلید رکورد تکراری نسازند
+
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+	ids      map[entryKey]uuid.UUID
+}
+
+// Open opens (or creates) the "i18n_messages" collection in dir.
+func Open(dir, defaultLocale string) (*Catalog, error) {
+	manager, err := collection_manager_memory.New[*Entry](dir, "i18n_messages")
+	if err != nil {
+		return nil, fmt.Errorf("error opening i18n_messages collection: %w", err)
+	}
+	c, err := NewCatalog(manager, defaultLocale)
+	if err != nil {
+		manager.Close()
+		return nil, err
+	}
+	c.ownsManager = true
+	return c, nil
+}
+
+// NewCatalog loads the translations of manager and follows its changes.
+func NewCatalog(manager *collection_manager_memory.Manager[*Entry], defaultLocale string) (*Catalog, error) {
+	c := &Catalog{DefaultLocale: NormalizeLocale(defaultLocale), manager: manager}
+	// هوک قبل از Reload ثبت می‌شود تا تغییری بین این دو گم نشود
+	c.stopHook = manager.OnChange(c.apply)
+	if err := c.Reload(); err != nil {
+		c.stopHook()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close stops following the collection and closes it if it was opened by Open.
+func (c *Catalog) Close() error {
+	c.stopHook()
+	if c.ownsManager {
+		return c.manager.Close()
+	}
+	return nil
+}
+
+// Reload rebuilds the lookup tables from the collection.
+func (c *Catalog) Reload() error {
+	entries, err := c.manager.ReadAll()
+	if err != nil {
+		return fmt.Errorf("error reading translations: %w", err)
+	}
+	messages := make(map[string]map[string]string)
+	ids := make(map[entryKey]uuid.UUID, len(entries))
+	for _, e := range entries {
+		if messages[e.Locale] == nil {
+			messages[e.Locale] = make(map[string]string)
+		}
+		messages[e.Locale][e.Key] = e.Text
+		ids[entryKey{e.Locale, e.Key}] = e.ID
+	}
+
+	c.mu.Lock()
+	c.messages, c.ids = messages, ids
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Catalog) apply(change collection_manager_memory.Change[*Entry]) {
+	e := change.Item
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages == nil {
+		return // هنوز Reload اجرا نشده است
+	}
+	k := entryKey{e.Locale, e.Key}
+	if change.Type == collection_manager_memory.ChangeDelete {
+		if c.ids[k] == e.ID {
+			delete(c.ids, k)
+			delete(c.messages[e.Locale], e.Key)
+			if len(c.messages[e.Locale]) == 0 {
+				delete(c.messages, e.Locale)
+			}
+		}
+		return
+	}
+	if c.messages[e.Locale] == nil {
+		c.messages[e.Locale] = make(map[string]string)
+	}
+	c.messages[e.Locale][e.Key] = e.Text
+	c.ids[k] = e.ID
+}
+
+// Set stores the translation of key in locale.
+func (c *Catalog) Set(locale, key, text string) error {
+	locale = NormalizeLocale(locale)
+	if locale == "" {
+		return ErrInvalidLocale
+	}
+	if key == "" {
+		return fmt.Errorf("i18n: empty key")
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.mu.RLock()
+	id, exists := c.ids[entryKey{locale, key}]
+	current := c.messages[locale][key]
+	c.mu.RUnlock()
+
+	if !exists {
+		if _, err := c.manager.Create(&Entry{Locale: locale, Key: key, Text: text}); err != nil {
+			return fmt.Errorf("error storing translation %s/%s: %w", locale, key, err)
+		}
+		return nil
+	}
+	if current == text {
+		return nil
+	}
+	entry, err := c.manager.Read(id)
+	if err != nil {
+		return fmt.Errorf("error reading translation %s/%s: %w", locale, key, err)
+	}
+	entry.Text = text
+	if _, err := c.manager.Update(entry); err != nil {
+		return fmt.Errorf("error updating translation %s/%s: %w", locale, key, err)
+	}
+	return nil
+}
+
+// Delete removes the translation of key in locale. Missing keys are ignored.
+func (c *Catalog) Delete(locale, key string) error {
+	locale = NormalizeLocale(locale)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.mu.RLock()
+	id, exists := c.ids[entryKey{locale, key}]
+	c.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+	if err := c.manager.Delete(id); err != nil {
+		return fmt.Errorf("error deleting translation %s/%s: %w", locale, key, err)
+	}
+	return nil
+}
+
+// Import stores messages for locale. With replace, keys of locale missing from
+// messages are deleted. It returns the number of stored or deleted keys.
+func (c *Catalog) Import(locale string, messages map[string]string, replace bool) (int, error) {
+	locale = NormalizeLocale(locale)
+	if locale == "" {
+		return 0, ErrInvalidLocale
+	}
+
+	changed := 0
+	var errs []error
+	if replace {
+		for key := range c.Messages(locale) {
+			if _, keep := messages[key]; !keep {
+				if err := c.Delete(locale, key); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				changed++
+			}
+		}
+	}
+	for key, text := range messages {
+		if current, ok := c.get(locale, key); ok && current == text {
+			continue
+		}
+		if err := c.Set(locale, key, text); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changed++
+	}
+	if len(errs) > 0 {
+		logger.Error("error importing translations", "locale", locale, "errors", len(errs))
+	}
+	return changed, errors.Join(errs...)
+}
+
+func (c *Catalog) get(locale, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	text, ok := c.messages[locale][key]
+	return text, ok
+}
+
+// Lookup returns the translation of key for locale, falling back to the base
+// language and DefaultLocale.
+func (c *Catalog) Lookup(locale, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, candidate := range c.fallbacks(NormalizeLocale(locale)) {
+		if text, ok := c.messages[candidate][key]; ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func (c *Catalog) fallbacks(locale string) []string {
+	candidates := make([]string, 0, 3)
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if base, _, ok := strings.Cut(locale, "-"); ok {
+			candidates = append(candidates, base)
+		}
+	}
+	if c.DefaultLocale != "" && c.DefaultLocale != locale {
+		candidates = append(candidates, c.DefaultLocale)
+	}
+	return candidates
+}
+
+// T returns the translation of key for locale, or key itself when there is none.
+// With args the text is used as fmt format.
+func (c *Catalog) T(locale, key string, args ...any) string {
+	text, ok := c.Lookup(locale, key)
+	if !ok {
+		text = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Locales returns the locales with at least one translation, sorted.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Messages returns a copy of the translations of locale without fallbacks.
+func (c *Catalog) Messages(locale string) map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	src := c.messages[NormalizeLocale(locale)]
+	messages := make(map[string]string, len(src))
+	for k, v := range src {
+		messages[k] = v
+	}
+	return messages
+}
+
+// NormalizeLocale returns locale as a BCP 47 style tag ("fa_ir" -> "fa-IR"),
+// or "" when it is malformed.
+func NormalizeLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	for i, part := range parts {
+		if part == "" || len(part) > 8 {
+			return ""
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return ""
+			}
+		}
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,238 @@
+package i18n
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// Formats accepted by Import and Export.
+const (
+	FormatJSON = "json" // flat or nested object of strings, nested keys are joined with "."
+	FormatPO   = "po"   // gettext PO, msgid is the key
+)
+
+// ErrInvalidFile is returned by ImportFile for unknown formats and files that cannot be parsed.
+var ErrInvalidFile = errors.New("i18n: invalid translation file")
+
+// ImportFile reads translations of locale in format from r; see Import. For PO
+// files locale may be empty, the Language header of the file is used then.
+func (c *Catalog) ImportFile(locale, format string, r io.Reader, replace bool) (string, int, error) {
+	var messages map[string]string
+	var err error
+	switch format {
+	case FormatJSON:
+		messages, err = decodeJSON(r)
+	case FormatPO:
+		var language string
+		language, messages, err = decodePO(r)
+		if locale == "" {
+			locale = language
+		}
+	default:
+		return locale, 0, fmt.Errorf("%w: unknown format %q", ErrInvalidFile, format)
+	}
+	if err != nil {
+		return locale, 0, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	if NormalizeLocale(locale) == "" {
+		return locale, 0, ErrInvalidLocale
+	}
+	n, err := c.Import(locale, messages, replace)
+	return NormalizeLocale(locale), n, err
+}
+
+// LoadFS imports the translation files in the root of fsys, one per locale named
+// after it: en.json, fa-IR.json or fa.po. Other files are ignored. With replace,
+// keys missing from a file are deleted from its locale. It returns the number of
+// stored or deleted keys; a failing file does not stop the others.
+//
+//	//go:embed locales/*.json
+//	var locales embed.FS
+//	sub, _ := fs.Sub(locales, "locales")
+//	catalog.LoadFS(sub, true)
+func (c *Catalog) LoadFS(fsys fs.FS, replace bool) (int, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("error reading translation files: %w", err)
+	}
+	total, files := 0, 0
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := path.Ext(name)
+		format := strings.TrimPrefix(ext, ".")
+		if entry.IsDir() || (format != FormatJSON && format != FormatPO) {
+			continue
+		}
+		n, err := c.loadFile(fsys, name, strings.TrimSuffix(name, ext), format, replace)
+		files++
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	logger.Info("translation files loaded", "files", files, "changed", total, "errors", len(errs))
+	return total, errors.Join(errs...)
+}
+
+func (c *Catalog) loadFile(fsys fs.FS, name, locale, format string, replace bool) (int, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	_, n, err := c.ImportFile(locale, format, f, replace)
+	return n, err
+}
+
+// Export writes the translations of locale in format to w, sorted by key.
+func (c *Catalog) Export(locale, format string, w io.Writer) error {
+	messages := c.Messages(locale)
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding translations: %w", err)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case FormatPO:
+		return encodePO(w, NormalizeLocale(locale), messages)
+	default:
+		return fmt.Errorf("i18n: unknown format %q", format)
+	}
+}
+
+func decodeJSON(r io.Reader) (map[string]string, error) {
+	var doc map[string]any
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error decoding translations: %w", err)
+	}
+	messages := make(map[string]string)
+	var flatten func(prefix string, v map[string]any) error
+	flatten = func(prefix string, v map[string]any) error {
+		for k, value := range v {
+			key := prefix + k
+			switch value := value.(type) {
+			case string:
+				messages[key] = value
+			case map[string]any:
+				if err := flatten(key+".", value); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("value of %q is not a string", key)
+			}
+		}
+		return nil
+	}
+	return messages, flatten("", doc)
+}
+
+// decodePO reads the msgid/msgstr pairs of a PO file. Fuzzy and untranslated
+// entries are skipped; msgctxt and plural forms are not supported.
+func decodePO(r io.Reader) (string, map[string]string, error) {
+	messages := make(map[string]string)
+	var language string
+	var id, str *strings.Builder
+	var current *strings.Builder
+	fuzzy := false
+
+	flush := func() {
+		if id != nil && str != nil {
+			switch {
+			case id.Len() == 0:
+				for _, line := range strings.Split(str.String(), "\n") {
+					if name, value, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) == "Language" {
+						language = strings.TrimSpace(value)
+					}
+				}
+			case !fuzzy && str.Len() > 0:
+				messages[id.String()] = str.String()
+			}
+		}
+		id, str, current, fuzzy = nil, nil, nil, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#,"):
+			if id != nil {
+				flush()
+			}
+			fuzzy = strings.Contains(line, "fuzzy")
+		case strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "msgid "):
+			keepFuzzy := fuzzy && id == nil // پرچم fuzzy مربوط به همین مدخل است
+			flush()
+			fuzzy = keepFuzzy
+			id = &strings.Builder{}
+			current = id
+			line = strings.TrimPrefix(line, "msgid ")
+			fallthrough
+		case strings.HasPrefix(line, "\"") && current != nil:
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return "", nil, fmt.Errorf("error parsing PO line %d: %w", lineNo, err)
+			}
+			current.WriteString(s)
+		case strings.HasPrefix(line, "msgstr "):
+			if id == nil {
+				return "", nil, fmt.Errorf("error parsing PO line %d: msgstr without msgid", lineNo)
+			}
+			str = &strings.Builder{}
+			current = str
+			s, err := strconv.Unquote(strings.TrimPrefix(line, "msgstr "))
+			if err != nil {
+				return "", nil, fmt.Errorf("error parsing PO line %d: %w", lineNo, err)
+			}
+			str.WriteString(s)
+		default:
+			// msgctxt و msgid_plural پشتیبانی نمی‌شوند
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("error reading PO file: %w", err)
+	}
+	flush()
+	return language, messages, nil
+}
+
+func encodePO(w io.Writer, locale string, messages map[string]string) error {
+	keys := make([]string, 0, len(messages))
+	for k := range messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "msgid \"\"\nmsgstr \"\"\n%s\n%s\n%s\n",
+		poQuote("Language: "+locale+"\n"),
+		poQuote("MIME-Version: 1.0\n"),
+		poQuote("Content-Type: text/plain; charset=UTF-8\n"))
+	for _, k := range keys {
+		fmt.Fprintf(bw, "\nmsgid %s\nmsgstr %s\n", poQuote(k), poQuote(messages[k]))
+	}
+	return bw.Flush()
+}
+
+// poQuote quotes s as a PO string; unlike strconv.Quote it keeps non-ASCII text readable.
+func poQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
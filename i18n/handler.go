@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Mount registers the catalog management API on group under path:
+//
+//	GET  path                 locales and their key counts
+//	GET  path/:locale         export (?format=json|po, default json)
+//	PUT  path/:locale         import the body (?format=json|po, ?replace=true)
+//	POST path/reload          reload the catalog from its collection
+//
+// Changes take effect for lookups immediately.
+func (c *Catalog) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{}, middleware...), handler)
+	}
+	group.GET(path, with(c.handleLocales)...)
+	group.GET(path+"/:locale", with(c.handleExport)...)
+	group.PUT(path+"/:locale", with(c.handleImport)...)
+	group.POST(path+"/reload", with(c.handleReload)...)
+}
+
+func (c *Catalog) handleLocales(ctx *mygin.Context) {
+	locales := make([]mygin.H, 0)
+	for _, locale := range c.Locales() {
+		locales = append(locales, mygin.H{"locale": locale, "keys": len(c.Messages(locale))})
+	}
+	ctx.JSON(http.StatusOK, mygin.H{"defaultLocale": c.DefaultLocale, "locales": locales})
+}
+
+func format(ctx *mygin.Context) string {
+	if f := ctx.GetQuery("format"); f != "" {
+		return f
+	}
+	if strings.Contains(ctx.GetHeader("Content-Type"), "x-gettext") {
+		return FormatPO
+	}
+	return FormatJSON
+}
+
+func (c *Catalog) handleExport(ctx *mygin.Context) {
+	locale := NormalizeLocale(ctx.Param("locale"))
+	if locale == "" {
+		ctx.JSON(http.StatusBadRequest, mygin.H{"error": ErrInvalidLocale.Error()})
+		return
+	}
+	f := format(ctx)
+	switch f {
+	case FormatJSON:
+		ctx.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	case FormatPO:
+		ctx.Writer.Header().Set("Content-Type", "text/x-gettext-translation; charset=utf-8")
+		ctx.Writer.Header().Set("Content-Disposition", `attachment; filename="`+locale+`.po"`)
+	default:
+		ctx.JSON(http.StatusBadRequest, mygin.H{"error": "unknown format " + f})
+		return
+	}
+	ctx.Status(http.StatusOK)
+	if err := c.Export(locale, f, ctx.Writer); err != nil {
+		logger.Error("error exporting translations", "locale", locale, "error", err)
+	}
+}
+
+func (c *Catalog) handleImport(ctx *mygin.Context) {
+	locale, n, err := c.ImportFile(ctx.Param("locale"), format(ctx), ctx.Req.Body, ctx.GetQueryBool("replace"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidLocale) || errors.Is(err, ErrInvalidFile) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, mygin.H{"error": err.Error(), "changed": n})
+		return
+	}
+	ctx.JSON(http.StatusOK, mygin.H{"locale": locale, "changed": n})
+}
+
+func (c *Catalog) handleReload(ctx *mygin.Context) {
+	if err := c.Reload(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, mygin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, mygin.H{"locales": c.Locales()})
+}
@@ -0,0 +1,188 @@
+package i18n
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestCatalogLookupAndPersistence(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range [][3]string{
+		{"en", "hello", "Hello %s"},
+		{"en", "bye", "Bye"},
+		{"fa", "hello", "سلام %s"},
+		{"fa_ir", "hello", "درود %s"},
+	} {
+		if err := c.Set(m[0], m[1], m[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		locale, key string
+		args        []any
+		want        string
+	}{
+		{"fa-IR", "hello", []any{"Sara"}, "درود Sara"},
+		{"fa-AF", "hello", []any{"Sara"}, "سلام Sara"},
+		{"fa", "bye", nil, "Bye"},
+		{"de", "missing", nil, "missing"},
+	}
+	for _, tt := range tests {
+		if got := c.T(tt.locale, tt.key, tt.args...); got != tt.want {
+			t.Errorf("T(%s, %s) = %q, want %q", tt.locale, tt.key, got, tt.want)
+		}
+	}
+
+	if err := c.Set("en", "bye", "Goodbye"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete("fa-IR", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = Open(dir, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got := c.T("en", "bye"); got != "Goodbye" {
+		t.Errorf("bye after reopen = %q", got)
+	}
+	if got := c.T("fa-IR", "hello", "x"); got != "سلام x" {
+		t.Errorf("deleted key still served: %q", got)
+	}
+	if got := c.Locales(); strings.Join(got, ",") != "en,fa" {
+		t.Errorf("Locales = %v", got)
+	}
+}
+
+func TestImportExportFormats(t *testing.T) {
+	c, err := Open(t.TempDir(), "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_, n, err := c.ImportFile("en", FormatJSON, strings.NewReader(`{"errors":{"notFound":"Not found"},"title":"Photos"}`), false)
+	if err != nil || n != 2 {
+		t.Fatalf("ImportFile json = %d, %v", n, err)
+	}
+	if got := c.T("en", "errors.notFound"); got != "Not found" {
+		t.Errorf("nested key = %q", got)
+	}
+
+	var po bytes.Buffer
+	if err := c.Export("en", FormatPO, &po); err != nil {
+		t.Fatal(err)
+	}
+	fuzzy := "\n#, fuzzy\nmsgid \"draft\"\nmsgstr \"Draft\"\n"
+	locale, n, err := c.ImportFile("", FormatPO, strings.NewReader(strings.Replace(po.String(), "Language: en", "Language: de", 1)+fuzzy), false)
+	if err != nil || locale != "de" || n != 2 {
+		t.Fatalf("ImportFile po = %s, %d, %v\n%s", locale, n, err, po.String())
+	}
+	if _, ok := c.Lookup("de", "draft"); ok {
+		t.Error("fuzzy entry imported")
+	}
+
+	if _, n, err := c.ImportFile("de", FormatJSON, strings.NewReader(`{"title":"Fotos"}`), true); err != nil || n != 2 {
+		t.Fatalf("replace = %d, %v", n, err)
+	}
+	if got := c.Messages("de"); len(got) != 1 || got["title"] != "Fotos" {
+		t.Errorf("de after replace = %v", got)
+	}
+	if _, _, err := c.ImportFile("de", FormatJSON, strings.NewReader(`{"n":1}`), false); err == nil {
+		t.Error("non-string value accepted")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c, err := Open(t.TempDir(), "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	engine := mygin.New()
+	c.Mount(engine.Group("/admin"), "/i18n")
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/i18n/fa", strings.NewReader(`{"hello":"سلام"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/i18n/fa?format=po", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "msgstr \"سلام\"") {
+		t.Fatalf("export: %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/i18n/fa", strings.NewReader(`not json`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid import: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestLoadFSAndMiddleware(t *testing.T) {
+	c, err := Open(t.TempDir(), "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	n, err := c.LoadFS(fstest.MapFS{
+		"en.json":   {Data: []byte(`{"album": {"not_found": "Album %s not found"}}`)},
+		"fa.json":   {Data: []byte(`{"album": {"not_found": "آلبوم %s پیدا نشد"}}`)},
+		"de-DE.po":  {Data: []byte("msgid \"album.not_found\"\nmsgstr \"Album %s nicht gefunden\"\n")},
+		"README.md": {Data: []byte("ignored")},
+	}, false)
+	if err != nil || n != 3 {
+		t.Fatalf("LoadFS: %d %v", n, err)
+	}
+
+	engine := mygin.New()
+	engine.Use(Middleware(c, Options{Supported: []string{"en", "fa", "de-DE"}}))
+	engine.GET("/albums/:id", func(ctx *mygin.Context) {
+		ctx.String(http.StatusNotFound, "%s", ctx.T("album.not_found", ctx.Param("id")))
+	})
+
+	tests := []struct {
+		name, query, cookie, acceptLanguage string
+		want, wantLocale                    string
+	}{
+		{"default", "", "", "", "Album 7 not found", "en"},
+		{"header", "", "", "ja, fa-IR;q=0.9, en;q=0.8", "آلبوم 7 پیدا نشد", "fa"},
+		{"base language", "", "", "de", "Album 7 nicht gefunden", "de-DE"},
+		{"cookie over header", "", "en", "fa", "Album 7 not found", "en"},
+		{"query over cookie", "?lang=fa", "de", "", "آلبوم 7 پیدا نشد", "fa"},
+		{"unsupported query", "?lang=ja", "", "de;q=0.5, *", "Album 7 nicht gefunden", "de-DE"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/albums/7"+tt.query, nil)
+		if tt.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+		}
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Body.String() != tt.want || w.Header().Get("Content-Language") != tt.wantLocale {
+			t.Errorf("%s: got %q (%s), want %q (%s)", tt.name, w.Body.String(), w.Header().Get("Content-Language"), tt.want, tt.wantLocale)
+		}
+	}
+}
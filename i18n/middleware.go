@@ -0,0 +1,139 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+var _ mygin.Translator = (*Catalog)(nil)
+
+// Options configures Middleware.
+type Options struct {
+	// Supported are the locales requests may select. When empty any well-formed
+	// locale is accepted and the Translator falls back for missing keys.
+	Supported []string
+	// Default is the locale of requests that select none (default the catalog's
+	// DefaultLocale).
+	Default string
+	// QueryParam selects the locale with the query string (default "lang"); "-"
+	// disables it.
+	QueryParam string
+	// Cookie selects the locale with a cookie (default "lang"); "-" disables it.
+	Cookie string
+}
+
+func (o *Options) defaults(t Translator) {
+	if o.QueryParam == "" {
+		o.QueryParam = "lang"
+	}
+	if o.Cookie == "" {
+		o.Cookie = "lang"
+	}
+	if o.Default == "" {
+		if catalog, ok := t.(*Catalog); ok {
+			o.Default = catalog.DefaultLocale
+		}
+	}
+	o.Default = NormalizeLocale(o.Default)
+	supported := make([]string, len(o.Supported))
+	for i, locale := range o.Supported {
+		supported[i] = NormalizeLocale(locale)
+	}
+	o.Supported = supported
+}
+
+// Middleware detects the locale of each request and makes c.T translate with t.
+// The locale is taken from the query parameter, then the cookie, then the
+// Accept-Language header, and answered in Content-Language:
+//
+//	catalog, _ := i18n.Open(dataDir, "en")
+//	catalog.LoadFS(os.DirFS("locales"), false) // locales/en.json, locales/fa.json
+//	engine.Use(i18n.Middleware(catalog, i18n.Options{Supported: []string{"en", "fa"}}))
+//	engine.GET("/albums/:id", func(c *mygin.Context) {
+//		c.JSON(http.StatusNotFound, mygin.H{"error": c.T("album.not_found")})
+//	})
+func Middleware(t Translator, opts Options) mygin.HandlerFunc {
+	opts.defaults(t)
+	return func(c *mygin.Context) {
+		locale := ""
+		if opts.QueryParam != "-" {
+			locale = opts.match(c.GetQuery(opts.QueryParam))
+		}
+		if locale == "" && opts.Cookie != "-" {
+			if cookie, err := c.Req.Cookie(opts.Cookie); err == nil {
+				locale = opts.match(cookie.Value)
+			}
+		}
+		if locale == "" {
+			// پاسخ به Accept-Language بستگی دارد و cacheها باید آن را جدا نگه دارند
+			c.Writer.Header().Add("Vary", "Accept-Language")
+			for _, tag := range ParseAcceptLanguage(c.GetHeader("Accept-Language")) {
+				if locale = opts.match(tag); locale != "" {
+					break
+				}
+			}
+		}
+		if locale == "" {
+			locale = opts.Default
+		}
+		if locale != "" {
+			c.Writer.Header().Set("Content-Language", locale)
+		}
+		c.SetLocale(locale, t)
+		c.Next()
+	}
+}
+
+// match returns the supported locale for a requested one: the locale itself,
+// its base language ("fa-IR" for "fa"), or a supported region of the requested
+// language ("fa" for "fa-IR"). It returns "" when none is supported.
+func (o *Options) match(requested string) string {
+	locale := NormalizeLocale(requested)
+	if locale == "" || len(o.Supported) == 0 {
+		return locale
+	}
+	base, _, _ := strings.Cut(locale, "-")
+	fallback := ""
+	for _, supported := range o.Supported {
+		if supported == locale {
+			return supported
+		}
+		if supportedBase, _, _ := strings.Cut(supported, "-"); fallback == "" && supportedBase == base {
+			fallback = supported
+		}
+	}
+	return fallback
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header by
+// descending quality, without "*" and tags with q=0.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		q := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		if tag == "" || tag == "*" || q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
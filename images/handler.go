@@ -0,0 +1,88 @@
+package images
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/blobstore"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Handler serves thumbnails on demand:
+//
+//	GET /thumbnails/:hash?size=256
+//
+// Thumbnails are immutable for a given (hash, size), so responses carry a
+// one-year Cache-Control and an ETag, and If-None-Match is answered with 304.
+// When size is missing the smallest allowed size is used.
+func (t *Thumbnailer) Handler() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		hash := c.Param("hash")
+		size := t.sizes[0]
+		if raw := c.GetQuery("size"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid size"})
+				return
+			}
+			size = parsed
+		}
+
+		etag := `"` + hash + "-" + strconv.Itoa(size) + `"`
+		header := c.Writer.Header()
+		if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, etag) && blobstore.ValidHash(hash) {
+			header.Set("ETag", etag)
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		path, err := t.Path(c.Req.Context(), ThumbKey{Hash: hash, Size: size})
+		switch {
+		case errors.Is(err, ErrInvalidSize):
+			c.JSON(http.StatusBadRequest, mygin.H{"error": err.Error(), "sizes": t.sizes})
+			return
+		case errors.Is(err, blobstore.ErrInvalidHash):
+			c.JSON(http.StatusBadRequest, mygin.H{"error": err.Error()})
+			return
+		case errors.Is(err, blobstore.ErrNotFound):
+			c.JSON(http.StatusNotFound, mygin.H{"error": err.Error()})
+			return
+		case errors.Is(err, ErrQueueFull):
+			header.Set("Retry-After", "1")
+			c.JSON(http.StatusServiceUnavailable, mygin.H{"error": err.Error()})
+			return
+		case err != nil:
+			logger.Error("error serving thumbnail", "hash", hash, "size", size, "error", err)
+			c.JSON(http.StatusInternalServerError, mygin.H{"error": "error generating thumbnail"})
+			return
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, mygin.H{"error": "error reading thumbnail"})
+			return
+		}
+		header.Set("ETag", etag)
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+		c.Data(http.StatusOK, "image/jpeg", data)
+	}
+}
+
+// Mount registers Handler on group as GET path/:hash.
+func (t *Thumbnailer) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	handlers := append(append([]mygin.HandlerFunc{}, middleware...), t.Handler())
+	group.GET(path+"/:hash", handlers...)
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
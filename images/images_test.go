@@ -0,0 +1,110 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mahdi-cpp/iris-tools/blobstore"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	return img
+}
+
+func TestOps(t *testing.T) {
+	img := testImage(40, 20)
+
+	if b := Fit(img, 10, 10).Bounds(); b.Dx() != 10 || b.Dy() != 5 {
+		t.Fatalf("Fit: got %v", b)
+	}
+	if b := Thumbnail(img, 8).Bounds(); b.Dx() != 8 || b.Dy() != 8 {
+		t.Fatalf("Thumbnail: got %v", b)
+	}
+
+	rotated := Rotate90(img)
+	if b := rotated.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Fatalf("Rotate90: got %v", b)
+	}
+	// گوشه بالا-چپ بعد از چرخش ساعتگرد به بالا-راست می‌رود
+	if got := rotated.RGBAAt(19, 0); got != img.RGBAAt(0, 0) {
+		t.Fatalf("Rotate90 pixel: got %v", got)
+	}
+	if got := Rotate270(rotated).RGBAAt(5, 3); got != img.RGBAAt(5, 3) {
+		t.Fatalf("Rotate270 should undo Rotate90, got %v", got)
+	}
+	if got := Rotate180(img).RGBAAt(0, 0); got != img.RGBAAt(39, 19) {
+		t.Fatalf("Rotate180 pixel: got %v", got)
+	}
+	if got := FlipHorizontal(img).RGBAAt(0, 2); got != img.RGBAAt(39, 2) {
+		t.Fatalf("FlipHorizontal pixel: got %v", got)
+	}
+}
+
+func TestThumbnailHandler(t *testing.T) {
+	dir := t.TempDir()
+	blobs, err := blobstore.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blobs.Close()
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, testImage(300, 200), "png", 0); err != nil {
+		t.Fatal(err)
+	}
+	blob, err := blobs.PutBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumbs, err := NewThumbnailer(blobs, Options{Dir: dir + "/thumbs", Sizes: []int{32, 64}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer thumbs.Close()
+
+	engine := mygin.New()
+	thumbs.Mount(engine.Group("/"), "/thumbnails")
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/thumbnails/"+blob.Hash+"?size=64", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("expected jpeg, got %d %s", w.Code, w.Body)
+	}
+	img, format, err := Decode(w.Body)
+	if err != nil || format != "jpeg" || img.Bounds().Dx() != 64 {
+		t.Fatalf("bad thumbnail: %v %s %v", err, format, img)
+	}
+
+	etag := w.Header().Get("ETag")
+	if w := get("/thumbnails/"+blob.Hash+"?size=64", etag); w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w.Code)
+	}
+	if w := get("/thumbnails/"+blob.Hash+"?size=100", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported size, got %d", w.Code)
+	}
+	missing := "0000000000000000000000000000000000000000000000000000000000000000"
+	if w := get("/thumbnails/"+missing, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
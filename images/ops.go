@@ -0,0 +1,209 @@
+package images
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+
+	_ "image/gif"
+)
+
+// پکیج images عملیات پایه تصویر (تغییر اندازه، بندانگشتی، چرخش) و خط تولید
+// بندانگشتی‌ها از روی blobstore را فراهم می‌کند. همه چیز با کتابخانه استاندارد نوشته شده
+// و برای کوچک کردن از میانگین‌گیری ناحیه‌ای (box filter) استفاده می‌شود.
+
+// Decode decodes a JPEG, PNG or GIF image.
+func Decode(r io.Reader) (image.Image, string, error) {
+	img, format, err := image.Decode(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("error decoding image: %w", err)
+	}
+	return img, format, nil
+}
+
+// Encode writes img as "jpeg" or "png". quality applies to JPEG (0 = 85).
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch strings.ToLower(format) {
+	case "jpeg", "jpg":
+		if quality <= 0 {
+			quality = 85
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case "png":
+		return png.Encode(w, img)
+	default:
+		return fmt.Errorf("unsupported image format: %s", format)
+	}
+}
+
+// Resize scales img to exactly width x height. Downscaling averages every source
+// pixel covered by a destination pixel; upscaling uses nearest neighbour.
+func Resize(img image.Image, width, height int) *image.RGBA {
+	src := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width <= 0 || height <= 0 || src.Empty() {
+		return dst
+	}
+
+	sw, sh := src.Dx(), src.Dy()
+	for y := 0; y < height; y++ {
+		y0 := src.Min.Y + y*sh/height
+		y1 := src.Min.Y + (y+1)*sh/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := src.Min.X + x*sw/width
+			x1 := src.Min.X + (x+1)*sw/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(b / n >> 8), A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// Fit scales img down to fit inside maxWidth x maxHeight keeping its aspect
+// ratio. Smaller images are returned unchanged.
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxWidth && h <= maxHeight {
+		return img
+	}
+	if w*maxHeight > h*maxWidth {
+		return Resize(img, maxWidth, max(1, h*maxWidth/w))
+	}
+	return Resize(img, max(1, w*maxHeight/h), maxHeight)
+}
+
+// Thumbnail crops the centered square of img and scales it to size x size.
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	return Resize(CropCenter(img, 1, 1), size, size)
+}
+
+// CropCenter returns the largest centered region of img with the aspect ratio aw:ah.
+func CropCenter(img image.Image, aw, ah int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	cw, ch := w, w*ah/aw
+	if ch > h {
+		cw, ch = h*aw/ah, h
+	}
+	x0 := b.Min.X + (w-cw)/2
+	y0 := b.Min.Y + (h-ch)/2
+	return subImage(img, image.Rect(x0, y0, x0+cw, y0+ch))
+}
+
+func subImage(img image.Image, r image.Rectangle) image.Image {
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			dst.Set(x, y, img.At(r.Min.X+x, r.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// Rotate90 rotates img 90 degrees clockwise.
+func Rotate90(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(b.Dy()-1-y, x, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// Rotate180 rotates img by 180 degrees.
+func Rotate180(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(b.Dx()-1-x, b.Dy()-1-y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// Rotate270 rotates img 90 degrees counter-clockwise.
+func Rotate270(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(y, b.Dx()-1-x, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// FlipHorizontal mirrors img left to right.
+func FlipHorizontal(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(b.Dx()-1-x, y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// FlipVertical mirrors img top to bottom.
+func FlipVertical(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			dst.Set(x, b.Dy()-1-y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// Orient transforms img so that it is displayed upright for the given EXIF
+// orientation (1-8). Other values return img unchanged.
+func Orient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return FlipHorizontal(img)
+	case 3:
+		return Rotate180(img)
+	case 4:
+		return FlipVertical(img)
+	case 5:
+		return FlipHorizontal(Rotate90(img))
+	case 6:
+		return Rotate90(img)
+	case 7:
+		return FlipHorizontal(Rotate270(img))
+	case 8:
+		return Rotate270(img)
+	}
+	return img
+}
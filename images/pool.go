@@ -0,0 +1,99 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrQueueFull is returned by Pool.Submit when the queue has no free slot.
+var ErrQueueFull = errors.New("image queue is full")
+
+// ErrPoolClosed is returned by Pool.Submit after Close.
+var ErrPoolClosed = errors.New("image pool is closed")
+
+// Pool runs CPU heavy image jobs on a fixed number of workers fed by a bounded queue.
+type Pool struct {
+	jobs   chan func()
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPool starts workers goroutines with a queue of queueSize pending jobs.
+func NewPool(workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = 1
+	}
+	p := &Pool{jobs: make(chan func(), queueSize)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// Submit queues fn without blocking and returns ErrQueueFull when the queue is full.
+func (p *Pool) Submit(fn func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.jobs <- fn:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Do queues fn, waiting for a free queue slot, and waits for fn to finish or ctx to be done.
+func (p *Pool) Do(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	job := func() {
+		if ctx.Err() != nil {
+			done <- ctx.Err()
+			return
+		}
+		done <- fn()
+	}
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrPoolClosed
+	}
+	select {
+	case p.jobs <- job:
+		p.mu.RUnlock()
+	case <-ctx.Done():
+		p.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting jobs and waits for queued jobs to finish.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
@@ -0,0 +1,209 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/mahdi-cpp/iris-tools/blobstore"
+	"github.com/mahdi-cpp/iris-tools/cache"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/metadata"
+)
+
+var logger = logging.For("images")
+
+// ErrInvalidSize is returned for thumbnail sizes that are not allowed.
+var ErrInvalidSize = errors.New("invalid thumbnail size")
+
+// DefaultSizes are the thumbnail edge lengths allowed when Options.Sizes is empty.
+var DefaultSizes = []int{64, 128, 256, 512}
+
+// ThumbKey identifies a generated thumbnail.
+type ThumbKey struct {
+	Hash string // blob hash of the original image
+	Size int    // edge length in pixels
+}
+
+// Options configures a Thumbnailer.
+type Options struct {
+	Dir        string // where generated thumbnails are stored (required)
+	Sizes      []int  // allowed sizes, DefaultSizes when empty
+	Quality    int    // JPEG quality, 85 when zero
+	Workers    int    // worker goroutines, 2 when zero
+	QueueSize  int    // pending jobs, 64 when zero
+	MemEntries int    // in-memory index of generated paths, 1024 when zero
+}
+
+// Thumbnailer generates square JPEG thumbnails of blobs and caches them on disk
+// under Dir as ab/<hash>_<size>.jpg. Generation runs on a bounded worker pool and
+// concurrent requests for the same (hash, size) share one job.
+type Thumbnailer struct {
+	blobs   *blobstore.Store
+	dir     string
+	sizes   []int
+	quality int
+	pool    *Pool
+	paths   *cache.Cache[ThumbKey, string]
+}
+
+// NewThumbnailer returns a Thumbnailer reading originals from blobs.
+func NewThumbnailer(blobs *blobstore.Store, opts Options) (*Thumbnailer, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("thumbnail directory is required")
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating thumbnail directory: %w", err)
+	}
+	if len(opts.Sizes) == 0 {
+		opts.Sizes = DefaultSizes
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 64
+	}
+	if opts.MemEntries <= 0 {
+		opts.MemEntries = 1024
+	}
+
+	paths, err := cache.New(cache.Options[ThumbKey, string]{Size: opts.MemEntries})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Thumbnailer{
+		blobs:   blobs,
+		dir:     opts.Dir,
+		sizes:   slices.Clone(opts.Sizes),
+		quality: opts.Quality,
+		pool:    NewPool(opts.Workers, opts.QueueSize),
+		paths:   paths,
+	}, nil
+}
+
+// Sizes returns the allowed thumbnail sizes.
+func (t *Thumbnailer) Sizes() []int {
+	return slices.Clone(t.sizes)
+}
+
+// Path returns the file of the thumbnail, generating it first when needed.
+func (t *Thumbnailer) Path(ctx context.Context, key ThumbKey) (string, error) {
+	if !slices.Contains(t.sizes, key.Size) {
+		return "", fmt.Errorf("%w: %d", ErrInvalidSize, key.Size)
+	}
+	if !blobstore.ValidHash(key.Hash) {
+		return "", blobstore.ErrInvalidHash
+	}
+	return t.paths.GetOrLoad(ctx, key, t.load)
+}
+
+// Generate creates thumbnails of hash for every allowed size in the background.
+// Sizes that cannot be queued because the pool is busy are skipped.
+func (t *Thumbnailer) Generate(hash string) {
+	for _, size := range t.sizes {
+		key := ThumbKey{Hash: hash, Size: size}
+		err := t.pool.Submit(func() {
+			// این جاب خودش روی pool اجرا می‌شود، پس رندر باید همین‌جا انجام شود نه با pool.Do
+			_, err := t.paths.GetOrLoad(context.Background(), key, func(_ context.Context, key ThumbKey) (string, error) {
+				return t.ensure(key, t.render)
+			})
+			if err != nil {
+				logger.Error("error generating thumbnail", "hash", key.Hash, "size", key.Size, "error", err)
+			}
+		})
+		if err != nil {
+			logger.Warn("thumbnail job not queued", "hash", hash, "size", size, "error", err)
+		}
+	}
+}
+
+// Remove deletes every cached thumbnail of hash, e.g. after the blob was garbage collected.
+func (t *Thumbnailer) Remove(hash string) error {
+	for _, size := range t.sizes {
+		key := ThumbKey{Hash: hash, Size: size}
+		t.paths.Delete(key)
+		if err := os.Remove(t.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error removing thumbnail: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close waits for queued jobs and stops the workers.
+func (t *Thumbnailer) Close() {
+	t.pool.Close()
+}
+
+func (t *Thumbnailer) path(key ThumbKey) string {
+	return filepath.Join(t.dir, key.Hash[:2], key.Hash+"_"+strconv.Itoa(key.Size)+".jpg")
+}
+
+// load returns the path of an existing thumbnail or renders it on the pool.
+func (t *Thumbnailer) load(ctx context.Context, key ThumbKey) (string, error) {
+	return t.ensure(key, func(key ThumbKey, path string) error {
+		return t.pool.Do(ctx, func() error { return t.render(key, path) })
+	})
+}
+
+// ensure returns the thumbnail path, calling render only when the file does not exist yet.
+func (t *Thumbnailer) ensure(key ThumbKey, render func(ThumbKey, string) error) (string, error) {
+	path := t.path(key)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := render(key, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func (t *Thumbnailer) render(key ThumbKey, path string) error {
+	file, err := t.blobs.Open(key.Hash)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// جهت EXIF قبل از برش اعمال می‌شود تا بندانگشتی عکس‌های موبایل کج نباشد
+	orientation := 1
+	if photo, _ := metadata.Extract(file); photo != nil && photo.Orientation != 0 {
+		orientation = photo.Orientation
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading blob: %w", err)
+	}
+
+	img, _, err := Decode(file)
+	if err != nil {
+		return err
+	}
+	thumb := Thumbnail(Orient(img, orientation), key.Size)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating thumbnail directory: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "thumb-*")
+	if err != nil {
+		return fmt.Errorf("error creating thumbnail file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if err := Encode(temp, thumb, "jpeg", t.quality); err != nil {
+		temp.Close()
+		return fmt.Errorf("error encoding thumbnail: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("error writing thumbnail: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("error saving thumbnail: %w", err)
+	}
+	return nil
+}
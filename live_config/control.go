@@ -0,0 +1,174 @@
+package live_config
+
+import (
+	"sync"
+
+	"github.com/mahdi-cpp/iris-tools/config"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/rate_limit"
+)
+
+var logger = logging.For("live_config")
+
+// Resizer is implemented by caches whose size can change at runtime, such as
+// cache.Cache.
+type Resizer interface {
+	Resize(size int) (int, error)
+}
+
+type registeredCache struct {
+	cache       Resizer
+	defaultSize int
+}
+
+// Control applies Settings to the logging, rate limiting and caching subsystems.
+// Every change made through the loader, by Update, the admin endpoint or a watched
+// file, reaches them through config.Loader.Subscribe:
+//
+//	loader := config.New[live_config.Settings]("live.yaml", "IRIS_LIVE")
+//	loader.Watch(5*time.Second, nil)
+//	ctl := live_config.New(loader)
+//	api.Use(rate_limit.DynamicMiddleware(store, ctl.RateLimit("api", defaultLimit), rate_limit.ByIP()))
+//	ctl.RegisterCache("thumbnails", thumbnails, 1000)
+//	ctl.Mount(adminGroup, "/config", authz.RequirePermission("config:write"))
+type Control struct {
+	loader      *config.Loader[Settings]
+	unsubscribe func()
+
+	mu            sync.RWMutex
+	settings      Settings
+	rateLimits    map[string]*rate_limit.Dynamic
+	defaultLimits map[string]rate_limit.Limit
+	caches        map[string]registeredCache
+	modules       map[string]bool // modules whose level was set from Settings
+}
+
+// New applies the current settings of loader and follows its changes.
+func New(loader *config.Loader[Settings]) *Control {
+	c := &Control{
+		loader:        loader,
+		rateLimits:    make(map[string]*rate_limit.Dynamic),
+		defaultLimits: make(map[string]rate_limit.Limit),
+		caches:        make(map[string]registeredCache),
+		modules:       make(map[string]bool),
+	}
+	c.unsubscribe = loader.Subscribe(c.apply)
+	c.apply(loader.Current())
+	return c
+}
+
+// Close stops following the loader.
+func (c *Control) Close() {
+	c.unsubscribe()
+}
+
+// Settings returns the applied settings.
+func (c *Control) Settings() Settings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.clone()
+}
+
+// Update changes a copy of the current settings with fn and makes it current. The
+// change is rejected when fn or Settings.Validate returns an error.
+func (c *Control) Update(fn func(s *Settings) error) (Settings, error) {
+	s := c.loader.Current().clone()
+	if err := fn(&s); err != nil {
+		return Settings{}, err
+	}
+	if err := c.loader.Set(s); err != nil {
+		return Settings{}, err
+	}
+	return c.Settings(), nil
+}
+
+// Enabled reports whether a feature toggle is on. Unknown features are off.
+func (c *Control) Enabled(feature string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings.Features[feature]
+}
+
+// RateLimit returns the limit named name for rate_limit.DynamicMiddleware. It
+// follows Settings.RateLimits[name] and falls back to defaultLimit.
+func (c *Control) RateLimit(name string, defaultLimit rate_limit.Limit) *rate_limit.Dynamic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.rateLimits[name]
+	if !ok {
+		d = rate_limit.NewDynamic(defaultLimit)
+		c.rateLimits[name] = d
+		c.defaultLimits[name] = defaultLimit
+	}
+	c.applyRateLimit(name, d)
+	return d
+}
+
+// RegisterCache makes the size of cache follow Settings.CacheSizes[name] and
+// defaultSize when the setting is absent.
+func (c *Control) RegisterCache(name string, cache Resizer, defaultSize int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caches[name] = registeredCache{cache: cache, defaultSize: defaultSize}
+	c.applyCache(name, c.caches[name])
+}
+
+// apply is called by the loader with every new settings value.
+func (c *Control) apply(s Settings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settings = s.clone()
+
+	if level, err := logging.ParseLevel(s.LogLevel); err == nil {
+		logging.SetLevel(level)
+	}
+	for module := range c.modules {
+		if _, ok := s.ModuleLevels[module]; !ok {
+			logging.ResetModuleLevel(module)
+			delete(c.modules, module)
+		}
+	}
+	for module, value := range s.ModuleLevels {
+		if level, err := logging.ParseLevel(value); err == nil {
+			logging.SetModuleLevel(module, level)
+			c.modules[module] = true
+		}
+	}
+
+	for name, d := range c.rateLimits {
+		c.applyRateLimit(name, d)
+	}
+	for name, rc := range c.caches {
+		c.applyCache(name, rc)
+	}
+	logger.Info("live settings applied", "logLevel", s.LogLevel, "rateLimits", len(s.RateLimits), "cacheSizes", len(s.CacheSizes), "features", len(s.Features))
+}
+
+// applyRateLimit sets d from the settings; c.mu must be held.
+func (c *Control) applyRateLimit(name string, d *rate_limit.Dynamic) {
+	limit := c.defaultLimits[name]
+	if setting, ok := c.settings.RateLimits[name]; ok {
+		if l, err := setting.Limit(); err == nil {
+			limit = l
+		}
+	}
+	if d.Get() != limit {
+		d.Set(limit)
+	}
+}
+
+// applyCache resizes rc from the settings; c.mu must be held.
+func (c *Control) applyCache(name string, rc registeredCache) {
+	size := rc.defaultSize
+	if s, ok := c.settings.CacheSizes[name]; ok {
+		size = s
+	}
+	if size <= 0 {
+		return
+	}
+	if evicted, err := rc.cache.Resize(size); err != nil {
+		logger.Error("error resizing cache", "cache", name, "size", size, "error", err)
+	} else if evicted > 0 {
+		logger.Info("cache resized", "cache", name, "size", size, "evicted", evicted)
+	}
+}
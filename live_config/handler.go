@@ -0,0 +1,123 @@
+package live_config
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// maxBodySize limits the size of settings payloads.
+const maxBodySize = 1 << 16
+
+// Mount registers the settings endpoint on group. The middleware must protect it,
+// typically with authentication and an authz permission check.
+//
+//	GET   path   the applied settings
+//	PUT   path   replace the settings
+//	PATCH path   JSON merge patch (RFC 7386): objects are merged, null removes a key
+func (c *Control) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{}, middleware...), handler)
+	}
+
+	group.GET(path, with(c.handleGet)...)
+	group.PUT(path, with(c.handlePut)...)
+	group.PATCH(path, with(c.handlePatch)...)
+}
+
+func (c *Control) handleGet(ctx *mygin.Context) {
+	ctx.JSON(http.StatusOK, c.Settings())
+}
+
+func (c *Control) handlePut(ctx *mygin.Context) {
+	body, ok := readBody(ctx)
+	if !ok {
+		return
+	}
+	c.update(ctx, func(s *Settings) error {
+		*s = Settings{}
+		return json.Unmarshal(body, s)
+	})
+}
+
+func (c *Control) handlePatch(ctx *mygin.Context) {
+	body, ok := readBody(ctx)
+	if !ok {
+		return
+	}
+	c.update(ctx, func(s *Settings) error {
+		current, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		var doc, patch any
+		if err := json.Unmarshal(current, &doc); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return err
+		}
+		merged, err := json.Marshal(mergePatch(doc, patch))
+		if err != nil {
+			return err
+		}
+		*s = Settings{}
+		return json.Unmarshal(merged, s)
+	})
+}
+
+func (c *Control) update(ctx *mygin.Context, fn func(s *Settings) error) {
+	var decodeErr error
+	settings, err := c.Update(func(s *Settings) error {
+		decodeErr = fn(s)
+		return decodeErr
+	})
+	switch {
+	case decodeErr != nil:
+		ctx.JSON(http.StatusBadRequest, mygin.H{"error": "invalid JSON body: " + decodeErr.Error()})
+	case err != nil:
+		ctx.JSON(http.StatusUnprocessableEntity, mygin.H{"error": err.Error()})
+	default:
+		logger.Info("live settings changed", "method", ctx.Method, "remote", ctx.Req.RemoteAddr)
+		ctx.JSON(http.StatusOK, settings)
+	}
+}
+
+func readBody(ctx *mygin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Req.Body, maxBodySize+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, mygin.H{"error": err.Error()})
+		return nil, false
+	}
+	if len(body) > maxBodySize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, mygin.H{"error": "payload too large"})
+		return nil, false
+	}
+	if !json.Valid(body) {
+		ctx.JSON(http.StatusBadRequest, mygin.H{"error": "invalid JSON body"})
+		return nil, false
+	}
+	return body, true
+}
+
+// mergePatch applies an RFC 7386 merge patch to doc.
+func mergePatch(doc, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	docObject, ok := doc.(map[string]any)
+	if !ok {
+		docObject = make(map[string]any)
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(docObject, key)
+			continue
+		}
+		docObject[key] = mergePatch(docObject[key], value)
+	}
+	return docObject
+}
@@ -0,0 +1,70 @@
+package live_config
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/cache"
+	"github.com/mahdi-cpp/iris-tools/config"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/rate_limit"
+)
+
+func TestControlPropagatesChanges(t *testing.T) {
+	loader := config.New[Settings]("", "TEST_LIVE")
+	loader.LookupEnv = func(string) (string, bool) { return "", false }
+	if err := loader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	defer logging.SetLevel(slog.LevelInfo)
+
+	ctl := New(loader)
+	defer ctl.Close()
+	defaultLimit := rate_limit.Limit{Requests: 10, Per: time.Second}
+	limit := ctl.RateLimit("api", defaultLimit)
+	thumbs, _ := cache.New(cache.Options[int, int]{Size: 10})
+	for i := 0; i < 10; i++ {
+		thumbs.Set(i, i)
+	}
+	ctl.RegisterCache("thumbs", thumbs, 10)
+
+	engine := mygin.New()
+	ctl.Mount(engine.RouterGroup, "/admin/config")
+	send := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, "/admin/config", strings.NewReader(body)))
+		return w
+	}
+
+	w := send("PATCH", `{"logLevel":"debug","rateLimits":{"api":{"requests":2,"per":"1m"}},"cacheSizes":{"thumbs":4},"features":{"beta":true}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH: %d %s", w.Code, w.Body)
+	}
+	if got := limit.Get(); got.Requests != 2 || got.Per != time.Minute {
+		t.Errorf("rate limit not applied: %+v", got)
+	}
+	if thumbs.Len() != 4 || !ctl.Enabled("beta") || !logging.For("x").Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("settings not applied: cache %d, beta %v", thumbs.Len(), ctl.Enabled("beta"))
+	}
+
+	// null یک کلید را حذف می‌کند و مقدار پیش‌فرض برمی‌گردد
+	if w := send("PATCH", `{"rateLimits":{"api":null}}`); w.Code != http.StatusOK {
+		t.Fatalf("PATCH null: %d %s", w.Code, w.Body)
+	}
+	if limit.Get() != defaultLimit || !ctl.Enabled("beta") {
+		t.Errorf("merge patch: limit %+v, beta %v", limit.Get(), ctl.Enabled("beta"))
+	}
+
+	if w := send("PATCH", `{"cacheSizes":{"thumbs":0}}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid size: %d %s", w.Code, w.Body)
+	}
+	if w := send("PUT", `{"logLevel":"warn"}`); w.Code != http.StatusOK || ctl.Enabled("beta") {
+		t.Fatalf("PUT: %d %s", w.Code, w.Body)
+	}
+}
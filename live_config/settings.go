@@ -0,0 +1,81 @@
+package live_config
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/rate_limit"
+)
+
+// Settings are the values that can be changed while the server runs. They are
+// loaded with config.Loader like any other configuration, e.g. from live.yaml:
+//
+//	logLevel: info
+//	moduleLevels: {collection_manager_memory: debug}
+//	rateLimits: {api: {requests: 100, per: 1m, burst: 20}}
+//	cacheSizes: {thumbnails: 5000}
+//	features: {new_search: true}
+//
+// An empty LogLevel leaves the global level unchanged.
+type Settings struct {
+	LogLevel     string               `json:"logLevel" default:"info"`
+	ModuleLevels map[string]string    `json:"moduleLevels,omitempty" env:"-"`
+	RateLimits   map[string]RateLimit `json:"rateLimits,omitempty" env:"-"`
+	CacheSizes   map[string]int       `json:"cacheSizes,omitempty" env:"-"`
+	Features     map[string]bool      `json:"features,omitempty" env:"-"`
+}
+
+// RateLimit is a rate_limit.Limit with Per written as a duration string such as "1m".
+type RateLimit struct {
+	Requests int    `json:"requests"`
+	Per      string `json:"per"`
+	Burst    int    `json:"burst,omitempty"`
+}
+
+// Limit converts r to a rate_limit.Limit.
+func (r RateLimit) Limit() (rate_limit.Limit, error) {
+	per, err := time.ParseDuration(r.Per)
+	if err != nil {
+		return rate_limit.Limit{}, fmt.Errorf("invalid per %q: %w", r.Per, err)
+	}
+	if r.Requests <= 0 || per <= 0 || r.Burst < 0 {
+		return rate_limit.Limit{}, fmt.Errorf("requests and per must be positive")
+	}
+	return rate_limit.Limit{Requests: r.Requests, Per: per, Burst: r.Burst}, nil
+}
+
+// Validate implements config.Validator.
+func (s *Settings) Validate() error {
+	if s.LogLevel != "" {
+		if _, err := logging.ParseLevel(s.LogLevel); err != nil {
+			return fmt.Errorf("logLevel: %w", err)
+		}
+	}
+	for module, level := range s.ModuleLevels {
+		if _, err := logging.ParseLevel(level); err != nil {
+			return fmt.Errorf("moduleLevels.%s: %w", module, err)
+		}
+	}
+	for name, limit := range s.RateLimits {
+		if _, err := limit.Limit(); err != nil {
+			return fmt.Errorf("rateLimits.%s: %w", name, err)
+		}
+	}
+	for name, size := range s.CacheSizes {
+		if size <= 0 {
+			return fmt.Errorf("cacheSizes.%s: size must be positive, got %d", name, size)
+		}
+	}
+	return nil
+}
+
+// clone returns a copy that does not share maps with s.
+func (s Settings) clone() Settings {
+	s.ModuleLevels = maps.Clone(s.ModuleLevels)
+	s.RateLimits = maps.Clone(s.RateLimits)
+	s.CacheSizes = maps.Clone(s.CacheSizes)
+	s.Features = maps.Clone(s.Features)
+	return s
+}
@@ -0,0 +1,175 @@
+package load_shedding
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+var logger = logging.For("load_shedding")
+
+// Options configures a Shedder.
+type Options struct {
+	// MaxInFlight is the number of requests served concurrently (default 64).
+	MaxInFlight int
+	// MaxQueue is the number of requests that may wait for a slot; 0 rejects
+	// every request over MaxInFlight immediately.
+	MaxQueue int
+	// QueueTimeout is how long a queued request waits before it is rejected
+	// (default 1s).
+	QueueTimeout time.Duration
+	// RetryAfter is sent with rejected requests (default 1s).
+	RetryAfter time.Duration
+}
+
+func (o *Options) defaults() {
+	if o.MaxInFlight <= 0 {
+		o.MaxInFlight = 64
+	}
+	if o.MaxQueue < 0 {
+		o.MaxQueue = 0
+	}
+	if o.QueueTimeout <= 0 {
+		o.QueueTimeout = time.Second
+	}
+	if o.RetryAfter <= 0 {
+		o.RetryAfter = time.Second
+	}
+}
+
+// Stats is a snapshot of a Shedder.
+type Stats struct {
+	InFlight    int    `json:"inFlight"`
+	Queued      int    `json:"queued"`
+	MaxInFlight int    `json:"maxInFlight"`
+	MaxQueue    int    `json:"maxQueue"`
+	Rejected    uint64 `json:"rejected"`
+}
+
+// Shedder bounds the number of concurrent requests so an overloaded server fails
+// fast with 503 instead of piling work onto the single-file storage layer.
+// Requests over the limit wait in a FIFO queue of bounded length:
+//
+//	shedder := load_shedding.New(load_shedding.Options{MaxInFlight: 32, MaxQueue: 64})
+//	engine.Use(shedder.Middleware())
+type Shedder struct {
+	mu       sync.Mutex
+	opts     Options
+	inFlight int
+	waiters  []chan struct{} // closed when a slot is handed to the waiter
+	rejected uint64
+}
+
+// New creates a Shedder.
+func New(opts Options) *Shedder {
+	opts.defaults()
+	return &Shedder{opts: opts}
+}
+
+// SetLimits changes MaxInFlight and MaxQueue at runtime. Requests already served
+// or queued are not affected; a lower limit takes effect as they finish.
+func (s *Shedder) SetLimits(maxInFlight, maxQueue int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts.MaxInFlight, s.opts.MaxQueue = maxInFlight, maxQueue
+	s.opts.defaults()
+	// با افزایش ظرفیت، صف‌نشین‌ها را راه می‌اندازیم
+	for len(s.waiters) > 0 && s.inFlight < s.opts.MaxInFlight {
+		s.inFlight++
+		s.handOff()
+	}
+}
+
+// Stats returns the current counters.
+func (s *Shedder) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		InFlight:    s.inFlight,
+		Queued:      len(s.waiters),
+		MaxInFlight: s.opts.MaxInFlight,
+		MaxQueue:    s.opts.MaxQueue,
+		Rejected:    s.rejected,
+	}
+}
+
+// Acquire takes a slot, waiting in the queue when all are in use. It returns false
+// when the queue is full, the wait times out or done is closed. Every successful
+// Acquire must be followed by Release.
+func (s *Shedder) Acquire(done <-chan struct{}) bool {
+	s.mu.Lock()
+	if s.inFlight < s.opts.MaxInFlight {
+		s.inFlight++
+		s.mu.Unlock()
+		return true
+	}
+	if len(s.waiters) >= s.opts.MaxQueue {
+		s.rejected++
+		s.mu.Unlock()
+		return false
+	}
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, ready)
+	timeout := s.opts.QueueTimeout
+	s.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return true
+	case <-timer.C:
+	case <-done:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.waiters {
+		if w == ready {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			s.rejected++
+			return false
+		}
+	}
+	// اسلات همزمان با پایان انتظار تحویل داده شده است
+	return true
+}
+
+// Release returns a slot taken with Acquire.
+func (s *Shedder) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.waiters) > 0 && s.inFlight <= s.opts.MaxInFlight {
+		s.handOff()
+		return
+	}
+	s.inFlight--
+}
+
+// handOff passes a slot to the first waiter; s.mu must be held.
+func (s *Shedder) handOff() {
+	close(s.waiters[0])
+	s.waiters = s.waiters[1:]
+}
+
+// Middleware rejects requests that cannot get a slot with 503 and Retry-After.
+func (s *Shedder) Middleware() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		if !s.Acquire(c.Req.Context().Done()) {
+			s.mu.Lock()
+			retryAfter := s.opts.RetryAfter
+			s.mu.Unlock()
+			logger.Debug("request shed", "method", c.Method, "path", c.Path)
+			c.Writer.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+			c.JSON(http.StatusServiceUnavailable, mygin.H{"error": "server overloaded"})
+			c.Abort()
+			return
+		}
+		defer s.Release()
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package load_shedding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestShedderQueueAndReject(t *testing.T) {
+	s := New(Options{MaxInFlight: 1, MaxQueue: 1, QueueTimeout: time.Minute})
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	engine := mygin.New()
+	engine.Use(s.Middleware())
+	engine.GET("/", func(c *mygin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	codes := make(chan int, 2)
+	var wg sync.WaitGroup
+	serve := func() {
+		defer wg.Done()
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		codes <- w.Code
+	}
+
+	wg.Add(1)
+	go serve()
+	<-started
+	wg.Add(1)
+	go serve()
+	for s.Stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// صف پر است، پس درخواست سوم فوراً رد می‌شود
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("third request: %d %v", w.Code, w.Header())
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("queued request got %d", code)
+		}
+	}
+	if stats := s.Stats(); stats.InFlight != 0 || stats.Queued != 0 || stats.Rejected != 1 {
+		t.Fatalf("stats after drain: %+v", stats)
+	}
+}
+
+func TestShedderQueueTimeout(t *testing.T) {
+	s := New(Options{MaxInFlight: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond})
+	if !s.Acquire(nil) {
+		t.Fatal("first acquire failed")
+	}
+	if s.Acquire(nil) {
+		t.Fatal("queued acquire should time out")
+	}
+
+	s.SetLimits(2, 1)
+	if !s.Acquire(nil) {
+		t.Fatal("acquire after raising the limit failed")
+	}
+	s.Release()
+	s.Release()
+	if stats := s.Stats(); stats.InFlight != 0 {
+		t.Fatalf("in flight after release: %+v", stats)
+	}
+}
@@ -0,0 +1,193 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// پکیج logging لاگر مرکزی پروژه است: هندلرهای slog با کنترل سطح، فرمت متن یا JSON،
+// چرخش فایل و لاگر جدا برای هر ماژول. لاگرهای For را می‌توان در متغیرهای سطح پکیج
+// نگه داشت؛ تغییرات بعدی Setup و SetModuleLevel روی آن‌ها هم اعمال می‌شود.
+
+// Format is the output format of log records.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// Options configures the root handler.
+type Options struct {
+	Level     slog.Level
+	Format    Format
+	AddSource bool
+
+	// Output receives the log records. When File is set, records go to a
+	// rotating file instead. Defaults to os.Stderr.
+	Output io.Writer
+	File   string
+	// MaxSize is the file size in bytes that triggers rotation (0 = never).
+	MaxSize int64
+	// MaxBackups is the number of rotated files kept (0 = keep none).
+	MaxBackups int
+	// Daily also rotates the file when the day changes.
+	Daily bool
+}
+
+var (
+	level  = new(slog.LevelVar)
+	root   atomic.Pointer[slog.Handler]
+	closer io.Closer
+
+	setupMu     sync.Mutex
+	moduleLevel sync.Map // module name -> slog.Level
+)
+
+func init() {
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+	root.Store(&h)
+}
+
+// Setup replaces the root handler. Loggers returned by For before the call keep
+// working and write to the new handler. The previous log file, if any, is closed.
+func Setup(opts Options) error {
+	setupMu.Lock()
+	defer setupMu.Unlock()
+
+	out := opts.Output
+	var fileCloser io.Closer
+	if opts.File != "" {
+		file, err := OpenRotatingFile(opts.File, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return err
+		}
+		if opts.Daily {
+			file.RotateDaily()
+		}
+		out, fileCloser = file, file
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+
+	level.Set(opts.Level)
+	handlerOpts := &slog.HandlerOptions{Level: level, AddSource: opts.AddSource}
+
+	var h slog.Handler
+	switch opts.Format {
+	case FormatJSON:
+		h = slog.NewJSONHandler(out, handlerOpts)
+	case FormatText, "":
+		h = slog.NewTextHandler(out, handlerOpts)
+	default:
+		if fileCloser != nil {
+			fileCloser.Close()
+		}
+		return fmt.Errorf("unknown log format: %s", opts.Format)
+	}
+
+	root.Store(&h)
+	if closer != nil {
+		closer.Close()
+	}
+	closer = fileCloser
+	slog.SetDefault(slog.New(&moduleHandler{}))
+	return nil
+}
+
+// Close closes the log file opened by Setup, if any.
+func Close() error {
+	setupMu.Lock()
+	defer setupMu.Unlock()
+	if closer == nil {
+		return nil
+	}
+	err := closer.Close()
+	closer = nil
+	return err
+}
+
+// SetLevel changes the global level.
+func SetLevel(l slog.Level) { level.Set(l) }
+
+// SetModuleLevel overrides the level of a single module.
+func SetModuleLevel(module string, l slog.Level) { moduleLevel.Store(module, l) }
+
+// ResetModuleLevel removes the override of a module.
+func ResetModuleLevel(module string) { moduleLevel.Delete(module) }
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	err := l.UnmarshalText([]byte(strings.TrimSpace(s)))
+	return l, err
+}
+
+// For returns the logger of a module. Records carry a "module" attribute.
+func For(module string) *slog.Logger {
+	return slog.New(&moduleHandler{module: module}).With("module", module)
+}
+
+// Default returns a logger without module attribute that follows Setup.
+func Default() *slog.Logger {
+	return slog.New(&moduleHandler{})
+}
+
+// moduleHandler resolves the root handler on every record so that loggers
+// created before Setup follow it. Attributes and groups are replayed on the
+// current root handler.
+type moduleHandler struct {
+	module string
+	ops    []handlerOp
+}
+
+type handlerOp struct {
+	attrs []slog.Attr
+	group string
+}
+
+func (h *moduleHandler) Enabled(_ context.Context, l slog.Level) bool {
+	if h.module != "" {
+		if ml, ok := moduleLevel.Load(h.module); ok {
+			return l >= ml.(slog.Level)
+		}
+	}
+	return l >= level.Level()
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, record slog.Record) error {
+	target := *root.Load()
+	for _, op := range h.ops {
+		if op.group != "" {
+			target = target.WithGroup(op.group)
+		} else {
+			target = target.WithAttrs(op.attrs)
+		}
+	}
+	// سطح ماژول ممکن است پایین‌تر از سطح هندلر ریشه باشد، پس آن را دور می‌زنیم
+	return target.Handle(ctx, record)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(handlerOp{attrs: attrs})
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(handlerOp{group: name})
+}
+
+func (h *moduleHandler) with(op handlerOp) *moduleHandler {
+	ops := make([]handlerOp, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &moduleHandler{module: h.module, ops: append(ops, op)}
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModuleLevels(t *testing.T) {
+	logger := For("storage") // قبل از Setup ساخته می‌شود
+
+	var buf bytes.Buffer
+	if err := Setup(Options{Level: slog.LevelInfo, Format: FormatJSON, Output: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	defer Setup(Options{})
+
+	logger.Debug("hidden")
+	logger.Info("loaded", "items", 3)
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, `"module":"storage"`) || !strings.Contains(out, `"items":3`) {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	buf.Reset()
+	SetModuleLevel("storage", slog.LevelDebug)
+	defer ResetModuleLevel("storage")
+	logger.Debug("visible")
+	For("other").Debug("still hidden")
+	if out := buf.String(); !strings.Contains(out, "visible") || strings.Contains(out, "still hidden") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expect := map[string]string{"": "fourth\n", ".1": "third\n", ".2": "second\n"}
+	for suffix, want := range expect {
+		got, err := os.ReadFile(path + suffix)
+		if err != nil || string(got) != want {
+			t.Fatalf("%s: got %q (%v), want %q", path+suffix, got, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatal("expected only two backups")
+	}
+}
+
+func TestRotatingFileDaily(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	file, err := OpenRotatingFile(path, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.RotateDaily()
+
+	day := time.Date(2026, 3, 1, 23, 59, 0, 0, time.Local)
+	file.now = func() time.Time { return day }
+	file.day = day.Format(time.DateOnly)
+	file.Write([]byte("monday\n"))
+	day = day.Add(2 * time.Minute)
+	file.Write([]byte("tuesday\n"))
+
+	if got, _ := os.ReadFile(path); string(got) != "tuesday\n" {
+		t.Fatalf("current file: %q", got)
+	}
+	if got, _ := os.ReadFile(path + ".1"); string(got) != "monday\n" {
+		t.Fatalf("rotated file: %q", got)
+	}
+}
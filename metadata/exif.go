@@ -0,0 +1,309 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// استخراج EXIF عکس‌ها (زمان عکاسی، GPS، مدل دوربین، جهت تصویر) بدون وابستگی خارجی.
+// JPEG (سگمنت APP1)، PNG (چانک eXIf) و HEIC (آیتم Exif در باکس meta) پشتیبانی می‌شوند.
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrNoExif            = errors.New("no EXIF data found")
+	ErrInvalidExif       = errors.New("invalid EXIF data")
+)
+
+// Photo is the metadata extracted from an image. It is plain data with json tags
+// so it can be embedded in collection items.
+type Photo struct {
+	Format       string     `json:"format,omitempty"` // "jpeg", "png" or "heic"
+	TakenAt      *time.Time `json:"takenAt,omitempty"`
+	Make         string     `json:"make,omitempty"`
+	Model        string     `json:"model,omitempty"`
+	LensModel    string     `json:"lensModel,omitempty"`
+	Software     string     `json:"software,omitempty"`
+	Orientation  int        `json:"orientation,omitempty"` // EXIF orientation 1-8
+	Width        int        `json:"width,omitempty"`
+	Height       int        `json:"height,omitempty"`
+	ISO          int        `json:"iso,omitempty"`
+	FNumber      float64    `json:"fNumber,omitempty"`
+	ExposureTime string     `json:"exposureTime,omitempty"` // e.g. "1/250"
+	FocalLength  float64    `json:"focalLength,omitempty"`  // millimetres
+	GPS          *GPS       `json:"gps,omitempty"`
+}
+
+// GPS is a position in decimal degrees. Altitude is in metres above sea level.
+type GPS struct {
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Altitude  *float64 `json:"altitude,omitempty"`
+}
+
+// Camera returns "Make Model" without repeating the make when the model already contains it.
+func (p *Photo) Camera() string {
+	if p.Make == "" || strings.HasPrefix(strings.ToLower(p.Model), strings.ToLower(p.Make)) {
+		return p.Model
+	}
+	return strings.TrimSpace(p.Make + " " + p.Model)
+}
+
+// TIFF/EXIF tags read by ParseExif.
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagOffsetTimeOrig   = 0x9011
+	tagFocalLength      = 0x920A
+	tagPixelXDimension  = 0xA002
+	tagPixelYDimension  = 0xA003
+	tagLensModel        = 0xA434
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+	tagGPSAltitudeRef  = 0x0005
+	tagGPSAltitude     = 0x0006
+)
+
+// typeSizes maps TIFF field types to their size in bytes.
+var typeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+type tiffReader struct {
+	buf   []byte
+	order binary.ByteOrder
+}
+
+// ParseExif parses a TIFF structure (the payload after "Exif\0\0") into p.
+func ParseExif(tiff []byte, p *Photo) error {
+	if len(tiff) < 8 {
+		return ErrInvalidExif
+	}
+	r := &tiffReader{buf: tiff}
+	switch string(tiff[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return fmt.Errorf("%w: bad byte order", ErrInvalidExif)
+	}
+	if r.order.Uint16(tiff[2:]) != 42 {
+		return fmt.Errorf("%w: bad TIFF magic", ErrInvalidExif)
+	}
+
+	ifd0, err := r.readIFD(r.order.Uint32(tiff[4:]))
+	if err != nil {
+		return err
+	}
+	p.Make = r.ascii(ifd0[tagMake])
+	p.Model = r.ascii(ifd0[tagModel])
+	p.Software = r.ascii(ifd0[tagSoftware])
+	if v, ok := r.uint(ifd0[tagOrientation]); ok && v >= 1 && v <= 8 {
+		p.Orientation = int(v)
+	}
+	dateTime := r.ascii(ifd0[tagDateTime])
+
+	if offset, ok := r.uint(ifd0[tagExifIFD]); ok {
+		exif, err := r.readIFD(offset)
+		if err != nil {
+			return err
+		}
+		if original := r.ascii(exif[tagDateTimeOriginal]); original != "" {
+			dateTime = original
+		}
+		if t, ok := parseExifTime(dateTime, r.ascii(exif[tagOffsetTimeOrig])); ok {
+			p.TakenAt = &t
+		}
+		if v, ok := r.uint(exif[tagISO]); ok {
+			p.ISO = int(v)
+		}
+		if v, ok := r.rational(exif[tagFNumber], 0); ok {
+			p.FNumber = v
+		}
+		if v, ok := r.rational(exif[tagFocalLength], 0); ok {
+			p.FocalLength = v
+		}
+		p.ExposureTime = r.exposure(exif[tagExposureTime])
+		p.LensModel = r.ascii(exif[tagLensModel])
+		if v, ok := r.uint(exif[tagPixelXDimension]); ok && p.Width == 0 {
+			p.Width = int(v)
+		}
+		if v, ok := r.uint(exif[tagPixelYDimension]); ok && p.Height == 0 {
+			p.Height = int(v)
+		}
+	} else if t, ok := parseExifTime(dateTime, ""); ok {
+		p.TakenAt = &t
+	}
+
+	if offset, ok := r.uint(ifd0[tagGPSIFD]); ok {
+		gps, err := r.readIFD(offset)
+		if err != nil {
+			return err
+		}
+		p.GPS = r.gps(gps)
+	}
+	return nil
+}
+
+func (r *tiffReader) readIFD(offset uint32) (map[uint16]tiffEntry, error) {
+	if int(offset)+2 > len(r.buf) {
+		return nil, fmt.Errorf("%w: IFD offset out of range", ErrInvalidExif)
+	}
+	count := int(r.order.Uint16(r.buf[offset:]))
+	start := int(offset) + 2
+	if start+count*12 > len(r.buf) {
+		return nil, fmt.Errorf("%w: IFD entries out of range", ErrInvalidExif)
+	}
+
+	entries := make(map[uint16]tiffEntry, count)
+	for i := 0; i < count; i++ {
+		raw := r.buf[start+i*12 : start+(i+1)*12]
+		tag, typ, n := r.order.Uint16(raw), r.order.Uint16(raw[2:]), r.order.Uint32(raw[4:])
+		size, ok := typeSizes[typ]
+		if !ok || n > uint32(len(r.buf)) {
+			continue
+		}
+		length := size * int(n)
+		var data []byte
+		if length <= 4 {
+			data = raw[8 : 8+length]
+		} else {
+			valueOffset := int(r.order.Uint32(raw[8:]))
+			if valueOffset < 0 || valueOffset+length > len(r.buf) {
+				continue
+			}
+			data = r.buf[valueOffset : valueOffset+length]
+		}
+		entries[tag] = tiffEntry{typ: typ, count: n, data: data}
+	}
+	return entries, nil
+}
+
+func (r *tiffReader) ascii(e tiffEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	s := string(e.data)
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func (r *tiffReader) uint(e tiffEntry) (uint32, bool) {
+	switch {
+	case e.count == 0:
+		return 0, false
+	case e.typ == 1 || e.typ == 7:
+		return uint32(e.data[0]), true
+	case e.typ == 3:
+		return uint32(r.order.Uint16(e.data)), true
+	case e.typ == 4:
+		return r.order.Uint32(e.data), true
+	}
+	return 0, false
+}
+
+// rational returns the i-th RATIONAL/SRATIONAL value of e.
+func (r *tiffReader) rational(e tiffEntry, i int) (float64, bool) {
+	if (e.typ != 5 && e.typ != 10) || uint32(i) >= e.count {
+		return 0, false
+	}
+	num, den := r.order.Uint32(e.data[i*8:]), r.order.Uint32(e.data[i*8+4:])
+	if den == 0 {
+		return 0, false
+	}
+	if e.typ == 10 {
+		return float64(int32(num)) / float64(int32(den)), true
+	}
+	return float64(num) / float64(den), true
+}
+
+func (r *tiffReader) exposure(e tiffEntry) string {
+	if e.typ != 5 || e.count == 0 {
+		return ""
+	}
+	num, den := r.order.Uint32(e.data), r.order.Uint32(e.data[4:])
+	switch {
+	case den == 0:
+		return ""
+	case num == 0:
+		return "0"
+	case num >= den:
+		return strconv.FormatFloat(float64(num)/float64(den), 'f', -1, 64)
+	default:
+		return "1/" + strconv.FormatFloat(math.Round(float64(den)/float64(num)), 'f', -1, 64)
+	}
+}
+
+func (r *tiffReader) gps(ifd map[uint16]tiffEntry) *GPS {
+	lat, ok1 := r.degrees(ifd[tagGPSLatitude])
+	lon, ok2 := r.degrees(ifd[tagGPSLongitude])
+	if !ok1 || !ok2 {
+		return nil
+	}
+	if r.ascii(ifd[tagGPSLatitudeRef]) == "S" {
+		lat = -lat
+	}
+	if r.ascii(ifd[tagGPSLongitudeRef]) == "W" {
+		lon = -lon
+	}
+	gps := &GPS{Latitude: lat, Longitude: lon}
+	if alt, ok := r.rational(ifd[tagGPSAltitude], 0); ok {
+		if ref, ok := r.uint(ifd[tagGPSAltitudeRef]); ok && ref == 1 {
+			alt = -alt
+		}
+		gps.Altitude = &alt
+	}
+	return gps
+}
+
+// degrees converts a degrees/minutes/seconds triple to decimal degrees.
+func (r *tiffReader) degrees(e tiffEntry) (float64, bool) {
+	d, ok1 := r.rational(e, 0)
+	m, ok2 := r.rational(e, 1)
+	s, ok3 := r.rational(e, 2)
+	if !ok1 || !ok2 || !ok3 {
+		return 0, false
+	}
+	return d + m/60 + s/3600, true
+}
+
+// parseExifTime parses "2006:01:02 15:04:05" with an optional "+03:30" offset.
+// Without an offset the time is interpreted in the local time zone.
+func parseExifTime(value, offset string) (time.Time, bool) {
+	if value == "" || strings.HasPrefix(value, "0000") {
+		return time.Time{}, false
+	}
+	if offset != "" {
+		if t, err := time.Parse("2006:01:02 15:04:05-07:00", value+offset); err == nil {
+			return t, true
+		}
+	}
+	t, err := time.ParseInLocation("2006:01:02 15:04:05", value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,194 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// buildTIFF writes a little-endian TIFF with IFD0, an Exif IFD and a GPS IFD.
+func buildTIFF() []byte {
+	type entry struct {
+		tag, typ uint16
+		count    uint32
+		value    []byte
+	}
+	le := binary.LittleEndian
+	short := func(v uint16) []byte { return le.AppendUint16(nil, v) }
+	long := func(v uint32) []byte { return le.AppendUint32(nil, v) }
+	rational := func(pairs ...uint32) []byte {
+		var b []byte
+		for _, v := range pairs {
+			b = le.AppendUint32(b, v)
+		}
+		return b
+	}
+	ascii := func(s string) (uint32, []byte) { return uint32(len(s) + 1), append([]byte(s), 0) }
+
+	buf := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	// هر IFD بعد از داده‌های IFD قبلی نوشته می‌شود؛ آفست‌ها بعداً وصله می‌شوند
+	writeIFD := func(entries []entry) (start int, patch map[uint16]int) {
+		start = len(buf)
+		patch = map[uint16]int{}
+		buf = le.AppendUint16(buf, uint16(len(entries)))
+		dataStart := start + 2 + len(entries)*12 + 4
+		var data []byte
+		for _, e := range entries {
+			buf = le.AppendUint16(buf, e.tag)
+			buf = le.AppendUint16(buf, e.typ)
+			buf = le.AppendUint32(buf, e.count)
+			if len(e.value) <= 4 {
+				patch[e.tag] = len(buf)
+				buf = append(buf, append(e.value, make([]byte, 4-len(e.value))...)...)
+			} else {
+				buf = le.AppendUint32(buf, uint32(dataStart+len(data)))
+				data = append(data, e.value...)
+			}
+		}
+		buf = le.AppendUint32(buf, 0)
+		buf = append(buf, data...)
+		return start, patch
+	}
+
+	makeCount, makeValue := ascii("Apple")
+	modelCount, modelValue := ascii("iPhone 15 Pro")
+	_, ifd0 := writeIFD([]entry{
+		{tagMake, 2, makeCount, makeValue},
+		{tagModel, 2, modelCount, modelValue},
+		{tagOrientation, 3, 1, short(6)},
+		{tagExifIFD, 4, 1, long(0)},
+		{tagGPSIFD, 4, 1, long(0)},
+	})
+
+	dateCount, dateValue := ascii("2024:03:20 14:30:05")
+	offsetCount, offsetValue := ascii("+03:30")
+	exifStart, _ := writeIFD([]entry{
+		{tagDateTimeOriginal, 2, dateCount, dateValue},
+		{tagOffsetTimeOrig, 2, offsetCount, offsetValue},
+		{tagISO, 3, 1, short(200)},
+		{tagFNumber, 5, 1, rational(18, 10)},
+		{tagExposureTime, 5, 1, rational(1, 250)},
+	})
+	gpsStart, _ := writeIFD([]entry{
+		{tagGPSLatitudeRef, 2, 2, []byte("N\x00")},
+		{tagGPSLatitude, 5, 3, rational(35, 1, 41, 1, 2400, 100)},
+		{tagGPSLongitudeRef, 2, 2, []byte("E\x00")},
+		{tagGPSLongitude, 5, 3, rational(51, 1, 23, 1, 0, 1)},
+		{tagGPSAltitude, 5, 1, rational(1200, 1)},
+	})
+	le.PutUint32(buf[ifd0[tagExifIFD]:], uint32(exifStart))
+	le.PutUint32(buf[ifd0[tagGPSIFD]:], uint32(gpsStart))
+	return buf
+}
+
+func checkPhoto(t *testing.T, p *Photo) {
+	t.Helper()
+	if p.Camera() != "Apple iPhone 15 Pro" || p.Orientation != 6 || p.ISO != 200 || p.FNumber != 1.8 || p.ExposureTime != "1/250" {
+		t.Fatalf("unexpected photo fields: %+v", p)
+	}
+	if p.TakenAt == nil || p.TakenAt.UTC().Format("2006-01-02 15:04:05") != "2024-03-20 11:00:05" {
+		t.Fatalf("unexpected takenAt: %v", p.TakenAt)
+	}
+	if p.GPS == nil || math.Abs(p.GPS.Latitude-35.69) > 1e-6 || math.Abs(p.GPS.Longitude-51.383333) > 1e-5 || *p.GPS.Altitude != 1200 {
+		t.Fatalf("unexpected GPS: %+v", p.GPS)
+	}
+}
+
+func TestExtractJPEG(t *testing.T) {
+	var img bytes.Buffer
+	jpeg.Encode(&img, image.NewGray(image.Rect(0, 0, 40, 30)), nil)
+
+	tiff := append([]byte("Exif\x00\x00"), buildTIFF()...)
+	app1 := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(tiff)+2))
+	file := append(append(append([]byte{0xFF, 0xD8}, app1...), tiff...), img.Bytes()[2:]...)
+
+	p, err := Extract(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Format != "jpeg" || p.Width != 40 || p.Height != 30 {
+		t.Fatalf("unexpected header fields: %+v", p)
+	}
+	checkPhoto(t, p)
+}
+
+func TestExtractPNG(t *testing.T) {
+	var img bytes.Buffer
+	png.Encode(&img, image.NewGray(image.Rect(0, 0, 7, 5)))
+	raw := img.Bytes()
+
+	tiff := buildTIFF()
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(tiff)))
+	chunk = append(append(chunk, "eXIf"...), tiff...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	ihdrEnd := 8 + 8 + 13 + 4
+	file := append(append(append([]byte{}, raw[:ihdrEnd]...), chunk...), raw[ihdrEnd:]...)
+
+	p, err := Extract(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Width != 7 || p.Height != 5 {
+		t.Fatalf("unexpected size: %dx%d", p.Width, p.Height)
+	}
+	checkPhoto(t, p)
+
+	p, err = Extract(bytes.NewReader(raw))
+	if !errors.Is(err, ErrNoExif) || p.Width != 7 {
+		t.Fatalf("expected ErrNoExif with header fields, got %v %+v", err, p)
+	}
+}
+
+func TestExtractHEIC(t *testing.T) {
+	box := func(kind string, payload ...[]byte) []byte {
+		body := bytes.Join(payload, nil)
+		b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+		return append(append(b, kind...), body...)
+	}
+	be := binary.BigEndian
+
+	infe := box("infe", []byte{2, 0, 0, 0}, be.AppendUint16(nil, 7), []byte{0, 0}, []byte("Exif"), []byte{0})
+	iinf := box("iinf", []byte{0, 0, 0, 0}, be.AppendUint16(nil, 1), infe)
+	ispe := box("ispe", []byte{0, 0, 0, 0}, be.AppendUint32(nil, 4032), be.AppendUint32(nil, 3024))
+	iprp := box("iprp", box("ipco", ispe))
+
+	exifItem := append(be.AppendUint32(nil, 6), append([]byte("Exif\x00\x00"), buildTIFF()...)...)
+	// iloc نسخه ۰: offset_size=4، length_size=4، base_offset_size=0
+	ilocFor := func(offset int) []byte {
+		body := []byte{0, 0, 0, 0, 0x44, 0x00}
+		body = be.AppendUint16(body, 1) // item_count
+		body = be.AppendUint16(body, 7) // item_ID
+		body = be.AppendUint16(body, 0) // data_reference_index
+		body = be.AppendUint16(body, 1) // extent_count
+		body = be.AppendUint32(body, uint32(offset))
+		body = be.AppendUint32(body, uint32(len(exifItem)))
+		return box("iloc", body)
+	}
+
+	ftyp := box("ftyp", []byte("heic"), []byte{0, 0, 0, 0}, []byte("mif1heic"))
+	meta := box("meta", []byte{0, 0, 0, 0}, iinf, iprp, ilocFor(0))
+	offset := len(ftyp) + len(meta) + 8
+	meta = box("meta", []byte{0, 0, 0, 0}, iinf, iprp, ilocFor(offset))
+	file := append(append(ftyp, meta...), box("mdat", exifItem)...)
+
+	p, err := Extract(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Format != "heic" || p.Width != 4032 || p.Height != 3024 {
+		t.Fatalf("unexpected header fields: %+v", p)
+	}
+	checkPhoto(t, p)
+}
+
+func TestExtractUnsupported(t *testing.T) {
+	if _, err := Extract(bytes.NewReader([]byte("GIF89a......"))); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxSegment bounds the metadata blocks read into memory.
+const maxSegment = 16 << 20
+
+var (
+	jpegMagic = []byte{0xFF, 0xD8}
+	pngMagic  = []byte("\x89PNG\r\n\x1a\n")
+	exifMagic = []byte("Exif\x00\x00")
+)
+
+// Extract reads the metadata of a JPEG, PNG or HEIC image. Width and Height are
+// filled from the image header when EXIF lacks them. ErrNoExif is returned, together
+// with the partially filled Photo, when the image has no EXIF block.
+func Extract(r io.ReadSeeker) (*Photo, error) {
+	head := make([]byte, 12)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("error reading image header: %w", err)
+	}
+	head = head[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("error seeking image: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(head, jpegMagic):
+		return extractJPEG(r)
+	case bytes.HasPrefix(head, pngMagic[:8]) && len(head) >= 8:
+		return extractPNG(r)
+	case len(head) >= 12 && string(head[4:8]) == "ftyp" && isHEIFBrand(string(head[8:12])):
+		return extractHEIC(r)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+func isHEIFBrand(brand string) bool {
+	switch brand {
+	case "heic", "heix", "heim", "heis", "hevc", "hevx", "mif1", "msf1", "avif":
+		return true
+	}
+	return false
+}
+
+// finish parses the TIFF block, if any, into p.
+func finish(p *Photo, tiff []byte) (*Photo, error) {
+	if tiff == nil {
+		return p, ErrNoExif
+	}
+	if err := ParseExif(tiff, p); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func extractJPEG(r io.Reader) (*Photo, error) {
+	p := &Photo{Format: "jpeg"}
+	br := &byteReader{r: r}
+	br.skip(2)
+
+	var tiff []byte
+	for br.err == nil {
+		if br.u8() != 0xFF {
+			return p, fmt.Errorf("%w: bad JPEG marker", ErrInvalidExif)
+		}
+		marker := br.u8()
+		for marker == 0xFF { // بایت‌های پرکننده
+			marker = br.u8()
+		}
+		switch {
+		case marker == 0xD9 || marker == 0xDA: // EOI / SOS: متادیتای بیشتری نمی‌آید
+			return finish(p, tiff)
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			continue
+		}
+
+		length := int(br.u16be()) - 2
+		if length < 0 || length > maxSegment {
+			return p, fmt.Errorf("%w: bad JPEG segment length", ErrInvalidExif)
+		}
+		switch {
+		case marker == 0xE1 && tiff == nil:
+			data := br.bytes(length)
+			if bytes.HasPrefix(data, exifMagic) {
+				tiff = data[len(exifMagic):]
+			}
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			data := br.bytes(length)
+			if len(data) >= 5 {
+				p.Height = int(binary.BigEndian.Uint16(data[1:]))
+				p.Width = int(binary.BigEndian.Uint16(data[3:]))
+			}
+		default:
+			br.skip(length)
+		}
+	}
+	if errors.Is(br.err, io.EOF) || errors.Is(br.err, io.ErrUnexpectedEOF) {
+		return finish(p, tiff)
+	}
+	return p, fmt.Errorf("error reading JPEG: %w", br.err)
+}
+
+func extractPNG(r io.Reader) (*Photo, error) {
+	p := &Photo{Format: "png"}
+	br := &byteReader{r: r}
+	br.skip(len(pngMagic))
+
+	var tiff []byte
+	for br.err == nil {
+		length := int(br.u32be())
+		kind := string(br.bytes(4))
+		if br.err != nil {
+			break
+		}
+		if length < 0 || length > maxSegment {
+			return p, fmt.Errorf("%w: bad PNG chunk length", ErrInvalidExif)
+		}
+		switch kind {
+		case "IHDR":
+			data := br.bytes(length)
+			if len(data) >= 8 {
+				p.Width = int(binary.BigEndian.Uint32(data))
+				p.Height = int(binary.BigEndian.Uint32(data[4:]))
+			}
+		case "eXIf":
+			tiff = br.bytes(length)
+		case "IEND":
+			return finish(p, tiff)
+		default:
+			br.skip(length)
+		}
+		br.skip(4) // CRC
+	}
+	if errors.Is(br.err, io.EOF) || errors.Is(br.err, io.ErrUnexpectedEOF) {
+		return finish(p, tiff)
+	}
+	return p, fmt.Errorf("error reading PNG: %w", br.err)
+}
+
+// byteReader reads big-endian values and remembers the first error.
+type byteReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *byteReader) bytes(n int) []byte {
+	if b.err != nil {
+		return nil
+	}
+	buf := make([]byte, n)
+	_, b.err = io.ReadFull(b.r, buf)
+	return buf
+}
+
+func (b *byteReader) skip(n int) {
+	if b.err != nil {
+		return
+	}
+	if s, ok := b.r.(io.Seeker); ok {
+		_, b.err = s.Seek(int64(n), io.SeekCurrent)
+		return
+	}
+	_, b.err = io.CopyN(io.Discard, b.r, int64(n))
+}
+
+func (b *byteReader) u8() byte {
+	if buf := b.bytes(1); b.err == nil {
+		return buf[0]
+	}
+	return 0
+}
+
+func (b *byteReader) u16be() uint16 {
+	if buf := b.bytes(2); b.err == nil {
+		return binary.BigEndian.Uint16(buf)
+	}
+	return 0
+}
+
+func (b *byteReader) u32be() uint32 {
+	if buf := b.bytes(4); b.err == nil {
+		return binary.BigEndian.Uint32(buf)
+	}
+	return 0
+}
@@ -0,0 +1,286 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// HEIC یک کانتینر ISOBMFF است: EXIF به صورت آیتمی از نوع "Exif" در باکس meta
+// تعریف می‌شود و محل داده‌اش در باکس iloc آمده است.
+
+func extractHEIC(r io.ReadSeeker) (*Photo, error) {
+	p := &Photo{Format: "heic"}
+
+	meta, err := findTopLevelBox(r, "meta")
+	if err != nil {
+		return p, err
+	}
+	if len(meta) < 4 {
+		return p, fmt.Errorf("%w: short meta box", ErrInvalidExif)
+	}
+	children := meta[4:] // version + flags
+
+	var exifID uint32
+	var found bool
+	if iinf, ok := childBox(children, "iinf"); ok {
+		exifID, found = findExifItem(iinf)
+	}
+	if ipco, ok := childBox(children, "iprp"); ok {
+		if ipco, ok = childBox(ipco, "ipco"); ok {
+			p.Width, p.Height = largestExtent(ipco)
+		}
+	}
+	if !found {
+		return p, ErrNoExif
+	}
+
+	iloc, ok := childBox(children, "iloc")
+	if !ok {
+		return p, fmt.Errorf("%w: missing iloc box", ErrInvalidExif)
+	}
+	offset, length, err := locateItem(iloc, exifID)
+	if err != nil {
+		return p, err
+	}
+	if length < 4 || length > maxSegment {
+		return p, fmt.Errorf("%w: bad Exif item length", ErrInvalidExif)
+	}
+
+	data := make([]byte, length)
+	if _, err := r.Seek(int64(offset), io.SeekStart); err != nil {
+		return p, fmt.Errorf("error seeking Exif item: %w", err)
+	}
+	if _, err := io.ReadFull(r, data); err != nil {
+		return p, fmt.Errorf("error reading Exif item: %w", err)
+	}
+
+	// آیتم Exif با یک فاصله ۴ بایتی تا هدر TIFF شروع می‌شود
+	skip := 4 + uint64(binary.BigEndian.Uint32(data))
+	if skip >= uint64(len(data)) {
+		return p, fmt.Errorf("%w: bad Exif header offset", ErrInvalidExif)
+	}
+	return finish(p, data[skip:])
+}
+
+// findTopLevelBox returns the payload of the first top-level box of the given type.
+func findTopLevelBox(r io.ReadSeeker, kind string) ([]byte, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return nil, ErrNoExif
+		}
+		size := uint64(binary.BigEndian.Uint32(header))
+		name := string(header[4:8])
+		headerSize := uint64(8)
+		switch size {
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, ErrNoExif
+			}
+			size = binary.BigEndian.Uint64(header[8:])
+			headerSize = 16
+		case 0:
+			if name != kind {
+				return nil, ErrNoExif
+			}
+		}
+		if size != 0 && size < headerSize {
+			return nil, fmt.Errorf("%w: bad box size", ErrInvalidExif)
+		}
+
+		if name == kind {
+			if size == 0 || size-headerSize > maxSegment {
+				return nil, fmt.Errorf("%w: %s box too large", ErrInvalidExif, kind)
+			}
+			payload := make([]byte, size-headerSize)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return nil, fmt.Errorf("error reading %s box: %w", kind, err)
+			}
+			return payload, nil
+		}
+		if _, err := r.Seek(int64(size-headerSize), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// eachBox calls fn for every box in buf until fn returns false.
+func eachBox(buf []byte, fn func(kind string, payload []byte) bool) {
+	for len(buf) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(buf))
+		headerSize := uint64(8)
+		if size == 1 && len(buf) >= 16 {
+			size = binary.BigEndian.Uint64(buf[8:])
+			headerSize = 16
+		} else if size == 0 {
+			size = uint64(len(buf))
+		}
+		if size < headerSize || size > uint64(len(buf)) {
+			return
+		}
+		if !fn(string(buf[4:8]), buf[headerSize:size]) {
+			return
+		}
+		buf = buf[size:]
+	}
+}
+
+func childBox(buf []byte, kind string) ([]byte, bool) {
+	var found []byte
+	eachBox(buf, func(k string, payload []byte) bool {
+		if k == kind {
+			found = payload
+			return false
+		}
+		return true
+	})
+	return found, found != nil
+}
+
+// findExifItem returns the item ID of the first "Exif" item in an iinf box.
+func findExifItem(iinf []byte) (uint32, bool) {
+	if len(iinf) < 6 {
+		return 0, false
+	}
+	entries := iinf[6:]
+	if iinf[0] != 0 {
+		if len(iinf) < 8 {
+			return 0, false
+		}
+		entries = iinf[8:]
+	}
+
+	var id uint32
+	var found bool
+	eachBox(entries, func(kind string, infe []byte) bool {
+		if kind != "infe" || len(infe) < 4 || infe[0] < 2 {
+			return true
+		}
+		body := infe[4:]
+		var itemID uint32
+		if infe[0] == 2 {
+			if len(body) < 8 {
+				return true
+			}
+			itemID, body = uint32(binary.BigEndian.Uint16(body)), body[2:]
+		} else {
+			if len(body) < 10 {
+				return true
+			}
+			itemID, body = binary.BigEndian.Uint32(body), body[4:]
+		}
+		// item_protection_index (2 بایت) و بعد نوع آیتم
+		if bytes.Equal(body[2:6], []byte("Exif")) {
+			id, found = itemID, true
+			return false
+		}
+		return true
+	})
+	return id, found
+}
+
+// largestExtent returns the biggest image spatial extent (ispe) in an ipco box.
+func largestExtent(ipco []byte) (int, int) {
+	var width, height int
+	eachBox(ipco, func(kind string, payload []byte) bool {
+		if kind == "ispe" && len(payload) >= 12 {
+			w := int(binary.BigEndian.Uint32(payload[4:]))
+			h := int(binary.BigEndian.Uint32(payload[8:]))
+			if w*h > width*height {
+				width, height = w, h
+			}
+		}
+		return true
+	})
+	return width, height
+}
+
+// locateItem returns the file offset and length of the first extent of an item.
+func locateItem(iloc []byte, itemID uint32) (uint64, uint64, error) {
+	b := &boxReader{buf: iloc}
+	version := b.u8()
+	b.skip(3)
+	sizes := b.u8()
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0x0F)
+	sizes = b.u8()
+	baseOffsetSize, indexSize := int(sizes>>4), 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0x0F)
+	}
+
+	var count uint32
+	if version < 2 {
+		count = uint32(b.uint(2))
+	} else {
+		count = uint32(b.uint(4))
+	}
+
+	for i := uint32(0); i < count && !b.failed; i++ {
+		var id uint32
+		if version < 2 {
+			id = uint32(b.uint(2))
+		} else {
+			id = uint32(b.uint(4))
+		}
+		method := uint64(0)
+		if version == 1 || version == 2 {
+			method = b.uint(2) & 0x0F
+		}
+		b.skip(2) // data_reference_index
+		base := b.uint(baseOffsetSize)
+		extents := int(b.uint(2))
+
+		var offset, length uint64
+		for e := 0; e < extents && !b.failed; e++ {
+			b.uint(indexSize)
+			extentOffset, extentLength := b.uint(offsetSize), b.uint(lengthSize)
+			if e == 0 {
+				offset, length = base+extentOffset, extentLength
+			}
+		}
+		if id == itemID && !b.failed {
+			if method != 0 || extents == 0 {
+				return 0, 0, fmt.Errorf("%w: unsupported Exif item location", ErrInvalidExif)
+			}
+			return offset, length, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: Exif item not located", ErrInvalidExif)
+}
+
+// boxReader reads big-endian integers from a box payload.
+type boxReader struct {
+	buf    []byte
+	failed bool
+}
+
+func (b *boxReader) skip(n int) {
+	if n > len(b.buf) {
+		b.failed, b.buf = true, nil
+		return
+	}
+	b.buf = b.buf[n:]
+}
+
+func (b *boxReader) u8() byte {
+	return byte(b.uint(1))
+}
+
+// uint reads an n byte unsigned integer (n may be 0, 1, 2, 4 or 8).
+func (b *boxReader) uint(n int) uint64 {
+	if n > len(b.buf) {
+		b.failed, b.buf = true, nil
+		return 0
+	}
+	var v uint64
+	for _, c := range b.buf[:n] {
+		v = v<<8 | uint64(c)
+	}
+	b.buf = b.buf[n:]
+	return v
+}
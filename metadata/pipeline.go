@@ -0,0 +1,39 @@
+package metadata
+
+import (
+	"errors"
+
+	"github.com/mahdi-cpp/iris-tools/blobstore"
+)
+
+// FromBlob extracts the metadata of a stored blob.
+func FromBlob(blobs *blobstore.Store, hash string) (*Photo, error) {
+	file, err := blobs.Open(hash)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Extract(file)
+}
+
+// Step returns an upload pipeline step: after a blob is stored it extracts the
+// metadata and hands it to save, e.g. to fill the fields of a photo record.
+// Images without EXIF are saved with the header fields only; blobs that are not
+// supported images are skipped.
+//
+//	blob, err := blobs.Put(upload)
+//	...
+//	err = extract(blob)
+func Step(blobs *blobstore.Store, save func(blob blobstore.Blob, photo *Photo) error) func(blob blobstore.Blob) error {
+	return func(blob blobstore.Blob) error {
+		photo, err := FromBlob(blobs, blob.Hash)
+		switch {
+		case errors.Is(err, ErrUnsupportedFormat):
+			return nil
+		case errors.Is(err, ErrNoExif):
+		case err != nil:
+			return err
+		}
+		return save(blob, photo)
+	}
+}
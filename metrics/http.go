@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Middleware records http_requests_total and http_request_duration_seconds by method,
+// route and status. The route is the request path with parameter values replaced by
+// their names (/photos/:id), which keeps the label cardinality bounded.
+func Middleware(r *Registry) mygin.HandlerFunc {
+	requests := r.Counter("http_requests_total", "HTTP requests by method, route and status.", "method", "route", "status")
+	duration := r.Histogram("http_request_duration_seconds", "HTTP request latency.", nil, "method", "route")
+	inflight := r.Gauge("http_requests_in_flight", "HTTP requests being served.").With()
+
+	return func(c *mygin.Context) {
+		start := time.Now()
+		inflight.Add(1)
+		defer inflight.Add(-1)
+
+		c.Next()
+
+		status := c.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := Route(c)
+		if c.FullPath() == "" && (status == http.StatusNotFound || status == http.StatusMethodNotAllowed) {
+			// درخواست‌های بدون مسیر ثبت‌شده برچسب‌های بی‌شمار نسازند
+			route = "unmatched"
+		}
+		requests.With(c.Method, route, strconv.Itoa(status)).Inc()
+		duration.With(c.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Route returns the registered path of the matched route, or the request path with
+// route parameter values replaced by ":name" when it is not known.
+func Route(c *mygin.Context) string {
+	if fullPath := c.FullPath(); fullPath != "" {
+		return fullPath
+	}
+	if len(c.Params) == 0 {
+		return c.Path
+	}
+	byValue := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		byValue[param.Value] = param.Key
+	}
+	segments := strings.Split(c.Path, "/")
+	for i, segment := range segments {
+		if name, ok := byValue[segment]; ok && segment != "" {
+			segments[i] = ":" + name
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Handler serves the registry in the Prometheus text format, e.g. on GET /metrics.
+func Handler(r *Registry) mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		c.Writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := r.WriteText(c.Writer); err != nil {
+			logger.Error("error writing metrics", "error", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/cache"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type note struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+func (n *note) SetID(id uuid.UUID) { n.ID = id }
+func (n *note) GetID() uuid.UUID   { return n.ID }
+func (n *note) GetRecordSize() int { return 128 }
+
+func TestEndpoint(t *testing.T) {
+	r := NewRegistry()
+
+	notes, err := collection_manager_memory.New[*note](t.TempDir(), "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notes.Close()
+	InstrumentManager(r, "notes", notes)
+	n, _ := notes.Create(&note{Text: "hello"})
+	notes.Read(n.ID)
+	notes.Read(uuid.New())
+
+	c, _ := cache.New(cache.Options[string, int]{Size: 4})
+	InstrumentCache(r, "thumbs", c)
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("b")
+
+	engine := mygin.New()
+	engine.Use(Middleware(r))
+	engine.GET("/notes/:id", func(c *mygin.Context) { c.String(http.StatusNotFound, "nope") })
+	engine.GET("/metrics", Handler(r))
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/notes/42", nil))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/notes/:id",status="404"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/notes/:id"} 1`,
+		`storage_operation_duration_seconds_count{collection="notes",op="read"} 2`,
+		`storage_operation_errors_total{collection="notes",op="read"} 1`,
+		`storage_items{collection="notes"} 1`,
+		`storage_file_bytes{collection="notes"} 128`,
+		`cache_hit_ratio{cache="thumbs"} 0.5`,
+		"# TYPE cache_hits_total counter",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	r := NewRegistry()
+	h := r.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}).With()
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(3)
+
+	var b strings.Builder
+	r.WriteText(&b)
+	for _, want := range []string{
+		`latency_seconds_bucket{le="0.1"} 1`,
+		`latency_seconds_bucket{le="1"} 2`,
+		`latency_seconds_bucket{le="+Inf"} 3`,
+		`latency_seconds_sum 3.55`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"github.com/mahdi-cpp/iris-tools/cache"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+var logger = logging.For("metrics")
+
+// InstrumentManager records the operation latencies and errors of a collection through
+// its Observe hook and exports its item count and data file size at scrape time.
+func InstrumentManager[T collection_manager_memory.CollectionItem](r *Registry, collection string, manager *collection_manager_memory.Manager[T]) {
+	duration := r.Histogram("storage_operation_duration_seconds", "Collection manager operation latency.", nil, "collection", "op")
+	errors := r.Counter("storage_operation_errors_total", "Failed collection manager operations.", "collection", "op")
+
+	manager.Observe(func(op collection_manager_memory.Op) {
+		duration.With(collection, op.Name).Observe(op.Duration.Seconds())
+		if op.Err != nil {
+			errors.With(collection, op.Name).Inc()
+		}
+	})
+
+	r.GaugeFunc("storage_items", "Items in a collection.", []string{"collection"}, func() []Sample {
+		return []Sample{{Labels: []string{collection}, Value: float64(manager.Count())}}
+	})
+	r.GaugeFunc("storage_file_bytes", "Size of a collection data file including deleted records.", []string{"collection"}, func() []Sample {
+		size, err := manager.FileSize()
+		if err != nil {
+			return nil
+		}
+		return []Sample{{Labels: []string{collection}, Value: float64(size)}}
+	})
+}
+
+// StatsSource is implemented by cache.Cache.
+type StatsSource interface {
+	Stats() cache.Stats
+}
+
+// InstrumentCache exports the counters and hit ratio of a cache at scrape time.
+func InstrumentCache(r *Registry, name string, c StatsSource) {
+	labels := []string{"cache"}
+	stat := func(pick func(cache.Stats) float64) func() []Sample {
+		return func() []Sample {
+			return []Sample{{Labels: []string{name}, Value: pick(c.Stats())}}
+		}
+	}
+	r.CounterFunc("cache_hits_total", "Cache hits.", labels, stat(func(s cache.Stats) float64 { return float64(s.Hits) }))
+	r.CounterFunc("cache_misses_total", "Cache misses.", labels, stat(func(s cache.Stats) float64 { return float64(s.Misses) }))
+	r.CounterFunc("cache_evictions_total", "Entries evicted because the cache was full.", labels, stat(func(s cache.Stats) float64 { return float64(s.Evictions) }))
+	r.GaugeFunc("cache_entries", "Entries in the cache.", labels, stat(func(s cache.Stats) float64 { return float64(s.Size) }))
+	r.GaugeFunc("cache_hit_ratio", "Hits divided by lookups.", labels, stat(cache.Stats.HitRatio))
+}
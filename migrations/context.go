@@ -0,0 +1,191 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/collection_file"
+)
+
+// ErrSkip can be returned by a transform function to drop the record.
+var ErrSkip = errors.New("skip record")
+
+// Context gives a migration access to the files of its collection directory.
+type Context struct {
+	Dir string
+}
+
+// Path returns the path of a file in the collection directory.
+func (c *Context) Path(name string) string {
+	return filepath.Join(c.Dir, name)
+}
+
+// TransformRecords rewrites every active record of a data file (e.g. "data.db")
+// through fn. Deleted and empty slots are dropped; a corrupt record aborts the
+// migration. recordSize 0 detects the size from the file. The original file is
+// replaced only after all records were written, and the index is reset so that
+// it is rebuilt on the next open. A missing file is not an error.
+func (c *Context) TransformRecords(fileName string, recordSize int, fn func(record map[string]any) (map[string]any, error)) error {
+	path := c.Path(fileName)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if recordSize == 0 {
+		size, err := collection_file.DetectRecordSize(path)
+		if errors.Is(err, collection_file.ErrRecordSizeUnknown) {
+			return nil // فایل خالی است
+		}
+		if err != nil {
+			return err
+		}
+		recordSize = size
+	}
+
+	tempPath := path + ".migrate"
+	out, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating migrated file: %w", err)
+	}
+	defer os.Remove(tempPath)
+
+	buffer := make([]byte, recordSize)
+	_, err = collection_file.Scan(path, recordSize, func(rec collection_file.Record) error {
+		switch rec.State {
+		case collection_file.StateCorrupt:
+			return fmt.Errorf("corrupt record at offset %d: %v", rec.Offset, rec.Err)
+		case collection_file.StateActive:
+		default:
+			return nil
+		}
+
+		var record map[string]any
+		if err := json.Unmarshal(rec.Data, &record); err != nil {
+			return fmt.Errorf("error unmarshaling record at offset %d: %w", rec.Offset, err)
+		}
+		record, err := fn(record)
+		if errors.Is(err, ErrSkip) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error transforming record at offset %d: %w", rec.Offset, err)
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("error marshaling record at offset %d: %w", rec.Offset, err)
+		}
+		if len(data)+1 > recordSize {
+			return fmt.Errorf("record at offset %d grows to %d bytes, larger than record size %d", rec.Offset, len(data)+1, recordSize)
+		}
+
+		clear(buffer)
+		buffer[0] = collection_file.StatusActive
+		copy(buffer[1:], data)
+		if _, err := out.Write(buffer); err != nil {
+			return fmt.Errorf("error writing migrated record: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return fmt.Errorf("error syncing migrated file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("error closing migrated file: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("error replacing data file: %w", err)
+	}
+	return c.RebuildIndex()
+}
+
+// ResizeRecords rewrites a data file with a new record size, e.g. after a
+// GetRecordSize change. It fails if a record does not fit.
+func (c *Context) ResizeRecords(fileName string, oldSize, newSize int) error {
+	if oldSize == newSize {
+		return nil
+	}
+	// TransformRecords اندازه رکورد ورودی و خروجی یکسان دارد، پس اینجا مستقیم می‌نویسیم
+	path := c.Path(fileName)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	var records [][]byte
+	_, err := collection_file.Scan(path, oldSize, func(rec collection_file.Record) error {
+		if rec.State == collection_file.StateCorrupt {
+			return fmt.Errorf("corrupt record at offset %d: %v", rec.Offset, rec.Err)
+		}
+		if rec.State != collection_file.StateActive {
+			return nil
+		}
+		if len(rec.Data)+1 > newSize {
+			return fmt.Errorf("record at offset %d (%d bytes) does not fit record size %d", rec.Offset, len(rec.Data)+1, newSize)
+		}
+		records = append(records, rec.Data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, len(records)*newSize)
+	for i, record := range records {
+		offset := i * newSize
+		data[offset] = collection_file.StatusActive
+		copy(data[offset+1:], record)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	return c.RebuildIndex()
+}
+
+// RenameField renames a top-level JSON field in every record of a data file.
+func (c *Context) RenameField(fileName string, recordSize int, from, to string) error {
+	return c.TransformRecords(fileName, recordSize, func(record map[string]any) (map[string]any, error) {
+		if value, ok := record[from]; ok {
+			delete(record, from)
+			record[to] = value
+		}
+		return record, nil
+	})
+}
+
+// SetDefault sets a top-level JSON field on every record that does not have it.
+func (c *Context) SetDefault(fileName string, recordSize int, field string, value any) error {
+	return c.TransformRecords(fileName, recordSize, func(record map[string]any) (map[string]any, error) {
+		if _, ok := record[field]; !ok {
+			record[field] = value
+		}
+		return record, nil
+	})
+}
+
+// RenameFile renames a file of the collection directory. A missing source is
+// not an error so the migration can be re-run after a partial failure.
+func (c *Context) RenameFile(from, to string) error {
+	err := os.Rename(c.Path(from), c.Path(to))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error renaming %s to %s: %w", from, to, err)
+	}
+	return nil
+}
+
+// RebuildIndex resets the index file so collection_manager_index rebuilds it on the next open.
+func (c *Context) RebuildIndex() error {
+	_, err := collection_file.ResetIndex(c.Dir)
+	return err
+}
@@ -0,0 +1,203 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+// پکیج migrations نسخه مهاجرت‌های اعمال‌شده را برای هر پوشه کالکشن در فایل
+// migrations.json نگه می‌دارد و مهاجرت‌های جدید را به ترتیب نسخه هنگام راه‌اندازی اجرا
+// می‌کند. مهاجرت‌ها باید قبل از باز کردن مدیریت‌کننده‌های کالکشن اجرا شوند چون فایل‌های
+// داده را مستقیماً بازنویسی می‌کنند.
+
+var logger = logging.For("migrations")
+
+const (
+	// StateFileName records the applied migrations of a collection directory.
+	StateFileName = "migrations.json"
+	lockFileName  = "migrations.lock"
+)
+
+// ErrLocked is returned when another process is migrating the same directory.
+var ErrLocked = errors.New("migrations are already running for this directory")
+
+// Migration is one ordered, named change to the stored data.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx *Context) error
+}
+
+// Applied is an entry of the state file.
+type Applied struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+type state struct {
+	Applied []Applied `json:"applied"`
+}
+
+// Runner runs the migrations of one collection directory.
+type Runner struct {
+	dir        string
+	migrations []Migration
+}
+
+// New returns a runner for dir.
+func New(dir string, migrations ...Migration) *Runner {
+	r := &Runner{dir: dir}
+	r.Add(migrations...)
+	return r
+}
+
+// Add registers migrations. Order of registration does not matter.
+func (r *Runner) Add(migrations ...Migration) {
+	r.migrations = append(r.migrations, migrations...)
+	sort.SliceStable(r.migrations, func(i, j int) bool {
+		return r.migrations[i].Version < r.migrations[j].Version
+	})
+}
+
+// Applied returns the migrations recorded in the state file.
+func (r *Runner) Applied() ([]Applied, error) {
+	s, err := r.readState()
+	if err != nil {
+		return nil, err
+	}
+	return s.Applied, nil
+}
+
+// Pending returns the registered migrations that have not been applied.
+func (r *Runner) Pending() ([]Migration, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	s, err := r.readState()
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[int]bool, len(s.Applied))
+	for _, a := range s.Applied {
+		done[a.Version] = true
+	}
+
+	var pending []Migration
+	for _, m := range r.migrations {
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Run applies every pending migration in version order. Each applied migration
+// is recorded immediately, so a failure leaves earlier migrations recorded and
+// the failing one pending. It returns the versions that were applied.
+func (r *Runner) Run() ([]int, error) {
+	if err := os.MkdirAll(r.dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("error creating directory %s: %w", r.dir, err)
+	}
+	unlock, err := r.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	pending, err := r.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int
+	for _, m := range pending {
+		start := time.Now()
+		if err := m.Up(&Context{Dir: r.dir}); err != nil {
+			return applied, fmt.Errorf("error running migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		if err := r.record(Applied{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()}); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m.Version)
+		logger.Info("migration applied", "dir", r.dir, "version", m.Version, "name", m.Name, "duration", time.Since(start))
+	}
+	return applied, nil
+}
+
+func (r *Runner) validate() error {
+	seen := make(map[int]string)
+	for _, m := range r.migrations {
+		if m.Up == nil {
+			return fmt.Errorf("migration %d (%s) has no Up function", m.Version, m.Name)
+		}
+		if other, ok := seen[m.Version]; ok {
+			return fmt.Errorf("duplicate migration version %d: %s and %s", m.Version, other, m.Name)
+		}
+		seen[m.Version] = m.Name
+	}
+	return nil
+}
+
+func (r *Runner) lock() (func(), error) {
+	path := filepath.Join(r.dir, lockFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("%w (remove %s if no migration is running)", ErrLocked, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating lock file: %w", err)
+	}
+	fmt.Fprintf(file, "%d\n", os.Getpid())
+	file.Close()
+	return func() { os.Remove(path) }, nil
+}
+
+func (r *Runner) readState() (state, error) {
+	var s state
+	data, err := os.ReadFile(filepath.Join(r.dir, StateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("error reading migration state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("error parsing migration state: %w", err)
+	}
+	return s, nil
+}
+
+func (r *Runner) record(a Applied) error {
+	s, err := r.readState()
+	if err != nil {
+		return err
+	}
+	s.Applied = append(s.Applied, a)
+	sort.Slice(s.Applied, func(i, j int) bool { return s.Applied[i].Version < s.Applied[j].Version })
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling migration state: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(r.dir, StateFileName), data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", tempPath, err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("error replacing %s: %w", path, err)
+	}
+	return nil
+}
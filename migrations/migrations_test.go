@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/collection_file"
+)
+
+func writeRecords(t *testing.T, path string, recordSize int, records ...string) {
+	t.Helper()
+	data := make([]byte, len(records)*recordSize)
+	for i, r := range records {
+		copy(data[i*recordSize+1:], r)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeRecords(t, filepath.Join(dir, "data.db"), 64, `{"id":"1","title":"a"}`, `{"id":"2","title":"b"}`)
+
+	runner := New(dir,
+		Migration{Version: 2, Name: "add_status", Up: func(ctx *Context) error {
+			return ctx.SetDefault("data.db", 0, "status", "draft")
+		}},
+		Migration{Version: 1, Name: "rename_title", Up: func(ctx *Context) error {
+			return ctx.RenameField("data.db", 64, "title", "name")
+		}},
+	)
+
+	applied, err := runner.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 || applied[0] != 1 || applied[1] != 2 {
+		t.Fatalf("unexpected applied versions %v", applied)
+	}
+
+	var got []map[string]any
+	_, err = collection_file.Scan(filepath.Join(dir, "data.db"), 64, func(rec collection_file.Record) error {
+		var m map[string]any
+		json.Unmarshal(rec.Data, &m)
+		got = append(got, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0]["name"] != "a" || got[0]["title"] != nil || got[1]["status"] != "draft" {
+		t.Fatalf("unexpected records %v", got)
+	}
+
+	// اجرای دوباره کاری انجام نمی‌دهد؛ مهاجرت ناموفق ثبت نمی‌شود
+	runner.Add(Migration{Version: 3, Name: "broken", Up: func(*Context) error { return errors.New("boom") }})
+	if applied, err := runner.Run(); err == nil || len(applied) != 0 {
+		t.Fatalf("expected failure without applied versions, got %v %v", applied, err)
+	}
+	pending, err := runner.Pending()
+	if err != nil || len(pending) != 1 || pending[0].Version != 3 {
+		t.Fatalf("unexpected pending %v %v", pending, err)
+	}
+}
@@ -0,0 +1,120 @@
+package mock_server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+// Exchange is one recorded request/response pair. JSON bodies are kept as raw
+// JSON in RequestBody and Body, other bodies as text in RequestText and Text:
+//
+//	{
+//	  "method": "GET",
+//	  "path": "/api/albums/:id",
+//	  "status": 200,
+//	  "headers": {"Content-Type": "application/json"},
+//	  "body": {"id": "…", "title": "Summer"}
+//	}
+//
+// Path may contain :name and *name segments like a route. Query, RequestBody and
+// RequestText are optional; when set the request must match them too.
+type Exchange struct {
+	ID uuid.UUID `json:"id"`
+
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Query       string          `json:"query,omitempty"`
+	RequestBody json.RawMessage `json:"requestBody,omitempty"`
+	RequestText string          `json:"requestText,omitempty"`
+
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	Text    string            `json:"text,omitempty"`
+}
+
+// LoadFile reads the exchanges of a fixture file holding one exchange or an array.
+func LoadFile(path string) ([]*Exchange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixture %s: %w", path, err)
+	}
+
+	var exchanges []*Exchange
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &exchanges)
+	} else {
+		exchange := &Exchange{}
+		err = json.Unmarshal(data, exchange)
+		exchanges = []*Exchange{exchange}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding fixture %s: %w", path, err)
+	}
+
+	for i, e := range exchanges {
+		if err := e.normalize(); err != nil {
+			return nil, fmt.Errorf("error in fixture %s, exchange %d: %w", path, i, err)
+		}
+	}
+	return exchanges, nil
+}
+
+// LoadDir reads every .json fixture file under dir, in lexical path order.
+func LoadDir(dir string) ([]*Exchange, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".json") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixtures in %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	var exchanges []*Exchange
+	for _, path := range paths {
+		loaded, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		exchanges = append(exchanges, loaded...)
+	}
+	return exchanges, nil
+}
+
+// normalize validates the exchange and fills defaults.
+func (e *Exchange) normalize() error {
+	e.Method = strings.ToUpper(e.Method)
+	if e.Method == "" {
+		return fmt.Errorf("method is required")
+	}
+	if e.Path == "" || e.Path[0] != '/' {
+		return fmt.Errorf("path %q must begin with '/'", e.Path)
+	}
+	if len(e.Path) > 1 {
+		e.Path = strings.TrimSuffix(e.Path, "/")
+	}
+	if e.Status == 0 {
+		e.Status = 200
+	}
+	if len(e.RequestBody) > 0 && !json.Valid(e.RequestBody) {
+		return fmt.Errorf("requestBody is not valid JSON")
+	}
+	if len(e.Body) > 0 && !json.Valid(e.Body) {
+		return fmt.Errorf("body is not valid JSON")
+	}
+	return nil
+}
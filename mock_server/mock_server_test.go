@@ -0,0 +1,82 @@
+package mock_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestServerMatching(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := `[
+		{"method": "get", "path": "/albums/:id", "body": {"id": "any"}},
+		{"method": "GET", "path": "/albums/7", "body": {"id": "7"}},
+		{"method": "GET", "path": "/albums", "body": []},
+		{"method": "GET", "path": "/albums", "body": [{"id": "7"}]},
+		{"method": "GET", "path": "/albums", "query": "sort=title&limit=1", "body": "sorted"},
+		{"method": "POST", "path": "/albums", "requestBody": {"title": "a", "year": 2024}, "status": 201, "body": {"id": "7"}},
+		{"method": "POST", "path": "/albums", "status": 400, "body": {"error": "invalid"}}
+	]`
+	if err := os.WriteFile(filepath.Join(dir, "albums.json"), []byte(fixtures), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exchanges, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(exchanges...)
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		want                 string
+	}{
+		{"GET", "/albums/3", "", 200, `{"id":"any"}`},
+		{"GET", "/albums/7/", "", 200, `{"id":"7"}`},
+		{"GET", "/albums", "", 200, `[]`},
+		{"GET", "/albums", "", 200, `[{"id":"7"}]`},
+		{"GET", "/albums", "", 200, `[{"id":"7"}]`}, // آخرین پاسخ تکرار می‌شود
+		{"GET", "/albums?limit=1&sort=title", "", 200, `"sorted"`},
+		{"POST", "/albums", `{ "year": 2024, "title": "a" }`, 201, `{"id":"7"}`},
+		{"POST", "/albums", `{"title": "b"}`, 400, `{"error":"invalid"}`},
+		{"DELETE", "/albums/7", "", 404, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+		if w.Code != tt.status || (tt.want != "" && normalizeJSON(w.Body.Bytes()) != tt.want) {
+			t.Errorf("%s %s: %d %s, want %d %s", tt.method, tt.target, w.Code, w.Body, tt.status, tt.want)
+		}
+	}
+
+	s.Reset()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/albums", nil))
+	if w.Body.String() != `[]` {
+		t.Errorf("after Reset: %s", w.Body)
+	}
+}
+
+func TestMiddlewarePassthrough(t *testing.T) {
+	s := New(&Exchange{Method: "GET", Path: "/mocked", Text: "mock", Headers: map[string]string{"Content-Type": "text/plain"}})
+	s.Passthrough = true
+
+	engine := mygin.New()
+	engine.Use(s.Middleware())
+	real := func(c *mygin.Context) { c.String(http.StatusOK, "real") }
+	engine.GET("/mocked", real)
+	engine.GET("/other", real)
+
+	for path, want := range map[string]string{"/mocked": "mock", "/other": "real"} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: got %q, want %q", path, w.Body, want)
+		}
+	}
+}
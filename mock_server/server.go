@@ -0,0 +1,264 @@
+package mock_server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+var logger = logging.For("mock_server")
+
+// maxBodySize limits how much of a request body is read for matching.
+const maxBodySize = 1 << 20
+
+// Server serves recorded exchanges instead of the real handlers, so frontends can
+// be developed against the API without its storage:
+//
+//	exchanges, err := mock_server.LoadDir("fixtures")
+//	mock := mock_server.New(exchanges...)
+//	http.ListenAndServe(":8080", mock)
+//
+// or, to keep the Engine's routes and middleware, before registering routes:
+//
+//	engine.Use(mock.Middleware())
+//
+// A request is answered by the most specific matching exchange: literal path
+// segments beat parameters, and exchanges with a query or request body beat those
+// without. When several exchanges match equally, e.g. a list recorded before and
+// after a create, they are replayed in order and the last one repeats.
+type Server struct {
+	// Passthrough lets Middleware continue the handler chain for unmatched requests
+	// instead of answering 404.
+	Passthrough bool
+
+	mu        sync.Mutex
+	exchanges []*Exchange
+	next      map[string]int // matchKey -> replay position
+}
+
+// New creates a Server serving exchanges.
+func New(exchanges ...*Exchange) *Server {
+	s := &Server{next: make(map[string]int)}
+	s.Add(exchanges...)
+	return s
+}
+
+// Add appends exchanges. Invalid exchanges are skipped and logged.
+func (s *Server) Add(exchanges ...*Exchange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range exchanges {
+		if err := e.normalize(); err != nil {
+			logger.Warn("exchange skipped", "method", e.Method, "path", e.Path, "error", err)
+			continue
+		}
+		s.exchanges = append(s.exchanges, e)
+	}
+}
+
+// Len returns the number of exchanges.
+func (s *Server) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.exchanges)
+}
+
+// Reset restarts every replay sequence from its first exchange.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next = make(map[string]int)
+}
+
+// Match returns the exchange that answers req, or nil. It reads the request body
+// and replaces it, so req can still be passed on.
+func (s *Server) Match(req *http.Request) *Exchange {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	normalizedBody := normalizeJSON(body)
+	query := normalizeQuery(req.URL.RawQuery)
+	path := req.URL.Path
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sequence []*Exchange
+	bestScore, bestKey := -1, ""
+	for _, e := range s.exchanges {
+		if e.Method != req.Method {
+			continue
+		}
+		literals, ok := matchPath(e.Path, path)
+		if !ok {
+			continue
+		}
+		score := literals * 4
+		if e.Query != "" {
+			if normalizeQuery(e.Query) != query {
+				continue
+			}
+			score++
+		}
+		if len(e.RequestBody) > 0 {
+			if normalizeJSON(e.RequestBody) != normalizedBody {
+				continue
+			}
+			score += 2
+		} else if e.RequestText != "" {
+			if e.RequestText != string(body) {
+				continue
+			}
+			score += 2
+		}
+
+		key := e.matchKey()
+		switch {
+		case score > bestScore:
+			bestScore, bestKey, sequence = score, key, []*Exchange{e}
+		case score == bestScore && key == bestKey:
+			sequence = append(sequence, e)
+		}
+	}
+	if len(sequence) == 0 {
+		return nil
+	}
+
+	i := s.next[bestKey]
+	if i < len(sequence)-1 {
+		s.next[bestKey] = i + 1
+	}
+	return sequence[min(i, len(sequence)-1)]
+}
+
+// ServeHTTP answers req with its matching exchange or 404.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	e := s.Match(req)
+	if e == nil {
+		notFound(w, req)
+		return
+	}
+	e.write(w)
+}
+
+// Middleware answers matched requests and aborts the chain. Unmatched requests get
+// 404, or reach the real handlers when Passthrough is set.
+func (s *Server) Middleware() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		e := s.Match(c.Req)
+		if e == nil {
+			if s.Passthrough {
+				c.Next()
+				return
+			}
+			notFound(c.Writer, c.Req)
+			c.Abort()
+			return
+		}
+		c.StatusCode = e.Status
+		e.write(c.Writer)
+		c.Abort()
+	}
+}
+
+func notFound(w http.ResponseWriter, req *http.Request) {
+	logger.Debug("no recorded exchange", "method", req.Method, "path", req.URL.Path)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(mygin.H{"error": "no recorded exchange for " + req.Method + " " + req.URL.Path})
+}
+
+// write sends the recorded response.
+func (e *Exchange) write(w http.ResponseWriter) {
+	header := w.Header()
+	for name, value := range e.Headers {
+		header.Set(name, value)
+	}
+	// Content-Length از پاسخ ضبط‌شده ممکن است با بدنه فعلی نخواند
+	header.Del("Content-Length")
+
+	body := []byte(e.Text)
+	if len(e.Body) > 0 {
+		body = e.Body
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json; charset=utf-8")
+		}
+	}
+	w.WriteHeader(e.Status)
+	w.Write(body)
+}
+
+// matchKey identifies exchanges that answer the same requests.
+func (e *Exchange) matchKey() string {
+	return e.Method + " " + e.Path + "?" + normalizeQuery(e.Query) + "\n" + normalizeJSON(e.RequestBody) + "\n" + e.RequestText
+}
+
+// matchPath matches a request path against an exchange path with :name and *name
+// segments and returns the number of literal segments.
+func matchPath(pattern, path string) (int, bool) {
+	if pattern == path {
+		return strings.Count(pattern, "/") + 1, true
+	}
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	literals := 0
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "*") {
+			return literals, true
+		}
+		if i >= len(pathSegments) {
+			return 0, false
+		}
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if pathSegments[i] == "" {
+				return 0, false
+			}
+		case segment == pathSegments[i]:
+			literals++
+		default:
+			return 0, false
+		}
+	}
+	return literals, len(patternSegments) == len(pathSegments)
+}
+
+// normalizeJSON makes equal JSON documents compare equal regardless of key order
+// and whitespace. Invalid JSON is returned as is.
+func normalizeJSON(data []byte) string {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return ""
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return string(data)
+	}
+	return string(normalized)
+}
+
+// normalizeQuery sorts the query parameters.
+func normalizeQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	return values.Encode()
+}
@@ -0,0 +1,214 @@
+package mygin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/mahdi-cpp/iris-tools/mygin/binding"
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// ErrEmptyBody is returned when binding a request without a body.
+var ErrEmptyBody = binding.ErrEmptyBody
+
+// ShouldBindWith decodes the request into obj with b and validates it with
+// DefaultValidator. After GetRawData it can be called several times, e.g. to
+// bind the same body into two structs. binding.JSON decodes with
+// Engine.JSONEncoder when it is set.
+func (c *Context) ShouldBindWith(obj any, b binding.Binding) error {
+	if b == binding.JSON && c.engine != nil && c.engine.JSONEncoder != nil {
+		b = binding.JSONWith(c.engine.JSONEncoder)
+	}
+	c.rewindBody()
+	defer c.rewindBody()
+	if err := b.Bind(c.Req, obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// BindWith is ShouldBindWith that answers 400 and aborts on failure.
+func (c *Context) BindWith(obj any, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+// ShouldBindJSON decodes the JSON request body into obj and validates it with
+// DefaultValidator (binding and validate tags). Validation failures satisfy
+// validation.Fields, so callers can answer 422 with the field messages:
+//
+//	var in CreateAlbum
+//	if err := c.ShouldBindJSON(&in); err != nil {
+//		if fields, ok := validation.Fields(err); ok {
+//			c.JSON(422, mygin.H{"error": "validation failed", "fields": fields})
+//			return
+//		}
+//		c.JSON(400, mygin.H{"error": err.Error()})
+//		return
+//	}
+func (c *Context) ShouldBindJSON(obj any) error {
+	return c.ShouldBindWith(obj, binding.JSON)
+}
+
+// ShouldBindXML decodes the XML request body into obj and validates it.
+func (c *Context) ShouldBindXML(obj any) error {
+	return c.ShouldBindWith(obj, binding.XML)
+}
+
+// ShouldBindYAML decodes the YAML request body into obj and validates it.
+func (c *Context) ShouldBindYAML(obj any) error {
+	return c.ShouldBindWith(obj, binding.YAML)
+}
+
+// ShouldBindMsgPack decodes the MessagePack request body into obj, using its json
+// tags, and validates it.
+func (c *Context) ShouldBindMsgPack(obj any) error {
+	return c.ShouldBindWith(obj, binding.MsgPack)
+}
+
+// BindJSON is ShouldBindJSON that answers 400 and aborts on failure:
+//
+//	400 {"error": "invalid JSON body: ..."}
+//	400 {"error": "validation failed", "fields": {"title": "is required"}}
+func (c *Context) BindJSON(obj any) error {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+// ShouldBindQuery sets the fields of the struct obj points to from the query
+// string using their form tags (see binding.MapForm for the supported types), then
+// validates it like ShouldBindJSON:
+//
+//	type Filter struct {
+//		Query string    `form:"q"`
+//		Tags  []string  `form:"tag"`
+//		Since time.Time `form:"since" time_format:"2006-01-02"`
+//		Limit *int      `form:"limit" validate:"omitempty,max=100"`
+//	}
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBindWith(obj, binding.Query)
+}
+
+// BindQuery is ShouldBindQuery that answers 400 and aborts on failure.
+func (c *Context) BindQuery(obj any) error {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+// ShouldBindUri sets the fields of the struct obj points to from the route params
+// using their uri tags, converting them like ShouldBindQuery, then validates it:
+//
+//	type AlbumParams struct {
+//		ID   uuid.UUID `uri:"id"`
+//		Page int       `uri:"page" validate:"min=1"`
+//	}
+//	r.GET("/albums/:id/pages/:page", func(c *mygin.Context) {
+//		var p AlbumParams
+//		if c.BindUri(&p) != nil {
+//			return
+//		}
+//	})
+func (c *Context) ShouldBindUri(obj any) error {
+	values := make(map[string][]string, len(c.Params))
+	for _, param := range c.Params {
+		values[param.Key] = []string{param.Value}
+	}
+	if err := binding.MapForm(obj, values, "uri"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// BindUri is ShouldBindUri that answers 400 and aborts on failure.
+func (c *Context) BindUri(obj any) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+// ShouldBind binds the request into obj with the binding registered for its
+// Content-Type (see binding.Lookup) and validates it:
+//
+//	application/json, +json               like ShouldBindJSON
+//	application/xml, text/xml             like ShouldBindXML
+//	application/yaml, application/x-yaml  like ShouldBindYAML
+//	application/msgpack, x-msgpack        like ShouldBindMsgPack
+//	multipart/form-data                   form tags, including file fields
+//	application/x-www-form-urlencoded     form tags, body and query values
+//
+// Requests without a body, such as GET, are bound from the query string. File
+// fields have the type *multipart.FileHeader, or []*multipart.FileHeader for
+// several files with the same name:
+//
+//	type Upload struct {
+//		Album  string                  `form:"album" validate:"required"`
+//		Photo  *multipart.FileHeader   `form:"photo" validate:"required"`
+//		Extras []*multipart.FileHeader `form:"extras"`
+//	}
+//
+// Multipart bodies beyond Engine.MaxMultipartMemory are stored in temporary files.
+// Other formats, e.g. CBOR, are added with binding.Register.
+func (c *Context) ShouldBind(obj any) error {
+	b, err := binding.Default(c.Req)
+	if err != nil {
+		return err
+	}
+	if b == binding.Multipart {
+		// حد حافظه Engine پیش از binding اعمال می‌شود
+		if err := c.Req.ParseMultipartForm(c.maxMultipartMemory()); err != nil {
+			return fmt.Errorf("invalid multipart body: %w", err)
+		}
+	}
+	return c.ShouldBindWith(obj, b)
+}
+
+// Bind is ShouldBind that answers 400 and aborts on failure.
+func (c *Context) Bind(obj any) error {
+	if err := c.ShouldBind(obj); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+func (c *Context) maxMultipartMemory() int64 {
+	if c.engine != nil && c.engine.MaxMultipartMemory > 0 {
+		return c.engine.MaxMultipartMemory
+	}
+	return binding.DefaultMaxMultipartMemory
+}
+
+func (c *Context) abortWithBindError(err error) {
+	if fields, ok := validation.Fields(err); ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, H{"error": "validation failed", "fields": fields})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, H{"error": err.Error()})
+}
+
+// validate runs DefaultValidator when obj points to a struct.
+func validate(obj any) error {
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct || DefaultValidator == nil {
+		return nil
+	}
+	return DefaultValidator.ValidateStruct(obj)
+}
@@ -0,0 +1,205 @@
+package binding
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"gopkg.in/yaml.v3"
+)
+
+// پکیج binding بدنه درخواست را بر اساس Content-Type به struct تبدیل می‌کند. Context
+// در mygin از این رجیستری استفاده می‌کند و اعتبارسنجی را پس از آن انجام می‌دهد.
+
+// ErrEmptyBody is returned when binding a request without a body.
+var ErrEmptyBody = errors.New("empty body")
+
+// DefaultMaxMultipartMemory is how much of a multipart body Multipart keeps in
+// memory when the request has not been parsed yet (32 MiB).
+const DefaultMaxMultipartMemory = 32 << 20
+
+// Binding decodes a request into obj. Implementations do not validate; the Bind
+// methods of mygin.Context do that afterwards.
+type Binding interface {
+	Name() string
+	Bind(req *http.Request, obj any) error
+}
+
+// Built-in bindings.
+var (
+	JSON      Binding = jsonBinding{}
+	XML       Binding = xmlBinding{}
+	YAML      Binding = yamlBinding{}
+	MsgPack   Binding = msgpackBinding{}
+	Form      Binding = formBinding{}
+	Multipart Binding = multipartBinding{}
+	Query     Binding = queryBinding{}
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Binding{
+		"application/json":                  JSON,
+		"application/xml":                   XML,
+		"text/xml":                          XML,
+		"application/yaml":                  YAML,
+		"application/x-yaml":                YAML,
+		"text/yaml":                         YAML,
+		"application/msgpack":               MsgPack,
+		"application/x-msgpack":             MsgPack,
+		"application/x-www-form-urlencoded": Form,
+		"multipart/form-data":               Multipart,
+	}
+)
+
+// Register makes b the binding for a media type, replacing a built-in one:
+//
+//	binding.Register("application/cbor", cborBinding{})
+func Register(mediaType string, b Binding) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(mediaType)] = b
+}
+
+// Lookup returns the binding registered for the media type of a Content-Type
+// header. Structured syntax suffixes such as application/merge-patch+json fall
+// back to the binding of application/json (or xml, yaml).
+func Lookup(contentType string) (Binding, bool) {
+	mediaType := MediaType(contentType)
+	if mediaType == "" {
+		return nil, false
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if b, ok := registry[mediaType]; ok {
+		return b, true
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		b, ok := registry["application/"+mediaType[i+1:]]
+		return b, ok
+	}
+	return nil, false
+}
+
+// MediaType returns the lower-case media type of a Content-Type header without its
+// parameters, e.g. "application/json" for "Application/JSON; charset=utf-8", or
+// "" when the header is empty or malformed.
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil && !errors.Is(err, mime.ErrInvalidMediaParameter) {
+		return ""
+	}
+	return mediaType
+}
+
+// Default returns the binding for a request: Query for GET requests and requests
+// without a body, the registered binding of the Content-Type otherwise.
+func Default(req *http.Request) (Binding, error) {
+	if req.Method == http.MethodGet || req.Body == nil || req.Body == http.NoBody {
+		return Query, nil
+	}
+	contentType := req.Header.Get("Content-Type")
+	if b, ok := Lookup(contentType); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("unsupported content type %q", contentType)
+}
+
+// decodeBody runs decode on the request body and names empty bodies and syntax
+// errors after the format.
+func decodeBody(req *http.Request, format string, decode func(io.Reader) error) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return fmt.Errorf("invalid %s body: %w", format, ErrEmptyBody)
+	}
+	if err := decode(req.Body); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = ErrEmptyBody
+		}
+		return fmt.Errorf("invalid %s body: %w", format, err)
+	}
+	return nil
+}
+
+// JSONDecoder decodes one JSON value from r into v.
+type JSONDecoder interface {
+	Decode(r io.Reader, v any) error
+}
+
+// JSONWith returns a JSON binding that decodes with dec instead of goccy/go-json;
+// mygin uses it for the JSONEncoder configured on the Engine.
+func JSONWith(dec JSONDecoder) Binding {
+	return jsonBinding{dec: dec}
+}
+
+type jsonBinding struct {
+	dec JSONDecoder // nil: goccy/go-json
+}
+
+func (jsonBinding) Name() string { return "json" }
+
+// Bind decodes the body after setting the default values of json tags.
+func (b jsonBinding) Bind(req *http.Request, obj any) error {
+	if err := ApplyDefaults(obj, "json"); err != nil {
+		return err
+	}
+	return decodeBody(req, "JSON", func(r io.Reader) error {
+		if b.dec != nil {
+			return b.dec.Decode(r, obj)
+		}
+		return json.NewDecoder(r).Decode(obj)
+	})
+}
+
+type xmlBinding struct{}
+
+func (xmlBinding) Name() string { return "xml" }
+
+func (xmlBinding) Bind(req *http.Request, obj any) error {
+	return decodeBody(req, "XML", func(r io.Reader) error { return xml.NewDecoder(r).Decode(obj) })
+}
+
+type yamlBinding struct{}
+
+func (yamlBinding) Name() string { return "yaml" }
+
+func (yamlBinding) Bind(req *http.Request, obj any) error {
+	return decodeBody(req, "YAML", func(r io.Reader) error { return yaml.NewDecoder(r).Decode(obj) })
+}
+
+type formBinding struct{}
+
+func (formBinding) Name() string { return "form" }
+
+// Bind maps the urlencoded body and the query string with form tags.
+func (formBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseForm(); err != nil {
+		return fmt.Errorf("invalid form body: %w", err)
+	}
+	return MapForm(obj, req.Form, "form")
+}
+
+type multipartBinding struct{}
+
+func (multipartBinding) Name() string { return "multipart" }
+
+// Bind maps the text and file fields of a multipart/form-data body with form tags.
+func (multipartBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseMultipartForm(DefaultMaxMultipartMemory); err != nil {
+		return fmt.Errorf("invalid multipart body: %w", err)
+	}
+	return MapFormFiles(obj, req.MultipartForm.Value, req.MultipartForm.File, "form")
+}
+
+type queryBinding struct{}
+
+func (queryBinding) Name() string { return "query" }
+
+func (queryBinding) Bind(req *http.Request, obj any) error {
+	return MapForm(obj, req.URL.Query(), "form")
+}
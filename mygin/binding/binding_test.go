@@ -0,0 +1,136 @@
+package binding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type album struct {
+	Title string `json:"title" xml:"title" yaml:"title" form:"title"`
+	Count int    `json:"count" xml:"count" yaml:"count" form:"count"`
+}
+
+type upperBinding struct{}
+
+func (upperBinding) Name() string { return "upper" }
+
+func (upperBinding) Bind(req *http.Request, obj any) error {
+	obj.(*album).Title = "UPPER"
+	return nil
+}
+
+func TestDefault(t *testing.T) {
+	Register("application/vnd.upper", upperBinding{})
+
+	tests := []struct {
+		method, contentType, body string
+		want                      album
+	}{
+		{"POST", "application/json; charset=utf-8", `{"title":"trip","count":2}`, album{"trip", 2}},
+		{"PATCH", "application/merge-patch+json", `{"count":3}`, album{"", 3}},
+		{"POST", "application/xml", `<album><title>trip</title><count>2</count></album>`, album{"trip", 2}},
+		{"POST", "application/yaml", "title: trip\ncount: 2\n", album{"trip", 2}},
+		{"POST", "application/x-www-form-urlencoded", "title=trip&count=2", album{"trip", 2}},
+		{"POST", "application/msgpack", "\x82\xa5title\xa4trip\xa5count\x02", album{"trip", 2}},
+		{"POST", "application/x-msgpack", "\x81\xa5count\xcd\x01\x00", album{"", 256}},
+		{"GET", "", "", album{"q", 0}},
+		{"POST", "application/vnd.upper", "x", album{"UPPER", 0}},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/albums?title=q", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		b, err := Default(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.contentType, err)
+		}
+		var got album
+		if err := b.Bind(req, &got); err != nil || got != tt.want {
+			t.Errorf("%s (%s): %+v %v", tt.contentType, b.Name(), got, err)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/albums", strings.NewReader("x"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if _, err := Default(req); err == nil {
+		t.Fatal("expected unsupported content type")
+	}
+}
+
+func TestMsgPack(t *testing.T) {
+	type photo struct {
+		Tags    []string       `json:"tags"`
+		Offset  int            `json:"offset"`
+		Ratio   float64        `json:"ratio"`
+		Data    []byte         `json:"data"`
+		Taken   time.Time      `json:"taken"`
+		Sizes   map[int]string `json:"sizes"`
+		Extra   map[string]any `json:"extra"`
+		Visible bool           `json:"visible,default=true"`
+	}
+	body := "\x87" +
+		"\xa4tags\x92\xa1a\xd9\x01b" + // fixstr and str 8
+		"\xa6offset\xd0\x80" + // int 8 -128
+		"\xa5ratio\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00" + // 1.5
+		"\xa4data\xc4\x03\x01\x02\x03" +
+		"\xa5taken\xd6\xff\x65\x53\xf1\x00" + // timestamp 32
+		"\xa5sizes\x81\x01\xa5small" +
+		"\xa5extra\x81\xa1n\xc0"
+	req := httptest.NewRequest("POST", "/photos", strings.NewReader(body))
+	var got photo
+	if err := MsgPack.Bind(req, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tags) != 2 || got.Tags[1] != "b" || got.Offset != -128 || got.Ratio != 1.5 ||
+		string(got.Data) != "\x01\x02\x03" || got.Taken.Unix() != 0x6553f100 ||
+		got.Sizes[1] != "small" || got.Extra["n"] != nil || !got.Visible {
+		t.Fatalf("unexpected %+v", got)
+	}
+
+	for _, body := range []string{"", "\x92\x01", "\xc1", "\x01\x02", "\xdd\xff\xff\xff\xff", "\xd4\x05\x00"} {
+		req := httptest.NewRequest("POST", "/photos", strings.NewReader(body))
+		if err := MsgPack.Bind(req, &got); err == nil {
+			t.Errorf("expected error for %q", body)
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	type paging struct {
+		Limit int `json:"limit,omitempty,default=50" form:"limit,default=50"`
+	}
+	type filter struct {
+		paging
+		Sort  []string       `form:"sort,default=name;id"`
+		Since *time.Duration `form:"since,default=1h"`
+		Query string         `json:"q" form:"q"`
+		Kind  string         `json:"kind,default=photo,video"`
+	}
+
+	var got filter
+	if err := Query.Bind(httptest.NewRequest("GET", "/?q=sea", nil), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Limit != 50 || len(got.Sort) != 2 || got.Sort[1] != "id" || got.Since == nil || *got.Since != time.Hour || got.Query != "sea" {
+		t.Fatalf("query defaults: %+v", got)
+	}
+	got = filter{}
+	if err := Query.Bind(httptest.NewRequest("GET", "/?limit=5&sort=date", nil), &got); err != nil || got.Limit != 5 || len(got.Sort) != 1 {
+		t.Fatalf("query values: %+v %v", got, err)
+	}
+
+	got = filter{}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"q":"sea","limit":0}`))
+	if err := JSON.Bind(req, &got); err != nil || got.Limit != 0 || got.Kind != "photo,video" || got.Query != "sea" {
+		t.Fatalf("json defaults: %+v %v", got, err)
+	}
+
+	type bad struct {
+		N int `form:"n,default=x"`
+	}
+	if err := Query.Bind(httptest.NewRequest("GET", "/", nil), &bad{}); err == nil {
+		t.Fatal("expected invalid default error")
+	}
+}
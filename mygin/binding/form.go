@@ -0,0 +1,281 @@
+package binding
+
+import (
+	"encoding"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// MapForm sets the fields of the struct obj points to from values, using the
+// struct tag named tag for the keys:
+//
+//	type Filter struct {
+//		Query  string    `form:"q"`
+//		Tags   []string  `form:"tag"`              // ?tag=a&tag=b
+//		Since  time.Time `form:"since" time_format:"2006-01-02"`
+//		Limit  *int      `form:"limit"`            // nil when absent
+//	}
+//
+// Fields without the tag use their Go name; "-" skips a field. Embedded and nested
+// structs without a tag are mapped with the same values. Supported types are
+// strings, bools, numbers, time.Duration, time.Time (time_format is a layout,
+// "unix" or "unixmilli"; RFC 3339 by default), types implementing
+// encoding.TextUnmarshaler such as uuid.UUID, pointers to and slices of these.
+// Values that cannot be converted are reported as validation.Errors.
+//
+// A default option sets fields whose key is absent, e.g. `form:"limit,default=50"`.
+// It must be the last option and may contain commas; slices take several values
+// separated by ';', e.g. `form:"sort,default=name;id"`.
+func MapForm(obj any, values map[string][]string, tag string) error {
+	return MapFormFiles(obj, values, nil, tag)
+}
+
+// MapFormFiles is MapForm that also sets *multipart.FileHeader and
+// []*multipart.FileHeader fields from the uploaded files.
+func MapFormFiles(obj any, values map[string][]string, files map[string][]*multipart.FileHeader, tag string) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: expected pointer to struct, got %T", obj)
+	}
+	if err := ApplyDefaults(obj, tag); err != nil {
+		return err
+	}
+	errs := validation.Errors{}
+	mapStruct(rv.Elem(), values, files, tag, errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType     = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+func mapStruct(rv reflect.Value, values map[string][]string, files map[string][]*multipart.FileHeader, tag string, errs validation.Errors) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, ok := field.Tag.Lookup(tag)
+		name, _, _ = strings.Cut(name, ",")
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+
+		// struct های تودرتو بدون تگ با همان مقادیر پر می‌شوند
+		if !ok && isNestedStruct(field.Type) {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(field.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			mapStruct(fv, values, files, tag, errs)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		switch field.Type {
+		case fileHeaderType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs[0]))
+			}
+			continue
+		case fileHeadersType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs))
+			}
+			continue
+		}
+
+		vs, present := values[name]
+		if !present || len(vs) == 0 {
+			continue
+		}
+		if err := setField(fv, field, vs); err != nil {
+			errs[name] = err.Error()
+		}
+	}
+}
+
+// ApplyDefaults sets the fields of the struct obj points to that have a default
+// option in the struct tag named tag, e.g. `json:"limit,default=50"`. The JSON
+// binding calls it before decoding, so fields missing from the body keep their
+// default. Nested structs are filled too; nil pointers to structs are left alone.
+func ApplyDefaults(obj any, tag string) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return applyDefaults(rv.Elem(), tag)
+}
+
+func applyDefaults(rv reflect.Value, tag string) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := rv.Field(i)
+		if field.Type.Kind() == reflect.Struct && isNestedStruct(field.Type) {
+			if err := applyDefaults(fv, tag); err != nil {
+				return err
+			}
+			continue
+		}
+		value, ok := tagDefault(field.Tag.Get(tag))
+		if !ok || !fv.CanSet() {
+			continue
+		}
+		values := []string{value}
+		if fv.Kind() == reflect.Slice {
+			values = strings.Split(value, ";")
+		}
+		if err := setField(fv, field, values); err != nil {
+			return fmt.Errorf("binding: invalid default for %s.%s: %w", t.Name(), field.Name, err)
+		}
+	}
+	return nil
+}
+
+// tagDefault returns the value of the default option of a tag: everything after
+// "default=" in the options.
+func tagDefault(tag string) (string, bool) {
+	_, options, _ := strings.Cut(tag, ",")
+	for options != "" {
+		if value, ok := strings.CutPrefix(options, "default="); ok {
+			return value, true
+		}
+		_, options, _ = strings.Cut(options, ",")
+	}
+	return "", false
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && t != fileHeaderType.Elem() &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setField converts vs into fv; slices take every value, other kinds the first.
+func setField(fv reflect.Value, field reflect.StructField, vs []string) error {
+	if fv.Kind() == reflect.Slice && !fv.Type().Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(fv.Type(), len(vs), len(vs))
+		for i, v := range vs {
+			if err := setValue(slice.Index(i), field, v); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setValue(fv, field, vs[0])
+}
+
+func setValue(fv reflect.Value, field reflect.StructField, v string) error {
+	if fv.Kind() == reflect.Ptr {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setValue(ptr.Elem(), field, v); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	switch {
+	case fv.Type() == timeType:
+		parsed, err := parseTime(v, field.Tag.Get("time_format"))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(parsed))
+		return nil
+	case fv.Type() == durationType:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("must be a duration, got %q", v)
+		}
+		fv.SetInt(int64(d))
+		return nil
+	case fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType):
+		if err := fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("invalid value %q", v)
+		}
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(v)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("must be a boolean, got %q", v)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(v, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", v)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(v, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a non-negative integer, got %q", v)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(v, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", v)
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+// parseTime parses v with a time_format layout, "unix" or "unixmilli".
+func parseTime(v, layout string) (time.Time, error) {
+	switch layout {
+	case "unix", "unixmilli":
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("must be a %s timestamp, got %q", layout, v)
+		}
+		if layout == "unix" {
+			return time.Unix(n, 0), nil
+		}
+		return time.UnixMilli(n), nil
+	case "":
+		layout = time.RFC3339
+	}
+	parsed, err := time.Parse(layout, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be a time in the format %s, got %q", layout, v)
+	}
+	return parsed, nil
+}
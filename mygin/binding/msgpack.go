@@ -0,0 +1,36 @@
+package binding
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type msgpackBinding struct{}
+
+func (msgpackBinding) Name() string { return "msgpack" }
+
+// Bind decodes a MessagePack body into obj using its json tags, after setting the
+// default values of json tags. Binary values bind to []byte fields and timestamp
+// extensions to time.Time fields.
+func (msgpackBinding) Bind(req *http.Request, obj any) error {
+	if err := ApplyDefaults(obj, "json"); err != nil {
+		return err
+	}
+	return decodeBody(req, "MessagePack", func(r io.Reader) error {
+		dec := msgpack.GetDecoder()
+		defer msgpack.PutDecoder(dec)
+		dec.Reset(r)
+		// مدل داده MessagePack و JSON یکی است، پس همان تگ‌های json خوانده می‌شوند
+		dec.SetCustomStructTag("json")
+		if err := dec.Decode(obj); err != nil {
+			return err
+		}
+		if _, err := dec.PeekCode(); !errors.Is(err, io.EOF) {
+			return errors.New("data after the value")
+		}
+		return nil
+	})
+}
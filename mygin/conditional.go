@@ -0,0 +1,72 @@
+package mygin
+
+import "strings"
+
+// Predicate reports whether a request meets a condition, for When and Unless.
+type Predicate func(c *Context) bool
+
+// When runs middleware only for requests matching predicate; other requests go on
+// to the next handler as if it were not in the chain:
+//
+//	r.Use(mygin.When(mygin.MethodIs(http.MethodPost, http.MethodPut), audit.Middleware()))
+func When(predicate Predicate, middleware HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if predicate(c) {
+			middleware(c)
+		}
+	}
+}
+
+// Unless runs middleware for every request except those whose path matches one
+// of paths (see PathIs), so global middleware can leave out health checks and
+// static assets:
+//
+//	r.Use(mygin.Unless(h.Require(), "/healthz", "/readyz", "/static/*"))
+func Unless(middleware HandlerFunc, paths ...string) HandlerFunc {
+	return When(Not(PathIs(paths...)), middleware)
+}
+
+// Not negates predicate.
+func Not(predicate Predicate) Predicate {
+	return func(c *Context) bool {
+		return !predicate(c)
+	}
+}
+
+// PathIs matches requests whose path equals one of patterns, or starts with
+// the part before a trailing "*": "/static/*" matches /static/app.js but not
+// /static.
+func PathIs(patterns ...string) Predicate {
+	exact := make(map[string]bool, len(patterns))
+	var prefixes []string
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			prefixes = append(prefixes, prefix)
+			continue
+		}
+		exact[pattern] = true
+	}
+	return func(c *Context) bool {
+		if exact[c.Path] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(c.Path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MethodIs matches requests with one of methods.
+func MethodIs(methods ...string) Predicate {
+	return func(c *Context) bool {
+		for _, method := range methods {
+			if strings.EqualFold(c.Method, method) {
+				return true
+			}
+		}
+		return false
+	}
+}
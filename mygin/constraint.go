@@ -0,0 +1,89 @@
+package mygin
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Param constraints restrict the values a :param accepts. They are written after
+// the param name in angle brackets, either as a registered name or as a regular
+// expression that must match the whole segment:
+//
+//	r.GET("/users/:id<uuid>", getUser)
+//	r.GET("/posts/:year<\d{4}>/:slug", getPost)
+//	r.GET("/users/:name", getUserByName) // receives what :id<uuid> rejects
+//
+// A request whose value does not satisfy the constraint falls through to the
+// other routes at the same position, or to 404.
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[string]func(string) bool{
+		"int":   regexp.MustCompile(`^-?[0-9]+$`).MatchString,
+		"uint":  regexp.MustCompile(`^[0-9]+$`).MatchString,
+		"uuid":  regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+		"alpha": regexp.MustCompile(`^[A-Za-z]+$`).MatchString,
+		"alnum": regexp.MustCompile(`^[A-Za-z0-9]+$`).MatchString,
+		"slug":  regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`).MatchString,
+	}
+)
+
+// RegisterConstraint adds a named param constraint, e.g.
+//
+//	mygin.RegisterConstraint("id", func(v string) bool {
+//		_, err := uuidutil.Strip(v)
+//		return err == nil
+//	})
+//
+// Constraints must be registered before the routes that use them.
+func RegisterConstraint(name string, match func(value string) bool) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+	constraints[name] = match
+}
+
+// compileConstraint returns the matcher of a named constraint or a regular
+// expression. It panics on an invalid expression, like route registration does for
+// other malformed paths.
+func compileConstraint(constraint, fullPath string) func(string) bool {
+	constraintsMu.RLock()
+	match, ok := constraints[constraint]
+	constraintsMu.RUnlock()
+	if ok {
+		return match
+	}
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+	if err != nil {
+		panic("invalid param constraint <" + constraint + "> in path " + fullPath + ": " + err.Error())
+	}
+	return re.MatchString
+}
+
+// paramEnd returns the length of the :param segment at the start of path,
+// including a constraint whose expression may contain '/' inside brackets.
+func paramEnd(path string) int {
+	depth := 0
+	for i := 1; i < len(path); i++ {
+		switch path[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case '/':
+			if depth <= 0 {
+				return i
+			}
+		}
+	}
+	return len(path)
+}
+
+// splitParam splits a param segment without its ':' into name and constraint.
+func splitParam(segment string) (name, constraint string) {
+	i := strings.IndexByte(segment, '<')
+	if i < 0 || segment[len(segment)-1] != '>' {
+		return segment, ""
+	}
+	return segment[:i], segment[i+1 : len(segment)-1]
+}
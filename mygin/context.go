@@ -1,11 +1,23 @@
 package mygin
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
 	"net/http"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/uuidutil"
 )
 
 // Context encapsulates the request and response objects, and holds route parameters.
@@ -15,11 +27,33 @@ type Context struct {
 	// Path-related fields
 	Path   string
 	Method string
-	Params map[string]string // Key: parameter name, Value: value from request URL
+	Params Params // URL parameters of the matched route, in path order; reused after the request, see Copy
 	// Response Status and flow control
 	StatusCode int
 	index      int           // Used for managing middleware chain execution
 	Handlers   HandlersChain // The chain of handlers/middlewares for this request
+
+	// Keys holds per-request values set by middlewares (e.g. the authenticated user).
+	mu   sync.RWMutex
+	Keys map[string]any
+
+	engine   *Engine // nil for contexts not created by Engine.ServeHTTP
+	rawBody  []byte  // request body buffered by GetRawData
+	buffered bool
+	fullPath string         // registered path of the matched route, e.g. "/users/:id"
+	meta     map[string]any // metadata of the matched route (see Route.Meta)
+	finished atomic.Bool    // set when the engine has finished the request
+	copied   bool           // created by Copy
+
+	requestID  string       // see RequestID
+	log        *slog.Logger // see Logger
+	locale     string       // see SetLocale
+	translator Translator
+	onFinish   []func(*Context) // see OnFinish
+	size       int64            // body bytes counted for OnFinish
+
+	// Errors holds the errors recorded with Error and AbortWithError.
+	Errors errorMsgs
 }
 
 // NewContext creates a new Context.
@@ -35,16 +69,184 @@ func NewContext(w http.ResponseWriter, req *http.Request, handlers HandlersChain
 	}
 }
 
+// Copy returns a snapshot of c for use in a goroutine that outlives the request,
+// e.g. to send a notification after answering:
+//
+//	cp := c.Copy()
+//	go func() {
+//		notify(cp.MustGet("user"), cp.Param("id"))
+//	}()
+//
+// The copy has its own Params and Keys, and a clone of the request whose context
+// is not canceled when the request ends; its body is the one buffered by
+// GetRawData, or empty. Writes to the copy's Writer are discarded and it has no
+// handlers to run. In DebugMode using c itself after the request finished panics.
+func (c *Context) Copy() *Context {
+	req := c.Req.Clone(context.WithoutCancel(c.Req.Context()))
+	req.Body = http.NoBody
+	if c.buffered {
+		req.Body = io.NopCloser(bytes.NewReader(c.rawBody))
+	}
+
+	c.mu.RLock()
+	keys := maps.Clone(c.Keys)
+	c.mu.RUnlock()
+
+	return &Context{
+		Writer:     &discardWriter{header: c.Writer.Header().Clone()},
+		Req:        req,
+		Path:       c.Path,
+		Method:     c.Method,
+		Params:     append(Params(nil), c.Params...),
+		StatusCode: c.StatusCode,
+		index:      -1,
+		Keys:       keys,
+		engine:     c.engine,
+		rawBody:    c.rawBody,
+		buffered:   c.buffered,
+		fullPath:   c.fullPath,
+		meta:       c.meta,
+		copied:     true,
+		requestID:  c.RequestID(),
+		log:        c.log,
+		locale:     c.locale,
+		translator: c.translator,
+		Errors:     append(errorMsgs(nil), c.Errors...),
+	}
+}
+
+// serve runs the handler chain and marks the request as finished.
+func (c *Context) serve() {
+	c.Next()
+	c.runFinish()
+	c.finished.Store(true)
+	if IsDebugging() {
+		// نوشتن مستقیم روی c.Writer هم پس از پایان درخواست تشخیص داده می‌شود
+		c.Writer = &finishedWriter{ResponseWriter: c.Writer, c: c}
+	}
+}
+
+// checkFinished panics in DebugMode when c is used after its request finished,
+// which usually means a goroutine kept c instead of a Copy.
+func (c *Context) checkFinished() {
+	if !c.copied && c.finished.Load() && IsDebugging() {
+		panic("mygin: Context of " + c.Method + " " + c.Path + " used after the request finished; use c.Copy() in goroutines")
+	}
+}
+
+// finishedWriter is the Writer of a finished Context in DebugMode; it panics
+// like checkFinished when a goroutine writes through it.
+type finishedWriter struct {
+	http.ResponseWriter
+	c *Context
+}
+
+func (w *finishedWriter) Header() http.Header {
+	w.c.checkFinished()
+	return w.ResponseWriter.Header()
+}
+
+func (w *finishedWriter) Write(b []byte) (int, error) {
+	w.c.checkFinished()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *finishedWriter) WriteHeader(code int) {
+	w.c.checkFinished()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *finishedWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// discardWriter is the Writer of a copied Context.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
 // Param returns the value of the URL parameter with the given key (e.g., "id").
 func (c *Context) Param(key string) string {
-	if c.Params == nil {
+	return c.Params.ByName(key)
+}
+
+// ErrInvalidParam is wrapped by the errors of the typed param accessors, such as
+// ParamUUID, when the param is missing or cannot be parsed.
+var ErrInvalidParam = errors.New("invalid param")
+
+// ParamInt returns the URL parameter key as an int:
+//
+//	page, err := c.ParamInt("page")
+//	if err != nil {
+//		c.AbortWithStatusJSON(http.StatusBadRequest, mygin.H{"error": err.Error()})
+//		return
+//	}
+func (c *Context) ParamInt(key string) (int, error) {
+	n, err := c.ParamInt64(key)
+	if err == nil && int64(int(n)) != n {
+		return 0, fmt.Errorf("%w %s: %d out of range", ErrInvalidParam, key, n)
+	}
+	return int(n), err
+}
+
+// ParamInt64 returns the URL parameter key as an int64.
+func (c *Context) ParamInt64(key string) (int64, error) {
+	value := c.Param(key)
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %s: %q is not an integer", ErrInvalidParam, key, value)
+	}
+	return n, nil
+}
+
+// ParamUUID returns the URL parameter key as a UUID. Like the rest handlers it
+// accepts canonical UUIDs, short IDs and prefixed short IDs (see uuidutil.Strip).
+func (c *Context) ParamUUID(key string) (uuid.UUID, error) {
+	value := c.Param(key)
+	id, err := uuidutil.Strip(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w %s: %q is not a valid id", ErrInvalidParam, key, value)
+	}
+	return id, nil
+}
+
+// FullPath returns the registered path of the matched route, e.g. "/users/:id",
+// or "" when no route matched (NoRoute, NoMethod). Unlike c.Path it has few
+// distinct values, so metrics and logs can use it as a label.
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
+// HandlerName returns the name of the last handler of the chain, the route's own
+// handler, e.g. "github.com/mahdi-cpp/iris-tools/rest.(*Resource[...]).read-fm".
+// It is "" for a context without handlers.
+func (c *Context) HandlerName() string {
+	if len(c.Handlers) == 0 {
 		return ""
 	}
-	return c.Params[key]
+	return nameOfFunction(c.Handlers[len(c.Handlers)-1])
+}
+
+// HandlerNames returns the names of all handlers of the chain, middleware first.
+func (c *Context) HandlerNames() []string {
+	names := make([]string, len(c.Handlers))
+	for i, handler := range c.Handlers {
+		names[i] = nameOfFunction(handler)
+	}
+	return names
+}
+
+// RouteMeta returns the metadata stored under key with Route.Meta for the matched route.
+func (c *Context) RouteMeta(key string) (any, bool) {
+	value, ok := c.meta[key]
+	return value, ok
 }
 
 // Status sets the HTTP Status code for the response.
 func (c *Context) Status(code int) {
+	c.checkFinished()
 	c.StatusCode = code
 	c.Writer.WriteHeader(code)
 }
@@ -54,6 +256,136 @@ func (c *Context) GetHeader(key string) string {
 	return c.Req.Header.Get(key)
 }
 
+// GetRawData reads the whole request body and keeps it, so middleware (signature
+// checks, logging) and the Bind methods can read it again afterwards: c.Req.Body
+// is replaced by a reader over the buffered bytes, and rewound before every Bind.
+// Later calls return the same bytes.
+func (c *Context) GetRawData() ([]byte, error) {
+	if !c.buffered {
+		if c.Req.Body != nil {
+			data, err := io.ReadAll(c.Req.Body)
+			if err != nil {
+				return nil, fmt.Errorf("error reading request body: %w", err)
+			}
+			c.Req.Body.Close()
+			c.rawBody = data
+		}
+		c.buffered = true
+	}
+	c.rewindBody()
+	return c.rawBody, nil
+}
+
+// rewindBody makes c.Req.Body read the buffered body from the start.
+func (c *Context) rewindBody() {
+	if c.buffered && c.Req.Body != nil {
+		c.Req.Body = io.NopCloser(bytes.NewReader(c.rawBody))
+	}
+}
+
+// IsPreflight reports whether the request is a CORS preflight request, an OPTIONS
+// request with Origin and Access-Control-Request-Method headers.
+func (c *Context) IsPreflight() bool {
+	return c.Req.Method == http.MethodOptions &&
+		c.Req.Header.Get("Origin") != "" &&
+		c.Req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// --- مقادیر درخواست (Request Values) ---
+
+// Set stores a value for the rest of the handler chain.
+func (c *Context) Set(key string, value any) {
+	c.checkFinished()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]any)
+	}
+	c.Keys[key] = value
+}
+
+// Get returns the value stored under key by Set.
+func (c *Context) Get(key string) (value any, exists bool) {
+	c.checkFinished()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists = c.Keys[key]
+	return
+}
+
+// MustGet returns the value stored under key and panics if it does not exist.
+func (c *Context) MustGet(key string) any {
+	if value, exists := c.Get(key); exists {
+		return value
+	}
+	panic("key \"" + key + "\" does not exist")
+}
+
+// Typed getters return the value stored under key with Set, or the zero value
+// when the key does not exist or holds another type:
+//
+//	userID := c.GetString("user_id")
+//	if c.GetBool("is_admin") { ... }
+
+// GetString returns the string stored under key.
+func (c *Context) GetString(key string) string {
+	return getAs[string](c, key)
+}
+
+// GetInt returns the int stored under key.
+func (c *Context) GetInt(key string) int {
+	return getAs[int](c, key)
+}
+
+// GetInt64 returns the int64 stored under key.
+func (c *Context) GetInt64(key string) int64 {
+	return getAs[int64](c, key)
+}
+
+// GetFloat64 returns the float64 stored under key.
+func (c *Context) GetFloat64(key string) float64 {
+	return getAs[float64](c, key)
+}
+
+// GetBool returns the bool stored under key.
+func (c *Context) GetBool(key string) bool {
+	return getAs[bool](c, key)
+}
+
+// GetDuration returns the time.Duration stored under key.
+func (c *Context) GetDuration(key string) time.Duration {
+	return getAs[time.Duration](c, key)
+}
+
+// GetTime returns the time.Time stored under key.
+func (c *Context) GetTime(key string) time.Time {
+	return getAs[time.Time](c, key)
+}
+
+// GetStringSlice returns the []string stored under key.
+func (c *Context) GetStringSlice(key string) []string {
+	return getAs[[]string](c, key)
+}
+
+// GetStringMap returns the map[string]any stored under key.
+func (c *Context) GetStringMap(key string) map[string]any {
+	return getAs[map[string]any](c, key)
+}
+
+// Value returns the value stored under key as a T, for types without a getter:
+//
+//	user, ok := mygin.Value[*auth.User](c, "user")
+func Value[T any](c *Context, key string) (T, bool) {
+	value, _ := c.Get(key)
+	typed, ok := value.(T)
+	return typed, ok
+}
+
+func getAs[T any](c *Context, key string) T {
+	typed, _ := Value[T](c, key)
+	return typed
+}
+
 // --- توابع کنترل جریان (Middleware Flow Control) ---
 
 // Next should be called in a middleware to execute the pending handlers.
@@ -66,9 +398,44 @@ func (c *Context) Next() {
 	}
 }
 
-// Abort prevents pending handlers from being called.
+// abortIndex is the index of an aborted chain, beyond any real handler.
+const abortIndex = math.MaxInt32
+
+// Abort prevents pending handlers from being called. It writes nothing; use
+// AbortWithStatus or AbortWithStatusJSON to answer the request as well.
 func (c *Context) Abort() {
-	c.index = len(c.Handlers)
+	c.checkFinished()
+	c.index = abortIndex
+}
+
+// IsAborted reports whether the chain was aborted, e.g. by an auth middleware
+// running before a middleware that wraps c.Next().
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+// AbortWithStatus aborts the chain and writes the status without a body.
+func (c *Context) AbortWithStatus(code int) {
+	c.Abort()
+	c.Status(code)
+}
+
+// AbortWithStatusJSON aborts the chain and answers with obj as JSON:
+//
+//	if token == "" {
+//		c.AbortWithStatusJSON(http.StatusUnauthorized, mygin.H{"error": "missing token"})
+//		return
+//	}
+func (c *Context) AbortWithStatusJSON(code int, obj any) {
+	c.Abort()
+	c.JSON(code, obj)
+}
+
+// AbortWithError aborts the chain, writes the status and records err in
+// c.Errors. The returned Error can carry metadata for logging.
+func (c *Context) AbortWithError(code int, err error) *Error {
+	c.AbortWithStatus(code)
+	return c.Error(err)
 }
 
 // --- توابع خواندن Query (Query Reading Helpers) ---
@@ -109,16 +476,35 @@ func (c *Context) GetQueryBool(key string) bool {
 
 // JSON sends a JSON response.
 func (c *Context) JSON(code int, obj interface{}) {
-	c.Writer.Header().Set("Content-Type", "application/json")
-	c.Status(code)
-
-	if err := json.NewEncoder(c.Writer).Encode(obj); err != nil {
-		http.Error(c.Writer, "JSON encoding error: "+err.Error(), http.StatusInternalServerError)
+	c.checkFinished()
+	// بدنه پیش از نوشتن وضعیت ساخته می‌شود تا خطای encode به 500 تبدیل شود
+	var buf bytes.Buffer
+	if err := c.jsonEncoder().Encode(&buf, obj); err != nil {
+		c.renderError("JSON", err)
+		return
 	}
+	c.Data(code, "application/json", buf.Bytes())
 }
 
-// HTML sends an HTML response by executing a template.
+// HTML sends an HTML response by executing the template name loaded with
+// Engine.LoadHTMLGlob or LoadHTMLFiles. Without loaded templates name is parsed
+// as a template file on every call.
 func (c *Context) HTML(code int, name string, data interface{}) {
+	c.checkFinished()
+	if c.engine != nil {
+		page, loaded, err := c.engine.executeHTML(name, data)
+		if err != nil {
+			c.Logger().ErrorContext(c.Req.Context(), "error rendering html", "template", name, "error", err)
+			c.StatusCode = http.StatusInternalServerError
+			http.Error(c.Writer, "Template execution error: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if loaded {
+			c.Data(code, "text/html; charset=utf-8", page)
+			return
+		}
+	}
+
 	c.Writer.Header().Set("Content-Type", "text/html")
 	c.Status(code)
 
@@ -135,6 +521,7 @@ func (c *Context) HTML(code int, name string, data interface{}) {
 
 // String sends a plain text response.
 func (c *Context) String(code int, format string, values ...interface{}) {
+	c.checkFinished()
 	c.Writer.Header().Set("Content-Type", "text/plain")
 	c.Status(code)
 	c.Writer.Write([]byte(fmt.Sprintf(format, values...)))
@@ -142,6 +529,7 @@ func (c *Context) String(code int, format string, values ...interface{}) {
 
 // Data sends raw byte data response.
 func (c *Context) Data(code int, contentType string, data []byte) {
+	c.checkFinished()
 	c.Writer.Header().Set("Content-Type", contentType)
 	c.Status(code)
 	c.Writer.Write(data)
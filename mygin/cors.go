@@ -0,0 +1,157 @@
+package mygin
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins lists the origins that may call the API: exact origins such as
+	// "https://app.example.com", patterns with one "*" such as
+	// "https://*.example.com", or "*" for every origin.
+	AllowOrigins []string
+	// AllowOriginFunc allows origins not in AllowOrigins, e.g. from a tenant table.
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods are the methods preflight requests may ask for. Defaults to
+	// GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowMethods []string
+	// AllowHeaders are the request headers preflight requests may ask for. When
+	// empty the headers the browser asks for are allowed.
+	AllowHeaders []string
+	// ExposeHeaders are the response headers scripts may read, e.g. X-Request-ID.
+	ExposeHeaders []string
+	// AllowCredentials lets browsers send cookies and Authorization headers. It
+	// cannot be combined with the "*" origin; list the origins or use
+	// AllowOriginFunc instead.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// CORS returns a middleware that adds the Access-Control-Allow-* headers for
+// allowed origins and answers preflight requests with 204. Add it with Use so it
+// also runs for OPTIONS requests without a route (see Engine.HandleOPTIONS):
+//
+//	r.Use(mygin.CORS(mygin.CORSConfig{
+//		AllowOrigins:     []string{"https://app.example.com", "https://*.example.dev"},
+//		AllowCredentials: true,
+//		MaxAge:           12 * time.Hour,
+//	}))
+//
+// Preflight requests from other origins get 403; other requests from them are
+// served without CORS headers, so the browser does not expose the response.
+// It panics when neither AllowOrigins nor AllowOriginFunc is set, and when the
+// "*" origin is combined with AllowCredentials, which would let every site make
+// credentialed requests.
+func CORS(config CORSConfig) HandlerFunc {
+	if len(config.AllowOrigins) == 0 && config.AllowOriginFunc == nil {
+		panic("mygin: CORS requires AllowOrigins or AllowOriginFunc")
+	}
+	if config.AllowCredentials && slices.Contains(config.AllowOrigins, "*") {
+		panic(`mygin: CORS does not allow the "*" origin with AllowCredentials`)
+	}
+	methods := config.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := ""
+	if config.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(config.MaxAge / time.Second))
+	}
+
+	allowAll := false
+	origins := make([]string, 0, len(config.AllowOrigins))
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins = append(origins, strings.ToLower(origin))
+	}
+	allowed := func(origin string) bool {
+		if allowAll {
+			return true
+		}
+		lower := strings.ToLower(origin)
+		for _, pattern := range origins {
+			if matchOrigin(pattern, lower) {
+				return true
+			}
+		}
+		return config.AllowOriginFunc != nil && config.AllowOriginFunc(origin)
+	}
+
+	return func(c *Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		header := c.Writer.Header()
+		preflight := c.IsPreflight()
+		// پاسخ به Origin بستگی دارد و cacheها باید آن را جدا نگه دارند
+		header.Add("Vary", "Origin")
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAll {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		if maxAge != "" {
+			header.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// matchOrigin matches a lower-case origin against an AllowOrigins entry, which
+// may contain one "*" standing for at least one character.
+func matchOrigin(pattern, origin string) bool {
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return pattern == origin
+	}
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
@@ -0,0 +1,62 @@
+package mygin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DebugWriter receives the output printed in DebugMode: registered routes,
+// loaded templates, listening addresses and warnings.
+var DebugWriter io.Writer = os.Stdout
+
+// DebugPrintRouteFunc replaces the line printed for each registered route in
+// DebugMode, e.g. to log routes with slog instead:
+//
+//	mygin.DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+//		slog.Debug("route", "method", method, "path", path, "handler", handler)
+//	}
+var DebugPrintRouteFunc func(method, path, handler string, handlers int)
+
+func debugPrint(format string, args ...any) {
+	if !IsDebugging() {
+		return
+	}
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	fmt.Fprintf(DebugWriter, "[MYGIN-debug] "+format, args...)
+}
+
+func debugPrintWarning(format string, args ...any) {
+	debugPrint("[WARNING] "+format, args...)
+}
+
+// debugPrintRoute prints a registered route as
+// "GET    /albums/:id --> photos.readAlbum (3 handlers)".
+func debugPrintRoute(host, method, path string, handlers HandlersChain) {
+	if !IsDebugging() {
+		return
+	}
+	handler := ""
+	if len(handlers) > 0 {
+		handler = nameOfFunction(handlers[len(handlers)-1])
+	}
+	if DebugPrintRouteFunc != nil {
+		DebugPrintRouteFunc(method, host+path, handler, len(handlers))
+		return
+	}
+	debugPrint("%-6s %-25s --> %s (%d handlers)", method, host+path, handler, len(handlers))
+}
+
+// debugPrintRouteIssues warns about the problems CheckRoutes finds when a server
+// starts in DebugMode.
+func (engine *Engine) debugPrintRouteIssues() {
+	if !IsDebugging() {
+		return
+	}
+	for _, issue := range engine.CheckRoutes().Issues {
+		debugPrintWarning("route %s", issue)
+	}
+}
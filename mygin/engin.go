@@ -1,42 +1,184 @@
 package mygin
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
+	"path"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin/binding"
 )
 
+var logger = logging.For("mygin")
+
+// DefaultMaxMultipartMemory is the default Engine.MaxMultipartMemory (32 MiB).
+const DefaultMaxMultipartMemory = binding.DefaultMaxMultipartMemory
+
 // Engine is the core struct that handles routing and implements http.Handler.
 type Engine struct {
 	*RouterGroup
 	router map[string]*node // The Radix Tree map: Key is HTTP method (e.g., "GET")
+	hosts  []*hostRoutes    // routes registered with Host, static patterns first
+
+	registrations []RouteInfo                // every addRoute call, in order (see Routes and CheckRoutes)
+	maxParams     int                        // most params of any route, the capacity of Context.Params
+	paramsPool    sync.Pool                  // *Params of capacity maxParams reused across requests
+	meta          map[routeID]map[string]any // metadata set with Route.Meta
+
+	// HandleMethodNotAllowed answers a request whose path is registered only for
+	// other methods with 405 and an Allow header instead of 404. Enabled by New.
+	HandleMethodNotAllowed bool
+
+	// RedirectFixedPath redirects a request that matches no route to the registered
+	// route it matches after cleaning the path (duplicate slashes, "." and "..")
+	// and ignoring letter case, e.g. /Users//7 to /users/7. GET requests get 301,
+	// others 308 so the method and body are kept. Disabled by default.
+	RedirectFixedPath bool
+
+	// HandleHEAD answers a HEAD request for a path registered only for GET by
+	// running the GET handlers and discarding the body. Content-Length is set from
+	// the discarded body unless the handlers set it. Disabled by default.
+	HandleHEAD bool
+
+	// HandleOPTIONS answers an OPTIONS request for a path without an OPTIONS route
+	// with 204 and an Allow header listing the methods registered for the path.
+	// Global middleware runs first, so a CORS middleware added with Use can answer
+	// preflight requests (see Context.IsPreflight); GlobalOPTIONS replaces the
+	// default response. Enabled by New.
+	HandleOPTIONS bool
+
+	// MaxMultipartMemory is how much of a multipart/form-data body ShouldBind keeps
+	// in memory; larger file parts are stored in temporary files. New sets
+	// DefaultMaxMultipartMemory.
+	MaxMultipartMemory int64
+
+	// Logger receives the engine's records: route registration, redirects and,
+	// through Context.Logger, the records of Recovery, ErrorHandler and rendering
+	// errors. New sets the "mygin" module logger of the logging package.
+	Logger *slog.Logger
+
+	// JSONEncoder encodes JSON responses and decodes JSON request bodies, e.g.
+	// GoJSON. When nil, responses use encoding/json and bodies binding.JSON.
+	JSONEncoder JSONEncoder
+
+	// ShutdownTimeout bounds the graceful shutdown RunWithContext starts when its
+	// context ends (default DefaultShutdownTimeout).
+	ShutdownTimeout time.Duration
+
+	// AutoTLS configures RunAutoTLS.
+	AutoTLS AutoTLSConfig
+
+	lifecycle lifecycle // servers and hooks, see RunWithContext and Shutdown
+
+	html htmlRender // templates for Context.HTML, see LoadHTMLGlob
+
+	noRoute     HandlersChain // set with NoRoute
+	noMethod    HandlersChain // set with NoMethod
+	options     HandlersChain // set with GlobalOPTIONS
+	allNoRoute  HandlersChain // global middleware + noRoute
+	allNoMethod HandlersChain // global middleware + noMethod
+	allOptions  HandlersChain // global middleware + options
 }
 
 // RouterGroup manages groups of routes and shared handlers (middleware).
 type RouterGroup struct {
 	Handlers HandlersChain
 	basePath string
+	host     string // host pattern set with Host, "" for any host
 	engine   *Engine
 }
 
 // New creates a new Engine instance.
 func New() *Engine {
 	engine := &Engine{
-		router: make(map[string]*node),
+		router:                 make(map[string]*node),
+		HandleMethodNotAllowed: true,
+		HandleOPTIONS:          true,
+		MaxMultipartMemory:     DefaultMaxMultipartMemory,
+		Logger:                 logger,
 	}
 	// Set up the default router group which points to the engine
 	engine.RouterGroup = &RouterGroup{
 		engine:   engine,
 		basePath: "/",
 	}
+	engine.rebuildErrorHandlers()
+	debugPrintWarning(`Running in "debug" mode. Switch to "release" mode in production.
+ - using env:	export %s=%s
+ - using code:	mygin.SetMode(mygin.ReleaseMode)`, EnvMode, ReleaseMode)
 	return engine
 }
 
+// Use adds global middleware. Unlike RouterGroup.Use it also applies to the
+// NoRoute and NoMethod handlers.
+func (engine *Engine) Use(middleware ...HandlerFunc) {
+	engine.RouterGroup.Use(middleware...)
+	engine.rebuildErrorHandlers()
+}
+
+// NoRoute sets the handlers for requests that match no route, e.g. to answer
+// with a JSON body instead of the default "404 page not found" text. Global
+// middleware runs before them.
+func (engine *Engine) NoRoute(handlers ...HandlerFunc) {
+	engine.noRoute = handlers
+	engine.rebuildErrorHandlers()
+}
+
+// NoMethod sets the handlers for requests answered with 405 (see
+// HandleMethodNotAllowed). The Allow header is already set when they run.
+func (engine *Engine) NoMethod(handlers ...HandlerFunc) {
+	engine.noMethod = handlers
+	engine.rebuildErrorHandlers()
+}
+
+// GlobalOPTIONS sets the handlers for automatic OPTIONS responses (see
+// HandleOPTIONS). The Allow header is already set when they run.
+func (engine *Engine) GlobalOPTIONS(handlers ...HandlerFunc) {
+	engine.options = handlers
+	engine.rebuildErrorHandlers()
+}
+
+func (engine *Engine) rebuildErrorHandlers() {
+	noRoute, noMethod, options := engine.noRoute, engine.noMethod, engine.options
+	if len(noRoute) == 0 {
+		noRoute = HandlersChain{defaultNoRoute}
+	}
+	if len(noMethod) == 0 {
+		noMethod = HandlersChain{defaultNoMethod}
+	}
+	if len(options) == 0 {
+		options = HandlersChain{defaultOptions}
+	}
+	engine.allNoRoute = engine.combineHandlers(noRoute)
+	engine.allNoMethod = engine.combineHandlers(noMethod)
+	engine.allOptions = engine.combineHandlers(options)
+}
+
+func defaultNoRoute(c *Context) {
+	c.StatusCode = http.StatusNotFound
+	http.NotFound(c.Writer, c.Req)
+}
+
+func defaultNoMethod(c *Context) {
+	c.StatusCode = http.StatusMethodNotAllowed
+	http.Error(c.Writer, "405 method not allowed", http.StatusMethodNotAllowed)
+}
+
+func defaultOptions(c *Context) {
+	c.Status(http.StatusNoContent)
+}
+
 // Group creates a new RouterGroup with a given relative path.
 func (group *RouterGroup) Group(relativePath string) *RouterGroup {
 	return &RouterGroup{
 		engine:   group.engine,
 		basePath: group.calculateAbsolutePath(relativePath),
+		host:     group.host,
 		Handlers: group.combineHandlers(group.Handlers), // Inherit middleware
 	}
 }
@@ -82,33 +224,64 @@ func (group *RouterGroup) combineHandlers(handlers HandlersChain) HandlersChain
 }
 
 // GET registers a GET request handler
-func (group *RouterGroup) GET(relativePath string, handlers ...HandlerFunc) {
-	group.handle(http.MethodGet, relativePath, handlers)
+func (group *RouterGroup) GET(relativePath string, handlers ...HandlerFunc) *Route {
+	return group.handle(http.MethodGet, relativePath, handlers)
 }
 
 // POST registers a POST request handler
-func (group *RouterGroup) POST(relativePath string, handlers ...HandlerFunc) {
-	group.handle(http.MethodPost, relativePath, handlers)
+func (group *RouterGroup) POST(relativePath string, handlers ...HandlerFunc) *Route {
+	return group.handle(http.MethodPost, relativePath, handlers)
+}
+
+// PUT registers a PUT request handler
+func (group *RouterGroup) PUT(relativePath string, handlers ...HandlerFunc) *Route {
+	return group.handle(http.MethodPut, relativePath, handlers)
 }
 
 // PATCH registers a PATCH request handler
-func (group *RouterGroup) PATCH(relativePath string, handlers ...HandlerFunc) {
-	group.handle(http.MethodPatch, relativePath, handlers)
+func (group *RouterGroup) PATCH(relativePath string, handlers ...HandlerFunc) *Route {
+	return group.handle(http.MethodPatch, relativePath, handlers)
 }
 
 // DELETE registers a DELETE request handler
-func (group *RouterGroup) DELETE(relativePath string, handlers ...HandlerFunc) {
-	group.handle(http.MethodDelete, relativePath, handlers)
+func (group *RouterGroup) DELETE(relativePath string, handlers ...HandlerFunc) *Route {
+	return group.handle(http.MethodDelete, relativePath, handlers)
+}
+
+// HEAD registers a HEAD request handler
+func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) *Route {
+	return group.handle(http.MethodHead, relativePath, handlers)
+}
+
+// OPTIONS registers an OPTIONS request handler
+func (group *RouterGroup) OPTIONS(relativePath string, handlers ...HandlerFunc) *Route {
+	return group.handle(http.MethodOptions, relativePath, handlers)
+}
+
+// Match registers one handler chain for several methods, e.g. for a webhook that
+// accepts GET and POST. The methods share the chain, so middleware runs the same
+// way for each of them.
+func (group *RouterGroup) Match(methods []string, relativePath string, handlers ...HandlerFunc) []*Route {
+	if len(methods) == 0 {
+		panic("Match needs at least one method")
+	}
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	chain := group.combineHandlers(handlers)
+	routes := make([]*Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, group.engine.addRoute(group.host, strings.ToUpper(method), absolutePath, chain))
+	}
+	return routes
 }
 
 // handle registers a new request handle with the given path and method.
-func (group *RouterGroup) handle(httpMethod, relativePath string, handlers HandlersChain) {
+func (group *RouterGroup) handle(httpMethod, relativePath string, handlers HandlersChain) *Route {
 	absolutePath := group.calculateAbsolutePath(relativePath)
 	handlers = group.combineHandlers(handlers)
-	group.engine.addRoute(httpMethod, absolutePath, handlers)
+	return group.engine.addRoute(group.host, httpMethod, absolutePath, handlers)
 }
 
-func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
+func (engine *Engine) addRoute(host, method, path string, handlers HandlersChain) *Route {
 	if method == "" {
 		panic("method must not be empty")
 	}
@@ -121,90 +294,205 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 		path = path[:len(path)-1]
 	}
 
-	if engine.router[method] == nil {
-		engine.router[method] = &node{path: "/"}
+	router := engine.routerFor(host)
+	root := router[method]
+	if root == nil {
+		root = &node{}
+		router[method] = root
 	}
-
-	// اگر مسیر ریشه است
-	if path == "/" {
-		engine.router[method].handlers = handlers
-		engine.router[method].fullPath = "/"
-	} else {
-		engine.router[method].add(path, handlers, path)
+	root.addRoute(path, handlers)
+	n := countParams(path)
+	if host != "" {
+		n += engine.hostRoutes(host).params
+	}
+	if n > engine.maxParams {
+		engine.maxParams = n
 	}
 
-	// Logging
-	handlersCount := len(handlers)
-	logString := formatRoutePrint(method, path, handlersCount)
-	fmt.Println(logString)
+	engine.registrations = append(engine.registrations, newRouteInfo(host, method, path, handlers))
+	engine.log().Debug("route registered", "host", host, "method", method, "path", path, "handlers", len(handlers))
+	debugPrintRoute(host, method, path, handlers)
+	return &Route{engine: engine, Host: host, Method: method, Path: path}
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var params Params
+	if engine.maxParams > 0 {
+		pp := engine.getParams()
+		defer engine.paramsPool.Put(pp)
+		params = *pp
+	}
 
-	root := engine.router[req.Method]
-	if root == nil {
-		http.NotFound(w, req)
+	host, served := engine.dispatch(w, req, req.Method, params)
+	if served {
 		return
 	}
 
-	handlers, params := root.find(req.URL.Path)
+	// HEAD با مسیر GET پاسخ داده می‌شود و بدنه دور ریخته می‌شود
+	if engine.HandleHEAD && req.Method == http.MethodHead {
+		hw := &headWriter{ResponseWriter: w}
+		if _, served := engine.dispatch(hw, req, http.MethodGet, params[:0]); served {
+			hw.finish()
+			return
+		}
+	}
 
-	if handlers != nil {
-		// 1. Context را با زنجیره کامل Handlers ایجاد کنید
-		c := NewContext(w, req, handlers)
-		c.Params = params
+	if engine.HandleOPTIONS && req.Method == http.MethodOptions {
+		if allowed := engine.allowedMethods(host, req.URL.Path, http.MethodOptions); len(allowed) > 0 {
+			allowed = append(allowed, http.MethodOptions)
+			sort.Strings(allowed)
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			engine.newContext(w, req, engine.allOptions).serve()
+			return
+		}
+	}
 
-		// 2. اجرای زنجیره را شروع کنید
-		c.Next()
+	if engine.RedirectFixedPath && req.Method != http.MethodConnect {
+		if fixed, ok := engine.fixPath(host, req.Method, cleanPath(req.URL.Path)); ok && fixed != req.URL.Path {
+			engine.redirect(w, req, fixed)
+			return
+		}
+	}
 
-	} else {
-		// مسیر پیدا نشد
-		http.NotFound(w, req)
-	}
-}
-
-// formatRoutePrint formats the route information for printing in the terminal.
-func formatRoutePrint(method, path string, handlers int) string {
-	// کد رنگ‌های ANSI
-	const (
-		reset   = "\033[0m"
-		yellow  = "\033[33m"
-		green   = "\033[32m"
-		blue    = "\033[34m"
-		magenta = "\033[35m"
-		// استفاده از رنگ‌های روشن برای نمایش متدها
-		lightBlue = "\033[94m"
-	)
-
-	// تعیین رنگ بر اساس متد HTTP
-	var methodColor string
-	switch method {
-	case http.MethodGet:
-		methodColor = blue
-	case http.MethodPost:
-		methodColor = green
-	case http.MethodPut, http.MethodPatch:
-		methodColor = yellow
-	case http.MethodDelete:
-		methodColor = magenta
-	default:
-		methodColor = reset
-	}
-
-	// 💡 رفع مشکل نمایش: استفاده از log.Printf برای زمان‌بندی و استفاده از Sprintf برای بخش متد و مسیر
-	// خروجی را به این صورت تغییر می‌دهیم تا با لاگ پیش‌فرض سیستم تداخل نداشته باشد:
-
-	return fmt.Sprintf(
-		"%s%-6s%s %s%s %s(%d handlers)%s",
-		methodColor,
-		strings.ToUpper(method),
-		reset,
-		lightBlue, // رنگ مسیر
-		path,
-		reset,
-		handlers,
-		reset,
-	)
-	// نکته: برای جلوگیری از تداخل با log.Fatal، دیگر زمان را مستقیماً در اینجا چاپ نمی‌کنیم.
+	if engine.HandleMethodNotAllowed {
+		if allowed := engine.allowedMethods(host, req.URL.Path, req.Method); len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			engine.newContext(w, req, engine.allNoMethod).serve()
+			return
+		}
+	}
+
+	// مسیر پیدا نشد
+	engine.newContext(w, req, engine.allNoRoute).serve()
+}
+
+// getParams returns an empty Params from the pool with room for the params of
+// every route.
+func (engine *Engine) getParams() *Params {
+	if pp, ok := engine.paramsPool.Get().(*Params); ok && cap(*pp) >= engine.maxParams {
+		*pp = (*pp)[:0]
+		return pp
+	}
+	params := make(Params, 0, engine.maxParams)
+	return &params
+}
+
+// newContext creates the Context of a request served by engine, so its handlers
+// use the engine's settings such as JSONEncoder, Logger and loaded templates.
+func (engine *Engine) newContext(w http.ResponseWriter, req *http.Request, handlers HandlersChain) *Context {
+	c := NewContext(w, req, handlers)
+	c.engine = engine
+	return c
+}
+
+// dispatch runs the route of method matching the request, trying the routes of
+// matching hosts first and then the routes without a host. It returns the first
+// host pattern matching the request, or nil.
+func (engine *Engine) dispatch(w http.ResponseWriter, req *http.Request, method string, params Params) (*hostRoutes, bool) {
+	var host *hostRoutes
+	for _, h := range engine.hosts {
+		if !h.match(req.Host, &params) {
+			continue
+		}
+		if engine.serveRoute(w, req, h.pattern, h.router, method, params) {
+			return h, true
+		}
+		if host == nil {
+			host = h
+		}
+		params = params[:0]
+	}
+	return host, engine.serveRoute(w, req, "", engine.router, method, params)
+}
+
+// serveRoute runs the route of router matching the request, if any. params holds
+// the host params.
+func (engine *Engine) serveRoute(w http.ResponseWriter, req *http.Request, host string, router map[string]*node, method string, params Params) bool {
+	root := router[method]
+	if root == nil {
+		return false
+	}
+	n := root.getValue(req.URL.Path, &params)
+	if n == nil {
+		return false
+	}
+
+	// 1. Context را با زنجیره کامل Handlers ایجاد کنید
+	c := engine.newContext(w, req, n.handlers)
+	c.Params = params
+	c.fullPath = n.fullPath
+	c.meta = engine.meta[routeKey(host, method, n.fullPath)]
+
+	// 2. اجرای زنجیره را شروع کنید
+	c.serve()
+	return true
+}
+
+// routers returns the routing tables a request for host is dispatched to.
+func (engine *Engine) routers(host *hostRoutes) []map[string]*node {
+	if host == nil {
+		return []map[string]*node{engine.router}
+	}
+	return []map[string]*node{host.router, engine.router}
+}
+
+// fixPath returns the registered spelling of path among the routes of method.
+func (engine *Engine) fixPath(host *hostRoutes, method, path string) (string, bool) {
+	for _, router := range engine.routers(host) {
+		if root := router[method]; root != nil {
+			if fixed, ok := root.fixPath(path); ok {
+				return fixed, true
+			}
+		}
+	}
+	return "", false
+}
+
+// redirect sends the client to path, keeping the query string.
+func (engine *Engine) redirect(w http.ResponseWriter, req *http.Request, path string) {
+	code := http.StatusMovedPermanently
+	if req.Method != http.MethodGet {
+		code = http.StatusPermanentRedirect
+	}
+	target := path
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	engine.log().Debug("redirecting to fixed path", "from", req.URL.Path, "to", path)
+	http.Redirect(w, req, target, code)
+}
+
+// cleanPath resolves "." and ".." elements and duplicate slashes and removes the
+// trailing slash, the form routes are registered in.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	return path.Clean(p)
+}
+
+// allowedMethods returns the sorted methods other than except that have a route
+// matching path.
+func (engine *Engine) allowedMethods(host *hostRoutes, path, except string) []string {
+	var allowed []string
+	for _, router := range engine.routers(host) {
+		for method, root := range router {
+			if method == except || slices.Contains(allowed, method) {
+				continue
+			}
+			var params Params
+			if root.getValue(path, &params) != nil {
+				allowed = append(allowed, method)
+			}
+		}
+	}
+	if engine.HandleHEAD && except != http.MethodHead && slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	sort.Strings(allowed)
+	return allowed
 }
@@ -0,0 +1,215 @@
+package mygin
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// ErrorType decides whether ErrorHandler shows an error to the client.
+type ErrorType int
+
+const (
+	// ErrorTypePrivate errors are logged but answered with the status text only.
+	// It is the type of errors recorded with Context.Error.
+	ErrorTypePrivate ErrorType = iota
+	// ErrorTypePublic errors are answered with their message.
+	ErrorTypePublic
+)
+
+// Error is an error recorded on a Context with Context.Error.
+type Error struct {
+	Err  error
+	Type ErrorType
+	Meta any // logged with the error; sent to the client for public errors
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the recorded error for errors.Is and errors.As.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// SetType sets the type of the error:
+//
+//	c.AbortWithError(http.StatusNotFound, err).SetType(mygin.ErrorTypePublic)
+func (e *Error) SetType(t ErrorType) *Error {
+	e.Type = t
+	return e
+}
+
+// SetMeta attaches data to the error, e.g. the id of the album that failed.
+func (e *Error) SetMeta(meta any) *Error {
+	e.Meta = meta
+	return e
+}
+
+// IsPublic reports whether the error is shown to the client.
+func (e *Error) IsPublic() bool {
+	return e.Type == ErrorTypePublic
+}
+
+// errorMsgs is the list of errors recorded on a Context, oldest first.
+type errorMsgs []*Error
+
+// Last returns the most recently recorded error, or nil.
+func (a errorMsgs) Last() *Error {
+	if len(a) == 0 {
+		return nil
+	}
+	return a[len(a)-1]
+}
+
+// ByType returns the errors of type t.
+func (a errorMsgs) ByType(t ErrorType) errorMsgs {
+	var matched errorMsgs
+	for _, e := range a {
+		if e.Type == t {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// String joins the messages of the errors, one per line.
+func (a errorMsgs) String() string {
+	messages := make([]string, len(a))
+	for i, e := range a {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Error records err for middleware that runs after the handlers, such as
+// ErrorHandler, and returns it as an *Error. It panics if err is nil.
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("mygin: Context.Error called with a nil error")
+	}
+	e, ok := err.(*Error)
+	if !ok {
+		e = &Error{Err: err}
+	}
+	c.Errors = append(c.Errors, e)
+	return e
+}
+
+// ErrorHandler logs the errors recorded with Context.Error and answers requests
+// whose handlers recorded errors without writing a body with a JSON envelope:
+//
+//	{"error": "album not found"}                                  public error
+//	{"error": "Internal Server Error"}                            private error
+//	{"error": "validation failed", "fields": {"title": "..."}}    validation.Fields
+//
+// The status is the one the handlers set, e.g. with AbortWithError, or 500 when
+// they set none below 400. The last public error provides the message; its Meta,
+// if any, is sent as "meta". Add ErrorHandler with Use before other middleware:
+//
+//	r.Use(mygin.ErrorHandler(), tracing.Middleware(nil))
+//	r.GET("/albums/:id", func(c *mygin.Context) {
+//		album, err := store.Get(c.Param("id"))
+//		if err != nil {
+//			c.AbortWithError(http.StatusNotFound, err).SetType(mygin.ErrorTypePublic)
+//			return
+//		}
+//		c.JSON(http.StatusOK, album)
+//	})
+func ErrorHandler() HandlerFunc {
+	return func(c *Context) {
+		w := &errorWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() { c.Writer = w.ResponseWriter }()
+
+		c.Next()
+
+		c.Writer = w.ResponseWriter
+		if w.sent || len(c.Errors) == 0 {
+			w.send()
+			logErrors(c)
+			return
+		}
+
+		status := w.status
+		if status < http.StatusBadRequest {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, errorEnvelope(c.Errors, status))
+		logErrors(c)
+	}
+}
+
+// errorEnvelope builds the body ErrorHandler sends for errs.
+func errorEnvelope(errs errorMsgs, status int) H {
+	if fields, ok := validation.Fields(errs.Last()); ok {
+		return H{"error": "validation failed", "fields": fields}
+	}
+	public := errs.ByType(ErrorTypePublic).Last()
+	if public == nil {
+		return H{"error": http.StatusText(status)}
+	}
+	body := H{"error": public.Error()}
+	if public.Meta != nil {
+		body["meta"] = public.Meta
+	}
+	return body
+}
+
+// logErrors logs the recorded errors with the request context, so the trace of
+// the request is attached; client errors are logged as warnings.
+func logErrors(c *Context) {
+	level := slog.LevelError
+	if c.StatusCode >= http.StatusBadRequest && c.StatusCode < http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+	for _, e := range c.Errors {
+		c.Logger().Log(c.Req.Context(), level, "request error",
+			"path", c.Path, "handler", c.HandlerName(),
+			"status", c.StatusCode, "error", e.Err, "meta", e.Meta)
+	}
+}
+
+// errorWriter holds back the status until the body is written, so ErrorHandler
+// can still answer with the error envelope when the handlers only set a status.
+type errorWriter struct {
+	http.ResponseWriter
+	status int
+	sent   bool
+}
+
+func (w *errorWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *errorWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.send()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	w.send()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *errorWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// send writes the held-back status, if any.
+func (w *errorWriter) send() {
+	if w.sent || w.status == 0 {
+		return
+	}
+	w.sent = true
+	w.ResponseWriter.WriteHeader(w.status)
+}
@@ -0,0 +1,85 @@
+package mygin
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// OnFinish registers fn to run after the handler chain returned, when the
+// response status and size are known, e.g. for audit logs or metrics:
+//
+//	func Audit(log *audit.Log) mygin.HandlerFunc {
+//		return func(c *mygin.Context) {
+//			c.OnFinish(func(c *mygin.Context) {
+//				log.Record(c.RequestID(), c.FullPath(), c.StatusCode, c.Size())
+//			})
+//			c.Next()
+//		}
+//	}
+//
+// Callbacks run in reverse order of registration, like deferred calls, and also
+// when a handler aborted the chain. They do not run for a panicking chain without
+// Recovery, nor for contexts created with Copy. The first call starts counting the
+// body bytes for Size, so register callbacks before the response is written.
+func (c *Context) OnFinish(fn func(c *Context)) {
+	c.checkFinished()
+	if c.onFinish == nil && !c.copied {
+		c.Writer = &finishWriter{ResponseWriter: c.Writer, c: c}
+	}
+	c.onFinish = append(c.onFinish, fn)
+}
+
+// Size returns the number of body bytes written since the first OnFinish call,
+// or -1 without one.
+func (c *Context) Size() int64 {
+	if c.onFinish == nil {
+		return -1
+	}
+	return c.size
+}
+
+// runFinish runs the OnFinish callbacks.
+func (c *Context) runFinish() {
+	for i := len(c.onFinish) - 1; i >= 0; i-- {
+		c.onFinish[i](c)
+	}
+}
+
+// finishWriter records the status and counts the body for OnFinish callbacks.
+type finishWriter struct {
+	http.ResponseWriter
+	c *Context
+}
+
+func (w *finishWriter) WriteHeader(code int) {
+	if w.c.StatusCode == 0 && code >= http.StatusOK {
+		w.c.StatusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *finishWriter) Write(b []byte) (int, error) {
+	if w.c.StatusCode == 0 {
+		w.c.StatusCode = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.c.size += int64(n)
+	return n, err
+}
+
+func (w *finishWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades, which type-assert http.Hijacker, work after
+// OnFinish was called.
+func (w *finishWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *finishWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,108 @@
+package mygin
+
+import (
+	"sort"
+	"strings"
+)
+
+// hostRoutes is the routing table of the routes registered for one host pattern.
+type hostRoutes struct {
+	pattern string
+	labels  []string // pattern split at '.', ":name" labels are params
+	params  int      // number of param labels
+	router  map[string]*node
+}
+
+// Host returns a group whose routes only match requests for the host pattern.
+// A label starting with ':' matches any single label and is available as a param:
+//
+//	api := r.Host("api.example.com")
+//	api.GET("/status", status)
+//	tenants := r.Host(":tenant.example.com")
+//	tenants.GET("/", func(c *mygin.Context) { c.String(200, c.Param("tenant")) })
+//
+// Patterns are matched case-insensitively against the request host without its
+// port. Patterns without params are tried before wildcard ones, and a request that
+// no route of a matching host handles falls through to the next matching pattern
+// and finally to the routes registered without a host.
+func (group *RouterGroup) Host(pattern string) *RouterGroup {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	if pattern == "" {
+		panic("host pattern must not be empty")
+	}
+	group.engine.hostRoutes(pattern)
+	return &RouterGroup{
+		engine:   group.engine,
+		basePath: group.basePath,
+		host:     pattern,
+		Handlers: group.combineHandlers(nil),
+	}
+}
+
+// hostRoutes returns the routing table of pattern, creating it if needed.
+func (engine *Engine) hostRoutes(pattern string) *hostRoutes {
+	for _, h := range engine.hosts {
+		if h.pattern == pattern {
+			return h
+		}
+	}
+	h := &hostRoutes{pattern: pattern, labels: strings.Split(pattern, "."), router: make(map[string]*node)}
+	for _, label := range h.labels {
+		if label == "" || label == ":" {
+			panic("invalid host pattern: " + pattern)
+		}
+		if label[0] == ':' {
+			h.params++
+		}
+	}
+	engine.hosts = append(engine.hosts, h)
+	// میزبان‌های ثابت پیش از میزبان‌های دارای پارامتر بررسی می‌شوند
+	sort.SliceStable(engine.hosts, func(i, j int) bool {
+		return engine.hosts[i].params == 0 && engine.hosts[j].params > 0
+	})
+	return h
+}
+
+// routerFor returns the routing table of a host pattern, "" being the default one.
+func (engine *Engine) routerFor(host string) map[string]*node {
+	if host == "" {
+		return engine.router
+	}
+	return engine.hostRoutes(host).router
+}
+
+// match reports whether host, without its port, matches the pattern and appends
+// the param labels to params.
+func (h *hostRoutes) match(host string, params *Params) bool {
+	host = strings.TrimSuffix(stripPort(host), ".")
+	start := len(*params)
+	for i, label := range h.labels {
+		end := strings.IndexByte(host, '.')
+		if end < 0 {
+			end = len(host)
+		}
+		// تعداد برچسب‌ها باید دقیقا برابر باشد
+		last := i == len(h.labels)-1
+		if end == 0 || last != (end == len(host)) ||
+			(label[0] != ':' && !strings.EqualFold(label, host[:end])) {
+			*params = (*params)[:start]
+			return false
+		}
+		if label[0] == ':' {
+			*params = append(*params, Param{Key: label[1:], Value: host[:end]})
+		}
+		if !last {
+			host = host[end+1:]
+		}
+	}
+	return true
+}
+
+// stripPort removes the port from a request host, keeping IPv6 brackets intact.
+func stripPort(host string) string {
+	i := strings.LastIndexByte(host, ':')
+	if i < 0 || strings.IndexByte(host[i:], ']') >= 0 {
+		return host
+	}
+	return host[:i]
+}
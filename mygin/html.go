@@ -0,0 +1,91 @@
+package mygin
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// htmlRender holds the templates loaded with LoadHTMLGlob or LoadHTMLFiles.
+type htmlRender struct {
+	templates *template.Template
+	load      func() (*template.Template, error) // re-parses the templates in DebugMode
+	funcMap   template.FuncMap
+	left      string
+	right     string
+}
+
+// Delims sets the action delimiters of templates loaded afterwards, e.g. "[[" and
+// "]]" when the pages also contain a client-side template syntax.
+func (engine *Engine) Delims(left, right string) {
+	engine.html.left, engine.html.right = left, right
+}
+
+// SetFuncMap sets the functions available to templates loaded afterwards.
+func (engine *Engine) SetFuncMap(funcMap template.FuncMap) {
+	engine.html.funcMap = funcMap
+}
+
+// LoadHTMLGlob parses the templates matching pattern once; Context.HTML renders
+// them by file name (or by the names of the templates they define):
+//
+//	r.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+//	r.LoadHTMLGlob("templates/*.html")
+//	r.GET("/", func(c *mygin.Context) { c.HTML(200, "index.html", data) })
+//
+// It panics when the templates do not parse, like route registration does. In
+// DebugMode the files are parsed again on every render, so template changes show
+// up without a restart.
+func (engine *Engine) LoadHTMLGlob(pattern string) {
+	engine.loadHTML(func() (*template.Template, error) {
+		return engine.newTemplate().ParseGlob(pattern)
+	})
+}
+
+// LoadHTMLFiles parses the given template files once (see LoadHTMLGlob).
+func (engine *Engine) LoadHTMLFiles(files ...string) {
+	engine.loadHTML(func() (*template.Template, error) {
+		return engine.newTemplate().ParseFiles(files...)
+	})
+}
+
+func (engine *Engine) loadHTML(load func() (*template.Template, error)) {
+	engine.SetHTMLTemplate(template.Must(load()))
+	engine.html.load = load
+}
+
+// SetHTMLTemplate makes Context.HTML render from an already parsed template set.
+// It is not reloaded in DebugMode.
+func (engine *Engine) SetHTMLTemplate(templates *template.Template) {
+	engine.html.templates = templates
+	engine.html.load = nil
+	engine.log().Debug("html templates loaded", "templates", templates.DefinedTemplates())
+	debugPrint("Loaded HTML templates%s", templates.DefinedTemplates())
+}
+
+func (engine *Engine) newTemplate() *template.Template {
+	return template.New("").Delims(engine.html.left, engine.html.right).Funcs(engine.html.funcMap)
+}
+
+// executeHTML renders the loaded template name into a buffer, so a failing
+// template results in a clean 500 instead of a half-written page. It reports
+// false when no templates were loaded.
+func (engine *Engine) executeHTML(name string, data any) ([]byte, bool, error) {
+	templates := engine.html.templates
+	if templates == nil {
+		return nil, false, nil
+	}
+	// در حالت debug قالب‌ها در هر رندر دوباره خوانده می‌شوند
+	if engine.html.load != nil && IsDebugging() {
+		reloaded, err := engine.html.load()
+		if err != nil {
+			return nil, true, fmt.Errorf("error reloading templates: %w", err)
+		}
+		templates = reloaded
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, true, fmt.Errorf("error executing template %s: %w", name, err)
+	}
+	return buf.Bytes(), true, nil
+}
@@ -0,0 +1,56 @@
+package mygin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	gojson "github.com/goccy/go-json"
+)
+
+// JSONEncoder is the JSON implementation an Engine uses for Context.JSON, AsciiJSON,
+// JSONP, SSEvent and the JSON binding, so endpoints with large payloads can use a
+// faster library than encoding/json:
+//
+//	r := mygin.New()
+//	r.JSONEncoder = mygin.GoJSON
+//
+// Encode must escape <, > and & like encoding/json; IndentedJSON and PureJSON
+// always use encoding/json.
+type JSONEncoder interface {
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// Built-in JSON encoders.
+var (
+	StdJSON JSONEncoder = stdJSON{} // encoding/json
+	GoJSON  JSONEncoder = goJSON{}  // github.com/goccy/go-json
+)
+
+type stdJSON struct{}
+
+func (stdJSON) Encode(w io.Writer, v any) error { return json.NewEncoder(w).Encode(v) }
+func (stdJSON) Decode(r io.Reader, v any) error { return json.NewDecoder(r).Decode(v) }
+
+type goJSON struct{}
+
+func (goJSON) Encode(w io.Writer, v any) error { return gojson.NewEncoder(w).Encode(v) }
+func (goJSON) Decode(r io.Reader, v any) error { return gojson.NewDecoder(r).Decode(v) }
+
+// jsonEncoder returns Engine.JSONEncoder, or StdJSON when it is not set.
+func (c *Context) jsonEncoder() JSONEncoder {
+	if c.engine != nil && c.engine.JSONEncoder != nil {
+		return c.engine.JSONEncoder
+	}
+	return StdJSON
+}
+
+// marshalJSON encodes obj with the engine's encoder, without the trailing newline.
+func (c *Context) marshalJSON(obj any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.jsonEncoder().Encode(&buf, obj); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
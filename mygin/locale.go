@@ -0,0 +1,38 @@
+package mygin
+
+import "fmt"
+
+// Translator translates message keys. *i18n.Catalog implements it.
+type Translator interface {
+	T(locale, key string, args ...any) string
+}
+
+// SetLocale sets the locale of the request and the Translator used by T. The i18n
+// middleware calls it after detecting the locale.
+func (c *Context) SetLocale(locale string, translator Translator) {
+	c.checkFinished()
+	c.locale, c.translator = locale, translator
+}
+
+// Locale returns the locale set with SetLocale, or "".
+func (c *Context) Locale() string {
+	return c.locale
+}
+
+// T returns the message key translated to the request's locale, formatted with
+// args. Without a Translator it formats key itself. Handlers use it for error
+// messages, templates through the data passed to HTML:
+//
+//	c.AbortWithStatusJSON(http.StatusNotFound, mygin.H{"error": c.T("album.not_found", id)})
+//	c.HTML(http.StatusOK, "album.html", mygin.H{"Album": album, "T": c.T}) // {{call .T "album.title"}}
+func (c *Context) T(key string, args ...any) string {
+	if c.translator == nil {
+		// key متن پیام است نه format، تا vet فراخوانی‌های T را مثل Printf بررسی نکند
+		text := key
+		if len(args) > 0 {
+			return fmt.Sprintf(text, args...)
+		}
+		return text
+	}
+	return c.translator.T(c.locale, key, args...)
+}
@@ -0,0 +1,213 @@
+package mygin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogFormat is the line format of the access log.
+type LogFormat string
+
+const (
+	// LogCommon is the Common Log Format:
+	//	127.0.0.1 - - [10/Oct/2026:13:55:36 +0330] "GET /albums HTTP/1.1" 200 2326
+	LogCommon LogFormat = "common"
+	// LogCombined is LogCommon followed by the quoted Referer and User-Agent.
+	LogCombined LogFormat = "combined"
+	// LogJSON writes one JSON object per request, including the latency and route.
+	LogJSON LogFormat = "json"
+	// LogSlog logs one record per request with Context.Logger instead of writing
+	// to Output; server errors are logged at level Error.
+	LogSlog LogFormat = "slog"
+)
+
+// AccessLogEntry describes one served request.
+type AccessLogEntry struct {
+	Time      time.Time     `json:"time"`
+	RequestID string        `json:"request_id"`
+	Latency   time.Duration `json:"-"`
+	ClientIP  string        `json:"client_ip"`
+	Method    string        `json:"method"`
+	Path      string        `json:"path"` // with the query string
+	Route     string        `json:"route,omitempty"`
+	Proto     string        `json:"proto"`
+	Status    int           `json:"status"`
+	Bytes     int64         `json:"bytes"`
+	Referer   string        `json:"referer,omitempty"`
+	UserAgent string        `json:"user_agent,omitempty"`
+	Errors    string        `json:"errors,omitempty"` // messages recorded with Context.Error
+}
+
+// LoggerConfig configures LoggerWithConfig.
+type LoggerConfig struct {
+	// Format is the line format (default LogCombined), or LogSlog.
+	Format LogFormat
+	// Formatter replaces Format; it returns one line without the newline.
+	Formatter func(entry AccessLogEntry) string
+	// Output receives the lines (default os.Stdout). A logging.RotatingFile
+	// rotates the access log by size and, with RotateDaily, by date.
+	Output io.Writer
+	// SkipPaths are paths that are not logged, e.g. "/healthz".
+	SkipPaths []string
+}
+
+// Logger returns an access log middleware writing the combined format to stdout.
+func Logger() HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig returns an access log middleware. Add it with Use before
+// other middleware so the latency covers them:
+//
+//	accessLog, _ := logging.OpenRotatingFile("/var/log/photos/access.log", 100<<20, 7)
+//	accessLog.RotateDaily()
+//	r.Use(mygin.LoggerWithConfig(mygin.LoggerConfig{Format: mygin.LogJSON, Output: accessLog}))
+func LoggerWithConfig(config LoggerConfig) HandlerFunc {
+	out := config.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	format := config.Formatter
+	if format == nil {
+		switch config.Format {
+		case LogCommon:
+			format = formatCommon
+		case LogJSON:
+			format = formatJSON
+		case LogCombined, "":
+			format = formatCombined
+		case LogSlog:
+		default:
+			panic("mygin: unknown log format " + string(config.Format))
+		}
+	}
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = true
+	}
+	var mu sync.Mutex // خطوط درخواست‌های هم‌زمان در هم نروند
+
+	return func(c *Context) {
+		if skip[c.Path] {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		w := &countingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() { c.Writer = w.ResponseWriter }()
+
+		c.Next()
+
+		status := c.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		entry := AccessLogEntry{
+			Time:      start,
+			RequestID: c.RequestID(),
+			Latency:   time.Since(start),
+			ClientIP:  remoteIP(c.Req),
+			Method:    c.Method,
+			Path:      c.Req.URL.RequestURI(),
+			Route:     c.FullPath(),
+			Proto:     c.Req.Proto,
+			Status:    status,
+			Bytes:     w.size,
+			Referer:   c.Req.Referer(),
+			UserAgent: c.Req.UserAgent(),
+			Errors:    c.Errors.String(),
+		}
+		if format == nil {
+			logRequest(c, entry)
+			return
+		}
+		line := format(entry) + "\n"
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := io.WriteString(out, line); err != nil {
+			c.engine.log().Error("error writing access log", "error", err)
+		}
+	}
+}
+
+// logRequest logs entry with the request's logger for LogSlog.
+func logRequest(c *Context, e AccessLogEntry) {
+	level := slog.LevelInfo
+	if e.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	c.Logger().Log(c.Req.Context(), level, "request",
+		"path", e.Path, "status", e.Status, "bytes", e.Bytes, "latency", e.Latency,
+		"client_ip", e.ClientIP, "user_agent", e.UserAgent)
+}
+
+func formatCommon(e AccessLogEntry) string {
+	size := "-"
+	if e.Bytes > 0 {
+		size = fmt.Sprint(e.Bytes)
+	}
+	return fmt.Sprintf(`%s - - [%s] "%s %s %s" %d %s`,
+		e.ClientIP, e.Time.Format("02/Jan/2006:15:04:05 -0700"), e.Method, e.Path, e.Proto, e.Status, size)
+}
+
+func formatCombined(e AccessLogEntry) string {
+	return fmt.Sprintf(`%s %q %q`, formatCommon(e), dashIfEmpty(e.Referer), dashIfEmpty(e.UserAgent))
+}
+
+func formatJSON(e AccessLogEntry) string {
+	data, err := json.Marshal(struct {
+		AccessLogEntry
+		LatencyMS float64 `json:"latency_ms"`
+	}{e, float64(e.Latency.Microseconds()) / 1000})
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(data)
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// remoteIP returns the address of the connection without the port.
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(req.RemoteAddr))
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// countingWriter counts the body bytes written.
+type countingWriter struct {
+	http.ResponseWriter
+	size int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
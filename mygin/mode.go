@@ -0,0 +1,51 @@
+package mygin
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+// Modes for SetMode.
+const (
+	// DebugMode favours development: registered routes, loaded templates and
+	// warnings such as the issues of CheckRoutes are printed to DebugWriter,
+	// templates are re-parsed on every render and a Context used after its
+	// request finished panics (see Context.Copy).
+	DebugMode = "debug"
+	// ReleaseMode caches everything that can be cached. It is the default.
+	ReleaseMode = "release"
+	// TestMode behaves like ReleaseMode and prints nothing; tests can check for it.
+	TestMode = "test"
+)
+
+// EnvMode is the environment variable that sets the initial mode.
+const EnvMode = "MYGIN_MODE"
+
+var mode atomic.Value
+
+func init() {
+	SetMode(os.Getenv(EnvMode))
+}
+
+// SetMode sets the mode of all engines; "" means ReleaseMode. It panics on an
+// unknown mode.
+func SetMode(value string) {
+	switch value {
+	case "":
+		value = ReleaseMode
+	case DebugMode, ReleaseMode, TestMode:
+	default:
+		panic("mygin: unknown mode " + value + ", expected debug, release or test")
+	}
+	mode.Store(value)
+}
+
+// Mode returns the current mode.
+func Mode() string {
+	return mode.Load().(string)
+}
+
+// IsDebugging reports whether the mode is DebugMode.
+func IsDebugging() bool {
+	return Mode() == DebugMode
+}
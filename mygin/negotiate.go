@@ -0,0 +1,118 @@
+package mygin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/mygin/binding"
+)
+
+// mediaTypes are the short names Accepts understands.
+var mediaTypes = map[string]string{
+	"json":      "application/json",
+	"xml":       "application/xml",
+	"yaml":      "application/yaml",
+	"html":      "text/html",
+	"text":      "text/plain",
+	"protobuf":  "application/x-protobuf",
+	"form":      "application/x-www-form-urlencoded",
+	"multipart": "multipart/form-data",
+	"sse":       "text/event-stream",
+}
+
+// ContentType returns the lower-case media type of the request body without its
+// parameters, e.g. "application/json" for "application/JSON; charset=utf-8", or ""
+// when the header is missing or malformed.
+func (c *Context) ContentType() string {
+	return binding.MediaType(c.Req.Header.Get("Content-Type"))
+}
+
+// Accepts returns the offer the client prefers according to its Accept header,
+// or "" when it accepts none of them. Offers are media types or the short names
+// json, xml, yaml, html, text, protobuf, form, multipart and sse:
+//
+//	switch c.Accepts("json", "html") {
+//	case "html":
+//		c.HTML(http.StatusOK, "album.html", album)
+//	default:
+//		c.JSON(http.StatusOK, album)
+//	}
+//
+// Quality values and wildcards such as text/* are honoured; among offers with the
+// same quality the earlier one wins. Without an Accept header the first offer is
+// returned.
+func (c *Context) Accepts(offers ...string) string {
+	header := c.Req.Header.Get("Accept")
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		mediaType := offer
+		if full, ok := mediaTypes[offer]; ok {
+			mediaType = full
+		}
+		if q := acceptQuality(ranges, strings.ToLower(mediaType)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// acceptRange is one media range of an Accept header.
+type acceptRange struct {
+	mediaType string // e.g. "text/*"
+	q         float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		if mediaType == "*" {
+			mediaType = "*/*"
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific range matching
+// mediaType: type/subtype before type/* before */*.
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, 0
+	for _, r := range ranges {
+		s := 0
+		switch r.mediaType {
+		case mediaType:
+			s = 3
+		case typ + "/*":
+			s = 2
+		case "*/*":
+			s = 1
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -1,195 +1,337 @@
 package mygin
 
-// node represents a node in the Radix Tree (Trie).
-type node struct {
-	path      string
-	children  []*node
-	handlers  HandlersChain
-	fullPath  string // Full path of the route (e.g., "/users/:id")
-	isParam   bool   // True if the node is a parameter node (starts with ':')
-	paramName string // Name of the parameter (e.g., "id")
-}
+import "strings"
 
-// addRoute is a wrapper for the core add function.
-func (n *node) addRoute(path string, handlers HandlersChain) {
-	n.add(path, handlers, path)
+// Param is a single URL parameter: the key of the route segment and its value.
+type Param struct {
+	Key   string
+	Value string
 }
 
-func (n *node) add(path string, handlers HandlersChain, fullPath string) {
-	// نرمال‌سازی مسیر: حذف اسلش انتهایی به جز برای ریشه
-	if len(path) > 1 && path[len(path)-1] == '/' {
-		path = path[:len(path)-1]
+// Params are the URL parameters of a request, in path order.
+type Params []Param
+
+// Get returns the value of the first param named name.
+func (ps Params) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
 	}
+	return "", false
+}
 
-	n.addRecursive(path, handlers, fullPath)
+// ByName returns the value of the first param named name, or "".
+func (ps Params) ByName(name string) string {
+	value, _ := ps.Get(name)
+	return value
 }
 
-func (n *node) addRecursive(path string, handlers HandlersChain, fullPath string) {
-	// اگر مسیر خالی باشد، هندلرها را تنظیم کن
-	if path == "" {
-		n.handlers = handlers
-		n.fullPath = fullPath
-		return
-	}
+type nodeKind uint8
 
-	// پیدا کردن طول پیشوند مشترک
-	i := 0
-	maxLen := min(len(n.path), len(path))
-	for i < maxLen && n.path[i] == path[i] {
-		i++
-	}
+const (
+	staticNode   nodeKind = iota // matches path literally
+	paramNode                    // :name, matches one segment
+	catchAllNode                 // *name, matches the rest of the path
+)
 
-	// اگر پیشوند مشترک کمتر از طول مسیر گره فعلی باشد، گره را تقسیم کن
-	if i < len(n.path) {
-		// تقسیم گره
-		child := &node{
-			path:      n.path[i:],
-			children:  n.children,
-			handlers:  n.handlers,
-			fullPath:  n.fullPath,
-			isParam:   n.isParam,
-			paramName: n.paramName,
-		}
+// node is a node of the radix tree of one HTTP method.
+//
+// Static children are compressed by common prefix, so at most one of them can
+// match a path; they are found by their first byte in indices and kept ordered
+// by priority (the number of routes below them) so busy branches are found
+// first. Param children are tried after the static child, constrained ones
+// first, and the catch-all child last. Lookup backtracks when a branch fails,
+// so static segments take precedence over params at every level.
+type node struct {
+	kind     nodeKind
+	path     string // static prefix; the segment source (":id<uuid>") for params
+	priority uint32
 
-		n.path = n.path[:i]
-		n.children = []*node{child}
-		n.handlers = nil
-		n.fullPath = ""
-		n.isParam = false
-		n.paramName = ""
-	}
+	indices       string // first byte of every static child, in children order
+	children      []*node
+	paramChildren []*node
+	catchAll      *node
+
+	paramName  string            // name of a param or catch-all node, e.g. "id"
+	constraint string            // param constraint, e.g. "uuid" (see constraint.go)
+	match      func(string) bool // compiled constraint
+
+	handlers HandlersChain
+	fullPath string // registered path of the route ending here, e.g. "/users/:id"
+}
 
-	// مسیر باقی‌مانده
-	remainingPath := path[i:]
+// countParams returns the number of params and catch-alls in path.
+func countParams(path string) int {
+	return strings.Count(path, ":") + strings.Count(path, "*")
+}
 
-	// اگر مسیر باقی‌مانده خالی باشد، هندلرها را تنظیم کن
-	if remainingPath == "" {
+// addRoute registers handlers for the absolute path below the root n.
+func (n *node) addRoute(path string, handlers HandlersChain) {
+	n.insert(path, path, handlers)
+}
+
+// insert adds the rest of a route below n, whose own path has been consumed.
+func (n *node) insert(path, fullPath string, handlers HandlersChain) {
+	n.priority++
+	if path == "" {
 		n.handlers = handlers
 		n.fullPath = fullPath
 		return
 	}
 
-	// بررسی برای پارامتر
-	if remainingPath[0] == ':' {
-		// پیدا کردن نام پارامتر
-		end := 1
-		for end < len(remainingPath) && remainingPath[end] != '/' {
-			end++
+	switch path[0] {
+	case '*':
+		// wildcard باید آخرین بخش مسیر باشد و بقیه مسیر را در یک پارامتر می‌گیرد
+		name := path[1:]
+		if name == "" || strings.Contains(name, "/") {
+			panic("catch-all routes are only allowed at the end of the path: " + fullPath)
 		}
+		if fullPath[len(fullPath)-len(path)-1] != '/' {
+			panic("catch-all must follow a '/' in path: " + fullPath)
+		}
+		if n.catchAll != nil && n.catchAll.paramName != name {
+			panic("catch-all " + path + " conflicts with *" + n.catchAll.paramName + " in path: " + fullPath)
+		}
+		if n.catchAll == nil {
+			n.catchAll = &node{kind: catchAllNode, path: path, paramName: name}
+		}
+		n.catchAll.insert("", fullPath, handlers)
 
-		paramName := remainingPath[1:end]
-		remainingAfterParam := remainingPath[end:]
+	case ':':
+		// پیدا کردن نام پارامتر و محدودیت آن
+		end := paramEnd(path)
+		name, constraint := splitParam(path[1:end])
+		if name == "" {
+			panic("param must have a name in path: " + fullPath)
+		}
+		n.paramChild(name, constraint, path[:end], fullPath).insert(path[end:], fullPath, handlers)
 
-		// بررسی آیا گره پارامتری با همین نام وجود دارد
-		for _, child := range n.children {
-			if child.isParam && child.paramName == paramName {
-				child.addRecursive(remainingAfterParam, handlers, fullPath)
-				return
+	default:
+		// فرزند ثابتی با پیشوند مشترک، یا فرزند جدید
+		for i := 0; i < len(n.indices); i++ {
+			if n.indices[i] != path[0] {
+				continue
+			}
+			child := n.children[i]
+			common := commonPrefix(child.path, path)
+			if common < len(child.path) {
+				child.split(common)
 			}
+			child.insert(path[common:], fullPath, handlers)
+			n.reorder(i)
+			return
 		}
 
-		// ایجاد گره پارامتری جدید
-		paramNode := &node{
-			path:      remainingPath[:end],
-			isParam:   true,
-			paramName: paramName,
+		end := strings.IndexAny(path, ":*")
+		if end < 0 {
+			end = len(path)
 		}
+		child := &node{kind: staticNode, path: path[:end]}
+		n.indices += path[:1]
+		n.children = append(n.children, child)
+		child.insert(path[end:], fullPath, handlers)
+		n.reorder(len(n.children) - 1)
+	}
+}
 
-		n.children = append(n.children, paramNode)
-		paramNode.addRecursive(remainingAfterParam, handlers, fullPath)
-		return
+// paramChild returns the child for :name<constraint>, creating it if needed.
+// Constrained params are kept before unconstrained ones so they are tried first.
+func (n *node) paramChild(name, constraint, source, fullPath string) *node {
+	for _, child := range n.paramChildren {
+		if child.paramName == name && child.constraint == constraint {
+			return child
+		}
 	}
 
-	// برای مسیرهای ثابت، فرزند موجود را پیدا کن یا ایجاد کن
-	for _, child := range n.children {
-		if !child.isParam && child.path != "" && child.path[0] == remainingPath[0] {
-			child.addRecursive(remainingPath, handlers, fullPath)
-			return
+	child := &node{kind: paramNode, path: source, paramName: name, constraint: constraint}
+	at := len(n.paramChildren)
+	if constraint != "" {
+		child.match = compileConstraint(constraint, fullPath)
+		for i, other := range n.paramChildren {
+			if other.constraint == "" {
+				at = i
+				break
+			}
 		}
 	}
+	n.paramChildren = append(n.paramChildren, nil)
+	copy(n.paramChildren[at+1:], n.paramChildren[at:])
+	n.paramChildren[at] = child
+	return child
+}
+
+// split moves everything after the first i bytes of n's path into a new child.
+func (n *node) split(i int) {
+	child := &node{
+		kind:          staticNode,
+		path:          n.path[i:],
+		priority:      n.priority,
+		indices:       n.indices,
+		children:      n.children,
+		paramChildren: n.paramChildren,
+		catchAll:      n.catchAll,
+		handlers:      n.handlers,
+		fullPath:      n.fullPath,
+	}
+	n.path = n.path[:i]
+	n.indices = child.path[:1]
+	n.children = []*node{child}
+	n.paramChildren = nil
+	n.catchAll = nil
+	n.handlers = nil
+	n.fullPath = ""
+}
 
-	// ایجاد گره جدید
-	newNode := &node{
-		path: remainingPath,
+// reorder moves the static child at i forward while it has a higher priority
+// than its predecessor.
+func (n *node) reorder(i int) {
+	for ; i > 0 && n.children[i-1].priority < n.children[i].priority; i-- {
+		n.children[i-1], n.children[i] = n.children[i], n.children[i-1]
 	}
-	n.children = append(n.children, newNode)
-	newNode.addRecursive("", handlers, fullPath)
+	indices := []byte(n.indices[:0])
+	for _, child := range n.children {
+		indices = append(indices, child.path[0])
+	}
+	n.indices = string(indices)
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
 	}
-	return b
+	return i
 }
 
-// find attempts to find a matching route in the tree.
-func (n *node) find(path string) (HandlersChain, map[string]string) {
-	return n.findRecursive(path, make(map[string]string))
+// getValue returns the node of the route matching path, or nil, and appends the
+// param values to params. It does not allocate when params has room for every
+// param of the route.
+func (n *node) getValue(path string, params *Params) *node {
+	return n.matchPath(path, path, params)
 }
 
-func (n *node) findRecursive(path string, params map[string]string) (HandlersChain, map[string]string) {
-	// اگر مسیر جاری با پیشوند مسیر هدف منطبق باشد
-	if len(path) >= len(n.path) && path[:len(n.path)] == n.path {
-		remainingPath := path[len(n.path):]
+// matchPath matches n against the start of path. full is the whole request path,
+// used to take catch-all values (which include the preceding '/') without
+// allocating.
+func (n *node) matchPath(full, path string, params *Params) *node {
+	switch n.kind {
+	case staticNode:
+		if !strings.HasPrefix(path, n.path) {
+			return nil
+		}
+		return n.matchChildren(full, path[len(n.path):], params)
 
-		// اگر مسیر دقیقاً تمام شده باشد
-		if remainingPath == "" {
-			if n.handlers != nil {
-				return n.handlers, params
-			}
-			return nil, nil
+	case paramNode:
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end == 0 || (n.match != nil && !n.match(path[:end])) {
+			return nil
 		}
+		*params = append(*params, Param{Key: n.paramName, Value: path[:end]})
+		if found := n.matchChildren(full, path[end:], params); found != nil {
+			return found
+		}
+		*params = (*params)[:len(*params)-1]
+	}
+	return nil
+}
 
-		// ابتدا فرزندان ثابت را بررسی کن
-		for _, child := range n.children {
-			if !child.isParam {
-				if handlers, foundParams := child.findRecursive(remainingPath, cloneParams(params)); handlers != nil {
-					return handlers, foundParams
-				}
-			}
+// matchChildren matches the rest of the path below n.
+func (n *node) matchChildren(full, rest string, params *Params) *node {
+	if rest == "" {
+		if n.handlers != nil {
+			return n
+		}
+		// "/static/" با "/static/*filepath" و مقدار "/" منطبق است
+		if n.catchAll != nil {
+			*params = append(*params, Param{Key: n.catchAll.paramName, Value: full[len(full)-1:]})
+			return n.catchAll
 		}
+		return nil
+	}
 
-		// سپس فرزندان پارامتری را بررسی کن
-		for _, child := range n.children {
-			if child.isParam {
-				// پیدا کردن انتهای بخش پارامتر
-				end := 0
-				for end < len(remainingPath) && remainingPath[end] != '/' {
-					end++
-				}
-
-				if end > 0 {
-					paramValue := remainingPath[:end]
-					newParams := cloneParams(params)
-					newParams[child.paramName] = paramValue
-
-					// اگر پارامتر تمام مسیر باقی‌مانده را پوشش دهد
-					if end == len(remainingPath) {
-						if child.handlers != nil {
-							return child.handlers, newParams
-						}
-					} else {
-						// اگر مسیر بیشتری باقی مانده، در فرزندان جستجو کن
-						nextPath := remainingPath[end:]
-						for _, grandChild := range child.children {
-							if handlers, foundParams := grandChild.findRecursive(nextPath, newParams); handlers != nil {
-								return handlers, foundParams
-							}
-						}
-					}
-				}
-			}
+	// ابتدا فرزند ثابت، سپس پارامترها و در آخر wildcard
+	if i := strings.IndexByte(n.indices, rest[0]); i >= 0 {
+		if found := n.children[i].matchPath(full, rest, params); found != nil {
+			return found
 		}
 	}
+	for _, child := range n.paramChildren {
+		if found := child.matchPath(full, rest, params); found != nil {
+			return found
+		}
+	}
+	if n.catchAll != nil {
+		*params = append(*params, Param{Key: n.catchAll.paramName, Value: full[len(full)-len(rest)-1:]})
+		return n.catchAll
+	}
+	return nil
+}
+
+// lookup returns the node of the route matching path and its params.
+func (n *node) lookup(path string) (*node, Params) {
+	var params Params
+	found := n.getValue(path, &params)
+	if found == nil {
+		return nil, nil
+	}
+	return found, params
+}
+
+// find attempts to find a matching route in the tree.
+func (n *node) find(path string) (HandlersChain, Params) {
+	found, params := n.lookup(path)
+	if found == nil {
+		return nil, nil
+	}
+	return found.handlers, params
+}
 
-	return nil, nil
+// fixPath matches path ignoring the case of static segments and returns it with
+// the registered spelling. Param and catch-all values are kept as they are.
+func (n *node) fixPath(path string) (string, bool) {
+	fixed, ok := n.fixPathRecursive(path, make([]byte, 0, len(path)))
+	return string(fixed), ok
 }
 
-func cloneParams(params map[string]string) map[string]string {
-	newParams := make(map[string]string)
-	for k, v := range params {
-		newParams[k] = v
+func (n *node) fixPathRecursive(path string, buf []byte) ([]byte, bool) {
+	switch n.kind {
+	case staticNode:
+		if len(path) < len(n.path) || !strings.EqualFold(path[:len(n.path)], n.path) {
+			return nil, false
+		}
+		buf = append(buf, n.path...)
+		path = path[len(n.path):]
+	case paramNode:
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end == 0 || (n.match != nil && !n.match(path[:end])) {
+			return nil, false
+		}
+		buf = append(buf, path[:end]...)
+		path = path[end:]
+	}
+
+	if path == "" {
+		return buf, n.handlers != nil || n.catchAll != nil
+	}
+	for _, child := range n.children {
+		if fixed, ok := child.fixPathRecursive(path, buf); ok {
+			return fixed, true
+		}
+	}
+	for _, child := range n.paramChildren {
+		if fixed, ok := child.fixPathRecursive(path, buf); ok {
+			return fixed, true
+		}
+	}
+	if n.catchAll != nil {
+		return append(buf, path...), true
 	}
-	return newParams
+	return nil, false
 }
@@ -0,0 +1,48 @@
+package mygin
+
+import (
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"strings"
+)
+
+// PprofPrefix is the path RegisterPprof registers the profiling handlers under.
+const PprofPrefix = "/debug/pprof"
+
+// RegisterPprof registers the net/http/pprof handlers under PprofPrefix of group,
+// so a running service can be profiled without a separate debug listener:
+//
+//	admin := r.Group("/admin")
+//	mygin.RegisterPprof(admin, h.Require(), az.RequirePermission("debug:pprof"))
+//	// go tool pprof https://photos.example.com/admin/debug/pprof/heap
+//
+// The handlers expose process internals and /profile and /trace run for the
+// requested seconds, so group or middleware must restrict access. Like any
+// import of net/http/pprof, it also registers the handlers on
+// http.DefaultServeMux, which mygin servers do not use.
+func RegisterPprof(group *RouterGroup, middleware ...HandlerFunc) {
+	pprofGroup := group.Group(PprofPrefix)
+	pprofGroup.Use(middleware...)
+	pprofGroup.GET("/cmdline", WrapF(pprof.Cmdline))
+	pprofGroup.GET("/profile", WrapF(pprof.Profile))
+	pprofGroup.Match([]string{http.MethodGet, http.MethodPost}, "/symbol", WrapF(pprof.Symbol))
+	pprofGroup.GET("/trace", WrapF(pprof.Trace))
+	pprofGroup.GET("/*profile", pprofProfile)
+}
+
+// pprofProfile serves the index at PprofPrefix/ and the named runtime profiles
+// such as heap and goroutine. pprof.Index only finds the names under the literal
+// /debug/pprof/ path, so it is not used for them when mounted elsewhere.
+func pprofProfile(c *Context) {
+	name := strings.TrimPrefix(c.Param("profile"), "/")
+	if name == "" {
+		pprof.Index(c.Writer, c.Req)
+		return
+	}
+	if runtimepprof.Lookup(name) == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	pprof.Handler(name).ServeHTTP(c.Writer, c.Req)
+}
@@ -0,0 +1,36 @@
+package mygin
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recovery returns a middleware that recovers from panics in the handlers after
+// it, logs them with their stack through Context.Logger and answers 500 when
+// nothing was written yet. Add it with Use, after Logger:
+//
+//	r.Use(mygin.Logger(), mygin.Recovery())
+//
+// http.ErrAbortHandler is re-panicked so net/http aborts the response.
+func Recovery() HandlerFunc {
+	return func(c *Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+			c.Logger().ErrorContext(c.Req.Context(), "panic recovered",
+				"panic", recovered, "stack", string(debug.Stack()))
+			if c.StatusCode == 0 {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, H{"error": http.StatusText(http.StatusInternalServerError)})
+				return
+			}
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
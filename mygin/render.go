@@ -0,0 +1,113 @@
+package mygin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// renderError answers 500 when a response body cannot be encoded.
+func (c *Context) renderError(format string, err error) {
+	c.checkFinished()
+	c.Logger().ErrorContext(c.Req.Context(), "error encoding response", "format", format, "path", c.Path, "error", err)
+	c.StatusCode = http.StatusInternalServerError
+	http.Error(c.Writer, format+" encoding error: "+err.Error(), http.StatusInternalServerError)
+}
+
+// YAML sends obj encoded as YAML with Content-Type application/yaml.
+func (c *Context) YAML(code int, obj any) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		c.renderError("YAML", err)
+		return
+	}
+	c.Data(code, "application/yaml; charset=utf-8", data)
+}
+
+// ProtoBuf sends msg encoded as protocol buffers with Content-Type
+// application/x-protobuf.
+func (c *Context) ProtoBuf(code int, msg proto.Message) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		c.renderError("ProtoBuf", err)
+		return
+	}
+	c.Data(code, "application/x-protobuf", data)
+}
+
+// IndentedJSON sends obj as pretty-printed JSON, e.g. for debugging endpoints.
+func (c *Context) IndentedJSON(code int, obj any) {
+	data, err := json.MarshalIndent(obj, "", "    ")
+	if err != nil {
+		c.renderError("JSON", err)
+		return
+	}
+	c.Data(code, "application/json", data)
+}
+
+// PureJSON sends obj as JSON without escaping <, > and & (c.JSON escapes them
+// so the output is safe to embed in HTML).
+func (c *Context) PureJSON(code int, obj any) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil {
+		c.renderError("JSON", err)
+		return
+	}
+	c.Data(code, "application/json", buf.Bytes())
+}
+
+// AsciiJSON sends obj as JSON with every non-ASCII character escaped as \uXXXX,
+// for clients that cannot handle UTF-8.
+func (c *Context) AsciiJSON(code int, obj any) {
+	data, err := c.marshalJSON(obj)
+	if err != nil {
+		c.renderError("JSON", err)
+		return
+	}
+	var buf bytes.Buffer
+	for _, r := range string(data) {
+		switch {
+		case r < 0x80:
+			buf.WriteRune(r)
+		case r > 0xFFFF:
+			// کاراکترهای خارج از BMP به صورت جفت جانشین نوشته می‌شوند
+			r -= 0x10000
+			fmt.Fprintf(&buf, "\\u%04x\\u%04x", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+		default:
+			fmt.Fprintf(&buf, "\\u%04x", r)
+		}
+	}
+	c.Data(code, "application/json", buf.Bytes())
+}
+
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// JSONP sends obj wrapped in the function named by the callback query parameter,
+// e.g. ?callback=handle answers handle({...});. Without callback it behaves like
+// JSON. Callbacks that are not plain (dotted) identifiers are rejected with 400.
+func (c *Context) JSONP(code int, obj any) {
+	callback := c.GetQuery("callback")
+	if callback == "" {
+		c.JSON(code, obj)
+		return
+	}
+	if !jsonpCallback.MatchString(callback) {
+		c.JSON(http.StatusBadRequest, H{"error": "invalid callback"})
+		return
+	}
+	data, err := c.marshalJSON(obj)
+	if err != nil {
+		c.renderError("JSON", err)
+		return
+	}
+	body := make([]byte, 0, len(callback)+len(data)+3)
+	body = append(append(append(append(body, callback...), '('), data...), ");"...)
+	c.Data(code, "application/javascript; charset=utf-8", body)
+}
@@ -0,0 +1,305 @@
+package mygin
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Host        string // host pattern set with RouterGroup.Host, "" for any host
+	Method      string
+	Path        string
+	Handler     string // name of the last handler in the chain
+	HandlerFunc HandlerFunc
+	Meta        map[string]any // set with Route.Meta, e.g. request schemas for validation and docs
+
+	handlers HandlersChain
+}
+
+// Route is returned by the registration methods to attach metadata to the route:
+//
+//	api.POST("/albums", create).Meta("summary", "Create an album")
+type Route struct {
+	Host   string
+	Method string
+	Path   string
+
+	engine *Engine
+}
+
+// Meta stores value under key for the route. Handlers read it with Context.RouteMeta,
+// tools such as documentation generators with Engine.Routes.
+func (r *Route) Meta(key string, value any) *Route {
+	k := routeKey(r.Host, r.Method, r.Path)
+	if r.engine.meta == nil {
+		r.engine.meta = make(map[routeID]map[string]any)
+	}
+	if r.engine.meta[k] == nil {
+		r.engine.meta[k] = make(map[string]any)
+	}
+	r.engine.meta[k][key] = value
+	return r
+}
+
+// Get returns the metadata stored under key.
+func (r *Route) Get(key string) (any, bool) {
+	value, ok := r.engine.meta[routeKey(r.Host, r.Method, r.Path)][key]
+	return value, ok
+}
+
+// routeID identifies a route registered for a method, host and path. A struct key
+// is looked up on every request without building a string.
+type routeID struct{ method, host, path string }
+
+func routeKey(host, method, path string) routeID {
+	return routeID{method: method, host: host, path: path}
+}
+
+// RoutesInfo is a list of routes.
+type RoutesInfo []RouteInfo
+
+// Routes returns the served routes in registration order. When a method and path
+// were registered more than once only the last registration is listed.
+func (engine *Engine) Routes() RoutesInfo {
+	index := make(map[routeID]int)
+	var routes RoutesInfo
+	for _, route := range engine.registrations {
+		key := routeKey(route.Host, route.Method, route.Path)
+		route.Meta = engine.meta[key]
+		if i, ok := index[key]; ok {
+			routes[i] = route
+			continue
+		}
+		index[key] = len(routes)
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+func newRouteInfo(host, method, path string, handlers HandlersChain) RouteInfo {
+	info := RouteInfo{Host: host, Method: method, Path: path, handlers: handlers}
+	if len(handlers) > 0 {
+		info.HandlerFunc = handlers[len(handlers)-1]
+		info.Handler = nameOfFunction(info.HandlerFunc)
+	}
+	return info
+}
+
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// RouteIssueKind classifies a problem found by CheckRoutes.
+type RouteIssueKind string
+
+const (
+	// IssueDuplicate: the same method and path were registered more than once; only
+	// the last registration is served.
+	IssueDuplicate RouteIssueKind = "duplicate"
+	// IssueShadowed: another route with the same shape (e.g. /users/:id and
+	// /users/:name) always matches first.
+	IssueShadowed RouteIssueKind = "shadowed"
+	// IssueUnreachable: a request for the route is dispatched to another route or to
+	// no route at all.
+	IssueUnreachable RouteIssueKind = "unreachable"
+	// IssueParamCollision: routes use different names for the same param position
+	// (e.g. /users/:id and /users/:userId/posts), or a route repeats a param name.
+	IssueParamCollision RouteIssueKind = "param_collision"
+)
+
+// RouteIssue is one problem in the route table.
+type RouteIssue struct {
+	Kind    RouteIssueKind
+	Method  string
+	Path    string
+	Other   string // the conflicting route, if any
+	Message string
+}
+
+func (i RouteIssue) String() string {
+	return fmt.Sprintf("%s %s %s: %s", i.Kind, i.Method, i.Path, i.Message)
+}
+
+// RouteReport is the result of CheckRoutes.
+type RouteReport struct {
+	Routes int
+	Issues []RouteIssue
+}
+
+// OK reports whether no issues were found.
+func (r RouteReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Err returns the issues as one error, or nil.
+func (r RouteReport) Err() error {
+	errs := make([]error, len(r.Issues))
+	for i, issue := range r.Issues {
+		errs[i] = errors.New(issue.String())
+	}
+	return errors.Join(errs...)
+}
+
+// CheckRoutes analyzes the route table for duplicate registrations, shadowed and
+// unreachable routes and param-name collisions. It is meant for tests:
+//
+//	if err := engine.CheckRoutes().Err(); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// Reachability is checked by dispatching a sample request for every route through
+// the router, with a placeholder value for each param.
+func (engine *Engine) CheckRoutes() RouteReport {
+	routes := engine.Routes()
+	report := RouteReport{Routes: len(routes)}
+
+	counts := make(map[routeID]int)
+	for _, route := range engine.registrations {
+		counts[routeKey(route.Host, route.Method, route.Path)]++
+	}
+	reported := make(map[routeID]bool)
+	for _, route := range engine.registrations {
+		key := routeKey(route.Host, route.Method, route.Path)
+		if n := counts[key]; n > 1 && !reported[key] {
+			reported[key] = true
+			report.Issues = append(report.Issues, RouteIssue{
+				Kind: IssueDuplicate, Method: route.Method, Path: route.Path, Other: route.Path,
+				Message: fmt.Sprintf("registered %d times, only the last registration is served", n),
+			})
+		}
+	}
+
+	for i, route := range routes {
+		report.Issues = append(report.Issues, engine.checkReachable(route, routes)...)
+		report.Issues = append(report.Issues, repeatedParams(route)...)
+		for _, other := range routes[:i] {
+			if other.Method == route.Method && other.Host == route.Host {
+				if issue, ok := paramCollision(other, route); ok {
+					report.Issues = append(report.Issues, issue)
+				}
+			}
+		}
+	}
+	return report
+}
+
+// checkReachable dispatches a sample path of route and reports when another route
+// (or none) handles it.
+func (engine *Engine) checkReachable(route RouteInfo, routes RoutesInfo) []RouteIssue {
+	root := engine.routerFor(route.Host)[route.Method]
+	if root == nil {
+		return nil
+	}
+	// برای پارامترهای محدود نمی‌توان مقدار نمونه ساخت
+	if strings.Contains(route.Path, "<") {
+		return nil
+	}
+	handlers, _ := root.find(samplePath(route.Path))
+	if sameChain(handlers, route.handlers) {
+		return nil
+	}
+
+	issue := RouteIssue{Kind: IssueUnreachable, Method: route.Method, Path: route.Path, Message: "no route matches its requests"}
+	for _, winner := range routes {
+		if winner.Method == route.Method && winner.Host == route.Host && sameChain(handlers, winner.handlers) {
+			issue.Other = winner.Path
+			issue.Message = "its requests are dispatched to " + winner.Path
+			if shape(winner.Path) == shape(route.Path) {
+				issue.Kind = IssueShadowed
+			}
+			break
+		}
+	}
+	return []RouteIssue{issue}
+}
+
+// sameChain compares chains by identity; every registration gets its own chain.
+func sameChain(a, b HandlersChain) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == 0 && len(b) == 0 && (a == nil) == (b == nil)
+	}
+	return len(a) == len(b) && &a[0] == &b[0]
+}
+
+// isParamSegment reports whether a path segment is a :param or *catch-all.
+func isParamSegment(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
+
+// segmentParamName returns the name of a param segment without ':' or '*' and
+// without its constraint.
+func segmentParamName(segment string) string {
+	name, _ := splitParam(segment[1:])
+	return name
+}
+
+// samplePath replaces every param segment with a placeholder no static segment uses.
+func samplePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isParamSegment(segment) {
+			segments[i] = "~" + segmentParamName(segment) + "~"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// shape is the path with param names removed; constraints are kept.
+func shape(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isParamSegment(segment) {
+			_, constraint := splitParam(segment[1:])
+			segments[i] = segment[:1] + constraint
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func repeatedParams(route RouteInfo) []RouteIssue {
+	var issues []RouteIssue
+	seen := make(map[string]bool)
+	for _, segment := range strings.Split(route.Path, "/") {
+		if !isParamSegment(segment) {
+			continue
+		}
+		if seen[segmentParamName(segment)] {
+			issues = append(issues, RouteIssue{
+				Kind: IssueParamCollision, Method: route.Method, Path: route.Path,
+				Message: fmt.Sprintf("param %s appears more than once, only the last value is kept", segment),
+			})
+		}
+		seen[segmentParamName(segment)] = true
+	}
+	return issues
+}
+
+// paramCollision reports routes that share a prefix up to a param position but name
+// the param differently. Routes with the same shape are left to checkReachable.
+func paramCollision(a, b RouteInfo) (RouteIssue, bool) {
+	if shape(a.Path) == shape(b.Path) {
+		return RouteIssue{}, false
+	}
+	as, bs := strings.Split(a.Path, "/"), strings.Split(b.Path, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		aParam, bParam := isParamSegment(as[i]), isParamSegment(bs[i])
+		switch {
+		case aParam && bParam && shape(as[i]) != shape(bs[i]):
+			// پارامترهایی با محدودیت متفاوت شاخه‌های جدا در درخت دارند
+			return RouteIssue{}, false
+		case aParam && bParam && segmentParamName(as[i]) != segmentParamName(bs[i]):
+			return RouteIssue{
+				Kind: IssueParamCollision, Method: b.Method, Path: b.Path, Other: a.Path,
+				Message: fmt.Sprintf("param %s conflicts with %s of %s", bs[i], as[i], a.Path),
+			}, true
+		case aParam && bParam:
+		case aParam != bParam || as[i] != bs[i]:
+			return RouteIssue{}, false
+		}
+	}
+	return RouteIssue{}, false
+}
@@ -0,0 +1,245 @@
+package mygin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout is the default Engine.ShutdownTimeout.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// lifecycle tracks the servers started by the Run methods and the start and
+// shutdown hooks.
+type lifecycle struct {
+	mu         sync.Mutex
+	servers    []*http.Server
+	onStart    []func(ctx context.Context) error
+	onShutdown []func(ctx context.Context) error
+	startOnce  sync.Once
+	startErr   error
+	stopping   bool
+	stopped    chan struct{} // closed when Shutdown finished
+	err        error         // result of Shutdown, set before stopped is closed
+}
+
+func (lc *lifecycle) done() chan struct{} {
+	if lc.stopped == nil {
+		lc.stopped = make(chan struct{})
+	}
+	return lc.stopped
+}
+
+// Run serves HTTP on addr until Shutdown is called. See RunWithContext.
+func (engine *Engine) Run(addr string) error {
+	return engine.RunWithContext(context.Background(), addr)
+}
+
+// RunWithContext serves HTTP on addr until ctx ends or Shutdown is called, then
+// shuts down gracefully within ShutdownTimeout:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+//	defer stop()
+//	if err := r.RunWithContext(ctx, ":8080"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// It returns after the shutdown finished, with nil or the error of Shutdown.
+func (engine *Engine) RunWithContext(ctx context.Context, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", addr, err)
+	}
+	return engine.serve(ctx, engine.newServer(engine), l, (*http.Server).Serve)
+}
+
+// RunUnix serves HTTP on the unix socket at path until Shutdown is called, e.g.
+// behind a reverse proxy on the same host. A socket left at path by a previous
+// run is replaced; the socket is removed when the server stops. Access is
+// governed by the socket's permissions, so path should be in a directory only
+// the proxy can reach.
+func (engine *Engine) RunUnix(path string) error {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return fmt.Errorf("error listening on %s: file exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("error removing stale socket %s: %w", path, err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", path, err)
+	}
+	return engine.RunListener(l)
+}
+
+// RunListener serves HTTP on l until Shutdown is called, e.g. with a socket
+// passed by systemd socket activation (the first one is file descriptor 3):
+//
+//	l, err := net.FileListener(os.NewFile(3, "photos.socket"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(r.RunListener(l))
+//
+// l is closed when the server stops.
+func (engine *Engine) RunListener(l net.Listener) error {
+	return engine.serve(context.Background(), engine.newServer(engine), l, (*http.Server).Serve)
+}
+
+func (engine *Engine) newServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:  handler,
+		ErrorLog: slog.NewLogLogger(engine.log().Handler(), slog.LevelWarn),
+	}
+}
+
+// serve serves l on srv with serveFunc until ctx ends or Shutdown is called.
+func (engine *Engine) serve(ctx context.Context, srv *http.Server, l net.Listener, serveFunc func(*http.Server, net.Listener) error) error {
+	lc := &engine.lifecycle
+	lc.mu.Lock()
+	if lc.stopping {
+		lc.mu.Unlock()
+		l.Close()
+		return http.ErrServerClosed
+	}
+	lc.servers = append(lc.servers, srv)
+	done := lc.done()
+	lc.mu.Unlock()
+
+	if err := engine.start(ctx); err != nil {
+		l.Close()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), engine.shutdownTimeout())
+		defer cancel()
+		return errors.Join(err, engine.Shutdown(shutdownCtx))
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), engine.shutdownTimeout())
+		defer cancel()
+		if err := engine.Shutdown(shutdownCtx); err != nil {
+			engine.log().Error("error shutting down", "error", err)
+		}
+	})
+	defer stop()
+
+	engine.log().Info("server listening", "addr", l.Addr().String())
+	if srv.Handler == engine {
+		debugPrint("Listening and serving on %s", l.Addr())
+		engine.debugPrintRouteIssues()
+	}
+	if err := serveFunc(srv, l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	// Serve بلافاصله برمی‌گردد؛ تا پایان درخواست‌های در جریان و hookها صبر می‌کنیم
+	<-done
+	return lc.err
+}
+
+func (engine *Engine) shutdownTimeout() time.Duration {
+	if engine.ShutdownTimeout > 0 {
+		return engine.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// OnStart registers fn to run before the first server started by a Run method
+// accepts connections, e.g. to open collection managers:
+//
+//	var albums *collection_manager_memory.Manager[*Album]
+//	r.OnStart(func(ctx context.Context) (err error) {
+//		albums, err = collection_manager_memory.New[*Album](dataDir, "albums")
+//		return err
+//	})
+//	r.OnShutdown(func(ctx context.Context) error {
+//		if albums == nil {
+//			return nil
+//		}
+//		return albums.Close()
+//	})
+//
+// Hooks run once, in order of registration, with the context of RunWithContext.
+// When one fails the rest are skipped, the engine shuts down, running the
+// OnShutdown hooks, and the Run method returns the error. Hooks registered
+// after the start do not run.
+func (engine *Engine) OnStart(fn func(ctx context.Context) error) {
+	engine.lifecycle.mu.Lock()
+	defer engine.lifecycle.mu.Unlock()
+	engine.lifecycle.onStart = append(engine.lifecycle.onStart, fn)
+}
+
+// start runs the OnStart hooks on the first call; later calls wait for it and
+// return its result.
+func (engine *Engine) start(ctx context.Context) error {
+	lc := &engine.lifecycle
+	lc.startOnce.Do(func() {
+		lc.mu.Lock()
+		hooks := lc.onStart
+		lc.mu.Unlock()
+		for i, hook := range hooks {
+			if err := hook(ctx); err != nil {
+				lc.startErr = fmt.Errorf("error running start hook %d: %w", i+1, err)
+				return
+			}
+		}
+	})
+	return lc.startErr
+}
+
+// OnShutdown registers fn to run during Shutdown after the servers stopped, e.g.
+// to flush and close collection managers. Hooks run in reverse order of
+// registration with the context of Shutdown; their errors are returned by it.
+func (engine *Engine) OnShutdown(fn func(ctx context.Context) error) {
+	engine.lifecycle.mu.Lock()
+	defer engine.lifecycle.mu.Unlock()
+	engine.lifecycle.onShutdown = append(engine.lifecycle.onShutdown, fn)
+}
+
+// Shutdown stops the servers started with the Run methods: it closes their
+// listeners and idle connections, waits for in-flight requests until ctx ends
+// and then closes the remaining connections, and runs the OnShutdown hooks.
+// Hijacked connections such as WebSockets are not waited for. Later calls wait
+// for the first one and return its result.
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	lc := &engine.lifecycle
+	lc.mu.Lock()
+	done := lc.done()
+	if lc.stopping {
+		lc.mu.Unlock()
+		select {
+		case <-done:
+			return lc.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	lc.stopping = true
+	servers, hooks := lc.servers, lc.onShutdown
+	lc.mu.Unlock()
+
+	engine.log().Info("shutting down", "servers", len(servers))
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error shutting down server: %w", err))
+			srv.Close()
+		}
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error running shutdown hook: %w", err))
+		}
+	}
+
+	lc.err = errors.Join(errs...)
+	close(done)
+	return lc.err
+}
@@ -1,7 +1,39 @@
 package mygin
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"math/big"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/mahdi-cpp/iris-tools/uuidutil"
+	"github.com/mahdi-cpp/iris-tools/validation"
 )
 
 func TestSimpleRoute(t *testing.T) {
@@ -44,3 +76,1954 @@ func TestSimpleRoute(t *testing.T) {
 	t.Logf("Route found successfully with %d handlers", len(handlers))
 	t.Logf("Params: %v", params)
 }
+
+func TestCheckRoutes(t *testing.T) {
+	ok := func(c *Context) {}
+
+	clean := New()
+	clean.GET("/", ok)
+	clean.GET("/users", ok)
+	clean.GET("/users/new", ok)
+	clean.GET("/users/:id", ok)
+	clean.GET("/users/:id/posts", ok)
+	clean.POST("/users/:id", ok)
+	if err := clean.CheckRoutes().Err(); err != nil {
+		t.Fatalf("unexpected issues: %v", err)
+	}
+	if routes := clean.Routes(); len(routes) != 6 || routes[1].Path != "/users" || routes[1].Handler == "" {
+		t.Fatalf("unexpected routes: %+v", routes)
+	}
+
+	broken := New()
+	broken.GET("/a", ok)
+	broken.GET("/a/", ok)
+	broken.GET("/users/:id", ok)
+	broken.GET("/users/:name", ok)
+	broken.GET("/users/:userId/posts", ok)
+	broken.GET("/x/:id/y/:id", ok)
+
+	kinds := make(map[RouteIssueKind][]string)
+	for _, issue := range broken.CheckRoutes().Issues {
+		kinds[issue.Kind] = append(kinds[issue.Kind], issue.Path)
+	}
+	if got := kinds[IssueDuplicate]; len(got) != 1 || got[0] != "/a" {
+		t.Fatalf("duplicates: %v", got)
+	}
+	if got := kinds[IssueShadowed]; len(got) != 1 || got[0] != "/users/:name" {
+		t.Fatalf("shadowed: %v", got)
+	}
+	if got := kinds[IssueParamCollision]; len(got) != 3 || len(kinds[IssueUnreachable]) != 0 {
+		t.Fatalf("param collisions: %v", got)
+	}
+}
+
+func TestRouteMeta(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {
+		summary, _ := c.RouteMeta("summary")
+		c.String(200, "%s %v", c.FullPath(), summary)
+	}).Meta("summary", "Get a user")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/7", nil))
+	if got := w.Body.String(); got != "/users/:id Get a user" {
+		t.Errorf("body = %q", got)
+	}
+	if routes := router.Routes(); routes[0].Meta["summary"] != "Get a user" {
+		t.Errorf("Routes meta = %v", routes[0].Meta)
+	}
+}
+
+func TestCatchAllRoute(t *testing.T) {
+	router := New()
+	router.GET("/static/*filepath", func(c *Context) {
+		c.String(200, "static %s", c.Param("filepath"))
+	})
+	router.GET("/static/app.js", func(c *Context) { c.String(200, "app") })
+	router.GET("/files/:dir/*rest", func(c *Context) {
+		c.String(200, "%s %s", c.Param("dir"), c.Param("rest"))
+	})
+
+	tests := map[string]string{
+		"/static/app.js":        "app",
+		"/static/css/site.css":  "static /css/site.css",
+		"/static/":              "static /",
+		"/files/docs/a/b.txt":   "docs /a/b.txt",
+		"/files/docs/":          "docs /",
+		"/static":               "404 page not found\n",
+		"/statics/css/site.css": "404 page not found\n",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+	if err := router.CheckRoutes().Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("catch-all in the middle of a path did not panic")
+		}
+	}()
+	router.GET("/bad/*rest/more", func(c *Context) {})
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	router := New()
+	ok := func(c *Context) { c.String(200, "ok") }
+	router.GET("/users/:id", ok)
+	router.DELETE("/users/:id", ok)
+	router.POST("/users", ok)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/users/7", nil))
+	if w.Code != 405 || w.Header().Get("Allow") != "DELETE, GET" {
+		t.Fatalf("PUT /users/7: %d Allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/missing", nil))
+	if w.Code != 404 {
+		t.Fatalf("PUT /missing: %d", w.Code)
+	}
+
+	router.HandleMethodNotAllowed = false
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/users/7", nil))
+	if w.Code != 404 || w.Header().Get("Allow") != "" {
+		t.Fatalf("disabled: %d Allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestNoRouteAndNoMethod(t *testing.T) {
+	router := New()
+	var seen []string
+	router.Use(func(c *Context) {
+		seen = append(seen, c.Path)
+		c.Next()
+	})
+	router.GET("/users", func(c *Context) { c.String(200, "users") })
+	router.NoRoute(func(c *Context) { c.JSON(404, H{"error": "not found"}) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != 404 || w.Body.String() != "{\"error\":\"not found\"}\n" {
+		t.Fatalf("NoRoute: %d %q", w.Code, w.Body)
+	}
+
+	// بدون NoMethod پاسخ پیش‌فرض 405 برمی‌گردد
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+	if w.Code != 405 || w.Body.String() != "405 method not allowed\n" {
+		t.Fatalf("default NoMethod: %d %q", w.Code, w.Body)
+	}
+
+	router.NoMethod(func(c *Context) { c.JSON(405, H{"allow": c.Writer.Header().Get("Allow")}) })
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+	if w.Code != 405 || w.Body.String() != "{\"allow\":\"GET\"}\n" {
+		t.Fatalf("NoMethod: %d %q", w.Code, w.Body)
+	}
+	if len(seen) != 3 {
+		t.Fatalf("global middleware ran for %v", seen)
+	}
+}
+
+func TestRedirectFixedPath(t *testing.T) {
+	router := New()
+	ok := func(c *Context) { c.String(200, "ok") }
+	router.GET("/users/:id/Posts", ok)
+	router.POST("/users", ok)
+	router.GET("/static/*filepath", ok)
+
+	tests := []struct {
+		method, path string
+		code         int
+		location     string
+	}{
+		{"GET", "/Users/AbC/posts", 301, "/users/AbC/Posts"},
+		{"GET", "/users//7/../8/Posts?x=1", 301, "/users/8/Posts?x=1"},
+		{"POST", "/USERS/", 308, "/users"},
+		{"GET", "/STATIC/Css/A.css", 301, "/static/Css/A.css"},
+		{"GET", "/users/7/Posts", 200, ""},
+		{"GET", "/nothing", 404, ""},
+	}
+
+	router.RedirectFixedPath = true
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: %d %q, want %d %q", tt.method, tt.path, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+
+	router.RedirectFixedPath = false
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/Users/7/Posts", nil))
+	if w.Code != 404 {
+		t.Errorf("disabled: got %d", w.Code)
+	}
+}
+
+func TestParamConstraints(t *testing.T) {
+	router := New()
+	router.GET("/users/:id<uuid>", func(c *Context) { c.String(200, "uuid %s", c.Param("id")) })
+	router.GET("/users/:name", func(c *Context) { c.String(200, "name %s", c.Param("name")) })
+	router.GET("/posts/:year<\\d{4}>/:slug<slug>", func(c *Context) {
+		c.String(200, "%s %s", c.Param("year"), c.Param("slug"))
+	})
+	router.GET("/items/:n<int>", func(c *Context) { c.String(200, "item %s", c.Param("n")) })
+
+	tests := map[string]string{
+		"/users/0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b": "uuid 0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b",
+		"/users/sara":             "name sara",
+		"/posts/2024/hello-world": "2024 hello-world",
+		"/posts/24/hello-world":   "404 page not found\n",
+		"/posts/2024/Hello":       "404 page not found\n",
+		"/items/-3":               "item -3",
+		"/items/x":                "404 page not found\n",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+	if err := router.CheckRoutes().Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("invalid constraint did not panic")
+		}
+	}()
+	router.GET("/bad/:x<[>", func(c *Context) {})
+}
+
+func TestRadixTreeMatching(t *testing.T) {
+	router := New()
+	route := func(name string) HandlerFunc {
+		return func(c *Context) { c.String(200, "%s %v", name, c.Params) }
+	}
+	router.GET("/", route("root"))
+	router.GET("/users/new", route("new"))
+	router.GET("/users/new/edit", route("edit"))
+	router.GET("/users/:id", route("user"))
+	router.GET("/users/:id/posts", route("posts"))
+	router.GET("/repos/:owner/:repo/issues/:number", route("issue"))
+	router.GET("/repos/:owner/:repo/*path", route("file"))
+	router.GET("/search", route("search"))
+	router.GET("/searches", route("searches"))
+
+	tests := map[string]string{
+		"/":                         "root []",
+		"/users/new":                "new []",
+		"/users/new/edit":           "edit []",
+		"/users/new/posts":          "posts [{id new}]",
+		"/users/7/posts":            "posts [{id 7}]",
+		"/repos/go/net/issues/12":   "issue [{owner go} {repo net} {number 12}]",
+		"/repos/go/net/issues/12/x": "file [{owner go} {repo net} {path /issues/12/x}]",
+		"/repos/go/net/README.md":   "file [{owner go} {repo net} {path /README.md}]",
+		"/search":                   "search []",
+		"/searches":                 "searches []",
+		"/searc":                    "404 page not found\n",
+		"/users/":                   "404 page not found\n",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+
+	// مسیر داغ جستجو نباید حافظه تخصیص دهد
+	root := router.router["GET"]
+	params := make(Params, 0, router.maxParams)
+	allocs := testing.AllocsPerRun(100, func() {
+		params = params[:0]
+		if root.getValue("/repos/go/net/issues/12", &params) == nil {
+			t.Fatal("no match")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("getValue allocated %v times", allocs)
+	}
+}
+
+// پارامتری که بعد از یک پیشوند ثابت تازه می‌آید گره جداگانه دارد و متن ":id" نیست
+func TestParamAfterStaticPrefix(t *testing.T) {
+	router := New()
+	router.GET("/albums", func(c *Context) { c.String(200, "list") })
+	router.GET("/albums/:id", func(c *Context) { c.String(200, "album %s", c.Param("id")) })
+	router.GET("/albums/:id/photos/:photo", func(c *Context) {
+		c.String(200, "photo %s %s", c.Param("id"), c.Param("photo"))
+	})
+	router.GET("/v1/users/:id", func(c *Context) { c.String(200, "user %s", c.Param("id")) })
+
+	tests := map[string]string{
+		"/albums":              "list",
+		"/albums/7":            "album 7",
+		"/albums/7/photos/9":   "photo 7 9",
+		"/v1/users/3":          "user 3",
+		"/albums/:id":          "album :id",
+		"/v1/users/3/unknown":  "404 page not found\n",
+		"/albums/7/photos":     "404 page not found\n",
+		"/v1/users/:id/extra/": "404 page not found\n",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+}
+
+func benchmarkLookup(b *testing.B, path string) {
+	router := New()
+	ok := func(c *Context) {}
+	for _, route := range []string{
+		"/", "/users", "/users/new", "/users/:id", "/users/:id/posts", "/users/:id/posts/:post",
+		"/repos/:owner/:repo", "/repos/:owner/:repo/issues/:number", "/static/*filepath",
+		"/api/v1/photos", "/api/v1/photos/:id<uuid>", "/api/v1/albums/:id/photos",
+	} {
+		router.GET(route, ok)
+	}
+	root := router.router["GET"]
+	params := make(Params, 0, router.maxParams)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		params = params[:0]
+		if root.getValue(path, &params) == nil {
+			b.Fatal("no match for " + path)
+		}
+	}
+}
+
+func BenchmarkLookupStatic(b *testing.B) {
+	benchmarkLookup(b, "/api/v1/photos")
+}
+
+func BenchmarkLookupParams(b *testing.B) {
+	benchmarkLookup(b, "/repos/mahdi-cpp/iris-tools/issues/42")
+}
+
+func BenchmarkLookupCatchAll(b *testing.B) {
+	benchmarkLookup(b, "/static/css/site.css")
+}
+
+// nopWriter is a ResponseWriter that allocates nothing.
+type nopWriter struct{ header http.Header }
+
+func (w *nopWriter) Header() http.Header         { return w.header }
+func (w *nopWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *nopWriter) WriteHeader(int)             {}
+
+func TestServeHTTPReusesParams(t *testing.T) {
+	ok := func(c *Context) {}
+	static := New()
+	static.GET("/ping", ok)
+	router := New()
+	router.GET("/repos/:owner/:repo/issues/:number", ok)
+
+	w := &nopWriter{header: make(http.Header)}
+	serve := func(engine *Engine, path string) float64 {
+		req := httptest.NewRequest("GET", path, nil)
+		return testing.AllocsPerRun(100, func() { engine.ServeHTTP(w, req) })
+	}
+	// مسیر پارامتردار نباید بیش از موتوری که پارامتر ندارد تخصیص دهد
+	if s, p := serve(static, "/ping"), serve(router, "/repos/go/net/issues/12"); p > s {
+		t.Errorf("route with params allocated %v times, static route %v", p, s)
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	router := New()
+	router.GET("/", func(c *Context) { c.String(200, "default") })
+	router.GET("/status", func(c *Context) { c.String(200, "status") })
+	router.Host("api.example.com").GET("/", func(c *Context) { c.String(200, "api") })
+	tenants := router.Host(":tenant.example.com").Group("/v1")
+	tenants.GET("/users/:id", func(c *Context) {
+		c.String(200, "%s %s", c.Param("tenant"), c.Param("id"))
+	})
+
+	tests := []struct{ host, path, want string }{
+		{"api.example.com", "/", "api"},
+		{"API.example.com:8080", "/", "api"},
+		{"acme.example.com", "/v1/users/7", "acme 7"},
+		{"api.example.com", "/v1/users/7", "api 7"},
+		{"a.b.example.com", "/v1/users/7", "404 page not found\n"},
+		{"acme.example.com", "/status", "status"},
+		{"other.org", "/", "default"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if got := w.Body.String(); got != tt.want {
+			t.Errorf("%s%s: got %q, want %q", tt.host, tt.path, got, tt.want)
+		}
+	}
+	if err := router.CheckRoutes().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if routes := router.Routes(); routes[3].Host != ":tenant.example.com" || routes[3].Path != "/v1/users/:id" {
+		t.Fatalf("routes: %+v", routes[3])
+	}
+}
+
+func TestMatch(t *testing.T) {
+	router := New()
+	calls := 0
+	hooks := router.Group("/hooks")
+	hooks.Use(func(c *Context) { calls++; c.Next() })
+	routes := hooks.Match([]string{"GET", "post"}, "/github", func(c *Context) { c.String(200, "%s", c.Method) })
+	if len(routes) != 2 || routes[1].Method != "POST" {
+		t.Fatalf("routes: %+v", routes)
+	}
+
+	for _, method := range []string{"GET", "POST"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/hooks/github", nil))
+		if w.Body.String() != method {
+			t.Errorf("%s: %q", method, w.Body)
+		}
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("PUT", "/hooks/github", nil))
+	if w.Code != 405 || w.Header().Get("Allow") != "GET, POST" {
+		t.Errorf("PUT: %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	if calls != 2 {
+		t.Errorf("group middleware ran %d times", calls)
+	}
+}
+
+func TestMountAndWrap(t *testing.T) {
+	legacy := http.NewServeMux()
+	legacy.HandleFunc("/old/items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("legacy " + r.Method + " " + r.URL.Path))
+	})
+
+	router := New()
+	var status int
+	router.Use(func(c *Context) { c.Next(); status = c.StatusCode })
+	router.Mount("/legacy", http.StripPrefix("/legacy", legacy))
+	router.GET("/legacy/new", func(c *Context) { c.String(200, "new") })
+	router.GET("/ping", WrapF(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("pong")) }))
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+	}{
+		{"POST", "/legacy/old/items", 202, "legacy POST /old/items"},
+		{"GET", "/legacy/new", 200, "new"},
+		{"GET", "/legacy/missing", 404, "404 page not found\n"},
+		{"GET", "/ping", 200, "pong"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || w.Body.String() != tt.body || status != tt.code {
+			t.Errorf("%s %s: %d %q (context status %d)", tt.method, tt.path, w.Code, w.Body, status)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "public", "css"), 0o755)
+	os.MkdirAll(filepath.Join(dir, "public", "docs"), 0o755)
+	os.WriteFile(filepath.Join(dir, "public", "css", "site.css"), []byte("body{}"), 0o644)
+	os.WriteFile(filepath.Join(dir, "public", "docs", "index.html"), []byte("<p>docs</p>"), 0o644)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644)
+	os.WriteFile(filepath.Join(dir, "favicon.ico"), []byte("\x00\x00\x01\x00"), 0o644)
+
+	router := New()
+	router.Static("/assets", filepath.Join(dir, "public"))
+	router.StaticFile("/favicon.ico", filepath.Join(dir, "favicon.ico"))
+	router.StaticFS("/embedded", http.FS(fstest.MapFS{"a.json": {Data: []byte(`{}`)}}))
+
+	tests := []struct {
+		method, path string
+		code         int
+		contentType  string
+		body         string
+	}{
+		{"GET", "/assets/css/site.css", 200, "text/css; charset=utf-8", "body{}"},
+		{"HEAD", "/assets/css/site.css", 200, "text/css; charset=utf-8", ""},
+		{"GET", "/assets/docs", 200, "text/html; charset=utf-8", "<p>docs</p>"},
+		{"GET", "/assets/css", 404, "", ""},
+		{"GET", "/assets/../secret.txt", 404, "", ""},
+		{"GET", "/assets/%2e%2e/secret.txt", 404, "", ""},
+		{"GET", "/favicon.ico", 200, "image/vnd.microsoft.icon", "\x00\x00\x01\x00"},
+		{"GET", "/embedded/a.json", 200, "application/json", "{}"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || (tt.code == 200 && (w.Header().Get("Content-Type") != tt.contentType || w.Body.String() != tt.body)) {
+			t.Errorf("%s %s: %d %q %q", tt.method, tt.path, w.Code, w.Header().Get("Content-Type"), w.Body)
+		}
+	}
+}
+
+func TestStaticSPA(t *testing.T) {
+	dist := fstest.MapFS{
+		"index.html": {Data: []byte("<app>")},
+		"app.js":     {Data: []byte("run()")},
+	}
+	router := New()
+	router.GET("/api/albums", func(c *Context) { c.String(200, "albums") })
+	router.StaticSPAFS("/", http.FS(dist))
+
+	tests := map[string]string{
+		"/":               "<app>",
+		"/albums/7":       "<app>",
+		"/app.js":         "run()",
+		"/missing.js":     "404 page not found\n",
+		"/api/albums":     "albums",
+		"/settings/users": "<app>",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestHandleHEAD(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {
+		c.Writer.Header().Set("X-User", c.Param("id"))
+		c.String(200, "user %s", c.Param("id"))
+	})
+	router.HEAD("/ping", func(c *Context) { c.Status(204) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("HEAD", "/users/7", nil))
+	if w.Code != 405 {
+		t.Fatalf("disabled: %d", w.Code)
+	}
+
+	router.HandleHEAD = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("HEAD", "/users/7", nil))
+	if w.Code != 200 || w.Body.Len() != 0 || w.Header().Get("Content-Length") != "6" || w.Header().Get("X-User") != "7" {
+		t.Fatalf("HEAD: %d %q %v", w.Code, w.Body, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/users/7", nil))
+	if w.Header().Get("Allow") != "GET, HEAD" {
+		t.Fatalf("Allow = %q", w.Header().Get("Allow"))
+	}
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	router := New()
+	router.Use(func(c *Context) {
+		// یک میان‌افزار CORS ساده درخواست preflight را خودش پاسخ می‌دهد
+		if c.IsPreflight() {
+			c.Writer.Header().Set("Access-Control-Allow-Methods", c.Writer.Header().Get("Allow"))
+			c.Status(200)
+			c.Abort()
+			return
+		}
+		c.Next()
+	})
+	ok := func(c *Context) { c.String(200, "ok") }
+	router.GET("/users/:id", ok)
+	router.DELETE("/users/:id", ok)
+	router.OPTIONS("/custom", func(c *Context) { c.String(200, "custom") })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/users/7", nil))
+	if w.Code != 204 || w.Header().Get("Allow") != "DELETE, GET, OPTIONS" {
+		t.Fatalf("OPTIONS: %d %q", w.Code, w.Header().Get("Allow"))
+	}
+
+	req := httptest.NewRequest("OPTIONS", "/users/7", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != 200 || w.Header().Get("Access-Control-Allow-Methods") != "DELETE, GET, OPTIONS" {
+		t.Fatalf("preflight: %d %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/custom", nil))
+	if w.Body.String() != "custom" {
+		t.Fatalf("registered OPTIONS route: %q", w.Body)
+	}
+
+	router.HandleOPTIONS = false
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/users/7", nil))
+	if w.Code != 405 {
+		t.Fatalf("disabled: %d", w.Code)
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	type album struct {
+		Title string   `json:"title" validate:"required,max=10"`
+		Tags  []string `json:"tags"`
+	}
+	router := New()
+	router.POST("/albums", func(c *Context) {
+		var in album
+		if c.BindJSON(&in) != nil {
+			return
+		}
+		c.String(200, "%s %v", in.Title, in.Tags)
+	})
+
+	tests := map[string]string{
+		`{"title":"trip","tags":["a"]}`: "trip [a]",
+		`{"title":""}`:                  `{"error":"validation failed","fields":{"title":"is required"}}` + "\n",
+		`{"title":`:                     "invalid JSON body",
+		``:                              `{"error":"invalid JSON body: empty body"}` + "\n",
+	}
+	for body, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/albums", strings.NewReader(body)))
+		if got := w.Body.String(); !strings.Contains(got, want) || (want != "trip [a]" && w.Code != 400) {
+			t.Errorf("%s: %d %q, want %q", body, w.Code, got, want)
+		}
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	type paging struct {
+		Limit *int `form:"limit"`
+		Page  int  `form:"page"`
+	}
+	type filter struct {
+		paging
+		Query string        `form:"q" validate:"max=10"`
+		Tags  []string      `form:"tag"`
+		Since time.Time     `form:"since" time_format:"2006-01-02"`
+		Owner uuid.UUID     `form:"owner"`
+		Wait  time.Duration `form:"wait"`
+		Skip  string        `form:"-"`
+	}
+
+	var got filter
+	var bindErr error
+	router := New()
+	router.GET("/photos", func(c *Context) {
+		got = filter{}
+		bindErr = c.ShouldBindQuery(&got)
+	})
+	get := func(query string) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/photos?"+query, nil))
+	}
+
+	get("q=sea&tag=a&tag=b&since=2024-03-01&owner=0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b&limit=20&page=2&wait=1s&Skip=x")
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	if got.Query != "sea" || len(got.Tags) != 2 || got.Since.Day() != 1 || got.Owner.String() != "0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b" ||
+		got.Limit == nil || *got.Limit != 20 || got.Page != 2 || got.Wait != time.Second || got.Skip != "" {
+		t.Fatalf("bound %+v", got)
+	}
+
+	get("q=sea")
+	if bindErr != nil || got.Limit != nil {
+		t.Fatalf("absent pointer: %v %+v", bindErr, got)
+	}
+
+	get("page=x&since=March")
+	fields, ok := validation.Fields(bindErr)
+	if !ok || fields["page"] == "" || fields["since"] == "" {
+		t.Fatalf("conversion errors: %v", bindErr)
+	}
+	get("q=a+very+long+query")
+	if fields, ok := validation.Fields(bindErr); !ok || fields["Query"] == "" {
+		t.Fatalf("validation errors: %v", bindErr)
+	}
+}
+
+func TestBindUri(t *testing.T) {
+	type albumParams struct {
+		ID   uuid.UUID `uri:"id"`
+		Page int       `uri:"page" validate:"min=1"`
+	}
+	router := New()
+	router.GET("/albums/:id/pages/:page", func(c *Context) {
+		var p albumParams
+		if c.BindUri(&p) != nil {
+			return
+		}
+		c.String(200, "%s %d", p.ID, p.Page)
+	})
+
+	tests := map[string]string{
+		"/albums/0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b/pages/3": "0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b 3",
+		"/albums/nope/pages/3":                                 `{"error":"validation failed","fields":{"id":"invalid value \"nope\""}}` + "\n",
+		"/albums/0190a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b/pages/0": `{"error":"validation failed","fields":{"Page":"must be at least 1"}}` + "\n",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	type upload struct {
+		Album  string                  `form:"album" validate:"required"`
+		Rating int                     `form:"rating"`
+		Photo  *multipart.FileHeader   `form:"photo" validate:"required"`
+		Extras []*multipart.FileHeader `form:"extras"`
+	}
+	router := New()
+	router.MaxMultipartMemory = 1 << 10
+	router.POST("/upload", func(c *Context) {
+		var in upload
+		if c.Bind(&in) != nil {
+			return
+		}
+		c.String(200, "%s %d %s %d %d", in.Album, in.Rating, in.Photo.Filename, in.Photo.Size, len(in.Extras))
+	})
+
+	send := func(withPhoto bool) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("album", "trip")
+		mw.WriteField("rating", "5")
+		if withPhoto {
+			fw, _ := mw.CreateFormFile("photo", "sea.jpg")
+			fw.Write(bytes.Repeat([]byte("x"), 4096))
+		}
+		for _, name := range []string{"a.jpg", "b.jpg"} {
+			fw, _ := mw.CreateFormFile("extras", name)
+			fw.Write([]byte(name))
+		}
+		mw.Close()
+		req := httptest.NewRequest("POST", "/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(true); w.Body.String() != "trip 5 sea.jpg 4096 2" {
+		t.Fatalf("multipart: %d %q", w.Code, w.Body)
+	}
+	if w := send(false); w.Code != 400 || !strings.Contains(w.Body.String(), `"Photo":"is required"`) {
+		t.Fatalf("missing file: %d %q", w.Code, w.Body)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/upload", strings.NewReader("album=x&rating=abc"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "rating") {
+		t.Fatalf("urlencoded: %d %q", w.Code, w.Body)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	RegisterValidation("notblank", func(value any, _ string) bool {
+		s, ok := value.(string)
+		return !ok || strings.TrimSpace(s) != ""
+	})
+	type createAlbum struct {
+		Title string `json:"title" binding:"required,min=3"`
+		Note  string `json:"note" binding:"notblank"`
+		Kind  string `json:"kind" validate:"oneof=photo video"`
+	}
+	router := New()
+	router.POST("/albums", func(c *Context) {
+		var in createAlbum
+		if err := c.ShouldBindJSON(&in); err != nil {
+			details, _ := validation.Details(err)
+			c.JSON(422, H{"error": "validation failed", "details": details})
+			return
+		}
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/albums", strings.NewReader(`{"title":"ab","note":"  ","kind":"audio"}`)))
+	want := `{"details":[{"field":"title","rule":"min","param":"3","message":"must be at least 3 characters"},` +
+		`{"field":"note","rule":"notblank","message":"failed the notblank rule"},` +
+		`{"field":"kind","rule":"oneof","param":"photo video","message":"must be one of [photo video]"}],"error":"validation failed"}` + "\n"
+	if w.Code != 422 || w.Body.String() != want {
+		t.Fatalf("got %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/albums", strings.NewReader(`{"title":"trip","note":"x","kind":"photo"}`)))
+	if w.Body.String() != "ok" {
+		t.Fatalf("valid: %s", w.Body)
+	}
+}
+
+func TestGetRawData(t *testing.T) {
+	router := New()
+	var signed []byte
+	router.Use(func(c *Context) {
+		// میان‌افزار امضا بدنه را می‌خواند و handler همچنان می‌تواند آن را bind کند
+		signed, _ = c.GetRawData()
+		c.Next()
+	})
+	router.POST("/hooks", func(c *Context) {
+		var a struct{ Event string }
+		var b map[string]any
+		if c.BindJSON(&a) != nil || c.BindJSON(&b) != nil {
+			return
+		}
+		rest, _ := io.ReadAll(c.Req.Body)
+		c.String(200, "%s %v %s", a.Event, b["Event"], rest)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/hooks", strings.NewReader(`{"Event":"push"}`)))
+	if w.Body.String() != `push push {"Event":"push"}` || string(signed) != `{"Event":"push"}` {
+		t.Fatalf("got %q, signed %q", w.Body, signed)
+	}
+}
+
+func TestHTMLTemplates(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte(`<h1>[[ upper .Title ]]</h1>{{ keep }}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "broken.html"), []byte(`[[ template "missing" ]]`), 0o644)
+
+	router := New()
+	router.Delims("[[", "]]")
+	router.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+	router.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+	router.GET("/:page", func(c *Context) {
+		c.HTML(200, c.Param("page")+".html", map[string]any{"Title": "<trip>"})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/index", nil))
+	if w.Code != 200 || w.Body.String() != "<h1>&lt;TRIP&gt;</h1>{{ keep }}" || w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Fatalf("index: %d %q", w.Code, w.Body)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/broken", nil))
+	if w.Code != 500 {
+		t.Fatalf("broken: %d %q", w.Code, w.Body)
+	}
+}
+
+func TestNoRouteUsesEngine(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "404.html"), []byte(`<p>{{ .Path }} not found</p>`), 0o644)
+
+	var logs bytes.Buffer
+	router := New()
+	router.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	router.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+	router.NoRoute(func(c *Context) {
+		c.Logger().Info("no route")
+		c.HTML(http.StatusNotFound, "404.html", H{"Path": c.Path})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != http.StatusNotFound || w.Body.String() != "<p>/missing not found</p>" {
+		t.Fatalf("NoRoute: %d %q", w.Code, w.Body)
+	}
+	if !strings.Contains(logs.String(), "no route") {
+		t.Fatalf("NoRoute did not log with the engine's Logger: %q", logs.String())
+	}
+}
+
+func TestTemplateReloadInDebugMode(t *testing.T) {
+	defer SetMode(Mode())
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	os.WriteFile(page, []byte("v1"), 0o644)
+
+	router := New()
+	router.LoadHTMLFiles(page)
+	router.GET("/", func(c *Context) { c.HTML(200, "page.html", nil) })
+	render := func() string {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+
+	SetMode(ReleaseMode)
+	os.WriteFile(page, []byte("v2"), 0o644)
+	if got := render(); got != "v1" {
+		t.Fatalf("release mode rendered %q", got)
+	}
+	SetMode(DebugMode)
+	if got := render(); got != "v2" {
+		t.Fatalf("debug mode rendered %q", got)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	router := New()
+	router.GET("/config", func(c *Context) {
+		c.YAML(200, H{"name": "iris", "limits": []int{1, 2}})
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/config", nil))
+	if w.Header().Get("Content-Type") != "application/yaml; charset=utf-8" || w.Body.String() != "limits:\n    - 1\n    - 2\nname: iris\n" {
+		t.Fatalf("got %q %q", w.Header().Get("Content-Type"), w.Body)
+	}
+}
+
+func TestProtoBuf(t *testing.T) {
+	router := New()
+	router.GET("/status", func(c *Context) { c.ProtoBuf(200, wrapperspb.String("ok")) })
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+
+	var got wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Value != "ok" || w.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("got %q %v %q", got.Value, err, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestJSONVariants(t *testing.T) {
+	obj := H{"html": "<b>", "name": "مهدی 😀"}
+	router := New()
+	router.GET("/indented", func(c *Context) { c.IndentedJSON(200, H{"a": 1}) })
+	router.GET("/pure", func(c *Context) { c.PureJSON(200, obj) })
+	router.GET("/ascii", func(c *Context) { c.AsciiJSON(200, obj) })
+	router.GET("/jsonp", func(c *Context) { c.JSONP(200, H{"a": 1}) })
+
+	tests := map[string]string{
+		"/indented":                  "{\n    \"a\": 1\n}",
+		"/pure":                      `{"html":"<b>","name":"مهدی 😀"}` + "\n",
+		"/ascii":                     `{"html":"\u003cb\u003e","name":"\u0645\u0647\u062f\u06cc \ud83d\ude00"}`,
+		"/jsonp?callback=app.handle": `app.handle({"a":1});`,
+		"/jsonp":                     `{"a":1}` + "\n",
+		"/jsonp?callback=alert(1)//": `{"error":"invalid callback"}` + "\n",
+	}
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if got := w.Body.String(); got != want {
+			t.Errorf("%s: got %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestSSEventAndStream(t *testing.T) {
+	router := New()
+	router.GET("/events", func(c *Context) {
+		n := 0
+		c.Stream(func(w io.Writer) bool {
+			n++
+			c.SSEvent("progress", H{"done": n * 50})
+			return n < 2
+		})
+		c.SSEvent("", "bye\nnow")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	want := "event: progress\ndata: {\"done\":50}\n\nevent: progress\ndata: {\"done\":100}\n\ndata: bye\ndata: now\n\n"
+	if w.Body.String() != want || w.Header().Get("Content-Type") != "text/event-stream" || !w.Flushed {
+		t.Fatalf("got %q %v flushed=%v", w.Body, w.Header(), w.Flushed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	if !NewContext(httptest.NewRecorder(), req, nil).Stream(func(io.Writer) bool { return true }) {
+		t.Fatal("Stream did not stop for a gone client")
+	}
+}
+
+func TestFileHelpers(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "7.zip"), []byte("0123456789"), 0o644)
+
+	router := New()
+	router.GET("/file", func(c *Context) { c.File(filepath.Join(dir, "7.zip")) })
+	router.GET("/missing", func(c *Context) { c.File(filepath.Join(dir, "none.zip")) })
+	router.GET("/download", func(c *Context) { c.FileAttachment(filepath.Join(dir, "7.zip"), c.Req.URL.Query().Get("name")) })
+	router.GET("/fs", func(c *Context) {
+		c.FileFromFS("../a.txt", http.FS(fstest.MapFS{"a.txt": {Data: []byte("a")}}))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/file", nil)
+	req.Header.Set("Range", "bytes=2-4")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" {
+		t.Fatalf("range: %d %q", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/fs", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "a" {
+		t.Fatalf("fs: %d %q", w.Code, w.Body)
+	}
+
+	for name, want := range map[string]string{
+		"album-7.zip":                `attachment; filename=album-7.zip`,
+		"../../etc/a\"b\r\nX: y.zip": `attachment; filename="abX: y.zip"`,
+		"آلبوم.zip":                  `attachment; filename*=utf-8''%D8%A2%D9%84%D8%A8%D9%88%D9%85.zip`,
+		"..":                         `attachment; filename=download`,
+	} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/download?name="+url.QueryEscape(name), nil))
+		if got := w.Header().Get("Content-Disposition"); got != want || w.Body.String() != "0123456789" {
+			t.Errorf("%q: got %q", name, got)
+		}
+	}
+}
+
+type countingJSON struct {
+	JSONEncoder
+	encoded, decoded int
+}
+
+func (j *countingJSON) Encode(w io.Writer, v any) error {
+	j.encoded++
+	return j.JSONEncoder.Encode(w, v)
+}
+
+func (j *countingJSON) Decode(r io.Reader, v any) error {
+	j.decoded++
+	return j.JSONEncoder.Decode(r, v)
+}
+
+func TestJSONEncoder(t *testing.T) {
+	enc := &countingJSON{JSONEncoder: GoJSON}
+	router := New()
+	router.JSONEncoder = enc
+	router.POST("/echo", func(c *Context) {
+		var in struct {
+			Name string `json:"name"`
+		}
+		if c.BindJSON(&in) != nil {
+			return
+		}
+		c.JSON(http.StatusOK, H{"name": in.Name, "html": "<b>"})
+	})
+	router.GET("/bad", func(c *Context) { c.JSON(http.StatusOK, H{"ch": make(chan int)}) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/echo", strings.NewReader(`{"name":"x"}`)))
+	if w.Code != http.StatusOK || w.Body.String() != "{\"html\":\"\\u003cb\\u003e\",\"name\":\"x\"}\n" {
+		t.Fatalf("got %d %q", w.Code, w.Body)
+	}
+	if enc.encoded != 1 || enc.decoded != 1 {
+		t.Fatalf("encoder used %d/%d times", enc.encoded, enc.decoded)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/bad", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("unencodable value: %d", w.Code)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	copies := make(chan *Context, 1)
+	kept := make(chan *Context, 1)
+	router := New()
+	router.POST("/albums/:id", func(c *Context) {
+		c.GetRawData()
+		c.Set("user", "sara")
+		copies <- c.Copy()
+		kept <- c
+		c.Set("user", "changed")
+		c.JSON(http.StatusCreated, H{})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("POST", "/albums/7", strings.NewReader("body")).WithContext(ctx)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	cancel()
+
+	cp := <-copies
+	data, _ := io.ReadAll(cp.Req.Body)
+	if cp.Param("id") != "7" || cp.MustGet("user") != "sara" || string(data) != "body" || cp.Req.Context().Err() != nil {
+		t.Fatalf("copy: %q %v %q %v", cp.Param("id"), cp.Keys, data, cp.Req.Context().Err())
+	}
+	cp.JSON(http.StatusOK, H{"ignored": true}) // discarded
+
+	original := <-kept
+	SetMode(DebugMode)
+	defer SetMode(ReleaseMode)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("using the original Context after the request did not panic in debug mode")
+		}
+	}()
+	original.Get("user")
+}
+
+func TestUseAfterFinish(t *testing.T) {
+	SetMode(DebugMode)
+	defer SetMode(ReleaseMode)
+	DebugWriter = io.Discard
+	defer func() { DebugWriter = os.Stdout }()
+
+	kept := make(chan *Context, 1)
+	router := New()
+	router.GET("/albums", func(c *Context) {
+		kept <- c
+		c.Status(http.StatusAccepted)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/albums", nil))
+	c := <-kept
+
+	uses := map[string]func(){
+		"JSON":   func() { c.JSON(http.StatusOK, H{}) },
+		"String": func() { c.String(http.StatusOK, "%s", "late") },
+		"Abort":  func() { c.AbortWithStatus(http.StatusBadRequest) },
+		"Header": func() { c.Writer.Header().Set("X-Late", "1") },
+		"Write":  func() { c.Writer.Write([]byte("late")) },
+	}
+	for name, use := range uses {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s after the request did not panic in debug mode", name)
+				}
+			}()
+			use()
+		}()
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), nil)
+	now := time.Now()
+	c.Set("user_id", "u-7")
+	c.Set("limit", 20)
+	c.Set("admin", true)
+	c.Set("timeout", time.Second)
+	c.Set("since", now)
+	c.Set("roles", []string{"editor"})
+	c.Set("claims", map[string]any{"sub": "u-7"})
+	c.Set("ratio", 0.5)
+	c.Set("size", int64(1<<40))
+
+	if c.GetString("user_id") != "u-7" || c.GetInt("limit") != 20 || !c.GetBool("admin") ||
+		c.GetDuration("timeout") != time.Second || !c.GetTime("since").Equal(now) ||
+		c.GetStringSlice("roles")[0] != "editor" || c.GetStringMap("claims")["sub"] != "u-7" ||
+		c.GetFloat64("ratio") != 0.5 || c.GetInt64("size") != 1<<40 {
+		t.Fatalf("typed getters returned wrong values: %v", c.Keys)
+	}
+	if c.GetString("limit") != "" || c.GetInt("missing") != 0 || c.GetBool("user_id") {
+		t.Fatal("wrong type or missing key did not return the zero value")
+	}
+	if v, ok := Value[[]string](c, "roles"); !ok || len(v) != 1 {
+		t.Fatalf("Value: %v %v", v, ok)
+	}
+	if _, ok := Value[int](c, "user_id"); ok {
+		t.Fatal("Value accepted a value of another type")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustGet did not panic on a missing key")
+		}
+	}()
+	c.MustGet("missing")
+}
+
+func TestAbort(t *testing.T) {
+	var aborted bool
+	router := New()
+	router.Use(func(c *Context) {
+		c.Next()
+		aborted = c.IsAborted()
+	})
+	auth := func(c *Context) {
+		switch c.GetHeader("Authorization") {
+		case "":
+			c.AbortWithStatusJSON(http.StatusUnauthorized, H{"error": "missing token"})
+		case "expired":
+			c.AbortWithError(http.StatusForbidden, errors.New("token expired"))
+		case "banned":
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+	router.GET("/me", auth, func(c *Context) { c.JSON(http.StatusOK, H{"user": "sara"}) })
+
+	tests := []struct {
+		token   string
+		code    int
+		body    string
+		aborted bool
+	}{
+		{"", 401, `{"error":"missing token"}` + "\n", true},
+		{"expired", 403, "", true},
+		{"banned", 403, "", true},
+		{"ok", 200, `{"user":"sara"}` + "\n", false},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.Header.Set("Authorization", tt.token)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code || w.Body.String() != tt.body || aborted != tt.aborted {
+			t.Errorf("token %q: got %d %q aborted=%v", tt.token, w.Code, w.Body, aborted)
+		}
+	}
+
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), nil)
+	cause := errors.New("db down")
+	e := c.AbortWithError(http.StatusInternalServerError, cause)
+	if !errors.Is(e, cause) || c.Errors.Last() != e || len(c.Errors) != 1 || !c.IsAborted() {
+		t.Fatalf("AbortWithError: %v %v", e, c.Errors)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	router := New()
+	router.Use(ErrorHandler())
+	router.GET("/public", func(c *Context) {
+		c.AbortWithError(http.StatusNotFound, errors.New("album not found")).
+			SetType(ErrorTypePublic).SetMeta(H{"id": "7"})
+	})
+	router.GET("/private", func(c *Context) {
+		c.Error(errors.New("db: connection refused"))
+	})
+	router.GET("/invalid", func(c *Context) {
+		c.Status(http.StatusUnprocessableEntity)
+		c.Error(validation.FieldErrors{{Field: "title", Rule: "required", Message: "is required"}})
+	})
+	router.GET("/written", func(c *Context) {
+		c.Error(errors.New("cache miss"))
+		c.JSON(http.StatusOK, H{"ok": true})
+	})
+	router.GET("/status", func(c *Context) { c.AbortWithStatus(http.StatusForbidden) })
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/public", 404, `{"error":"album not found","meta":{"id":"7"}}`},
+		{"/private", 500, `{"error":"Internal Server Error"}`},
+		{"/invalid", 422, `{"error":"validation failed","fields":{"title":"is required"}}`},
+		{"/written", 200, `{"ok":true}`},
+		{"/status", 403, ``},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code || strings.TrimSpace(w.Body.String()) != tt.body {
+			t.Errorf("%s: got %d %q", tt.path, w.Code, w.Body)
+		}
+	}
+}
+
+func TestContentTypeAndAccepts(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", "Application/JSON; charset=utf-8")
+	if got := NewContext(httptest.NewRecorder(), req, nil).ContentType(); got != "application/json" {
+		t.Fatalf("ContentType = %q", got)
+	}
+
+	tests := []struct {
+		accept string
+		offers []string
+		want   string
+	}{
+		{"", []string{"json", "html"}, "json"},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", []string{"json", "html"}, "html"},
+		{"application/json", []string{"html", "json"}, "json"},
+		{"text/*;q=0.5, application/json;q=0.4", []string{"json", "text"}, "text"},
+		{"*/*", []string{"xml", "json"}, "xml"},
+		{"text/html;q=0, */*", []string{"html", "json"}, "json"},
+		{"image/png", []string{"json", "html"}, ""},
+		{"application/vnd.api+json", []string{"application/vnd.api+json"}, "application/vnd.api+json"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		if got := NewContext(httptest.NewRecorder(), req, nil).Accepts(tt.offers...); got != tt.want {
+			t.Errorf("Accept %q, offers %v: got %q, want %q", tt.accept, tt.offers, got, tt.want)
+		}
+	}
+}
+
+func TestTypedParams(t *testing.T) {
+	id := uuid.Must(uuid.NewV7())
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), nil)
+	c.Params = Params{{"id", id.String()}, {"short", "alb_" + uuidutil.Short(id)}, {"page", "3"}, {"big", "9000000000"}, {"bad", "x7"}}
+
+	if got, err := c.ParamUUID("id"); err != nil || got != id {
+		t.Fatalf("ParamUUID(id) = %v, %v", got, err)
+	}
+	if got, err := c.ParamUUID("short"); err != nil || got != id {
+		t.Fatalf("ParamUUID(short) = %v, %v", got, err)
+	}
+	if got, err := c.ParamInt("page"); err != nil || got != 3 {
+		t.Fatalf("ParamInt = %v, %v", got, err)
+	}
+	if got, err := c.ParamInt64("big"); err != nil || got != 9000000000 {
+		t.Fatalf("ParamInt64 = %v, %v", got, err)
+	}
+	for _, err := range []error{
+		func() error { _, err := c.ParamUUID("bad"); return err }(),
+		func() error { _, err := c.ParamInt("bad"); return err }(),
+		func() error { _, err := c.ParamInt64("missing"); return err }(),
+	} {
+		if !errors.Is(err, ErrInvalidParam) {
+			t.Errorf("error %v does not wrap ErrInvalidParam", err)
+		}
+	}
+}
+
+func handlerNameTarget(c *Context) {
+	c.JSON(http.StatusOK, H{"handler": c.HandlerName(), "route": c.FullPath()})
+}
+
+func TestHandlerName(t *testing.T) {
+	var names []string
+	router := New()
+	router.Use(func(c *Context) {
+		names = c.HandlerNames()
+		c.Next()
+	})
+	router.GET("/users/:id", handlerNameTarget)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/7", nil))
+	want := `{"handler":"github.com/mahdi-cpp/iris-tools/mygin.handlerNameTarget","route":"/users/:id"}`
+	if strings.TrimSpace(w.Body.String()) != want {
+		t.Fatalf("got %s", w.Body)
+	}
+	if len(names) != 2 || names[1] != "github.com/mahdi-cpp/iris-tools/mygin.handlerNameTarget" {
+		t.Fatalf("HandlerNames = %v", names)
+	}
+	if c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), nil); c.HandlerName() != "" {
+		t.Fatal("HandlerName of a context without handlers is not empty")
+	}
+}
+
+func TestCORS(t *testing.T) {
+	router := New()
+	router.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.example.dev"},
+		AllowOriginFunc:  func(origin string) bool { return origin == "https://tenant.io" },
+		ExposeHeaders:    []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	router.GET("/albums", func(c *Context) { c.JSON(http.StatusOK, H{}) })
+
+	send := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/albums", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			req.Header.Set("Access-Control-Request-Headers", "Authorization")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", "https://app.example.com", false)
+	if w.Code != 200 || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" || w.Header().Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+		t.Fatalf("simple request: %d %v", w.Code, w.Header())
+	}
+
+	for _, origin := range []string{"https://staging.example.dev", "https://tenant.io"} {
+		w = send("OPTIONS", origin, true)
+		h := w.Header()
+		if w.Code != 204 || h.Get("Access-Control-Allow-Origin") != origin || h.Get("Access-Control-Allow-Methods") != "GET, HEAD, POST, PUT, PATCH, DELETE" ||
+			h.Get("Access-Control-Allow-Headers") != "Authorization" || h.Get("Access-Control-Max-Age") != "3600" {
+			t.Fatalf("preflight from %s: %d %v", origin, w.Code, h)
+		}
+	}
+
+	if w = send("OPTIONS", "https://evil.com", true); w.Code != 403 {
+		t.Fatalf("preflight from a foreign origin: %d", w.Code)
+	}
+	if w = send("GET", "https://example.dev", false); w.Code != 200 || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("request from a foreign origin: %d %v", w.Code, w.Header())
+	}
+	if w = send("GET", "", false); w.Header().Get("Vary") != "" {
+		t.Fatalf("request without Origin: %v", w.Header())
+	}
+
+	open := New()
+	open.Use(CORS(CORSConfig{AllowOrigins: []string{"*"}}))
+	open.GET("/", func(c *Context) {})
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://any.site")
+	open.ServeHTTP(w, req)
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("wildcard: %v", w.Header())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("wildcard origin with credentials did not panic")
+		}
+	}()
+	CORS(CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{Output: &buf, SkipPaths: []string{"/healthz"}}))
+	router.GET("/albums/:id", func(c *Context) { c.String(http.StatusOK, "album") })
+	router.GET("/healthz", func(c *Context) {})
+
+	req := httptest.NewRequest("GET", "/albums/7?full=1", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("User-Agent", "photos/1.0")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+	line := buf.String()
+	if !strings.HasPrefix(line, "10.0.0.1 - - [") || !strings.HasSuffix(line, `] "GET /albums/7?full=1 HTTP/1.1" 200 5 "-" "photos/1.0"`+"\n") {
+		t.Fatalf("combined line: %q", line)
+	}
+
+	buf.Reset()
+	router = New()
+	router.Use(LoggerWithConfig(LoggerConfig{Format: LogJSON, Output: &buf}))
+	router.GET("/albums/:id", func(c *Context) { c.AbortWithError(http.StatusNotFound, errors.New("no album")) })
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/albums/7", nil))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("JSON line %q: %v", buf.String(), err)
+	}
+	if entry["status"] != float64(404) || entry["route"] != "/albums/:id" || entry["errors"] != "no album" || entry["latency_ms"] == nil {
+		t.Fatalf("JSON entry: %v", entry)
+	}
+}
+
+func TestStructuredLogging(t *testing.T) {
+	var buf bytes.Buffer
+	router := New()
+	router.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	router.Use(LoggerWithConfig(LoggerConfig{Format: LogSlog}), Recovery())
+	router.GET("/albums/:id", func(c *Context) {
+		c.Logger().Info("album loaded")
+	})
+	router.GET("/panic", func(c *Context) { panic("boom") })
+
+	req := httptest.NewRequest("GET", "/albums/7", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var records []map[string]any
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record map[string]any
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 || records[0]["msg"] != "album loaded" || records[0]["request_id"] != "req-42" ||
+		records[0]["route"] != "/albums/:id" || records[1]["msg"] != "request" || records[1]["status"] != float64(200) {
+		t.Fatalf("records: %v", records)
+	}
+
+	buf.Reset()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(buf.String(), `"msg":"panic recovered","request_id":"`) ||
+		!strings.Contains(buf.String(), `"panic":"boom"`) || !strings.Contains(buf.String(), `"level":"ERROR","msg":"request"`) {
+		t.Fatalf("panic: %d %s", w.Code, buf.String())
+	}
+
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), nil)
+	if id := c.RequestID(); len(id) != 36 || c.RequestID() != id || c.Copy().RequestID() != id {
+		t.Fatalf("generated request id %q is not stable", id)
+	}
+}
+
+func TestPprof(t *testing.T) {
+	router := New()
+	admin := router.Group("/admin")
+	RegisterPprof(admin, func(c *Context) {
+		if c.GetHeader("Authorization") != "Bearer debug" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	})
+
+	get := func(path string, authorized bool) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		if authorized {
+			req.Header.Set("Authorization", "Bearer debug")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/admin/debug/pprof/", false); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unauthorized index: got %d, want 401", w.Code)
+	}
+	w := get("/admin/debug/pprof/", true)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("index: got %d %q", w.Code, w.Body.String())
+	}
+	w = get("/admin/debug/pprof/goroutine?debug=1", true)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Fatalf("goroutine profile: got %d %q", w.Code, w.Body.String())
+	}
+	if w := get("/admin/debug/pprof/cmdline", true); w.Code != http.StatusOK {
+		t.Fatalf("cmdline: got %d", w.Code)
+	}
+	if w := get("/admin/debug/pprof/nope", true); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown profile: got %d, want 404", w.Code)
+	}
+}
+
+type upperTranslator struct{}
+
+func (upperTranslator) T(locale, key string, args ...any) string {
+	return locale + ":" + strings.ToUpper(key)
+}
+
+func TestTranslate(t *testing.T) {
+	router := New()
+	router.GET("/plain", func(c *Context) {
+		c.String(http.StatusOK, "%s|%s", c.T("album %s", "7"), c.Locale())
+	})
+	router.GET("/translated", func(c *Context) {
+		c.SetLocale("fa", upperTranslator{})
+		c.String(http.StatusOK, "%s|%s", c.T("album.title"), c.Copy().T("x"))
+	})
+
+	for path, want := range map[string]string{"/plain": "album 7|", "/translated": "fa:ALBUM.TITLE|fa:X"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: got %q, want %q", path, w.Body.String(), want)
+		}
+	}
+}
+
+func TestConditionalMiddleware(t *testing.T) {
+	var ran []string
+	mark := func(name string) HandlerFunc {
+		return func(c *Context) {
+			ran = append(ran, name)
+			c.Next()
+		}
+	}
+	deny := func(c *Context) { c.AbortWithStatus(http.StatusUnauthorized) }
+
+	router := New()
+	router.Use(Unless(deny, "/healthz", "/static/*"))
+	router.Use(When(MethodIs(http.MethodPost), mark("audit")))
+	router.Use(When(Not(PathIs("/static/*")), mark("log")))
+	ok := func(c *Context) { c.Status(http.StatusOK) }
+	router.GET("/healthz", ok)
+	router.POST("/healthz", ok)
+	router.GET("/static/*filepath", ok)
+	router.GET("/albums", ok)
+
+	tests := []struct {
+		method, path string
+		status       int
+		ran          string
+	}{
+		{"GET", "/healthz", http.StatusOK, "log"},
+		{"POST", "/healthz", http.StatusOK, "audit,log"},
+		{"GET", "/static/app.js", http.StatusOK, ""},
+		{"GET", "/albums", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		ran = nil
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.status || strings.Join(ran, ",") != tt.ran {
+			t.Errorf("%s %s: got %d %v, want %d %q", tt.method, tt.path, w.Code, ran, tt.status, tt.ran)
+		}
+	}
+}
+
+func TestOnFinish(t *testing.T) {
+	var finished []string
+	record := func(name string) HandlerFunc {
+		return func(c *Context) {
+			c.OnFinish(func(c *Context) {
+				finished = append(finished, fmt.Sprintf("%s %d %d", name, c.StatusCode, c.Size()))
+			})
+			c.Next()
+		}
+	}
+
+	router := New()
+	router.Use(record("outer"), record("inner"))
+	router.GET("/albums", func(c *Context) {
+		c.Writer.WriteHeader(http.StatusCreated) // بدون c.Status هم وضعیت ثبت می‌شود
+		c.Writer.Write([]byte("created"))
+	})
+	router.GET("/denied", func(c *Context) {
+		c.AbortWithStatus(http.StatusForbidden)
+	})
+
+	for path, want := range map[string]string{
+		"/albums": "inner 201 7,outer 201 7",
+		"/denied": "inner 403 0,outer 403 0",
+	} {
+		finished = nil
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		if got := strings.Join(finished, ","); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+	plain := New()
+	plain.GET("/plain", func(c *Context) {
+		if c.Size() != -1 {
+			t.Errorf("Size without OnFinish = %d", c.Size())
+		}
+	})
+	plain.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/plain", nil))
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	router := New()
+	started, release := make(chan struct{}), make(chan struct{})
+	router.GET("/slow", func(c *Context) {
+		close(started)
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+	var hooks []string
+	router.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "first")
+		return nil
+	})
+	router.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "second")
+		return errors.New("flush failed")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	go func() { ran <- router.RunWithContext(ctx, addr) }()
+
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			got <- err.Error()
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		got <- string(body)
+	}()
+	<-started
+
+	cancel()
+	select {
+	case err := <-ran:
+		t.Fatalf("RunWithContext returned before the request finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if body := <-got; body != "done" {
+		t.Fatalf("in-flight request: %q", body)
+	}
+	if err := <-ran; err == nil || !strings.Contains(err.Error(), "flush failed") {
+		t.Fatalf("RunWithContext = %v", err)
+	}
+	if strings.Join(hooks, ",") != "second,first" {
+		t.Fatalf("hooks ran %v", hooks)
+	}
+	if err := router.Run(addr); !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Run after Shutdown = %v", err)
+	}
+}
+
+func TestRunTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	if err := New().RunTLS("127.0.0.1:0", filepath.Join(dir, "missing.pem"), keyFile); err == nil {
+		t.Fatal("RunTLS without a certificate succeeded")
+	}
+	if err := New().RunAutoTLS(); err == nil {
+		t.Fatal("RunAutoTLS without domains succeeded")
+	}
+
+	router := New()
+	router.GET("/", func(c *Context) { c.String(http.StatusOK, "secure") })
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	l.Close()
+	ran := make(chan error, 1)
+	go func() { ran <- router.RunTLS(addr, certFile, keyFile) }()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = client.Get("https://" + addr + "/"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "secure" || resp.TLS == nil {
+		t.Fatalf("got %q", body)
+	}
+	if err := router.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-ran; err != nil {
+		t.Fatalf("RunTLS = %v", err)
+	}
+}
+
+func TestRunUnixAndListener(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mygin") // مسیر سوکت یونیکس محدودیت طول دارد
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "photos.sock")
+	os.WriteFile(path, nil, 0o600)
+	if err := New().RunUnix(path); err == nil {
+		t.Fatal("RunUnix replaced a regular file")
+	}
+	os.Remove(path)
+
+	// سوکت باقی‌مانده از اجرای قبلی جایگزین می‌شود
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	router := New()
+	router.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+	ran := make(chan error, 1)
+	go func() { ran <- router.RunUnix(path) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = client.Get("http://photos/ping"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Fatalf("got %q", body)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { ran <- router.RunListener(l) }()
+	for i := 0; i < 100; i++ {
+		if resp, err = http.Get("http://" + l.Addr().String() + "/ping"); err == nil {
+			resp.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := router.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-ran; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket left behind: %v", err)
+	}
+}
+
+func TestDebugOutput(t *testing.T) {
+	var out bytes.Buffer
+	DebugWriter = &out
+	defer func() { DebugWriter = os.Stdout }()
+	defer SetMode(Mode())
+
+	SetMode(ReleaseMode)
+	New().GET("/quiet", func(c *Context) {})
+	if out.Len() != 0 {
+		t.Fatalf("release mode printed %q", out.String())
+	}
+
+	SetMode(DebugMode)
+	router := New()
+	router.Use(func(c *Context) { c.Next() })
+	router.GET("/albums/:id", handlerForName)
+	router.GET("/albums/:name", handlerForName)
+	router.debugPrintRouteIssues()
+	for _, want := range []string{
+		`[MYGIN-debug] [WARNING] Running in "debug" mode`,
+		"export MYGIN_MODE=release",
+		"[MYGIN-debug] GET    /albums/:id               --> github.com/mahdi-cpp/iris-tools/mygin.handlerForName (2 handlers)\n",
+		"[MYGIN-debug] [WARNING] route shadowed GET /albums/:name",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	var routes []string
+	DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+		routes = append(routes, fmt.Sprintf("%s %s %d", method, path, handlers))
+	}
+	defer func() { DebugPrintRouteFunc = nil }()
+	router.Host("photos.example.com").POST("/albums", handlerForName)
+	if strings.Join(routes, ",") != "POST photos.example.com/albums 2" {
+		t.Errorf("DebugPrintRouteFunc got %v", routes)
+	}
+}
+
+func handlerForName(c *Context) {}
+
+func TestLifecycleHooks(t *testing.T) {
+	var events []string
+	hook := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			events = append(events, name)
+			return err
+		}
+	}
+
+	router := New()
+	router.OnStart(hook("open albums", nil))
+	router.OnStart(hook("open photos", nil))
+	router.OnShutdown(hook("close albums", nil))
+	router.OnShutdown(hook("close photos", nil))
+	router.GET("/ping", func(c *Context) { c.String(http.StatusOK, "%s", strings.Join(events, ",")) })
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := make(chan error, 1)
+	go func() { ran <- router.RunListener(l) }()
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = http.Get("http://" + l.Addr().String() + "/ping"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "open albums,open photos" {
+		t.Fatalf("start hooks before serving: %q", body)
+	}
+	if err := router.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-ran
+	if got := strings.Join(events, ","); got != "open albums,open photos,close photos,close albums" {
+		t.Fatalf("events = %s", got)
+	}
+
+	// شکست یک hook شروع، سرور را متوقف و hookهای shutdown را اجرا می‌کند
+	events = nil
+	failing := New()
+	failing.OnStart(hook("open albums", errors.New("disk full")))
+	failing.OnStart(hook("open photos", nil))
+	failing.OnShutdown(hook("close albums", nil))
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := failing.RunListener(l); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("RunListener = %v", err)
+	}
+	if got := strings.Join(events, ","); got != "open albums,close albums" {
+		t.Fatalf("events = %s", got)
+	}
+	if _, err := net.Dial("tcp", l.Addr().String()); err == nil {
+		t.Fatal("listener still open")
+	}
+}
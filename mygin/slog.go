@@ -0,0 +1,60 @@
+package mygin
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the request header Context.RequestID takes the ID from, so
+// a request keeps the ID a proxy or the calling service gave it.
+const RequestIDHeader = "X-Request-ID"
+
+// log returns Engine.Logger, or the "mygin" module logger when it is not set.
+func (engine *Engine) log() *slog.Logger {
+	if engine != nil && engine.Logger != nil {
+		return engine.Logger
+	}
+	return logger
+}
+
+// Logger returns the engine's logger with the request_id, method and route of
+// the request attached, for handlers and middleware:
+//
+//	c.Logger().Info("album created", "album", album.ID)
+//
+// Records are logged with c.Req.Context() by the middleware of this package, so
+// handlers that add the trace to their records should use InfoContext and friends.
+func (c *Context) Logger() *slog.Logger {
+	if c.log == nil {
+		c.log = c.engine.log().With(
+			"request_id", c.RequestID(),
+			"method", c.Method,
+			"route", c.FullPath(),
+		)
+	}
+	return c.log
+}
+
+// RequestID returns the ID of the request: the X-Request-ID header when it is a
+// reasonable token, otherwise a UUID v7 generated on the first call.
+func (c *Context) RequestID() string {
+	if c.requestID == "" {
+		if id := c.Req.Header.Get(RequestIDHeader); validRequestID(id) {
+			c.requestID = id
+		} else {
+			c.requestID = uuid.Must(uuid.NewV7()).String()
+		}
+	}
+	return c.requestID
+}
+
+// validRequestID accepts IDs of up to 128 printable ASCII characters, so clients
+// cannot forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	return strings.IndexFunc(id, func(r rune) bool { return r <= ' ' || r > '~' }) < 0
+}
@@ -0,0 +1,173 @@
+package mygin
+
+import (
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Static serves the files of the directory root under relativePath:
+//
+//	r.Static("/assets", "./public") // GET /assets/css/site.css -> ./public/css/site.css
+//
+// Directory listings are not served; a directory answers with its index.html or 404.
+func (group *RouterGroup) Static(relativePath, root string) {
+	group.StaticFS(relativePath, http.Dir(root))
+}
+
+// StaticFS serves the files of fsys under relativePath, e.g. an embedded file
+// system wrapped with http.FS. Paths are cleaned before they reach fsys, so
+// requests cannot escape it with "..".
+func (group *RouterGroup) StaticFS(relativePath string, fsys http.FileSystem) {
+	group.staticFS(relativePath, fsys, false)
+}
+
+// StaticSPA serves a single-page app from the directory root under relativePath.
+// Unknown paths fall back to root/index.html so the app's client-side router
+// (history API) can handle them:
+//
+//	r.StaticSPA("/", "./dist") // /albums/7 -> ./dist/index.html
+//
+// Paths whose last segment has a file extension (/app.js, /logo.png) are not
+// rewritten and answer 404 when missing. Routes registered below relativePath,
+// such as /api, take precedence.
+func (group *RouterGroup) StaticSPA(relativePath, root string) {
+	group.StaticSPAFS(relativePath, http.Dir(root))
+}
+
+// StaticSPAFS is StaticSPA for an http.FileSystem, e.g. an embedded build.
+func (group *RouterGroup) StaticSPAFS(relativePath string, fsys http.FileSystem) {
+	group.staticFS(relativePath, fsys, true)
+}
+
+func (group *RouterGroup) staticFS(relativePath string, fsys http.FileSystem, spa bool) {
+	if strings.ContainsAny(relativePath, ":*") {
+		panic("URL parameters can not be used when serving a static folder: " + relativePath)
+	}
+	handler := func(c *Context) {
+		name := c.Param("filepath")
+		if serveFile(c, fsys, name) {
+			return
+		}
+		// مسیرهای ناشناخته برنامه تک‌صفحه‌ای به index.html برمی‌گردند
+		if spa && path.Ext(name) == "" && serveFile(c, fsys, "/index.html") {
+			return
+		}
+		defaultNoRoute(c)
+	}
+	pattern := joinPath(relativePath, "/*filepath")
+	group.GET(pattern, handler)
+	group.HEAD(pattern, handler)
+}
+
+// StaticFile serves a single file, e.g. r.StaticFile("/favicon.ico", "./favicon.ico").
+func (group *RouterGroup) StaticFile(relativePath, file string) {
+	if strings.ContainsAny(relativePath, ":*") {
+		panic("URL parameters can not be used when serving a static file: " + relativePath)
+	}
+	handler := func(c *Context) {
+		c.File(file)
+	}
+	group.GET(relativePath, handler)
+	group.HEAD(relativePath, handler)
+}
+
+// File writes the file at filepath on disk, answering 404 when it does not exist.
+// Range and conditional requests are handled like Static:
+//
+//	r.GET("/photos/:id/original", func(c *mygin.Context) {
+//		c.File(filepath.Join(photosDir, c.Param("id")+".jpg"))
+//	})
+//
+// filepath is used as given; callers must not pass unchecked user input.
+func (c *Context) File(file string) {
+	dir, name := filepath.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+	c.FileFromFS(name, http.Dir(dir))
+}
+
+// FileFromFS writes the file name of fsys, e.g. http.FS(embedded), answering 404
+// when it does not exist. name is cleaned first, so it cannot escape fsys.
+func (c *Context) FileFromFS(name string, fsys http.FileSystem) {
+	c.checkFinished()
+	if !serveFile(c, fsys, name) {
+		defaultNoRoute(c)
+	}
+}
+
+// FileAttachment is File with a Content-Disposition header that makes browsers
+// download the file as filename:
+//
+//	c.FileAttachment("/data/exports/7.zip", "album-7.zip")
+//
+// Directories, control characters and quotes are removed from filename; names that
+// are not ASCII are sent as filename* (RFC 6266).
+func (c *Context) FileAttachment(file, filename string) {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": safeFilename(filename)})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	c.Writer.Header().Set("Content-Disposition", disposition)
+	c.File(file)
+}
+
+// safeFilename keeps only the last path element of name, without control
+// characters and quotes, so it cannot break the header or point to a directory.
+func safeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		if r == '\\' {
+			return '/'
+		}
+		return r
+	}, name)
+	name = path.Base(name)
+	if name == "." || name == "/" || name == ".." {
+		return "download"
+	}
+	return name
+}
+
+// serveFile writes the file name of fsys, or the index.html of a directory. It
+// reports false when there is no such file. Content-Type comes from the extension
+// or, failing that, from the first bytes of the file; Range and conditional
+// requests are handled by http.ServeContent.
+func serveFile(c *Context, fsys http.FileSystem, name string) bool {
+	// مسیر تمیز می‌شود تا ".." نتواند از ریشه خارج شود
+	name = path.Clean("/" + name)
+	f, err := fsys.Open(name)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.Logger().WarnContext(c.Req.Context(), "error opening static file", "path", name, "error", err)
+		}
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		index, err := fsys.Open(path.Join(name, "index.html"))
+		if err != nil {
+			return false
+		}
+		defer index.Close()
+		if info, err = index.Stat(); err != nil || info.IsDir() {
+			return false
+		}
+		f = index
+	}
+
+	http.ServeContent(&statusWriter{ResponseWriter: c.Writer, c: c}, c.Req, info.Name(), info.ModTime(), f)
+	return true
+}
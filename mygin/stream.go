@@ -0,0 +1,101 @@
+package mygin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SSEvent sends a Server-Sent Event and flushes it to the client:
+//
+//	c.SSEvent("progress", mygin.H{"done": 40})
+//
+// yields "event: progress\ndata: {"done":40}\n\n". Strings and byte slices are sent
+// as they are, one data line per line; other values are encoded as JSON with the engine's JSONEncoder. An empty
+// name sends an unnamed "message" event. The first event writes the
+// text/event-stream headers with status 200.
+func (c *Context) SSEvent(name string, data any) error {
+	c.checkFinished()
+	var payload string
+	switch v := data.(type) {
+	case string:
+		payload = v
+	case []byte:
+		payload = string(v)
+	default:
+		encoded, err := c.marshalJSON(v)
+		if err != nil {
+			return fmt.Errorf("error encoding event %s: %w", name, err)
+		}
+		payload = string(encoded)
+	}
+
+	c.startEventStream()
+	var buf bytes.Buffer
+	if name != "" {
+		buf.WriteString("event: " + strings.ReplaceAll(name, "\n", "") + "\n")
+	}
+	for _, line := range strings.Split(payload, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteByte('\n')
+	if _, err := c.Writer.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing event %s: %w", name, err)
+	}
+	return c.flush()
+}
+
+func (c *Context) startEventStream() {
+	if c.StatusCode != 0 {
+		return
+	}
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // nginx نباید رویدادها را بافر کند
+	c.Status(http.StatusOK)
+}
+
+// Stream calls step until it returns false or the client goes away, flushing
+// after every call, and reports whether the client went away:
+//
+//	c.Stream(func(w io.Writer) bool {
+//		select {
+//		case msg := <-feed:
+//			c.SSEvent("message", msg)
+//			return true
+//		case <-c.Req.Context().Done():
+//			return false
+//		}
+//	})
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	c.checkFinished()
+	done := c.Req.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+		}
+		keepOpen := step(c.Writer)
+		if err := c.flush(); err != nil {
+			return true
+		}
+		if !keepOpen {
+			return false
+		}
+	}
+}
+
+// flush sends buffered response data to the client.
+func (c *Context) flush() error {
+	err := http.NewResponseController(c.Writer).Flush()
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("error flushing response: %w", err)
+	}
+	return nil
+}
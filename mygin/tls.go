@@ -0,0 +1,102 @@
+package mygin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// AutoTLSConfig configures RunAutoTLS.
+type AutoTLSConfig struct {
+	// CacheDir keeps the account key and certificates across restarts, so they
+	// are not requested again and again from Let's Encrypt, which rate-limits
+	// issuance. Defaults to mygin-autocert in the user cache directory.
+	CacheDir string
+	// Email is the contact address of the ACME account for expiry notices.
+	Email string
+	// Addr is the HTTPS address (default ":443").
+	Addr string
+	// HTTPAddr answers the ACME HTTP-01 challenges and redirects other requests
+	// to HTTPS (default ":80"); "-" disables it, leaving the TLS-ALPN-01
+	// challenge on Addr.
+	HTTPAddr string
+}
+
+// RunTLS serves HTTPS on addr with the certificate and key in the PEM files
+// until Shutdown is called. See RunWithContext for graceful shutdown.
+func (engine *Engine) RunTLS(addr, certFile, keyFile string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", addr, err)
+	}
+	return engine.serve(context.Background(), engine.newServer(engine), l, func(srv *http.Server, l net.Listener) error {
+		return srv.ServeTLS(l, certFile, keyFile)
+	})
+}
+
+// RunAutoTLS serves HTTPS for domains with certificates obtained and renewed
+// from Let's Encrypt, until Shutdown is called:
+//
+//	r.AutoTLS = mygin.AutoTLSConfig{CacheDir: "/var/lib/photos/autocert", Email: "ops@example.com"}
+//	log.Fatal(r.RunAutoTLS("photos.example.com", "www.photos.example.com"))
+//
+// Requesting a certificate means accepting the Let's Encrypt terms of service.
+// Certificates are only requested for domains, which must resolve to this
+// server and, for the HTTP-01 challenge, reach it on port 80.
+func (engine *Engine) RunAutoTLS(domains ...string) error {
+	if len(domains) == 0 {
+		return errors.New("mygin: RunAutoTLS requires at least one domain")
+	}
+	config := engine.AutoTLS
+	if config.CacheDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("error finding cache directory for certificates: %w", err)
+		}
+		config.CacheDir = filepath.Join(dir, "mygin-autocert")
+	}
+	if config.Addr == "" {
+		config.Addr = ":443"
+	}
+	if config.HTTPAddr == "" {
+		config.HTTPAddr = ":80"
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(config.CacheDir),
+		Email:      config.Email,
+	}
+	l, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", config.Addr, err)
+	}
+	if config.HTTPAddr != "-" {
+		httpListener, err := net.Listen("tcp", config.HTTPAddr)
+		if err != nil {
+			l.Close()
+			return fmt.Errorf("error listening on %s: %w", config.HTTPAddr, err)
+		}
+		go func() {
+			// HTTPHandler درخواست‌های غیر از challenge را به HTTPS هدایت می‌کند
+			err := engine.serve(context.Background(), engine.newServer(manager.HTTPHandler(nil)), httpListener, (*http.Server).Serve)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				engine.log().Error("error serving ACME challenges", "addr", config.HTTPAddr, "error", err)
+			}
+		}()
+	}
+
+	srv := engine.newServer(engine)
+	srv.TLSConfig = manager.TLSConfig()
+	engine.log().Info("serving certificates from Let's Encrypt", "domains", domains, "cache", config.CacheDir)
+	return engine.serve(context.Background(), srv, l, func(srv *http.Server, l net.Listener) error {
+		return srv.ServeTLS(l, "", "")
+	})
+}
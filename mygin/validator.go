@@ -0,0 +1,72 @@
+package mygin
+
+import (
+	"fmt"
+
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// StructValidator validates the structs filled by the Bind methods.
+type StructValidator interface {
+	ValidateStruct(obj any) error
+}
+
+// DefaultValidator is used by every Bind method after decoding. Replace it to
+// plug in another validation engine, or set it to nil to disable validation.
+//
+// The default validator checks the rules of the binding and validate tags with
+// the validation package, so request structs and stored items share one rule
+// syntax:
+//
+//	type CreateAlbum struct {
+//		Title string `json:"title" binding:"required,min=3,max=100"`
+//	}
+//
+// Failures are validation.FieldErrors: validation.Fields returns the messages by
+// field and validation.Details the field, rule and param of each failure, e.g.
+// for a 422 response.
+var DefaultValidator StructValidator = defaultValidator{}
+
+type defaultValidator struct{}
+
+func (defaultValidator) ValidateStruct(obj any) error {
+	var failed validation.FieldErrors
+	seen := make(map[string]bool)
+	for _, tag := range []string{"binding", "validate"} {
+		err := validation.StructTag(obj, tag)
+		if err == nil {
+			continue
+		}
+		details, ok := err.(validation.FieldErrors)
+		if !ok {
+			return err
+		}
+		// هر فیلد فقط با اولین خطایش گزارش می‌شود
+		for _, fe := range details {
+			if !seen[fe.Field] {
+				seen[fe.Field] = true
+				failed = append(failed, fe)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
+
+// RegisterValidation adds a custom rule for binding and validate tags. fn gets the
+// field value and the rule argument, e.g. "3" for `binding:"maxwords=3"`:
+//
+//	mygin.RegisterValidation("notblank", func(value any, _ string) bool {
+//		s, ok := value.(string)
+//		return !ok || strings.TrimSpace(s) != ""
+//	})
+func RegisterValidation(name string, fn func(value any, param string) bool) {
+	validation.RegisterRule(name, func(value any, arg string) error {
+		if !fn(value, arg) {
+			return fmt.Errorf("failed the %s rule", name)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+package mygin
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// anyMethods are the methods Mount registers.
+var anyMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// WrapH adapts an http.Handler to a HandlerFunc. The status it writes is recorded in
+// Context.StatusCode so middleware such as metrics and tracing see it.
+func WrapH(h http.Handler) HandlerFunc {
+	return func(c *Context) {
+		h.ServeHTTP(&statusWriter{ResponseWriter: c.Writer, c: c}, c.Req)
+	}
+}
+
+// WrapF adapts an http.HandlerFunc to a HandlerFunc (see WrapH).
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return WrapH(f)
+}
+
+// Mount forwards every request for path and below it, with any method, to h, e.g.
+// to embed pprof, promhttp or an older mux:
+//
+//	r.Mount("/debug/pprof", http.HandlerFunc(pprof.Index), authMiddleware)
+//	r.Mount("/legacy", http.StripPrefix("/legacy", legacyMux))
+//
+// The request path is passed unchanged; wrap h with http.StripPrefix when it
+// expects paths relative to the mount point. Routes registered below path take
+// precedence over the mounted handler.
+func (group *RouterGroup) Mount(path string, h http.Handler, middleware ...HandlerFunc) {
+	handlers := append(append([]HandlerFunc{}, middleware...), WrapH(h))
+	group.Match(anyMethods, path, handlers...)
+	group.Match(anyMethods, joinPath(path, "/*path"), handlers...)
+}
+
+// joinPath appends a path that starts with '/' to base.
+func joinPath(base, path string) string {
+	if base != "" && base[len(base)-1] == '/' {
+		return base + path[1:]
+	}
+	return base + path
+}
+
+// statusWriter records the status written by a wrapped http.Handler.
+type statusWriter struct {
+	http.ResponseWriter
+	c *Context
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.c.StatusCode == 0 {
+		w.c.StatusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.c.StatusCode == 0 {
+		w.c.StatusCode = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers such as pprof's trace flush through the wrapper.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// headWriter discards the body written by GET handlers answering a HEAD request.
+// The header is held back until the handlers return so Content-Length can be set
+// from the size of the discarded body.
+type headWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+	sent   bool
+}
+
+func (w *headWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.size += len(b)
+	return len(b), nil
+}
+
+// Flush sends the header; the Content-Length is unknown at that point.
+func (w *headWriter) Flush() {
+	w.send(false)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish sends the held-back header after the handlers returned.
+func (w *headWriter) finish() {
+	w.send(true)
+}
+
+func (w *headWriter) send(withLength bool) {
+	if w.sent {
+		return
+	}
+	w.sent = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	header := w.ResponseWriter.Header()
+	if withLength && header.Get("Content-Length") == "" && w.status != http.StatusNoContent && w.status != http.StatusNotModified {
+		header.Set("Content-Length", strconv.Itoa(w.size))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
@@ -0,0 +1,365 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/events"
+)
+
+// Delivery statuses.
+const (
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+)
+
+// Delivery is a queued message. Sent deliveries are deleted from the queue;
+// deliveries that ran out of attempts stay with StatusFailed.
+type Delivery struct {
+	ID          uuid.UUID `json:"id"`
+	Channel     string    `json:"channel"`
+	Message     Message   `json:"message"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (d *Delivery) SetID(id uuid.UUID)       { d.ID = id }
+func (d *Delivery) GetID() uuid.UUID         { return d.ID }
+func (d *Delivery) SetCreatedAt(t time.Time) { d.CreatedAt = t }
+func (d *Delivery) SetUpdatedAt(t time.Time) { d.UpdatedAt = t }
+func (d *Delivery) GetRecordSize() int       { return 8192 }
+
+// Options configures a Dispatcher.
+type Options struct {
+	MaxAttempts  int           // default 5
+	MinBackoff   time.Duration // default 30s, doubled after every failed attempt
+	MaxBackoff   time.Duration // default 1h
+	PollInterval time.Duration // default 5s
+	SendTimeout  time.Duration // default 1m
+}
+
+func (o *Options) defaults() {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 30 * time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Hour
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 5 * time.Second
+	}
+	if o.SendTimeout <= 0 {
+		o.SendTimeout = time.Minute
+	}
+}
+
+// Dispatcher queues messages in a collection and sends them in the background
+// with the Sender registered for their channel, retrying failures with
+// exponential backoff. Queued messages survive restarts.
+type Dispatcher struct {
+	Templates *Templates
+
+	queue       *collection_manager_memory.Manager[*Delivery]
+	ownsQueue   bool
+	opts        Options
+	now         func() time.Time
+	mu          sync.RWMutex
+	senders     map[string]Sender
+	wake        chan struct{}
+	stop        chan struct{}
+	done        chan struct{}
+	started     atomic.Bool
+	startOnce   sync.Once
+	stopOnce    sync.Once
+	processing  sync.Mutex
+	unsubscribe []func()
+}
+
+// Open opens (or creates) the "notifications" queue collection in dir.
+func Open(dir string, opts Options) (*Dispatcher, error) {
+	queue, err := collection_manager_memory.New[*Delivery](dir, "notifications")
+	if err != nil {
+		return nil, fmt.Errorf("error opening notifications collection: %w", err)
+	}
+	d := NewDispatcher(queue, opts)
+	d.ownsQueue = true
+	return d, nil
+}
+
+// NewDispatcher returns a dispatcher using queue. Call Start to begin sending.
+func NewDispatcher(queue *collection_manager_memory.Manager[*Delivery], opts Options) *Dispatcher {
+	opts.defaults()
+	return &Dispatcher{
+		Templates: NewTemplates(),
+		queue:     queue,
+		opts:      opts,
+		now:       time.Now,
+		senders:   make(map[string]Sender),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Register sets the sender of channel, e.g. "email" or "webhook".
+func (d *Dispatcher) Register(channel string, sender Sender) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.senders[channel] = sender
+}
+
+// Enqueue stores msg for delivery over channel and returns the delivery ID.
+func (d *Dispatcher) Enqueue(ctx context.Context, channel string, msg Message) (uuid.UUID, error) {
+	if len(msg.To) == 0 {
+		return uuid.Nil, ErrNoRecipient
+	}
+	d.mu.RLock()
+	_, ok := d.senders[channel]
+	d.mu.RUnlock()
+	if !ok {
+		return uuid.Nil, fmt.Errorf("notify: no sender registered for channel %q", channel)
+	}
+
+	delivery, err := d.queue.CreateContext(ctx, &Delivery{
+		Channel:     channel,
+		Message:     msg,
+		Status:      StatusPending,
+		NextAttempt: d.now(),
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("error queueing notification: %w", err)
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return delivery.ID, nil
+}
+
+// Notify renders the template name with data and enqueues the result.
+func (d *Dispatcher) Notify(ctx context.Context, channel, template string, to []string, data any) (uuid.UUID, error) {
+	msg, err := d.Templates.Render(template, to, data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return d.Enqueue(ctx, channel, msg)
+}
+
+// Route is called for every event forwarded by Subscribe. It returns the channel,
+// template, recipients and template data of the notification, or ok false to skip.
+type Route func(e events.Event) (channel, template string, to []string, data any, ok bool)
+
+// Subscribe turns bus events matching pattern (e.g. "shares.create") into
+// notifications. Subscriptions end with Stop.
+func (d *Dispatcher) Subscribe(bus *events.Bus, pattern string, route Route) {
+	unsubscribe := bus.SubscribeFunc(pattern, 0, func(e events.Event) {
+		channel, template, to, data, ok := route(e)
+		if !ok {
+			return
+		}
+		if _, err := d.Notify(context.Background(), channel, template, to, data); err != nil {
+			logger.Error("error queueing notification", "topic", e.Topic, "template", template, "error", err)
+		}
+	})
+	d.mu.Lock()
+	d.unsubscribe = append(d.unsubscribe, unsubscribe)
+	d.mu.Unlock()
+}
+
+// Pending returns the queued deliveries, oldest first.
+func (d *Dispatcher) Pending() ([]*Delivery, error) {
+	return d.list(StatusPending)
+}
+
+// Failed returns the deliveries that ran out of attempts.
+func (d *Dispatcher) Failed() ([]*Delivery, error) {
+	return d.list(StatusFailed)
+}
+
+func (d *Dispatcher) list(status string) ([]*Delivery, error) {
+	all, err := d.queue.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	result := all[:0]
+	for _, delivery := range all {
+		if delivery.Status == status {
+			result = append(result, delivery)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+// Retry resets a failed delivery so it is sent again.
+func (d *Dispatcher) Retry(ctx context.Context, id uuid.UUID) error {
+	delivery, err := d.queue.ReadContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttempt = d.now()
+	if _, err := d.queue.UpdateContext(ctx, delivery); err != nil {
+		return fmt.Errorf("error updating notification: %w", err)
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start begins sending queued messages in the background.
+func (d *Dispatcher) Start() {
+	d.startOnce.Do(func() {
+		d.started.Store(true)
+		go d.loop()
+	})
+}
+
+// Stop ends event subscriptions and waits for the current delivery to finish.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		d.mu.Lock()
+		for _, unsubscribe := range d.unsubscribe {
+			unsubscribe()
+		}
+		d.unsubscribe = nil
+		d.mu.Unlock()
+
+		d.startOnce.Do(func() {}) // Start پس از Stop کاری انجام نمی‌دهد
+		close(d.stop)
+		if d.started.Load() {
+			<-d.done
+		}
+	})
+}
+
+// Close stops the dispatcher and closes the queue if it was opened by Open.
+func (d *Dispatcher) Close() error {
+	d.Stop()
+	if d.ownsQueue {
+		return d.queue.Close()
+	}
+	return nil
+}
+
+// Process sends every due delivery once and returns how many were sent. It is
+// called by the background loop and can be used as a scheduler job.
+func (d *Dispatcher) Process(ctx context.Context) (int, error) {
+	d.processing.Lock()
+	defer d.processing.Unlock()
+
+	pending, err := d.Pending()
+	if err != nil {
+		return 0, fmt.Errorf("error reading notification queue: %w", err)
+	}
+
+	sent := 0
+	var errs []error
+	for _, delivery := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		if d.now().Before(delivery.NextAttempt) {
+			continue
+		}
+		ok, err := d.deliver(ctx, delivery)
+		if ok {
+			sent++
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// deliver sends one delivery and updates or removes its record. A send failure is
+// recorded on the delivery; the returned error is a queue storage error.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery) (bool, error) {
+	d.mu.RLock()
+	sender, ok := d.senders[delivery.Channel]
+	d.mu.RUnlock()
+
+	var sendErr error
+	if !ok {
+		sendErr = fmt.Errorf("no sender registered for channel %q", delivery.Channel)
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, d.opts.SendTimeout)
+		sendErr = sender.Send(sendCtx, delivery.Message)
+		cancel()
+	}
+
+	if sendErr == nil {
+		if err := d.queue.DeleteContext(ctx, delivery.ID); err != nil {
+			return true, fmt.Errorf("error removing sent notification: %w", err)
+		}
+		return true, nil
+	}
+
+	delivery.Attempts++
+	delivery.LastError = sendErr.Error()
+	if delivery.Attempts >= d.opts.MaxAttempts {
+		delivery.Status = StatusFailed
+		logger.Error("notification failed", "id", delivery.ID, "channel", delivery.Channel, "attempts", delivery.Attempts, "error", sendErr)
+	} else {
+		delivery.NextAttempt = d.now().Add(d.backoff(delivery.Attempts))
+		logger.Warn("error sending notification, will retry", "id", delivery.ID, "channel", delivery.Channel, "attempts", delivery.Attempts, "error", sendErr)
+	}
+	if _, err := d.queue.UpdateContext(ctx, delivery); err != nil {
+		return false, fmt.Errorf("error updating notification: %w", err)
+	}
+	return false, nil
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.opts.MinBackoff
+	for i := 1; i < attempts && backoff < d.opts.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.opts.MaxBackoff)
+}
+
+func (d *Dispatcher) loop() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-d.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		if _, err := d.Process(ctx); err != nil {
+			logger.Error("error processing notification queue", "error", err)
+		}
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+// پکیج notify پیام‌های کاربر (دعوت به اشتراک، آماده شدن خروجی و ...) را از طریق
+// ایمیل یا webhook ارسال می‌کند. پیام‌ها ابتدا در صف ذخیره می‌شوند و Dispatcher
+// آن‌ها را با تلاش مجدد ارسال می‌کند.
+
+var logger = logging.For("notify")
+
+// ErrNoRecipient is returned when a message has no recipient.
+var ErrNoRecipient = errors.New("notify: message has no recipient")
+
+// Message is one notification. Text and HTML are alternative bodies; senders
+// that support only one use Text.
+type Message struct {
+	To      []string          `json:"to"`
+	Subject string            `json:"subject"`
+	Text    string            `json:"text,omitempty"`
+	HTML    string            `json:"html,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Sender delivers messages over one channel.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SenderFunc adapts a function to Sender.
+type SenderFunc func(ctx context.Context, msg Message) error
+
+func (f SenderFunc) Send(ctx context.Context, msg Message) error { return f(ctx, msg) }
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/events"
+)
+
+func TestTemplatesRender(t *testing.T) {
+	ts := NewTemplates()
+	err := ts.Add("share", Template{
+		Subject: "{{.Owner}} shared {{.Album}}",
+		Text:    "Open {{.URL}}",
+		HTML:    "<a href=\"{{.URL}}\">{{.Album}}</a>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := ts.Render("share", []string{"a@example.com"}, map[string]string{"Owner": "Sara", "Album": "<Trip>", "URL": "https://x/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Subject != "Sara shared <Trip>" || msg.Text != "Open https://x/1" {
+		t.Errorf("message = %+v", msg)
+	}
+	if !strings.Contains(msg.HTML, "&lt;Trip&gt;") {
+		t.Errorf("html not escaped: %s", msg.HTML)
+	}
+	if _, err := ts.Render("share", nil, map[string]string{}); err == nil {
+		t.Error("missing key did not fail")
+	}
+}
+
+func TestWebhookSenderSigns(t *testing.T) {
+	var gotSig string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get(SignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	sender := &WebhookSender{URL: server.URL, Secret: "s3cret"}
+	if err := sender.Send(context.Background(), Message{To: []string{"u1"}, Subject: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotSig != Sign("s3cret", body) {
+		t.Errorf("signature = %q", gotSig)
+	}
+}
+
+func TestBuildEmailAlternative(t *testing.T) {
+	data, err := BuildEmail("Iris <noreply@example.com>", Message{To: []string{"a@example.com"}, Subject: "سلام", Text: "plain", HTML: "<b>html</b>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := string(data)
+	for _, want := range []string{"multipart/alternative", "=?utf-8?q?", "text/plain", "text/html", "@example.com>"} {
+		if !strings.Contains(email, want) {
+			t.Errorf("email does not contain %q:\n%s", want, email)
+		}
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	d, err := Open(t.TempDir(), Options{MaxAttempts: 2, MinBackoff: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	fail := true
+	var sent []Message
+	d.Register("email", SenderFunc(func(ctx context.Context, msg Message) error {
+		if fail {
+			return errors.New("smtp down")
+		}
+		sent = append(sent, msg)
+		return nil
+	}))
+	if err := d.Templates.Add("ready", Template{Subject: "Export {{.}} ready", Text: "done"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := events.New()
+	defer bus.Close()
+	d.Subscribe(bus, "exports.ready", func(e events.Event) (string, string, []string, any, bool) {
+		return "email", "ready", []string{"a@example.com"}, e.Payload, true
+	})
+	bus.Publish("exports.ready", "photos.zip")
+	waitFor(t, func() bool { p, _ := d.Pending(); return len(p) == 1 })
+
+	if n, _ := d.Process(context.Background()); n != 0 {
+		t.Fatalf("sent %d while failing", n)
+	}
+	// still backing off
+	if n, _ := d.Process(context.Background()); n != 0 {
+		t.Fatalf("sent %d during backoff", n)
+	}
+
+	now = now.Add(time.Minute)
+	d.Process(context.Background())
+	failed, _ := d.Failed()
+	if len(failed) != 1 || failed[0].Attempts != 2 || failed[0].LastError != "smtp down" {
+		t.Fatalf("failed = %+v", failed)
+	}
+
+	fail = false
+	if err := d.Retry(context.Background(), failed[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.Process(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Process = %d, %v", n, err)
+	}
+	if len(sent) != 1 || sent[0].Subject != "Export photos.zip ready" {
+		t.Fatalf("sent = %+v", sent)
+	}
+	if p, _ := d.Pending(); len(p) != 0 {
+		t.Fatalf("queue not empty: %+v", p)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
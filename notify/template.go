@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sync"
+	"text/template"
+)
+
+// Template describes a message; every field is a Go template executed with the
+// data passed to Render. HTML is escaped with html/template.
+type Template struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type parsedTemplate struct {
+	subject, text *template.Template
+	html          *htmltemplate.Template
+}
+
+// Templates is a named set of message templates, safe for concurrent use.
+type Templates struct {
+	mu        sync.RWMutex
+	templates map[string]parsedTemplate
+}
+
+// NewTemplates returns an empty template set.
+func NewTemplates() *Templates {
+	return &Templates{templates: make(map[string]parsedTemplate)}
+}
+
+// Add parses t and stores it under name, replacing an existing template.
+func (ts *Templates) Add(name string, t Template) error {
+	var p parsedTemplate
+	var err error
+	if p.subject, err = template.New(name + ".subject").Option("missingkey=error").Parse(t.Subject); err != nil {
+		return fmt.Errorf("error parsing subject of template %q: %w", name, err)
+	}
+	if t.Text != "" {
+		if p.text, err = template.New(name + ".text").Option("missingkey=error").Parse(t.Text); err != nil {
+			return fmt.Errorf("error parsing text of template %q: %w", name, err)
+		}
+	}
+	if t.HTML != "" {
+		if p.html, err = htmltemplate.New(name + ".html").Option("missingkey=error").Parse(t.HTML); err != nil {
+			return fmt.Errorf("error parsing html of template %q: %w", name, err)
+		}
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.templates[name] = p
+	return nil
+}
+
+// Render executes the template name with data and returns the message for to.
+func (ts *Templates) Render(name string, to []string, data any) (Message, error) {
+	ts.mu.RLock()
+	p, ok := ts.templates[name]
+	ts.mu.RUnlock()
+	if !ok {
+		return Message{}, fmt.Errorf("notify: unknown template %q", name)
+	}
+
+	msg := Message{To: to}
+	var buf bytes.Buffer
+	if err := p.subject.Execute(&buf, data); err != nil {
+		return Message{}, fmt.Errorf("error rendering subject of template %q: %w", name, err)
+	}
+	msg.Subject = buf.String()
+	if p.text != nil {
+		buf.Reset()
+		if err := p.text.Execute(&buf, data); err != nil {
+			return Message{}, fmt.Errorf("error rendering text of template %q: %w", name, err)
+		}
+		msg.Text = buf.String()
+	}
+	if p.html != nil {
+		buf.Reset()
+		if err := p.html.Execute(&buf, data); err != nil {
+			return Message{}, fmt.Errorf("error rendering html of template %q: %w", name, err)
+		}
+		msg.HTML = buf.String()
+	}
+	return msg, nil
+}
@@ -0,0 +1,408 @@
+package object_storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+var logger = logging.For("object_storage")
+
+var _ collection_manager_memory.Storage = (*Storage)(nil)
+
+var ErrClosed = errors.New("storage is closed")
+
+// Options configures a Storage.
+type Options struct {
+	// SegmentSize is the size of new segments (default 1 MiB). An existing collection
+	// keeps the segment size stored in its manifest.
+	SegmentSize int
+	// CacheDir keeps downloaded segments on local disk so a restart only fetches the
+	// segments that changed. Empty disables the disk cache.
+	CacheDir string
+	// FlushInterval uploads changed segments in the background (default 1s). Writes
+	// newer than the last flush are lost on a crash; call Sync (or Manager.Sync) to
+	// make them durable. A negative value disables background flushing.
+	FlushInterval time.Duration
+	// Timeout bounds every request (default 30s).
+	Timeout time.Duration
+}
+
+type manifest struct {
+	Size        int64    `json:"size"`
+	SegmentSize int      `json:"segmentSize"`
+	Segments    []string `json:"segments"` // sha256 of each segment
+}
+
+// Storage is a collection_manager_memory.Storage kept in an S3 bucket:
+//
+//	client := &object_storage.Client{Endpoint: "http://localhost:9000", Region: "us-east-1",
+//		Bucket: "iris", AccessKey: key, SecretKey: secret, PathStyle: true}
+//	storage, err := object_storage.Open(client, "collections/photos", object_storage.Options{CacheDir: "cache"})
+//	photos, err := collection_manager_memory.NewWithStorage[*Photo](storage)
+//
+// Writes change segments in memory; changed segments are uploaded by Sync, by the
+// background flush and on Close. Only one process may open a key at a time.
+type Storage struct {
+	client *Client
+	key    string
+	opts   Options
+
+	mu       sync.Mutex
+	manifest manifest
+	segments map[int][]byte
+	dirty    map[int]bool
+	closed   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open loads the manifest of key, or starts an empty collection when there is none.
+func Open(client *Client, key string, opts Options) (*Storage, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = 1 << 20
+	}
+	if opts.FlushInterval == 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.CacheDir != "" {
+		if err := os.MkdirAll(opts.CacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating cache directory: %w", err)
+		}
+	}
+
+	s := &Storage{
+		client:   client,
+		key:      key,
+		opts:     opts,
+		manifest: manifest{SegmentSize: opts.SegmentSize},
+		segments: make(map[int][]byte),
+		dirty:    make(map[int]bool),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	ctx, cancel := s.context()
+	data, err := client.Get(ctx, s.manifestKey())
+	cancel()
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	default:
+		if err := json.Unmarshal(data, &s.manifest); err != nil {
+			return nil, fmt.Errorf("error decoding manifest: %w", err)
+		}
+		if s.manifest.SegmentSize <= 0 {
+			return nil, fmt.Errorf("invalid segment size %d in manifest", s.manifest.SegmentSize)
+		}
+	}
+
+	if opts.FlushInterval > 0 {
+		go s.flushLoop()
+	} else {
+		close(s.done)
+	}
+	return s, nil
+}
+
+func (s *Storage) manifestKey() string           { return s.key + "/manifest.json" }
+func (s *Storage) segmentKey(hash string) string { return s.key + "/segments/" + hash }
+
+func (s *Storage) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.opts.Timeout)
+}
+
+// Size returns the size of the data.
+func (s *Storage) Size() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	return s.manifest.Size, nil
+}
+
+// ReadAt implements io.ReaderAt.
+func (s *Storage) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("invalid offset %d", off)
+	}
+
+	n := 0
+	segmentSize := int64(s.manifest.SegmentSize)
+	for n < len(p) && off < s.manifest.Size {
+		index := int(off / segmentSize)
+		segment, err := s.load(index)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], segment[off%segmentSize:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt implements io.WriterAt; writing past the end grows the data with zeros.
+func (s *Storage) WriteAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("invalid offset %d", off)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	segmentSize := int64(s.manifest.SegmentSize)
+	end := off + int64(len(p))
+	newSize := max(s.manifest.Size, end)
+
+	// سگمنت‌های بین انتهای فعلی و off هم باید تا اندازه جدید پر شوند
+	first := int(min(off, s.manifest.Size) / segmentSize)
+	last := int((end - 1) / segmentSize)
+	for index := first; index <= last; index++ {
+		segment, err := s.load(index)
+		if err != nil {
+			return 0, err
+		}
+		length := int(min(segmentSize, newSize-int64(index)*segmentSize))
+		if len(segment) < length {
+			segment = append(segment, make([]byte, length-len(segment))...)
+		}
+
+		start := int64(index) * segmentSize
+		if from, to := max(off, start), min(end, start+segmentSize); from < to {
+			copy(segment[from-start:to-start], p[from-off:to-off])
+		}
+		s.segments[index] = segment
+		s.dirty[index] = true
+	}
+	s.manifest.Size = newSize
+	return len(p), nil
+}
+
+// load returns segment index from memory, the disk cache or the bucket. Segments
+// beyond the manifest are new and empty. Must be called with s.mu held.
+func (s *Storage) load(index int) ([]byte, error) {
+	if segment, ok := s.segments[index]; ok {
+		return segment, nil
+	}
+	if index >= len(s.manifest.Segments) {
+		return nil, nil
+	}
+
+	hash := s.manifest.Segments[index]
+	segment, err := s.fetch(hash)
+	if err != nil {
+		return nil, err
+	}
+	s.segments[index] = segment
+	return segment, nil
+}
+
+func (s *Storage) fetch(hash string) ([]byte, error) {
+	if s.opts.CacheDir != "" {
+		if data, err := os.ReadFile(filepath.Join(s.opts.CacheDir, hash)); err == nil && sha256Hex(data) == hash {
+			return data, nil
+		}
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+	data, err := s.client.Get(ctx, s.segmentKey(hash))
+	if err != nil {
+		return nil, fmt.Errorf("error reading segment: %w", err)
+	}
+	if sha256Hex(data) != hash {
+		return nil, fmt.Errorf("segment %s is corrupted", hash)
+	}
+	s.cache(hash, data)
+	return data, nil
+}
+
+func (s *Storage) cache(hash string, data []byte) {
+	if s.opts.CacheDir == "" {
+		return
+	}
+	path := filepath.Join(s.opts.CacheDir, hash)
+	if err := os.WriteFile(path+".tmp", data, 0644); err == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+// Sync uploads the changed segments and then the manifest.
+func (s *Storage) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return s.flush()
+}
+
+// flush must be called with s.mu held.
+func (s *Storage) flush() error {
+	if len(s.dirty) == 0 {
+		return nil
+	}
+
+	next := s.manifest
+	next.Segments = append([]string(nil), s.manifest.Segments...)
+	for index := range s.dirty {
+		for len(next.Segments) <= index {
+			next.Segments = append(next.Segments, "")
+		}
+		next.Segments[index] = sha256Hex(s.segments[index])
+	}
+	if err := s.publish(next); err != nil {
+		return err
+	}
+	clear(s.dirty)
+	return nil
+}
+
+// publish uploads the segments of next that are missing in the bucket, replaces the
+// manifest and deletes the segments no longer referenced. Must be called with s.mu
+// held and with every segment of next present in s.segments or in the bucket.
+func (s *Storage) publish(next manifest) error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	existing := make(map[string]bool, len(s.manifest.Segments))
+	for _, hash := range s.manifest.Segments {
+		existing[hash] = true
+	}
+	uploaded := make(map[string]bool)
+	for index, hash := range next.Segments {
+		if existing[hash] || uploaded[hash] {
+			continue
+		}
+		segment := s.segments[index]
+		if err := s.client.Put(ctx, s.segmentKey(hash), segment); err != nil {
+			return fmt.Errorf("error writing segment: %w", err)
+		}
+		s.cache(hash, segment)
+		uploaded[hash] = true
+	}
+
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Put(ctx, s.manifestKey(), data); err != nil {
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+
+	referenced := make(map[string]bool, len(next.Segments))
+	for _, hash := range next.Segments {
+		referenced[hash] = true
+	}
+	for hash := range existing {
+		if referenced[hash] {
+			continue
+		}
+		if err := s.client.Delete(ctx, s.segmentKey(hash)); err != nil {
+			logger.Warn("error deleting stale segment", "key", s.key, "segment", hash, "error", err)
+		}
+		if s.opts.CacheDir != "" {
+			os.Remove(filepath.Join(s.opts.CacheDir, hash))
+		}
+	}
+	s.manifest = next
+	return nil
+}
+
+// Replace swaps the whole content at once, e.g. when the collection is compacted.
+func (s *Storage) Replace(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("error reading new content: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+
+	segmentSize := s.manifest.SegmentSize
+	next := manifest{Size: int64(len(data)), SegmentSize: segmentSize}
+	segments := make(map[int][]byte)
+	for index := 0; index*segmentSize < len(data); index++ {
+		segment := bytes.Clone(data[index*segmentSize : min((index+1)*segmentSize, len(data))])
+		segments[index] = segment
+		next.Segments = append(next.Segments, sha256Hex(segment))
+	}
+
+	previous := s.segments
+	s.segments = segments
+	if err := s.publish(next); err != nil {
+		s.segments = previous
+		return err
+	}
+	clear(s.dirty)
+	return nil
+}
+
+// Close stops the background flush and uploads pending changes.
+func (s *Storage) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	err := s.flush()
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+	return err
+}
+
+func (s *Storage) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if !s.closed {
+				if err := s.flush(); err != nil {
+					logger.Error("error flushing segments", "key", s.key, "error", err)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+	"sync"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// SwaggerUIAssets is the base URL of the swagger-ui-dist files loaded by SwaggerUI.
+// Point it to a self-hosted copy for offline deployments.
+var SwaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5"
+
+// Handler serves the document of engine's routes as JSON. The document is built
+// on the first request and rebuilt when routes were added since.
+func Handler(engine *mygin.Engine, info Info, servers ...Server) mygin.HandlerFunc {
+	var mu sync.Mutex
+	var doc *Document
+	var built int
+	return func(c *mygin.Context) {
+		routes := engine.Routes()
+		mu.Lock()
+		if doc == nil || built != len(routes) {
+			doc, built = Generate(routes, info, servers...), len(routes)
+		}
+		current := doc
+		mu.Unlock()
+		c.JSON(http.StatusOK, current)
+	}
+}
+
+var swaggerPage = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Assets}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.Assets}}/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui", deepLinking: true});
+</script>
+</body>
+</html>
+`))
+
+// SwaggerUI serves a Swagger UI page for the document at specURL.
+func SwaggerUI(title, specURL string) mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		err := swaggerPage.Execute(c.Writer, map[string]string{"Title": title, "Assets": SwaggerUIAssets, "SpecURL": specURL})
+		if err != nil {
+			c.Abort()
+		}
+	}
+}
+
+// Mount serves the document at GET /openapi.json and, with ui set, Swagger UI at
+// GET /docs. Both routes are hidden from the document.
+func Mount(engine *mygin.Engine, info Info, ui bool, middleware ...mygin.HandlerFunc) {
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{}, middleware...), handler)
+	}
+	Describe(engine.GET("/openapi.json", with(Handler(engine, info))...), Docs{Hidden: true})
+	if ui {
+		Describe(engine.GET("/docs", with(SwaggerUI(info.Title, "/openapi.json"))...), Docs{Hidden: true})
+	}
+}
@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/request_schema"
+)
+
+// پکیج openapi از روی Engine.Routes و متادیتای مسیرها (اسکیمای request_schema،
+// structهای Query و Uri و توضیحات Describe) یک سند OpenAPI 3.1 می‌سازد.
+
+// DocsKey is the route metadata key of Docs.
+const DocsKey = "openapi.docs"
+
+// Docs describes an operation. Hidden operations are left out of the document.
+type Docs struct {
+	Summary     string
+	Description string
+	Tags        []string
+	OperationID string
+	Deprecated  bool
+	Hidden      bool
+}
+
+// Describe attaches docs to route:
+//
+//	openapi.Describe(api.GET("/albums/:id", read), openapi.Docs{Summary: "Get an album", Tags: []string{"albums"}})
+func Describe(route *mygin.Route, docs Docs) *mygin.Route {
+	return route.Meta(DocsKey, docs)
+}
+
+// Info is the info object of the document.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is an entry of the servers list.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document is an OpenAPI 3.1 document.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Servers    []Server                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components,omitempty"`
+}
+
+// Components holds the schemas of named Go types.
+type Components struct {
+	Schemas map[string]any `json:"schemas,omitempty"`
+}
+
+// Operation is one method of a path.
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   any    `json:"schema"`
+}
+
+// RequestBody is a JSON request body.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body.
+type MediaType struct {
+	Schema any `json:"schema"`
+}
+
+// paramPattern matches :name, :name<constraint> and *name segments.
+var paramPattern = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)(?:<([^/]*)>)?`)
+
+// paramSchema describes a path param, using its mygin constraint when it has one.
+func paramSchema(constraint string) map[string]any {
+	switch constraint {
+	case "":
+		return map[string]any{"type": "string"}
+	case "int":
+		return map[string]any{"type": "integer"}
+	case "uint":
+		return map[string]any{"type": "integer", "minimum": 0}
+	case "uuid":
+		return map[string]any{"type": "string", "format": "uuid"}
+	case "alpha":
+		return map[string]any{"type": "string", "pattern": "^[A-Za-z]+$"}
+	case "alnum":
+		return map[string]any{"type": "string", "pattern": "^[A-Za-z0-9]+$"}
+	case "slug":
+		return map[string]any{"type": "string", "pattern": "^[a-z0-9]+(?:-[a-z0-9]+)*$"}
+	}
+	if _, err := regexp.Compile(constraint); err != nil {
+		// constraint ثبت‌شده با RegisterConstraint
+		return map[string]any{"type": "string"}
+	}
+	return map[string]any{"type": "string", "pattern": "^(?:" + constraint + ")$"}
+}
+
+// Generate builds the document of routes.
+func Generate(routes mygin.RoutesInfo, info Info, servers ...Server) *Document {
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Servers: servers,
+		Paths:   make(map[string]map[string]*Operation),
+	}
+	schemas := newSchemaSet()
+
+	for _, route := range routes {
+		docs, _ := route.Meta[DocsKey].(Docs)
+		if docs.Hidden {
+			continue
+		}
+		path := paramPattern.ReplaceAllString(route.Path, "{$1}")
+		op := &Operation{
+			OperationID: docs.OperationID,
+			Summary:     docs.Summary,
+			Description: docs.Description,
+			Tags:        docs.Tags,
+			Deprecated:  docs.Deprecated,
+			Responses:   make(map[string]*Response),
+		}
+		if op.OperationID == "" {
+			op.OperationID = operationID(route.Method, route.Path)
+		}
+		for _, match := range paramPattern.FindAllStringSubmatch(route.Path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: paramSchema(match[2])})
+		}
+		if structs, ok := route.Meta[ParamsKey].([]paramStruct); ok {
+			schemas.mergeParams(op, structs)
+			op.Responses["400"] = &Response{Description: "Invalid parameters"}
+		}
+
+		if body, ok := route.Meta[request_schema.BodyKey].(*request_schema.Schema); ok && body != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: schemas.of(body)}}}
+			op.Responses["400"] = &Response{Description: "Invalid body"}
+			op.Responses["422"] = &Response{Description: "Validation failed"}
+		}
+		if responses, ok := route.Meta[request_schema.ResponsesKey].(request_schema.Responses); ok {
+			for status, s := range responses {
+				resp := &Response{Description: http.StatusText(status)}
+				if s != nil {
+					resp.Content = map[string]MediaType{"application/json": {Schema: schemas.of(s)}}
+				}
+				op.Responses[strconv.Itoa(status)] = resp
+			}
+		}
+		if !hasSuccess(op.Responses) {
+			op.Responses["default"] = &Response{Description: "Response"}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	if len(schemas.components) > 0 {
+		doc.Components.Schemas = schemas.components
+	}
+	return doc
+}
+
+func hasSuccess(responses map[string]*Response) bool {
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			return true
+		}
+	}
+	return false
+}
+
+// operationID derives an ID such as "getUsersById" from method and path.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '_' || r == '.' }) {
+		if part[0] == ':' || part[0] == '*' {
+			b.WriteString("By")
+			part = part[1:]
+			if i := strings.IndexByte(part, '<'); i >= 0 {
+				part = part[:i]
+			}
+		}
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
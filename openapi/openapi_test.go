@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/request_schema"
+)
+
+type albumInput struct {
+	Title string   `json:"title" validate:"required,max=100"`
+	Kind  string   `json:"kind" validate:"oneof=photo video"`
+	Tags  []string `json:"tags,omitempty" validate:"max=10"`
+}
+
+type album struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	Cover     *album    `json:"cover,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	secret    string
+}
+
+func TestGenerate(t *testing.T) {
+	engine := mygin.New()
+	handler := func(c *mygin.Context) {}
+	api := engine.Group("/api")
+	request_schema.Response(
+		request_schema.Body(api.POST("/albums", handler), request_schema.Of[albumInput]()),
+		http.StatusCreated, request_schema.Of[album]())
+	Describe(request_schema.Response(api.GET("/albums/:id<uuid>", handler), http.StatusOK, request_schema.Of[album]()),
+		Docs{Summary: "Get an album", Tags: []string{"albums"}})
+	api.DELETE("/albums/:id", handler)
+	Mount(engine, Info{Title: "Iris", Version: "1.0"}, true)
+
+	doc := Generate(engine.Routes(), Info{Title: "Iris", Version: "1.0"})
+	if _, ok := doc.Paths["/openapi.json"]; ok {
+		t.Error("hidden route documented")
+	}
+
+	get := doc.Paths["/api/albums/{id}"]["get"]
+	if get == nil || get.Summary != "Get an album" || get.OperationID != "getApiAlbumsById" {
+		t.Fatalf("get operation = %+v", get)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || get.Parameters[0].In != "path" || get.Parameters[0].Schema.(map[string]any)["format"] != "uuid" {
+		t.Errorf("parameters = %+v", get.Parameters)
+	}
+	if doc.Paths["/api/albums/{id}"]["delete"].Responses["default"] == nil {
+		t.Error("delete has no default response")
+	}
+
+	post := doc.Paths["/api/albums"]["post"]
+	if post.RequestBody == nil || post.Responses["201"] == nil || post.Responses["422"] == nil {
+		t.Fatalf("post operation = %+v", post)
+	}
+
+	input, _ := doc.Components.Schemas["albumInput"].(map[string]any)
+	if required, _ := input["required"].([]string); len(required) != 1 || required[0] != "title" {
+		t.Errorf("albumInput required = %v", input["required"])
+	}
+	props := input["properties"].(map[string]any)
+	if title := props["title"].(map[string]any); title["maxLength"] != float64(100) {
+		t.Errorf("title = %v", title)
+	}
+	if kind := props["kind"].(map[string]any); len(kind["enum"].([]any)) != 2 {
+		t.Errorf("kind = %v", kind)
+	}
+
+	albumSchema := doc.Components.Schemas["album"].(map[string]any)["properties"].(map[string]any)
+	if albumSchema["cover"].(map[string]any)["$ref"] != "#/components/schemas/album" {
+		t.Errorf("cover = %v", albumSchema["cover"])
+	}
+	if albumSchema["id"].(map[string]any)["format"] != "uuid" || albumSchema["secret"] != nil {
+		t.Errorf("album properties = %v", albumSchema)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var served map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil || served["openapi"] != "3.1.0" {
+		t.Fatalf("served document: %v %s", err, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if !strings.Contains(w.Body.String(), "SwaggerUIBundle") {
+		t.Errorf("docs page: %s", w.Body)
+	}
+}
+
+type albumFilter struct {
+	Query string        `form:"q" validate:"required"`
+	Tags  []string      `form:"tag,default=new;shared"`
+	Limit int           `form:"limit,default=50" validate:"max=100"`
+	Since time.Time     `form:"since" time_format:"unix"`
+	Wait  time.Duration `form:"wait"`
+	Page  struct {
+		Cursor string `form:"cursor"`
+	}
+	internal bool
+}
+
+type albumParams struct {
+	ID    uuid.UUID `uri:"id"`
+	Page  int       `uri:"page" validate:"min=1"`
+	Extra string    `uri:"extra"`
+}
+
+func TestGenerateParams(t *testing.T) {
+	engine := mygin.New()
+	handler := func(c *mygin.Context) {}
+	Query[albumFilter](engine.GET("/albums", handler))
+	Uri[albumParams](engine.GET("/albums/:id/pages/:page", handler))
+
+	doc := Generate(engine.Routes(), Info{Title: "Iris", Version: "1.0"})
+	list := doc.Paths["/albums"]["get"]
+	params := map[string]Parameter{}
+	for _, p := range list.Parameters {
+		params[p.Name] = p
+	}
+	if len(params) != 6 || params["internal"].Name != "" || params["cursor"].In != "query" {
+		t.Fatalf("query parameters = %+v", list.Parameters)
+	}
+	if q := params["q"]; !q.Required || q.Schema.(map[string]any)["type"] != "string" {
+		t.Errorf("q = %+v", q)
+	}
+	limit := params["limit"].Schema.(map[string]any)
+	if params["limit"].Required || limit["type"] != "integer" || limit["maximum"] != float64(100) || limit["default"] != float64(50) {
+		t.Errorf("limit = %+v", limit)
+	}
+	if tags := params["tag"].Schema.(map[string]any); tags["type"] != "array" || len(tags["default"].([]any)) != 2 {
+		t.Errorf("tag = %+v", tags)
+	}
+	if since := params["since"].Schema.(map[string]any); since["type"] != "integer" {
+		t.Errorf("since = %+v", since)
+	}
+	if wait := params["wait"].Schema.(map[string]any); wait["type"] != "string" {
+		t.Errorf("wait = %+v", wait)
+	}
+	if list.Responses["400"] == nil {
+		t.Error("no 400 response")
+	}
+
+	pages := doc.Paths["/albums/{id}/pages/{page}"]["get"]
+	if len(pages.Parameters) != 2 {
+		t.Fatalf("path parameters = %+v", pages.Parameters)
+	}
+	if id := pages.Parameters[0]; id.Name != "id" || id.Schema.(map[string]any)["format"] != "uuid" {
+		t.Errorf("id = %+v", id)
+	}
+	if page := pages.Parameters[1]; !page.Required || page.Schema.(map[string]any)["minimum"] != float64(1) {
+		t.Errorf("page = %+v", page)
+	}
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// ParamsKey is the route metadata key of the structs set with Query and Uri.
+const ParamsKey = "openapi.params"
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	fileHeaderType      = reflect.TypeOf(multipart.FileHeader{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// paramStruct is a struct bound from the parameters in In, keyed by Tag.
+type paramStruct struct {
+	In   string
+	Tag  string
+	Type reflect.Type
+}
+
+// Query documents the query parameters of route with the form tags of T, the
+// struct its handler binds with Context.BindQuery:
+//
+//	type AlbumFilter struct {
+//		Query string   `form:"q"`
+//		Tags  []string `form:"tag"`
+//		Limit int      `form:"limit,default=50" validate:"max=100"`
+//	}
+//	openapi.Query[AlbumFilter](api.GET("/albums", list))
+//
+// Types, defaults and validate rules become the parameter schemas; fields with a
+// required rule are required parameters.
+func Query[T any](route *mygin.Route) *mygin.Route {
+	return addParams(route, paramStruct{In: "query", Tag: "form", Type: reflect.TypeFor[T]()})
+}
+
+// Uri documents the path parameters of route with the uri tags of T, the struct
+// its handler binds with Context.BindUri, replacing the string schemas derived
+// from the path.
+func Uri[T any](route *mygin.Route) *mygin.Route {
+	return addParams(route, paramStruct{In: "path", Tag: "uri", Type: reflect.TypeFor[T]()})
+}
+
+func addParams(route *mygin.Route, p paramStruct) *mygin.Route {
+	for p.Type.Kind() == reflect.Ptr {
+		p.Type = p.Type.Elem()
+	}
+	if p.Type.Kind() != reflect.Struct {
+		panic("openapi: parameters of " + route.Path + " must be a struct, got " + p.Type.String())
+	}
+	var params []paramStruct
+	if existing, ok := route.Get(ParamsKey); ok {
+		params = append(params, existing.([]paramStruct)...)
+	}
+	return route.Meta(ParamsKey, append(params, p))
+}
+
+// structParams returns the parameters of the fields of t, mapped like
+// binding.MapForm: nested structs without a tag share the parameters.
+func (s *schemaSet) structParams(t reflect.Type, in, tag string) []Parameter {
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value, tagged := field.Tag.Lookup(tag)
+		if value == "-" || !field.IsExported() {
+			continue
+		}
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if !tagged && isNestedStruct(ft) {
+			params = append(params, s.structParams(ft, in, tag)...)
+			continue
+		}
+		if ft == fileHeaderType || (ft.Kind() == reflect.Slice && ft.Elem() == reflect.PointerTo(fileHeaderType)) {
+			continue
+		}
+		name, _, _ := strings.Cut(value, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := s.paramTypeSchema(field.Type, field.Tag.Get("time_format"))
+		required := applyValidateTag(schema, field.Tag.Get("validate"))
+		if raw, ok := tagDefault(value); ok {
+			schema["default"] = defaultValue(schema, raw)
+		}
+		params = append(params, Parameter{Name: name, In: in, Required: required || in == "path", Schema: schema})
+	}
+	return params
+}
+
+// paramTypeSchema is typeSchema for the text forms binding.MapForm parses.
+func (s *schemaSet) paramTypeSchema(t reflect.Type, timeFormat string) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType:
+		return map[string]any{"type": "string", "examples": []string{"1m30s"}}
+	case t == timeType && (timeFormat == "unix" || timeFormat == "unixmilli"):
+		return map[string]any{"type": "integer"}
+	case t == timeType && timeFormat != "":
+		return map[string]any{"type": "string", "description": "layout " + timeFormat}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		return map[string]any{"type": "array", "items": s.paramTypeSchema(t.Elem(), timeFormat)}
+	case t != timeType && reflect.PointerTo(t).Implements(textUnmarshalerType):
+		schema := s.typeSchema(t)
+		if schema["$ref"] != nil || len(schema) == 0 {
+			return map[string]any{"type": "string"}
+		}
+		return schema
+	}
+	return s.typeSchema(t)
+}
+
+// defaultValue converts a default option to the type of schema; slice defaults
+// are separated by ';'.
+func defaultValue(schema map[string]any, raw string) any {
+	if schema["type"] == "array" {
+		items, _ := schema["items"].(map[string]any)
+		var values []any
+		for _, part := range strings.Split(raw, ";") {
+			values = append(values, defaultValue(items, part))
+		}
+		return values
+	}
+	switch schema["type"] {
+	case "integer", "number", "boolean":
+		var v any
+		if json.Unmarshal([]byte(raw), &v) == nil {
+			return v
+		}
+	}
+	return raw
+}
+
+// isNestedStruct reports whether MapForm maps the fields of t rather than t
+// itself, as for time.Time and uuid.UUID.
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// tagDefault returns the value of the default option of a tag, like binding does.
+func tagDefault(tag string) (string, bool) {
+	_, options, _ := strings.Cut(tag, ",")
+	for options != "" {
+		if value, ok := strings.CutPrefix(options, "default="); ok {
+			return value, true
+		}
+		_, options, _ = strings.Cut(options, ",")
+	}
+	return "", false
+}
+
+// mergeParams adds the parameters of the structs set with Query and Uri to op.
+// A struct parameter replaces a path parameter of the same name; struct fields
+// missing from the path are left out.
+func (s *schemaSet) mergeParams(op *Operation, structs []paramStruct) {
+	for _, p := range structs {
+		for _, param := range s.structParams(p.Type, p.In, p.Tag) {
+			i := indexOfParam(op.Parameters, param.Name, param.In)
+			switch {
+			case i >= 0:
+				op.Parameters[i] = param
+			case p.In != "path":
+				op.Parameters = append(op.Parameters, param)
+			}
+		}
+	}
+}
+
+func indexOfParam(params []Parameter, name, in string) int {
+	for i, param := range params {
+		if param.Name == name && param.In == in {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/request_schema"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// schemaSet converts schemas and collects named struct types as components.
+type schemaSet struct {
+	components map[string]any
+	names      map[reflect.Type]string
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{components: make(map[string]any), names: make(map[reflect.Type]string)}
+}
+
+func (s *schemaSet) of(schema *request_schema.Schema) any {
+	if schema.JSON != nil {
+		return schema.JSON
+	}
+	return s.typeSchema(schema.Type)
+}
+
+// typeSchema returns the JSON schema of t; named structs become $ref to components.
+func (s *schemaSet) typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case uuidType:
+		return map[string]any{"type": "string", "format": "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": s.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + s.component(t)}
+	}
+	return map[string]any{} // interface و انواع دیگر: هر مقداری مجاز است
+}
+
+// component registers the struct t under a unique name and returns it.
+func (s *schemaSet) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for i := 2; s.components[name] != nil; i++ {
+		name = t.Name() + strconv.Itoa(i)
+	}
+	s.names[t] = name
+	s.components[name] = map[string]any{} // جلوگیری از بازگشت بی‌پایان برای انواع خودارجاع
+	s.components[name] = s.structSchema(t)
+	return name
+}
+
+func (s *schemaSet) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	s.addFields(t, properties, &required)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (s *schemaSet) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(ft, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := s.typeSchema(field.Type)
+		if applyValidateTag(prop, field.Tag.Get("validate")) {
+			*required = append(*required, name)
+		}
+		properties[name] = prop
+	}
+}
+
+// applyValidateTag maps the validation package rules to JSON schema keywords and
+// reports whether the field is required.
+func applyValidateTag(prop map[string]any, tag string) bool {
+	if tag == "" || prop["$ref"] != nil {
+		return strings.Contains(tag, "required")
+	}
+	required := false
+	for _, part := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		n, _ := strconv.ParseFloat(arg, 64)
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			prop["format"] = "email"
+		case "uuid":
+			prop["format"] = "uuid"
+		case "oneof":
+			var values []any
+			for _, v := range strings.Fields(arg) {
+				values = append(values, v)
+			}
+			prop["enum"] = values
+		case "min", "max", "len":
+			for _, keyword := range boundKeywords(prop["type"], name) {
+				prop[keyword] = n
+			}
+		}
+	}
+	return required
+}
+
+func boundKeywords(typ any, rule string) []string {
+	var min, max string
+	switch typ {
+	case "string":
+		min, max = "minLength", "maxLength"
+	case "array":
+		min, max = "minItems", "maxItems"
+	case "object":
+		min, max = "minProperties", "maxProperties"
+	case "integer", "number":
+		min, max = "minimum", "maximum"
+	default:
+		return nil
+	}
+	switch rule {
+	case "min":
+		return []string{min}
+	case "max":
+		return []string{max}
+	}
+	return []string{min, max}
+}
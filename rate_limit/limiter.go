@@ -0,0 +1,194 @@
+package rate_limit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/scheduler"
+)
+
+var logger = logging.For("rate_limit")
+
+// Limit allows Requests per Per on average with bursts of up to Burst requests
+// (token bucket). Burst defaults to Requests.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(max(l.Requests, 1))
+}
+
+// rate returns the refill rate in tokens per second.
+func (l Limit) rate() float64 {
+	if l.Requests <= 0 || l.Per <= 0 {
+		return 0
+	}
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the outcome of one Take.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed; zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the bucket of every key. MemoryStore is local to the process,
+// CollectionStore persists buckets so limits survive restarts. A store shared
+// between instances only has to implement Take atomically per key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the token bucket state of one key. It expires once it has refilled
+// completely, because a full bucket is the same as no bucket at all.
+type bucket struct {
+	id      uuid.UUID // رکورد ذخیره شده در CollectionStore
+	tokens  float64
+	last    time.Time
+	expires time.Time
+	dirty   bool
+}
+
+// take refills b up to now and consumes one token if possible.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	burst, rate := limit.burst(), limit.rate()
+	if b.last.IsZero() || !now.Before(b.expires) {
+		b.tokens = burst
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rate)
+	}
+	b.last = now
+	b.dirty = true
+
+	result := Result{Limit: int(burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else if rate > 0 {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	} else {
+		result.RetryAfter = time.Duration(math.MaxInt64)
+	}
+	result.Remaining = int(b.tokens)
+
+	if rate > 0 {
+		result.Reset = seconds((burst - b.tokens) / rate)
+		b.expires = now.Add(result.Reset)
+	} else {
+		b.expires = never // بدون نرخ، سطل هیچ‌وقت پر نمی‌شود
+	}
+	return result
+}
+
+// never is used as expiry of buckets that do not refill.
+var never = time.Unix(1<<40, 0)
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// table is the in-memory bucket map shared by both stores.
+type table struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func newTable() *table {
+	return &table{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (t *table) take(key string, limit Limit) (Result, *bucket) {
+	b := t.buckets[key]
+	if b == nil {
+		b = &bucket{}
+		t.buckets[key] = b
+	}
+	return b.take(limit, t.now()), b
+}
+
+// MemoryStore keeps buckets in memory. Expired buckets are removed by Prune.
+type MemoryStore struct {
+	t *table
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{t: newTable()}
+}
+
+// Take consumes one request of key.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	result, _ := s.t.take(key, limit)
+	return result, nil
+}
+
+// Prune removes buckets that have refilled completely and returns their number.
+func (s *MemoryStore) Prune() int {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	now, removed := s.t.now(), 0
+	for key, b := range s.t.buckets {
+		if !now.Before(b.expires) {
+			delete(s.t.buckets, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of tracked keys.
+func (s *MemoryStore) Len() int {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	return len(s.t.buckets)
+}
+
+// Job returns Prune as a scheduler job.
+func (s *MemoryStore) Job() scheduler.Job {
+	return func(ctx context.Context) error {
+		s.Prune()
+		return nil
+	}
+}
+
+// Dynamic holds a Limit that can be changed while a middleware uses it, e.g. from
+// runtime configuration.
+type Dynamic struct {
+	limit atomic.Pointer[Limit]
+}
+
+// NewDynamic returns a Dynamic starting at limit.
+func NewDynamic(limit Limit) *Dynamic {
+	d := &Dynamic{}
+	d.Set(limit)
+	return d
+}
+
+// Set replaces the limit. Existing buckets keep their tokens and refill at the new rate.
+func (d *Dynamic) Set(limit Limit) {
+	d.limit.Store(&limit)
+}
+
+// Get returns the current limit.
+func (d *Dynamic) Get() Limit {
+	return *d.limit.Load()
+}
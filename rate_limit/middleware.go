@@ -0,0 +1,167 @@
+package rate_limit
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/tokens"
+)
+
+// KeyFunc returns the key a request is limited by, or "" to skip limiting.
+type KeyFunc func(c *mygin.Context) string
+
+// ByIP limits by the remote address of the connection.
+func ByIP() KeyFunc {
+	return func(c *mygin.Context) string {
+		host, _, err := net.SplitHostPort(c.Req.RemoteAddr)
+		if err != nil {
+			return "ip:" + c.Req.RemoteAddr
+		}
+		return "ip:" + host
+	}
+}
+
+// ByHeader limits by a request header. The header is not authenticated, so a
+// client can get a new bucket for every value it sends; use ByAPIKey for API keys.
+func ByHeader(name string) KeyFunc {
+	return func(c *mygin.Context) string {
+		if value := c.GetHeader(name); value != "" {
+			return "header:" + name + ":" + value
+		}
+		return ""
+	}
+}
+
+// ByAPIKey limits by the API key authenticated by tokens.Middleware, which must
+// run before the rate limit. Requests without an authenticated key are skipped.
+func ByAPIKey() KeyFunc {
+	return func(c *mygin.Context) string {
+		if key, ok := tokens.CurrentKey(c); ok {
+			return "key:" + key.ID.String()
+		}
+		return ""
+	}
+}
+
+// FirstOf limits by the first non-empty key, e.g. by API key for clients that
+// authenticated with one and by IP for the others:
+//
+//	rate_limit.FirstOf(rate_limit.ByAPIKey(), rate_limit.ByIP())
+func FirstOf(keys ...KeyFunc) KeyFunc {
+	return func(c *mygin.Context) string {
+		for _, key := range keys {
+			if k := key(c); k != "" {
+				return k
+			}
+		}
+		return ""
+	}
+}
+
+// Option configures RateLimit.
+type Option func(*config)
+
+type config struct {
+	store Store
+	key   KeyFunc
+	burst int
+}
+
+// WithStore keeps the buckets in store, e.g. a CollectionStore or a store shared
+// between instances, instead of a MemoryStore.
+func WithStore(store Store) Option {
+	return func(c *config) { c.store = store }
+}
+
+// WithKey limits by key instead of the client IP.
+func WithKey(key KeyFunc) Option {
+	return func(c *config) { c.key = key }
+}
+
+// WithBurst allows bursts of up to burst requests (default the limit itself).
+func WithBurst(burst int) Option {
+	return func(c *config) { c.burst = burst }
+}
+
+// RateLimit is Middleware allowing limit requests per window for each client IP,
+// with the buckets kept in a MemoryStore that is pruned every window:
+//
+//	engine.Use(rate_limit.RateLimit(100, time.Minute))
+//	api.Use(tokens.Middleware(keys), rate_limit.RateLimit(1000, time.Hour,
+//		rate_limit.WithKey(rate_limit.ByAPIKey()),
+//		rate_limit.WithStore(store)))
+func RateLimit(limit int, window time.Duration, opts ...Option) mygin.HandlerFunc {
+	cfg := config{key: ByIP()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.store == nil {
+		cfg.store = &prunedStore{MemoryStore: NewMemoryStore(), every: window}
+	}
+	return Middleware(cfg.store, Limit{Requests: limit, Per: window, Burst: cfg.burst}, cfg.key)
+}
+
+// prunedStore prunes its MemoryStore while taking tokens, so RateLimit does not
+// need a scheduler job.
+type prunedStore struct {
+	*MemoryStore
+	every time.Duration
+	next  atomic.Int64 // unix nano of the next prune
+}
+
+func (s *prunedStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := s.t.now().UnixNano()
+	if next := s.next.Load(); now >= next && s.next.CompareAndSwap(next, now+int64(s.every)) {
+		s.Prune()
+	}
+	return s.MemoryStore.Take(ctx, key, limit)
+}
+
+// Middleware rejects requests over limit with 429. Every response carries the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (seconds)
+// headers, rejected ones also Retry-After. Store errors let the request through.
+func Middleware(store Store, limit Limit, key KeyFunc) mygin.HandlerFunc {
+	return DynamicMiddleware(store, NewDynamic(limit), key)
+}
+
+// DynamicMiddleware is Middleware with a limit that can be changed at runtime.
+func DynamicMiddleware(store Store, limit *Dynamic, key KeyFunc) mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+
+		result, err := store.Take(c.Req.Context(), k, limit.Get())
+		if err != nil {
+			logger.Error("error taking rate limit token", "key", k, "error", err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		if !result.Allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, mygin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d > math.MaxInt64-time.Second {
+		return int(d / time.Second)
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,128 @@
+package rate_limit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/tokens"
+)
+
+func TestMemoryStoreRefill(t *testing.T) {
+	s := NewMemoryStore()
+	now := time.Unix(1000, 0)
+	s.t.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Per: time.Second}
+
+	for i := 0; i < 2; i++ {
+		if r, _ := s.Take(context.Background(), "a", limit); !r.Allowed {
+			t.Fatalf("request %d denied", i)
+		}
+	}
+	r, _ := s.Take(context.Background(), "a", limit)
+	if r.Allowed || r.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("third request: %+v", r)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if r, _ := s.Take(context.Background(), "a", limit); !r.Allowed {
+		t.Fatalf("request after refill denied: %+v", r)
+	}
+
+	now = now.Add(time.Hour)
+	if removed := s.Prune(); removed != 1 || s.Len() != 0 {
+		t.Fatalf("Prune removed %d, %d left", removed, s.Len())
+	}
+}
+
+func TestCollectionStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	limit := Limit{Requests: 3, Per: time.Hour}
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		s.Take(context.Background(), "client", limit)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(dir, Options{FlushInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if r, _ := s.Take(context.Background(), "client", limit); r.Allowed {
+		t.Fatalf("limit was reset by restart: %+v", r)
+	}
+	if r, _ := s.Take(context.Background(), "other", limit); !r.Allowed || r.Remaining != 2 {
+		t.Fatalf("other key: %+v", r)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	engine := mygin.New()
+	engine.Use(Middleware(NewMemoryStore(), Limit{Requests: 1, Per: time.Minute}, ByIP()))
+	engine.GET("/ping", func(c *mygin.Context) { c.String(http.StatusOK, "pong") })
+
+	codes := make([]int, 2)
+	var last *httptest.ResponseRecorder
+	for i := range codes {
+		last = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		engine.ServeHTTP(last, req)
+		codes[i] = last.Code
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Fatalf("codes = %v", codes)
+	}
+	if got := last.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q", got)
+	}
+	if got := last.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %q", got)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	engine := mygin.New()
+	// به جای tokens.Middleware فقط key-a معتبر است
+	keyA := &tokens.Key{ID: uuid.New()}
+	engine.Use(func(c *mygin.Context) {
+		if c.GetHeader("X-API-Key") == "key-a" {
+			c.Set(tokens.KeyContextKey, keyA)
+		}
+	})
+	engine.Use(RateLimit(2, time.Minute, WithKey(FirstOf(ByAPIKey(), ByIP()))))
+	engine.GET("/ping", func(c *mygin.Context) { c.String(http.StatusOK, "pong") })
+
+	send := func(remoteAddr, apiKey string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	got := []int{
+		send("10.0.0.1:1", ""), send("10.0.0.1:2", ""), send("10.0.0.1:3", ""),
+		send("10.0.0.1:4", "key-a"), send("10.0.0.1:5", "forged"), send("10.0.0.2:1", ""),
+	}
+	want := []int{200, 200, 429, 200, 429, 200}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("codes = %v, want %v", got, want)
+		}
+	}
+}
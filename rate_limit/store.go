@@ -0,0 +1,217 @@
+package rate_limit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/scheduler"
+)
+
+// Bucket is the persisted state of one rate-limited key. Records whose ExpiresAt
+// has passed describe a full bucket and are deleted on the next flush.
+type Bucket struct {
+	ID        uuid.UUID `json:"id"`
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	Last      time.Time `json:"last"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (b *Bucket) SetID(id uuid.UUID) { b.ID = id }
+func (b *Bucket) GetID() uuid.UUID   { return b.ID }
+func (b *Bucket) GetRecordSize() int { return 512 }
+
+// maxKeyLength keeps records within their record size; longer keys are hashed.
+const maxKeyLength = 256
+
+func storageKey(key string) string {
+	if len(key) <= maxKeyLength {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Options configures a CollectionStore.
+type Options struct {
+	// FlushInterval is how often changed buckets are written to the collection
+	// (default 1s). A negative interval writes every Take through, so no request
+	// is lost on a crash at the cost of one record write per request.
+	FlushInterval time.Duration
+}
+
+// CollectionStore keeps buckets in memory and persists them in a collection, so
+// limits survive restarts. Changes are written in batches every FlushInterval and
+// on Close.
+type CollectionStore struct {
+	t            *table
+	manager      *collection_manager_memory.Manager[*Bucket]
+	ownsManager  bool
+	writeThrough bool
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Open opens (or creates) the "rate_limits" collection in dir.
+func Open(dir string, opts Options) (*CollectionStore, error) {
+	manager, err := collection_manager_memory.New[*Bucket](dir, "rate_limits")
+	if err != nil {
+		return nil, fmt.Errorf("error opening rate_limits collection: %w", err)
+	}
+	s, err := NewCollectionStore(manager, opts)
+	if err != nil {
+		manager.Close()
+		return nil, err
+	}
+	s.ownsManager = true
+	return s, nil
+}
+
+// NewCollectionStore loads the buckets stored in manager. Expired records are
+// deleted. The manager is not closed by Close.
+func NewCollectionStore(manager *collection_manager_memory.Manager[*Bucket], opts Options) (*CollectionStore, error) {
+	s := &CollectionStore{
+		t:            newTable(),
+		manager:      manager,
+		writeThrough: opts.FlushInterval < 0,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	records, err := manager.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading rate limit buckets: %w", err)
+	}
+	now := s.t.now()
+	for _, record := range records {
+		if !now.Before(record.ExpiresAt) {
+			if err := manager.Delete(record.ID); err != nil {
+				return nil, fmt.Errorf("error deleting expired rate limit bucket: %w", err)
+			}
+			continue
+		}
+		s.t.buckets[record.Key] = &bucket{id: record.ID, tokens: record.Tokens, last: record.Last, expires: record.ExpiresAt}
+	}
+
+	if s.writeThrough {
+		close(s.done)
+	} else {
+		interval := opts.FlushInterval
+		if interval == 0 {
+			interval = time.Second
+		}
+		go s.flushLoop(interval)
+	}
+	return s, nil
+}
+
+// Take consumes one request of key.
+func (s *CollectionStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	key = storageKey(key)
+
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	result, b := s.t.take(key, limit)
+	if s.writeThrough {
+		if err := s.persist(key, b); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// persist writes b to the collection. It must be called with s.t.mu held.
+func (s *CollectionStore) persist(key string, b *bucket) error {
+	record := &Bucket{ID: b.id, Key: key, Tokens: b.tokens, Last: b.last, ExpiresAt: b.expires}
+	if b.id == uuid.Nil {
+		created, err := s.manager.Create(record)
+		if err != nil {
+			return fmt.Errorf("error storing rate limit bucket: %w", err)
+		}
+		b.id = created.ID
+	} else if _, err := s.manager.Update(record); err != nil {
+		return fmt.Errorf("error updating rate limit bucket: %w", err)
+	}
+	b.dirty = false
+	return nil
+}
+
+// Flush writes changed buckets and deletes expired ones from memory and the collection.
+func (s *CollectionStore) Flush() error {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+
+	var errs []error
+	now := s.t.now()
+	for key, b := range s.t.buckets {
+		switch {
+		case !now.Before(b.expires):
+			if b.id != uuid.Nil {
+				if err := s.manager.Delete(b.id); err != nil {
+					errs = append(errs, fmt.Errorf("error deleting rate limit bucket: %w", err))
+					continue
+				}
+			}
+			delete(s.t.buckets, key)
+		case b.dirty:
+			errs = append(errs, s.persist(key, b))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Len returns the number of tracked keys.
+func (s *CollectionStore) Len() int {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	return len(s.t.buckets)
+}
+
+// Job returns Flush as a scheduler job, e.g. for stores opened with write-through.
+func (s *CollectionStore) Job() scheduler.Job {
+	return func(ctx context.Context) error {
+		return s.Flush()
+	}
+}
+
+// Close stops the flush loop, writes pending changes and closes the collection
+// if the store opened it.
+func (s *CollectionStore) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		if !s.writeThrough {
+			close(s.stop)
+			<-s.done
+		}
+		err = s.Flush()
+		if s.ownsManager {
+			err = errors.Join(err, s.manager.Close())
+		}
+	})
+	return err
+}
+
+func (s *CollectionStore) flushLoop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				logger.Error("error flushing rate limit buckets", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package request_recorder
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/mock_server"
+)
+
+// Record is one recorded request/response pair. JSON bodies are kept as raw JSON
+// in RequestBody and Body, other bodies as text in RequestText and Text.
+type Record struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Method         string            `json:"method"`
+	Path           string            `json:"path"`
+	Route          string            `json:"route,omitempty"` // registered path, e.g. "/albums/:id"
+	Query          string            `json:"query,omitempty"`
+	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
+	RequestBody    json.RawMessage   `json:"requestBody,omitempty"`
+	RequestText    string            `json:"requestText,omitempty"`
+
+	Status          int               `json:"status"`
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+	Body            json.RawMessage   `json:"body,omitempty"`
+	Text            string            `json:"text,omitempty"`
+
+	Duration  time.Duration `json:"duration"`
+	Truncated bool          `json:"truncated,omitempty"` // a body was cut at Options.MaxBodySize
+}
+
+func (r *Record) SetID(id uuid.UUID)       { r.ID = id }
+func (r *Record) GetID() uuid.UUID         { return r.ID }
+func (r *Record) SetCreatedAt(t time.Time) { r.CreatedAt = t }
+func (r *Record) SetUpdatedAt(t time.Time) { r.UpdatedAt = t }
+func (r *Record) GetRecordSize() int       { return 32768 }
+
+// Exchange converts the record to a mock_server fixture that answers the same
+// request with the recorded response.
+func (r *Record) Exchange() *mock_server.Exchange {
+	return &mock_server.Exchange{
+		ID:          r.ID,
+		Method:      r.Method,
+		Path:        r.Path,
+		Query:       r.Query,
+		RequestBody: r.RequestBody,
+		RequestText: r.RequestText,
+		Status:      r.Status,
+		Headers:     r.ResponseHeaders,
+		Body:        r.Body,
+		Text:        r.Text,
+	}
+}
+
+// redacted replaces secrets in headers and query parameters.
+const redacted = "[REDACTED]"
+
+func redactHeaders(header map[string][]string, names map[string]bool) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if names[strings.ToLower(name)] {
+			result[name] = redacted
+			continue
+		}
+		result[name] = strings.Join(values, ", ")
+	}
+	return result
+}
+
+func redactQuery(rawQuery string, fields map[string]bool) string {
+	if rawQuery == "" || len(fields) == 0 {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	for key := range values {
+		if fields[strings.ToLower(key)] {
+			values[key] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// redactJSON replaces the values of the given fields at any depth of a JSON
+// document. Documents that are not valid JSON are returned unchanged.
+func redactJSON(data []byte, fields map[string]bool) (json.RawMessage, bool) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, false
+	}
+	if len(fields) == 0 || !redactValue(v, fields) {
+		return data, true
+	}
+	redactedData, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	return redactedData, true
+}
+
+func redactValue(v any, fields map[string]bool) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if fields[strings.ToLower(key)] {
+				v[key] = redacted
+				changed = true
+				continue
+			}
+			changed = redactValue(value, fields) || changed
+		}
+	case []any:
+		for _, value := range v {
+			changed = redactValue(value, fields) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,342 @@
+package request_recorder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/admin"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mock_server"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+var logger = logging.For("request_recorder")
+
+// DefaultRedactHeaders and DefaultRedactFields are used when Options leaves them empty.
+var (
+	DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization", "X-Api-Key"}
+	DefaultRedactFields  = []string{"password", "token", "access_token", "refresh_token", "secret", "client_secret"}
+)
+
+// Options configures a Recorder.
+type Options struct {
+	// SampleRate is the fraction of requests recorded, between 0 and 1 (default 1).
+	SampleRate float64
+	// Filter decides which requests may be recorded, e.g. to skip health checks.
+	Filter func(c *mygin.Context) bool
+	// RedactHeaders are request and response headers whose values are replaced.
+	RedactHeaders []string
+	// RedactFields are JSON body fields and query parameters whose values are
+	// replaced, at any depth. Names are matched case-insensitively.
+	RedactFields []string
+	// Redact is called on every record before it is stored, for rules the options
+	// above cannot express.
+	Redact func(r *Record)
+	// MaxBodySize is how many bytes of each body are kept (default 4096). Longer
+	// bodies and binary bodies are dropped and the record is marked Truncated.
+	MaxBodySize int
+	// MaxRecords bounds the collection; the oldest records are deleted (default 1000).
+	MaxRecords int
+}
+
+func (o *Options) defaults() {
+	if o.SampleRate <= 0 {
+		o.SampleRate = 1
+	}
+	if len(o.RedactHeaders) == 0 {
+		o.RedactHeaders = DefaultRedactHeaders
+	}
+	if len(o.RedactFields) == 0 {
+		o.RedactFields = DefaultRedactFields
+	}
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = 4096
+	}
+	if o.MaxRecords <= 0 {
+		o.MaxRecords = 1000
+	}
+}
+
+// Recorder stores sanitized request/response pairs in a collection for debugging,
+// replay with mock_server and browsing in the admin UI:
+//
+//	recorder, err := request_recorder.Open(dataDir, request_recorder.Options{SampleRate: 0.1})
+//	engine.Use(recorder.Middleware())
+//	adminUI.Register("recordings", recorder.Collection())
+type Recorder struct {
+	opts          Options
+	manager       *collection_manager_memory.Manager[*Record]
+	ownsManager   bool
+	redactHeaders map[string]bool
+	redactFields  map[string]bool
+
+	mu  sync.Mutex
+	ids []uuid.UUID // oldest first; UUIDv7 ids sort by creation time
+}
+
+// Open opens (or creates) the "request_recordings" collection in dir.
+func Open(dir string, opts Options) (*Recorder, error) {
+	manager, err := collection_manager_memory.New[*Record](dir, "request_recordings")
+	if err != nil {
+		return nil, fmt.Errorf("error opening request_recordings collection: %w", err)
+	}
+	r, err := NewRecorder(manager, opts)
+	if err != nil {
+		manager.Close()
+		return nil, err
+	}
+	r.ownsManager = true
+	return r, nil
+}
+
+// NewRecorder records into manager. The manager is not closed by Close.
+func NewRecorder(manager *collection_manager_memory.Manager[*Record], opts Options) (*Recorder, error) {
+	opts.defaults()
+	r := &Recorder{
+		opts:          opts,
+		manager:       manager,
+		redactHeaders: lowerSet(opts.RedactHeaders),
+		redactFields:  lowerSet(opts.RedactFields),
+	}
+
+	records, err := manager.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading recordings: %w", err)
+	}
+	for _, record := range records {
+		r.ids = append(r.ids, record.ID)
+	}
+	sort.Slice(r.ids, func(i, j int) bool { return r.ids[i].String() < r.ids[j].String() })
+	r.prune()
+	return r, nil
+}
+
+func lowerSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	return set
+}
+
+// Middleware records the requests it samples. Recording happens after the rest of
+// the chain has run, so it adds to the latency but not to the handler's view of
+// the request.
+func (r *Recorder) Middleware() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		if r.opts.SampleRate < 1 && rand.Float64() >= r.opts.SampleRate {
+			c.Next()
+			return
+		}
+		if r.opts.Filter != nil && !r.opts.Filter(c) {
+			c.Next()
+			return
+		}
+
+		// بدنه درخواست را می‌خوانیم و برای handler دوباره می‌سازیم
+		var requestBody []byte
+		if c.Req.Body != nil {
+			requestBody, _ = io.ReadAll(io.LimitReader(c.Req.Body, int64(r.opts.MaxBodySize)+1))
+			c.Req.Body = readCloser{io.MultiReader(bytes.NewReader(requestBody), c.Req.Body), c.Req.Body}
+		}
+
+		original := c.Writer
+		w := &captureWriter{ResponseWriter: original, limit: r.opts.MaxBodySize}
+		c.Writer = w
+		start := time.Now()
+
+		c.Next()
+
+		c.Writer = original
+		record := r.newRecord(c, requestBody, w, time.Since(start))
+		if err := r.add(record); err != nil {
+			logger.Warn("error recording request", "method", record.Method, "path", record.Path, "error", err)
+		}
+	}
+}
+
+func (r *Recorder) newRecord(c *mygin.Context, requestBody []byte, w *captureWriter, duration time.Duration) *Record {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	record := &Record{
+		Method:          c.Req.Method,
+		Path:            c.Req.URL.Path,
+		Route:           c.FullPath(),
+		Query:           redactQuery(c.Req.URL.RawQuery, r.redactFields),
+		RequestHeaders:  redactHeaders(c.Req.Header, r.redactHeaders),
+		Status:          status,
+		ResponseHeaders: redactHeaders(w.Header(), r.redactHeaders),
+		Duration:        duration,
+	}
+
+	var truncated bool
+	record.RequestBody, record.RequestText, truncated = r.body(requestBody, c.Req.Header.Get("Content-Type"))
+	record.Truncated = truncated
+	record.Body, record.Text, truncated = r.body(w.body.Bytes(), w.Header().Get("Content-Type"))
+	record.Truncated = record.Truncated || truncated
+
+	if r.opts.Redact != nil {
+		r.opts.Redact(record)
+	}
+	return record
+}
+
+// body sorts a captured body into JSON or text, redacting JSON fields.
+func (r *Recorder) body(data []byte, contentType string) (json.RawMessage, string, bool) {
+	if len(data) == 0 {
+		return nil, "", false
+	}
+	if len(data) > r.opts.MaxBodySize || !textual(contentType) {
+		return nil, "", true
+	}
+	if strings.Contains(strings.ToLower(contentType), "x-www-form-urlencoded") {
+		return nil, redactQuery(string(data), r.redactFields), false
+	}
+	if doc, ok := redactJSON(data, r.redactFields); ok {
+		return doc, "", false
+	}
+	return nil, string(data), false
+}
+
+// textual reports whether a body of contentType is worth keeping as text.
+func textual(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	contentType = strings.ToLower(contentType)
+	return strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "xml") ||
+		strings.Contains(contentType, "x-www-form-urlencoded")
+}
+
+// add stores a record and deletes the oldest ones over MaxRecords.
+func (r *Recorder) add(record *Record) error {
+	created, err := r.manager.Create(record)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.ids = append(r.ids, created.ID)
+	r.mu.Unlock()
+	r.prune()
+	return nil
+}
+
+func (r *Recorder) prune() {
+	r.mu.Lock()
+	var expired []uuid.UUID
+	if over := len(r.ids) - r.opts.MaxRecords; over > 0 {
+		expired = append(expired, r.ids[:over]...)
+		r.ids = append(r.ids[:0], r.ids[over:]...)
+	}
+	r.mu.Unlock()
+
+	for _, id := range expired {
+		if err := r.manager.Delete(id); err != nil {
+			logger.Warn("error deleting old recording", "id", id, "error", err)
+		}
+	}
+}
+
+// Records returns the stored records, newest first.
+func (r *Recorder) Records() ([]*Record, error) {
+	records, err := r.manager.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID.String() > records[j].ID.String() })
+	return records, nil
+}
+
+// Exchanges returns the stored records as mock_server fixtures, oldest first, so
+// repeated requests replay in the order they were recorded.
+func (r *Recorder) Exchanges() ([]*mock_server.Exchange, error) {
+	records, err := r.Records()
+	if err != nil {
+		return nil, err
+	}
+	exchanges := make([]*mock_server.Exchange, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		exchanges = append(exchanges, records[i].Exchange())
+	}
+	return exchanges, nil
+}
+
+// Clear deletes every record.
+func (r *Recorder) Clear() error {
+	r.mu.Lock()
+	ids := r.ids
+	r.ids = nil
+	r.mu.Unlock()
+
+	for _, id := range ids {
+		if err := r.manager.Delete(id); err != nil {
+			return fmt.Errorf("error deleting recording %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Collection exposes the records to the admin UI.
+func (r *Recorder) Collection() admin.Collection {
+	return admin.Memory[*Record](r.manager)
+}
+
+// Close closes the collection when it was opened by Open.
+func (r *Recorder) Close() error {
+	if r.ownsManager {
+		return r.manager.Close()
+	}
+	return nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureWriter keeps the status and the first limit bytes of the response.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	limit  int
+}
+
+func (w *captureWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	// یک بایت بیشتر از حد نگه می‌داریم تا بدنه‌های بلند تشخیص داده شوند
+	if room := w.limit + 1 - w.body.Len(); room > 0 {
+		w.body.Write(p[:min(len(p), room)])
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (w *captureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,103 @@
+package request_recorder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mahdi-cpp/iris-tools/mock_server"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestRecorderRedactsAndReplays(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := Open(dir, Options{MaxRecords: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := mygin.New()
+	engine.Use(recorder.Middleware())
+	engine.POST("/login", func(c *mygin.Context) {
+		c.Writer.Header().Set("Set-Cookie", "session=abc")
+		c.JSON(http.StatusOK, mygin.H{"user": "sara", "token": "t0k3n"})
+	})
+	engine.GET("/albums/:id", func(c *mygin.Context) {
+		c.JSON(http.StatusOK, mygin.H{"id": c.Param("id")})
+	})
+
+	send := func(method, target, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send("GET", "/albums/1", "")
+	send("POST", "/login?password=x", `{"user":"sara","password":"hunter2"}`)
+	send("GET", "/albums/2", "")
+
+	records, err := recorder.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2 after pruning", len(records))
+	}
+	login := records[1]
+	if login.Path != "/login" || records[0].Route != "/albums/:id" {
+		t.Fatalf("unexpected records: %+v, %+v", records[0], login)
+	}
+	for _, s := range []string{string(login.RequestBody), string(login.Body), login.Query, login.RequestHeaders["Authorization"], login.ResponseHeaders["Set-Cookie"]} {
+		if strings.Contains(s, "hunter2") || strings.Contains(s, "t0k3n") || strings.Contains(s, "secret") || strings.Contains(s, "abc") {
+			t.Errorf("secret not redacted: %s", s)
+		}
+	}
+	if recorder.Close() != nil {
+		t.Fatal("close failed")
+	}
+
+	// پس از باز کردن دوباره، ضبط‌ها به‌عنوان fixture قابل پخش هستند
+	recorder, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Close()
+	exchanges, err := recorder.Exchanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := mock_server.New(exchanges...)
+	w := httptest.NewRecorder()
+	mock.ServeHTTP(w, httptest.NewRequest("GET", "/albums/2", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"2"`) {
+		t.Fatalf("replay: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestRecorderSkipsBinaryAndLargeBodies(t *testing.T) {
+	recorder, err := Open(t.TempDir(), Options{MaxBodySize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Close()
+
+	engine := mygin.New()
+	engine.Use(recorder.Middleware())
+	engine.GET("/image", func(c *mygin.Context) { c.Data(http.StatusOK, "image/png", []byte{1, 2, 3}) })
+	engine.GET("/long", func(c *mygin.Context) { c.String(http.StatusOK, "0123456789") })
+
+	for _, path := range []string{"/image", "/long"} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.Len() == 0 {
+			t.Fatalf("%s: response not passed through", path)
+		}
+	}
+
+	records, _ := recorder.Records()
+	for _, r := range records {
+		if !r.Truncated || len(r.Body) > 0 || r.Text != "" {
+			t.Errorf("%s: body kept: %+v", r.Path, r)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package request_schema
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// JSONSchema is the supported subset of JSON Schema (draft 2020-12): type,
+// properties, required, additionalProperties (boolean), items, enum, const,
+// minLength, maxLength, pattern, format (email, uuid, date-time, date),
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, minItems, maxItems and
+// uniqueItems. Unknown keywords are ignored.
+type JSONSchema struct {
+	Type                 typeList               `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Const                any                    `json:"const,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// typeList accepts "type": "string" as well as "type": ["string", "null"].
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = typeList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = many
+	return nil
+}
+
+func (t typeList) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// compile checks the schema and compiles its patterns.
+func (s *JSONSchema) compile() error {
+	for _, typ := range s.Type {
+		switch typ {
+		case "object", "array", "string", "number", "integer", "boolean", "null":
+		default:
+			return fmt.Errorf("json schema: unknown type %q", typ)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("json schema: invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if prop == nil {
+			return fmt.Errorf("json schema: property %q has no schema", name)
+		}
+		if err := prop.compile(); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+func (s *JSONSchema) typeAllowed(actual string) bool {
+	if len(s.Type) == 0 {
+		return true
+	}
+	for _, typ := range s.Type {
+		if typ == actual || typ == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// validate records the first problem of value (and of every nested value) in v.
+// The root value is reported under "body".
+func (s *JSONSchema) validate(v *validation.Validator, path string, value any) {
+	name := path
+	if name == "" {
+		name = "body"
+	}
+
+	actual := typeOf(value)
+	if !s.typeAllowed(actual) {
+		v.Add(name, fmt.Sprintf("must be of type %s", joinTypes(s.Type)))
+		return
+	}
+	if s.Const != nil && !reflect.DeepEqual(normalize(s.Const), value) {
+		v.Add(name, fmt.Sprintf("must be %v", s.Const))
+		return
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if reflect.DeepEqual(normalize(allowed), value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.Add(name, fmt.Sprintf("must be one of %v", s.Enum))
+			return
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		s.validateString(v, name, value)
+	case float64:
+		s.validateNumber(v, name, value)
+	case []any:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			v.Add(name, fmt.Sprintf("must contain at least %d items", *s.MinItems))
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			v.Add(name, fmt.Sprintf("must contain at most %d items", *s.MaxItems))
+		}
+		if s.UniqueItems {
+			for i := range value {
+				for j := i + 1; j < len(value); j++ {
+					if reflect.DeepEqual(value[i], value[j]) {
+						v.Add(name, "must not contain duplicate items")
+					}
+				}
+			}
+		}
+		if s.Items != nil {
+			for i, item := range value {
+				s.Items.validate(v, path+"["+strconv.Itoa(i)+"]", item)
+			}
+		}
+	case map[string]any:
+		for _, required := range s.Required {
+			if _, ok := value[required]; !ok {
+				v.Add(join(path, required), "is required")
+			}
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					v.Add(join(path, key), "is not allowed")
+				}
+				continue
+			}
+			prop.validate(v, join(path, key), value[key])
+		}
+	}
+}
+
+func (s *JSONSchema) validateString(v *validation.Validator, name, value string) {
+	length := utf8.RuneCountInString(value)
+	switch {
+	case s.MinLength != nil && length < *s.MinLength:
+		v.Add(name, fmt.Sprintf("must be at least %d characters", *s.MinLength))
+	case s.MaxLength != nil && length > *s.MaxLength:
+		v.Add(name, fmt.Sprintf("must be at most %d characters", *s.MaxLength))
+	case s.pattern != nil && !s.pattern.MatchString(value):
+		v.Add(name, "has an invalid format")
+	case s.Format != "" && !validFormat(s.Format, value):
+		v.Add(name, "must be a valid "+s.Format)
+	}
+}
+
+func (s *JSONSchema) validateNumber(v *validation.Validator, name string, value float64) {
+	switch {
+	case s.Minimum != nil && value < *s.Minimum:
+		v.Add(name, fmt.Sprintf("must be at least %v", *s.Minimum))
+	case s.Maximum != nil && value > *s.Maximum:
+		v.Add(name, fmt.Sprintf("must be at most %v", *s.Maximum))
+	case s.ExclusiveMinimum != nil && value <= *s.ExclusiveMinimum:
+		v.Add(name, fmt.Sprintf("must be greater than %v", *s.ExclusiveMinimum))
+	case s.ExclusiveMaximum != nil && value >= *s.ExclusiveMaximum:
+		v.Add(name, fmt.Sprintf("must be less than %v", *s.ExclusiveMaximum))
+	}
+}
+
+func validFormat(format, value string) bool {
+	switch format {
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "uuid":
+		return uuid.Validate(value) == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	}
+	return true // فرمت‌های ناشناخته مانند JSON Schema فقط توضیحی هستند
+}
+
+// normalize converts schema literals to the types produced by decoding a body.
+func normalize(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out any
+	if json.Unmarshal(data, &out) != nil {
+		return value
+	}
+	return out
+}
+
+func joinTypes(types typeList) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprint([]string(types))
+}
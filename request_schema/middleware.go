@@ -0,0 +1,111 @@
+package request_schema
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// DefaultMaxBodySize limits bodies read by Middleware and Validate.
+const DefaultMaxBodySize = 1 << 20
+
+// Options configures Middleware and Validate.
+type Options struct {
+	// MaxBodySize is the largest accepted body in bytes (default DefaultMaxBodySize).
+	MaxBodySize int64
+}
+
+// Middleware binds and validates the body of every route with a schema attached
+// by Body before its handlers run; routes without a schema are passed through.
+// The decoded body is available with Get and the raw body stays readable.
+//
+// Rejected requests get one of these bodies:
+//
+//	400 {"error": "invalid JSON body: ..."}
+//	413 {"error": "request body too large"}
+//	415 {"error": "unsupported content type, expected application/json"}
+//	422 {"error": "validation failed", "fields": {"title": "is required"}}
+func Middleware(opts ...Options) mygin.HandlerFunc {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return func(c *mygin.Context) {
+		value, ok := c.RouteMeta(BodyKey)
+		s, _ := value.(*Schema)
+		if !ok || s == nil {
+			c.Next()
+			return
+		}
+		if bind(c, s, o) {
+			c.Next()
+		}
+	}
+}
+
+// Validate binds and validates the body against s. Use it instead of Body and
+// Middleware to validate a single route.
+func Validate(s *Schema, opts ...Options) mygin.HandlerFunc {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return func(c *mygin.Context) {
+		if bind(c, s, o) {
+			c.Next()
+		}
+	}
+}
+
+// bind reads, decodes and validates the body; on failure it writes the error
+// response, aborts and returns false.
+func bind(c *mygin.Context, s *Schema, o Options) bool {
+	reject := func(status int, body mygin.H) bool {
+		c.JSON(status, body)
+		c.Abort()
+		return false
+	}
+
+	if ct := c.GetHeader("Content-Type"); ct != "" {
+		if mediaType, _, err := mime.ParseMediaType(ct); err != nil || mediaType != "application/json" && !isJSONSuffix(mediaType) {
+			return reject(http.StatusUnsupportedMediaType, mygin.H{"error": "unsupported content type, expected application/json"})
+		}
+	}
+
+	limit := o.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Req.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return reject(http.StatusRequestEntityTooLarge, mygin.H{"error": "request body too large"})
+		}
+		return reject(http.StatusBadRequest, mygin.H{"error": "error reading body: " + err.Error()})
+	}
+	c.Req.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return reject(http.StatusBadRequest, mygin.H{"error": "invalid JSON body: empty body"})
+	}
+	value, err := s.decode(data)
+	if fields, ok := validation.Fields(err); ok {
+		return reject(http.StatusUnprocessableEntity, mygin.H{"error": "validation failed", "fields": fields})
+	}
+	if err != nil {
+		return reject(http.StatusBadRequest, mygin.H{"error": "invalid JSON body: " + err.Error()})
+	}
+	c.Set(valueKey, value)
+	return true
+}
+
+// isJSONSuffix reports media types such as "application/merge-patch+json".
+func isJSONSuffix(mediaType string) bool {
+	return len(mediaType) > 5 && mediaType[len(mediaType)-5:] == "+json"
+}
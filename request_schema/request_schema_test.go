@@ -0,0 +1,121 @@
+package request_schema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type albumInput struct {
+	Title string   `json:"title" validate:"required,max=20"`
+	Tags  []string `json:"tags" validate:"max=2"`
+}
+
+var albumJSONSchema = MustFromJSON(`{
+	"type": "object",
+	"required": ["title"],
+	"additionalProperties": false,
+	"properties": {
+		"title": {"type": "string", "minLength": 1, "maxLength": 20},
+		"owner": {"type": "string", "format": "email"},
+		"rating": {"type": "integer", "minimum": 1, "maximum": 5},
+		"tags": {"type": "array", "items": {"type": "string", "enum": ["trip", "family"]}}
+	}
+}`)
+
+func newEngine() *mygin.Engine {
+	engine := mygin.New()
+	engine.Use(Middleware(Options{MaxBodySize: 1024}))
+	Body(engine.POST("/struct", func(c *mygin.Context) {
+		in, ok := Get[albumInput](c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, mygin.H{"error": "no body"})
+			return
+		}
+		c.JSON(http.StatusCreated, in)
+	}), Of[albumInput]())
+	Body(engine.POST("/json", func(c *mygin.Context) {
+		in, _ := Get[any](c)
+		c.JSON(http.StatusCreated, in)
+	}), albumJSONSchema)
+	engine.POST("/free", func(c *mygin.Context) { c.Status(http.StatusNoContent) })
+	return engine
+}
+
+func TestMiddleware(t *testing.T) {
+	engine := newEngine()
+
+	tests := []struct {
+		name, path, contentType, body string
+		want                          int
+		field                         string
+	}{
+		{"struct ok", "/struct", "application/json", `{"title":"Trip"}`, http.StatusCreated, ""},
+		{"struct missing", "/struct", "", `{"tags":["a"]}`, http.StatusUnprocessableEntity, "title"},
+		{"struct bad json", "/struct", "", `{"title":`, http.StatusBadRequest, ""},
+		{"wrong type", "/struct", "text/plain", `{"title":"x"}`, http.StatusUnsupportedMediaType, ""},
+		{"too large", "/struct", "", `{"title":"` + strings.Repeat("x", 2000) + `"}`, http.StatusRequestEntityTooLarge, ""},
+		{"empty", "/struct", "", ``, http.StatusBadRequest, ""},
+		{"json ok", "/json", "", `{"title":"Trip","rating":5,"tags":["trip"]}`, http.StatusCreated, ""},
+		{"json required", "/json", "", `{}`, http.StatusUnprocessableEntity, "title"},
+		{"json integer", "/json", "", `{"title":"x","rating":2.5}`, http.StatusUnprocessableEntity, "rating"},
+		{"json enum", "/json", "", `{"title":"x","tags":["trip","work"]}`, http.StatusUnprocessableEntity, "tags[1]"},
+		{"json format", "/json", "", `{"title":"x","owner":"nope"}`, http.StatusUnprocessableEntity, "owner"},
+		{"json additional", "/json", "", `{"title":"x","extra":1}`, http.StatusUnprocessableEntity, "extra"},
+		{"json root type", "/json", "", `[1]`, http.StatusUnprocessableEntity, "body"},
+		{"no schema", "/free", "text/plain", `anything`, http.StatusNoContent, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.field != "" {
+				var body struct {
+					Fields map[string]string `json:"fields"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &body)
+				if body.Fields[tt.field] == "" {
+					t.Errorf("no error for %q: %s", tt.field, w.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteMetadata(t *testing.T) {
+	engine := newEngine()
+	Response(engine.GET("/albums/:id", func(c *mygin.Context) {}), http.StatusOK, Of[albumInput]())
+
+	found := 0
+	for _, route := range engine.Routes() {
+		switch route.Path {
+		case "/struct":
+			if s, _ := route.Meta[BodyKey].(*Schema); s == nil || s.Type.Name() != "albumInput" {
+				t.Errorf("body schema of /struct = %v", route.Meta)
+			}
+			found++
+		case "/albums/:id":
+			if responses, _ := route.Meta[ResponsesKey].(Responses); responses[http.StatusOK] == nil {
+				t.Errorf("responses of /albums/:id = %v", route.Meta)
+			}
+			found++
+		}
+	}
+	if found != 2 {
+		t.Fatalf("found %d routes", found)
+	}
+	if _, err := FromJSON([]byte(`{"type":"strin"}`)); err == nil {
+		t.Error("invalid type accepted")
+	}
+}
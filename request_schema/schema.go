@@ -0,0 +1,130 @@
+package request_schema
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/goccy/go-json"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// پکیج request_schema اسکیمای بدنه درخواست را هنگام ثبت مسیر به آن متصل می‌کند.
+// Middleware بدنه را پیش از اجرای هندلر bind و اعتبارسنجی می‌کند و همین
+// متادیتا برای تولید مستندات مسیرها هم استفاده می‌شود.
+
+// Route metadata keys (see mygin.Route.Meta).
+const (
+	BodyKey      = "request_schema.body"
+	ResponsesKey = "request_schema.responses"
+)
+
+// valueKey is the Context key of the bound body.
+const valueKey = "request_schema.value"
+
+// Schema describes a request or response body either by a Go type or by a JSON
+// schema document.
+type Schema struct {
+	// Type is the Go type bodies are decoded into. Structs are checked with their
+	// validate tags (validation.Struct) and Validatable.
+	Type reflect.Type
+	// JSON is a JSON schema; bodies are decoded into generic values.
+	JSON *JSONSchema
+}
+
+// Of returns the schema of type T (pointer types are dereferenced).
+func Of[T any]() *Schema {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return &Schema{Type: t}
+}
+
+// FromJSON parses a JSON schema document.
+func FromJSON(doc []byte) (*Schema, error) {
+	var s JSONSchema
+	if err := json.Unmarshal(doc, &s); err != nil {
+		return nil, fmt.Errorf("error parsing JSON schema: %w", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &Schema{JSON: &s}, nil
+}
+
+// MustFromJSON is FromJSON that panics on error, for package level schemas.
+func MustFromJSON(doc string) *Schema {
+	s, err := FromJSON([]byte(doc))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// decode parses data and validates the result. Decoding errors are returned as
+// is, validation errors as validation.Errors.
+func (s *Schema) decode(data []byte) (any, error) {
+	if s.JSON != nil {
+		var value any
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		v := validation.New()
+		s.JSON.validate(v, "", value)
+		return value, v.Err()
+	}
+
+	ptr := reflect.New(s.Type)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	value := ptr.Interface()
+	v := validation.New()
+	if s.Type.Kind() == reflect.Struct {
+		v.Merge("", validation.Struct(value))
+	}
+	if item, ok := value.(validation.Validatable); ok {
+		v.Merge("", item.Validate())
+	}
+	return value, v.Err()
+}
+
+// Body attaches s as the request body schema of route; Middleware enforces it.
+//
+//	request_schema.Body(api.POST("/albums", create), request_schema.Of[AlbumInput]())
+func Body(route *mygin.Route, s *Schema) *mygin.Route {
+	return route.Meta(BodyKey, s)
+}
+
+// Responses maps status codes to response body schemas; it is documentation only.
+type Responses map[int]*Schema
+
+// Response documents the body of route responses with status.
+func Response(route *mygin.Route, status int, s *Schema) *mygin.Route {
+	responses := Responses{}
+	if existing, ok := route.Get(ResponsesKey); ok {
+		for code, schema := range existing.(Responses) {
+			responses[code] = schema
+		}
+	}
+	responses[status] = s
+	return route.Meta(ResponsesKey, responses)
+}
+
+// Get returns the body bound by Middleware or Validate as T (either the decoded
+// type or a pointer to it).
+func Get[T any](c *mygin.Context) (T, bool) {
+	var zero T
+	value, ok := c.Get(valueKey)
+	if !ok {
+		return zero, false
+	}
+	if v, ok := value.(T); ok {
+		return v, true
+	}
+	if p, ok := value.(*T); ok && p != nil {
+		return *p, true
+	}
+	return zero, false
+}
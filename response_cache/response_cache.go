@@ -0,0 +1,297 @@
+package response_cache
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/cache"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// پکیج response_cache پاسخ کامل درخواست‌های GET را نگه می‌دارد تا handlerهای پرهزینه
+// (فهرست آلبوم‌ها، جستجو) برای درخواست‌های تکراری دوباره اجرا نشوند.
+
+// Response is a stored response.
+type Response struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	StoredAt time.Time
+}
+
+// Store keeps responses. *cache.Cache[string, *Response] is a Store; stores shared
+// between instances only need to implement these methods.
+type Store interface {
+	Get(key string) (*Response, bool)
+	SetWithTTL(key string, resp *Response, ttl time.Duration)
+	Purge()
+}
+
+var _ Store = (*cache.Cache[string, *Response])(nil)
+
+// KeyFunc returns the cache key of a request, or "" to bypass the cache.
+type KeyFunc func(c *mygin.Context) string
+
+// Options configures a Cache.
+type Options struct {
+	// TTL is how long a response is served from the cache (default 1m). A
+	// max-age or s-maxage in the response's Cache-Control takes precedence.
+	TTL time.Duration
+	// Size is the number of responses kept by the default store (default 1000).
+	Size int
+	// MaxBodySize is the largest body that is stored (default 1 MiB).
+	MaxBodySize int
+	// VaryHeaders are request headers that are part of the default key, e.g.
+	// Accept-Language. Requests with credentials, an Authorization, Cookie or
+	// X-API-Key header, are not cached unless that header is listed here.
+	VaryHeaders []string
+	// Key replaces the default key of method, path, sorted query and VaryHeaders.
+	// It decides alone which requests are cached, including those with
+	// credentials.
+	Key KeyFunc
+	// Store replaces the in-memory store.
+	Store Store
+}
+
+func (o *Options) defaults() {
+	if o.TTL <= 0 {
+		o.TTL = time.Minute
+	}
+	if o.Size <= 0 {
+		o.Size = 1000
+	}
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = 1 << 20
+	}
+}
+
+// Cache stores full responses of GET requests:
+//
+//	albums, _ := response_cache.New(response_cache.Options{TTL: 30 * time.Second})
+//	r.GET("/albums", albums.Middleware(), listAlbums)
+//	r.POST("/albums", func(c *mygin.Context) {
+//		createAlbum(c)
+//		albums.Invalidate("/albums")
+//	})
+//
+// Only 200 responses without Set-Cookie are stored, and only when their
+// Cache-Control allows it (no no-store, no-cache or private). A request with
+// Cache-Control: no-cache skips the lookup and refreshes the entry; no-store
+// bypasses the cache. Responses carry X-Cache: HIT or MISS and, when served from
+// the cache, Age. Add the middleware after compression.Middleware so it stores
+// uncompressed bodies.
+type Cache struct {
+	opts  Options
+	store Store
+	now   func() time.Time
+
+	mu          sync.Mutex
+	generations map[string]uint64 // path -> invalidation count, part of the key
+}
+
+// New creates a Cache.
+func New(opts Options) (*Cache, error) {
+	opts.defaults()
+	store := opts.Store
+	if store == nil {
+		memory, err := cache.New(cache.Options[string, *Response]{Size: opts.Size})
+		if err != nil {
+			return nil, err
+		}
+		store = memory
+	}
+	return &Cache{opts: opts, store: store, now: time.Now, generations: make(map[string]uint64)}, nil
+}
+
+// Invalidate drops the stored responses of paths, for every query and header.
+func (c *Cache) Invalidate(paths ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, path := range paths {
+		c.generations[path]++
+	}
+}
+
+// Purge drops every stored response.
+func (c *Cache) Purge() {
+	c.store.Purge()
+}
+
+func (c *Cache) generation(path string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[path]
+}
+
+// credentialHeaders identify the user of a request: the Authorization header,
+// the session cookie of auth and the API keys of tokens.
+var credentialHeaders = []string{"Authorization", "Cookie", "X-API-Key"}
+
+// key is the default KeyFunc.
+func (c *Cache) key(ctx *mygin.Context) string {
+	// پاسخ یک کاربر نباید به کاربر دیگری برگردانده شود
+	for _, name := range credentialHeaders {
+		if ctx.GetHeader(name) != "" && !c.varies(name) {
+			return ""
+		}
+	}
+	var b strings.Builder
+	b.WriteString(ctx.Method + " " + ctx.Req.Host + ctx.Path)
+	if query := ctx.Req.URL.Query(); len(query) > 0 {
+		b.WriteString("?" + query.Encode()) // Encode کلیدها را مرتب می‌کند
+	}
+	for _, name := range c.opts.VaryHeaders {
+		b.WriteString("\n" + name + ": " + ctx.GetHeader(name))
+	}
+	return b.String()
+}
+
+func (c *Cache) varies(header string) bool {
+	for _, name := range c.opts.VaryHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware serves stored responses and stores the responses of the handlers
+// that follow it.
+func (c *Cache) Middleware() mygin.HandlerFunc {
+	keyFunc := c.opts.Key
+	if keyFunc == nil {
+		keyFunc = c.key
+	}
+	return func(ctx *mygin.Context) {
+		requestCC := parseCacheControl(ctx.GetHeader("Cache-Control"))
+		if ctx.Method != http.MethodGet || requestCC.has("no-store") {
+			ctx.Next()
+			return
+		}
+		key := keyFunc(ctx)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		key = strconv.FormatUint(c.generation(ctx.Path), 10) + " " + key
+
+		if !requestCC.has("no-cache") {
+			if resp, ok := c.store.Get(key); ok {
+				c.serve(ctx, resp)
+				ctx.Abort()
+				return
+			}
+		}
+
+		w := &recorder{ResponseWriter: ctx.Writer, max: c.opts.MaxBodySize}
+		w.Header().Set("X-Cache", "MISS")
+		ctx.Writer = w
+		defer func() { ctx.Writer = w.ResponseWriter }()
+		ctx.Next()
+
+		if ttl, ok := c.storable(w); ok {
+			header := w.Header().Clone()
+			header.Del("X-Cache")
+			c.store.SetWithTTL(key, &Response{Status: w.status, Header: header, Body: w.body.Bytes(), StoredAt: c.now()}, ttl)
+		}
+	}
+}
+
+// storable reports whether the recorded response may be stored, and for how long.
+func (c *Cache) storable(w *recorder) (time.Duration, bool) {
+	if w.status != http.StatusOK || w.skip || w.Header().Get("Set-Cookie") != "" {
+		return 0, false
+	}
+	cc := parseCacheControl(w.Header().Get("Cache-Control"))
+	if cc.has("no-store") || cc.has("no-cache") || cc.has("private") {
+		return 0, false
+	}
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if value, ok := cc[directive]; ok {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				return 0, false
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return c.opts.TTL, true
+}
+
+func (c *Cache) serve(ctx *mygin.Context, resp *Response) {
+	header := ctx.Writer.Header()
+	for name, values := range resp.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set("X-Cache", "HIT")
+	header.Set("Age", strconv.Itoa(int(c.now().Sub(resp.StoredAt)/time.Second)))
+	header.Set("Content-Length", strconv.Itoa(len(resp.Body)))
+	ctx.Status(resp.Status)
+	ctx.Writer.Write(resp.Body)
+}
+
+// cacheControl holds the directives of a Cache-Control header, lower-case.
+type cacheControl map[string]string
+
+func parseCacheControl(header string) cacheControl {
+	cc := cacheControl{}
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			cc[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+// recorder writes the response through and keeps a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	max    int
+	status int
+	body   bytes.Buffer
+	skip   bool // too large or streamed
+}
+
+func (w *recorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.skip {
+		if w.body.Len()+len(b) > w.max {
+			w.skip = true
+			w.body = bytes.Buffer{}
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush marks the response as streamed; streamed responses are not stored.
+func (w *recorder) Flush() {
+	w.skip = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *recorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
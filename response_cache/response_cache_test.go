@@ -0,0 +1,106 @@
+package response_cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestMiddleware(t *testing.T) {
+	albums, err := New(Options{VaryHeaders: []string{"Accept-Language"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	router := mygin.New()
+	router.GET("/albums", albums.Middleware(), func(c *mygin.Context) {
+		calls++
+		c.JSON(http.StatusOK, mygin.H{"calls": calls, "q": c.GetQuery("q")})
+	})
+	router.GET("/private", albums.Middleware(), func(c *mygin.Context) {
+		calls++
+		c.Writer.Header().Set("Cache-Control", "private")
+		c.String(http.StatusOK, "%d", calls)
+	})
+
+	send := func(path string, header ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("/albums?q=a&page=1")
+	second := send("/albums?page=1&q=a")
+	if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" ||
+		second.Body.String() != first.Body.String() || calls != 1 {
+		t.Fatalf("second request was not served from the cache: %v %q calls=%d", second.Header(), second.Body, calls)
+	}
+
+	checks := []struct {
+		name   string
+		w      *httptest.ResponseRecorder
+		xcache string
+	}{
+		{"other language", send("/albums?q=a&page=1", "Accept-Language", "fa"), "MISS"},
+		{"authorized", send("/albums?q=a&page=1", "Authorization", "Bearer t"), ""},
+		{"no-cache", send("/albums?q=a&page=1", "Cache-Control", "no-cache"), "MISS"},
+		{"private response", send("/private"), "MISS"},
+		{"private response again", send("/private"), "MISS"},
+	}
+	for _, check := range checks {
+		if got := check.w.Header().Get("X-Cache"); got != check.xcache {
+			t.Errorf("%s: X-Cache = %q, want %q", check.name, got, check.xcache)
+		}
+	}
+
+	albums.Invalidate("/albums")
+	if w := send("/albums?q=a&page=1"); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("Invalidate did not drop the response: %v", w.Header())
+	}
+	if w := send("/albums?q=a&page=1"); w.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("refreshed response was not stored: %v", w.Header())
+	}
+	albums.Purge()
+	if w := send("/albums?q=a&page=1"); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("Purge did not drop the response: %v", w.Header())
+	}
+}
+
+func TestCredentialsNotShared(t *testing.T) {
+	me, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := mygin.New()
+	router.GET("/me", me.Middleware(), func(c *mygin.Context) {
+		user := c.GetHeader("X-API-Key")
+		if cookie, err := c.Req.Cookie("session"); err == nil {
+			user = cookie.Value
+		}
+		c.String(http.StatusOK, "user=%s", user)
+	})
+
+	for _, header := range []string{"Cookie", "X-API-Key"} {
+		value := func(user string) string {
+			if header == "Cookie" {
+				return "session=" + user
+			}
+			return user
+		}
+		for _, user := range []string{"alice", "bob"} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/me", nil)
+			req.Header.Set(header, value(user))
+			router.ServeHTTP(w, req)
+			if w.Body.String() != "user="+user || w.Header().Get("X-Cache") != "" {
+				t.Fatalf("%s of %s: %q X-Cache=%q", header, user, w.Body, w.Header().Get("X-Cache"))
+			}
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"github.com/mahdi-cpp/iris-tools/validation"
+)
+
+// Action identifies the operation a request performs on a resource.
+type Action string
+
+const (
+	ActionList   Action = "list"
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// ErrForbidden can be returned by Authorize to reject a request with 403.
+var ErrForbidden = errors.New("forbidden")
+
+// Item is implemented by the models stored in the collection managers.
+type Item interface {
+	GetID() uuid.UUID
+	SetID(uuid.UUID)
+}
+
+// Store is the CRUD method set shared by collection_manager_memory and collection_manager_json.
+type Store[T Item] interface {
+	Create(item T) (T, error)
+	Read(id uuid.UUID) (T, error)
+	ReadAll() ([]T, error)
+	Update(item T) (T, error)
+	Delete(id uuid.UUID) error
+}
+
+// ContextStore is implemented by stores with context-aware methods, such as
+// collection_manager_memory. The request context is then passed to the store so
+// storage trace spans become children of the HTTP server span.
+type ContextStore[T Item] interface {
+	CreateContext(ctx context.Context, item T) (T, error)
+	ReadContext(ctx context.Context, id uuid.UUID) (T, error)
+	ReadAllContext(ctx context.Context) ([]T, error)
+	UpdateContext(ctx context.Context, item T) (T, error)
+	DeleteContext(ctx context.Context, id uuid.UUID) error
+}
+
+// boundStore adapts a ContextStore to Store for one request.
+type boundStore[T Item] struct {
+	store ContextStore[T]
+	ctx   context.Context
+}
+
+func (b boundStore[T]) Create(item T) (T, error)     { return b.store.CreateContext(b.ctx, item) }
+func (b boundStore[T]) Read(id uuid.UUID) (T, error) { return b.store.ReadContext(b.ctx, id) }
+func (b boundStore[T]) ReadAll() ([]T, error)        { return b.store.ReadAllContext(b.ctx) }
+func (b boundStore[T]) Update(item T) (T, error)     { return b.store.UpdateContext(b.ctx, item) }
+func (b boundStore[T]) Delete(id uuid.UUID) error    { return b.store.DeleteContext(b.ctx, id) }
+
+// ValidationError is returned by hooks to reject input with 422 and field details.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed: %v", e.Fields)
+}
+
+// Resource describes a REST endpoint set for model T whose create/update
+// payloads are decoded into In. Only Store and Build are required:
+//
+//	albums := &rest.Resource[*Album, AlbumInput]{
+//		Store: albumManager,
+//		Build: func(c *mygin.Context, in AlbumInput, existing *Album) (*Album, error) {
+//			if existing == nil {
+//				existing = &Album{}
+//			}
+//			existing.Title = in.Title
+//			return existing, nil
+//		},
+//	}
+//	albums.Mount(api, "/albums")
+type Resource[T Item, In any] struct {
+	Store Store[T]
+
+	// Authorize is called before every action. For list and create item is the zero value.
+	Authorize func(c *mygin.Context, action Action, item T) error
+	// Validate checks decoded input before it is applied.
+	Validate func(c *mygin.Context, in In) error
+	// Build turns input into a model; existing is the zero value on create.
+	Build func(c *mygin.Context, in In, existing T) (T, error)
+	// Present shapes a model for the response. Defaults to the model itself.
+	Present func(c *mygin.Context, item T) any
+	// Filter decides which items a list request returns.
+	Filter func(c *mygin.Context, item T) bool
+	// Less orders list results. Defaults to ID order, which is creation order for UUIDv7.
+	Less func(a, b T) bool
+
+	// DefaultLimit and MaxLimit bound the page size of list requests (defaults 50 and 500).
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Mount registers the resource routes on group under path:
+//
+//	GET    path          list (?offset=&limit=)
+//	POST   path          create
+//	GET    path/:id      read
+//	PATCH  path/:id      update
+//	DELETE path/:id      delete
+func (r *Resource[T, In]) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	if r.Store == nil || r.Build == nil {
+		panic("rest: Resource requires Store and Build")
+	}
+
+	with := func(handler mygin.HandlerFunc) []mygin.HandlerFunc {
+		return append(append([]mygin.HandlerFunc{}, middleware...), handler)
+	}
+
+	group.GET(path, with(r.list)...)
+	group.POST(path, with(r.create)...)
+	group.GET(path+"/:id", with(r.read)...)
+	group.PATCH(path+"/:id", with(r.update)...)
+	group.DELETE(path+"/:id", with(r.delete)...)
+}
+
+// store returns Store bound to the request context when it supports ContextStore.
+func (r *Resource[T, In]) store(c *mygin.Context) Store[T] {
+	if cs, ok := r.Store.(ContextStore[T]); ok {
+		return boundStore[T]{store: cs, ctx: c.Req.Context()}
+	}
+	return r.Store
+}
+
+func (r *Resource[T, In]) authorize(c *mygin.Context, action Action, item T) bool {
+	if r.Authorize == nil {
+		return true
+	}
+	if err := r.Authorize(c, action, item); err != nil {
+		c.JSON(http.StatusForbidden, mygin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func (r *Resource[T, In]) present(c *mygin.Context, item T) any {
+	if r.Present == nil {
+		return item
+	}
+	return r.Present(c, item)
+}
+
+// writeError maps hook and store errors to status codes.
+func writeError(c *mygin.Context, status int, err error) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusUnprocessableEntity, mygin.H{"error": "validation failed", "fields": validationErr.Fields})
+		return
+	}
+	// خطاهای validation هم از هوک‌ها و هم از Validatable مدیریت‌کننده‌ها می‌آیند
+	if fields, ok := validation.Fields(err); ok {
+		c.JSON(http.StatusUnprocessableEntity, mygin.H{"error": "validation failed", "fields": fields})
+		return
+	}
+	if errors.Is(err, ErrForbidden) {
+		status = http.StatusForbidden
+	}
+	c.JSON(status, mygin.H{"error": err.Error()})
+}
+
+func (r *Resource[T, In]) list(c *mygin.Context) {
+	var zero T
+	if !r.authorize(c, ActionList, zero) {
+		return
+	}
+
+	items, err := r.store(c).ReadAll()
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if r.Filter != nil {
+		filtered := items[:0]
+		for _, item := range items {
+			if r.Filter(c, item) {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	less := r.Less
+	if less == nil {
+		less = func(a, b T) bool { return a.GetID().String() < b.GetID().String() }
+	}
+	sort.Slice(items, func(i, j int) bool { return less(items[i], items[j]) })
+
+	defaultLimit, maxLimit := r.DefaultLimit, r.MaxLimit
+	if defaultLimit <= 0 {
+		defaultLimit = 50
+	}
+	if maxLimit <= 0 {
+		maxLimit = 500
+	}
+
+	total := len(items)
+	offset := min(max(c.GetQueryIntDefault("offset", 0), 0), total)
+	limit := c.GetQueryIntDefault("limit", defaultLimit)
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, maxLimit)
+	end := min(offset+limit, total)
+
+	page := make([]any, 0, end-offset)
+	for _, item := range items[offset:end] {
+		page = append(page, r.present(c, item))
+	}
+
+	c.JSON(http.StatusOK, mygin.H{
+		"items":  page,
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// load parses the :id param and reads the item, writing the error response on failure.
+// The id may be a canonical UUID, a short ID or a prefixed short ID (see uuidutil).
+func (r *Resource[T, In]) load(c *mygin.Context) (T, bool) {
+	var zero T
+	id, err := c.ParamUUID("id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid id"})
+		return zero, false
+	}
+
+	item, err := r.store(c).Read(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, mygin.H{"error": err.Error()})
+		return zero, false
+	}
+	return item, true
+}
+
+// bind decodes and validates the request body.
+func (r *Resource[T, In]) bind(c *mygin.Context) (In, bool) {
+	in := newValue[In]()
+	if err := json.NewDecoder(c.Req.Body).Decode(in); err != nil {
+		c.JSON(http.StatusBadRequest, mygin.H{"error": "invalid JSON body: " + err.Error()})
+		return *in, false
+	}
+
+	if r.Validate != nil {
+		if err := r.Validate(c, *in); err != nil {
+			writeError(c, http.StatusBadRequest, err)
+			return *in, false
+		}
+	}
+	return *in, true
+}
+
+func (r *Resource[T, In]) read(c *mygin.Context) {
+	item, ok := r.load(c)
+	if !ok || !r.authorize(c, ActionRead, item) {
+		return
+	}
+	c.JSON(http.StatusOK, r.present(c, item))
+}
+
+func (r *Resource[T, In]) create(c *mygin.Context) {
+	var zero T
+	if !r.authorize(c, ActionCreate, zero) {
+		return
+	}
+
+	in, ok := r.bind(c)
+	if !ok {
+		return
+	}
+
+	item, err := r.Build(c, in, zero)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	created, err := r.store(c).Create(item)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, r.present(c, created))
+}
+
+func (r *Resource[T, In]) update(c *mygin.Context) {
+	existing, ok := r.load(c)
+	if !ok || !r.authorize(c, ActionUpdate, existing) {
+		return
+	}
+
+	in, ok := r.bind(c)
+	if !ok {
+		return
+	}
+
+	id := existing.GetID()
+	item, err := r.Build(c, in, existing)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	item.SetID(id) // ورودی اجازه تغییر شناسه را ندارد
+
+	updated, err := r.store(c).Update(item)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, r.present(c, updated))
+}
+
+func (r *Resource[T, In]) delete(c *mygin.Context) {
+	existing, ok := r.load(c)
+	if !ok || !r.authorize(c, ActionDelete, existing) {
+		return
+	}
+
+	if err := r.store(c).Delete(existing.GetID()); err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// newValue returns a pointer to a new In, allocating the pointed-to struct when In is itself a pointer type.
+func newValue[In any]() *In {
+	in := new(In)
+	if t := reflect.TypeOf(in).Elem(); t.Kind() == reflect.Ptr {
+		reflect.ValueOf(in).Elem().Set(reflect.New(t.Elem()))
+	}
+	return in
+}
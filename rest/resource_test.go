@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type Album struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Owner string    `json:"owner"`
+}
+
+func (a *Album) SetID(id uuid.UUID) { a.ID = id }
+func (a *Album) GetID() uuid.UUID   { return a.ID }
+func (a *Album) GetRecordSize() int { return 200 }
+
+type AlbumInput struct {
+	Title string `json:"title"`
+}
+
+func newTestEngine(t *testing.T) *mygin.Engine {
+	albums, err := collection_manager_memory.New[*Album](t.TempDir(), "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { albums.Close() })
+
+	resource := &Resource[*Album, AlbumInput]{
+		Store: albums,
+		Authorize: func(c *mygin.Context, action Action, item *Album) error {
+			if action == ActionDelete && item.Owner != c.GetHeader("X-User") {
+				return ErrForbidden
+			}
+			return nil
+		},
+		Validate: func(c *mygin.Context, in AlbumInput) error {
+			if in.Title == "" {
+				return &ValidationError{Fields: map[string]string{"title": "required"}}
+			}
+			return nil
+		},
+		Build: func(c *mygin.Context, in AlbumInput, existing *Album) (*Album, error) {
+			if existing == nil {
+				existing = &Album{Owner: c.GetHeader("X-User")}
+			}
+			existing.Title = in.Title
+			return existing, nil
+		},
+		Present: func(c *mygin.Context, item *Album) any {
+			return mygin.H{"id": item.ID, "title": item.Title}
+		},
+	}
+
+	engine := mygin.New()
+	resource.Mount(engine.Group("/api"), "/albums")
+	return engine
+}
+
+func do(engine *mygin.Engine, method, path, body, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestResource(t *testing.T) {
+	engine := newTestEngine(t)
+
+	w := do(engine, http.MethodPost, "/api/albums", `{"title":""}`, "mahdi")
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for empty title, got %d: %s", w.Code, w.Body)
+	}
+
+	w = do(engine, http.MethodPost, "/api/albums", `{"title":"Summer"}`, "mahdi")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		ID    uuid.UUID `json:"id"`
+		Title string    `json:"title"`
+		Owner string    `json:"owner"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Owner != "" {
+		t.Fatal("Present should hide the owner field")
+	}
+
+	w = do(engine, http.MethodPatch, "/api/albums/"+created.ID.String(), `{"title":"Winter"}`, "mahdi")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Winter") {
+		t.Fatalf("unexpected update response %d: %s", w.Code, w.Body)
+	}
+
+	w = do(engine, http.MethodGet, "/api/albums?limit=10", "", "mahdi")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"total":1`) {
+		t.Fatalf("unexpected list response %d: %s", w.Code, w.Body)
+	}
+
+	w = do(engine, http.MethodDelete, "/api/albums/"+created.ID.String(), "", "someone-else")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for foreign delete, got %d", w.Code)
+	}
+
+	w = do(engine, http.MethodDelete, "/api/albums/"+created.ID.String(), "", "mahdi")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	w = do(engine, http.MethodGet, "/api/albums/"+created.ID.String(), "", "mahdi")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", w.Code)
+	}
+}
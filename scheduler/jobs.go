@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Compactor is implemented by collection managers that can reclaim the space of
+// deleted records, e.g. collection_manager_memory.Manager.
+type Compactor interface {
+	Compact() error
+}
+
+// CompactJob returns a job that compacts every given manager in turn. A failing
+// manager does not stop the others; all errors are returned together.
+func CompactJob(managers ...Compactor) Job {
+	return func(ctx context.Context) error {
+		var errs []error
+		for i, manager := range managers {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := manager.Compact(); err != nil {
+				errs = append(errs, fmt.Errorf("error compacting manager %d: %w", i, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
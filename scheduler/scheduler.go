@@ -0,0 +1,304 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+var logger = logging.For("scheduler")
+
+// پکیج scheduler کارهای دوره‌ای (فشرده‌سازی، بکاپ، پاک‌سازی TTL و ...) را با
+// عبارت cron یا فاصله ثابت اجرا می‌کند. Start و Stop امضای func(context.Context) error
+// دارند تا مستقیماً به هوک‌های چرخه عمر سرور وصل شوند.
+
+// Job is the work run by the scheduler. ctx is cancelled when the scheduler
+// stops or the job timeout expires.
+type Job func(ctx context.Context) error
+
+// ErrDuplicateJob is returned when a job name is registered twice.
+var ErrDuplicateJob = errors.New("job already registered")
+
+// Option configures a job.
+type Option func(*entry)
+
+// WithJitter delays every activation by a random duration in [0, d), so that
+// many instances do not hit the storage at the same moment.
+func WithJitter(d time.Duration) Option {
+	return func(e *entry) { e.jitter = d }
+}
+
+// WithTimeout cancels the job context after d.
+func WithTimeout(d time.Duration) Option {
+	return func(e *entry) { e.timeout = d }
+}
+
+// AllowOverlap lets a new activation start while the previous run is still going.
+// By default an activation that finds the job running is skipped.
+func AllowOverlap() Option {
+	return func(e *entry) { e.allowOverlap = true }
+}
+
+// JobStatus is a snapshot of a registered job.
+type JobStatus struct {
+	Name      string
+	Next      time.Time
+	LastStart time.Time
+	LastEnd   time.Time
+	LastError error
+	Runs      int
+	Failures  int
+	Skipped   int // activations skipped because the previous run was still going
+	Running   int
+}
+
+type entry struct {
+	name         string
+	schedule     Schedule
+	job          Job
+	jitter       time.Duration
+	timeout      time.Duration
+	allowOverlap bool
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// Scheduler runs registered jobs on their schedules between Start and Stop.
+// Nothing stops it implicitly: callers must call Stop on shutdown, e.g. by
+// registering both methods as lifecycle hooks of a mygin Engine:
+//
+//	s := scheduler.New()
+//	s.Every("compact", time.Hour, compactJob)
+//	engine.OnStart(s.Start)
+//	engine.OnShutdown(s.Stop)
+//
+// OnShutdown hooks run after the servers stopped, so Stop then waits for
+// running jobs within the engine's ShutdownTimeout.
+type Scheduler struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	ctx     context.Context
+	cancel  context.CancelFunc
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+
+	// ErrorHandler is called when a job returns an error or panics. Defaults to logging an error.
+	ErrorHandler func(name string, err error)
+}
+
+// New returns a stopped scheduler without jobs.
+func New() *Scheduler {
+	return &Scheduler{entries: make(map[string]*entry)}
+}
+
+// Add registers a job. Jobs added while the scheduler is running start immediately.
+func (s *Scheduler) Add(name string, schedule Schedule, job Job, opts ...Option) error {
+	e := &entry{name: name, schedule: schedule, job: job}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.status.Name = name
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	s.entries[name] = e
+	if s.ctx != nil {
+		s.startLoop(e)
+	}
+	return nil
+}
+
+// Cron registers a job with a cron expression (see ParseCron).
+func (s *Scheduler) Cron(name, expr string, job Job, opts ...Option) error {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return err
+	}
+	return s.Add(name, schedule, job, opts...)
+}
+
+// Every registers a job that runs at a fixed interval.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job, opts ...Option) error {
+	return s.Add(name, Every(interval), job, opts...)
+}
+
+// Start starts every job loop. It returns immediately; calling it on a running
+// scheduler is a no-op.
+func (s *Scheduler) Start(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx != nil {
+		return nil
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, e := range s.entries {
+		s.startLoop(e)
+	}
+	return nil
+}
+
+// Stop stops scheduling new runs, cancels running jobs and waits for them to
+// return or for ctx to be done. mygin's Engine.Shutdown does not call it unless
+// it was registered with OnShutdown.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.ctx == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.ctx, s.cancel = nil, nil
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for running jobs: %w", ctx.Err())
+	}
+}
+
+// Run runs a registered job now, outside its schedule, and returns its error.
+// Overlap protection applies as for scheduled runs.
+func (s *Scheduler) Run(ctx context.Context, name string) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("job not found: %s", name)
+	}
+	if !e.begin() {
+		return fmt.Errorf("job %s is already running", name)
+	}
+	return s.execute(ctx, e)
+}
+
+// Jobs returns a snapshot of every job ordered by name.
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	entries := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+
+	result := make([]JobStatus, 0, len(entries))
+	for _, e := range entries {
+		e.mu.Lock()
+		result = append(result, e.status)
+		e.mu.Unlock()
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// startLoop must be called with s.mu held.
+func (s *Scheduler) startLoop(e *entry) {
+	ctx := s.ctx
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		s.loop(ctx, e)
+	}()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	last := time.Now()
+	for {
+		next := e.schedule.Next(last)
+		if next.IsZero() {
+			return
+		}
+		last = next
+		if e.jitter > 0 {
+			next = next.Add(rand.N(e.jitter))
+		}
+
+		e.mu.Lock()
+		e.status.Next = next
+		e.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !e.begin() {
+			continue
+		}
+		s.runs.Add(1)
+		go func() {
+			defer s.runs.Done()
+			if err := s.execute(ctx, e); err != nil {
+				s.handleError(e.name, err)
+			}
+		}()
+	}
+}
+
+// begin reserves a run slot, or records a skipped activation when the job is
+// still running and overlap is not allowed.
+func (e *entry) begin() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.status.Running > 0 && !e.allowOverlap {
+		e.status.Skipped++
+		return false
+	}
+	e.status.Running++
+	e.status.LastStart = time.Now()
+	return true
+}
+
+func (s *Scheduler) execute(ctx context.Context, e *entry) (err error) {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+		e.mu.Lock()
+		e.status.Running--
+		e.status.Runs++
+		e.status.LastEnd = time.Now()
+		e.status.LastError = err
+		if err != nil {
+			e.status.Failures++
+		}
+		e.mu.Unlock()
+	}()
+
+	return e.job(ctx)
+}
+
+func (s *Scheduler) handleError(name string, err error) {
+	if s.ErrorHandler != nil {
+		s.ErrorHandler(name, err)
+		return
+	}
+	logger.Error("job failed", "job", name, "error", err)
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2024, time.January, 31, 10, 7, 30, 0, time.UTC) // Wednesday
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, 2, 1, 3, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 feb *", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := ParseCronIn(c.expr, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := s.Next(base); !got.Equal(c.want) {
+			t.Errorf("%s: next = %s, want %s", c.expr, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"* * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every nope"} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestOverlapProtection(t *testing.T) {
+	s := New()
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+
+	err := s.Every("slow", 5*time.Millisecond, func(ctx context.Context) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		if n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Start(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	status := s.Jobs()[0]
+	if maxRunning.Load() != 1 {
+		t.Fatalf("expected at most one concurrent run, got %d", maxRunning.Load())
+	}
+	if status.Skipped == 0 || status.Runs == 0 {
+		t.Fatalf("expected runs and skipped activations, got %+v", status)
+	}
+}
@@ -0,0 +1,38 @@
+package search
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Handler serves the index:
+//
+//	GET /search?q=tehran&type=photos,albums&offset=0&limit=20
+//
+// type may also be repeated. limit is capped at maxLimit (100 when zero).
+func Handler(x *Index, maxLimit int) mygin.HandlerFunc {
+	if maxLimit <= 0 {
+		maxLimit = 100
+	}
+	return func(c *mygin.Context) {
+		q := Query{
+			Text:   c.GetQuery("q"),
+			Offset: c.GetQueryIntDefault("offset", 0),
+			Limit:  min(c.GetQueryIntDefault("limit", 20), maxLimit),
+		}
+		for _, value := range c.Req.URL.Query()["type"] {
+			for _, typ := range strings.Split(value, ",") {
+				if typ = strings.TrimSpace(typ); typ != "" {
+					q.Types = append(q.Types, typ)
+				}
+			}
+		}
+		if strings.TrimSpace(q.Text) == "" {
+			c.JSON(http.StatusBadRequest, mygin.H{"error": "query parameter q is required"})
+			return
+		}
+		c.JSON(http.StatusOK, x.Search(q))
+	}
+}
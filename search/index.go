@@ -0,0 +1,282 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+// Index یک ایندکس معکوس در رم است که فیلدهای متنی چند کالکشن را کنار هم نگه می‌دارد
+// تا با یک پرس‌وجو در همه جستجو شود. هر سند با (Type, ID) شناخته می‌شود.
+
+// FieldText is one weighted text field of a document.
+type FieldText struct {
+	Name   string
+	Text   string
+	Weight float64 // 1 when zero
+}
+
+// Document is an entry of the index. Item is returned with hits as is.
+type Document struct {
+	Type   string
+	ID     uuid.UUID
+	Fields []FieldText
+	Item   any
+}
+
+// Query describes a search request.
+type Query struct {
+	Text   string
+	Types  []string // restrict hits to these document types, all types when empty
+	Offset int
+	Limit  int // 20 when zero
+}
+
+// Hit is a matched document.
+type Hit struct {
+	Type  string    `json:"type"`
+	ID    uuid.UUID `json:"id"`
+	Score float64   `json:"score"`
+	Item  any       `json:"item,omitempty"`
+}
+
+// Results is one page of hits ordered by score.
+type Results struct {
+	Hits   []Hit `json:"hits"`
+	Total  int   `json:"total"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type docKey struct {
+	typ string
+	id  uuid.UUID
+}
+
+type indexedDoc struct {
+	item  any
+	terms map[string]float64 // term -> weighted frequency
+}
+
+// Index is a concurrent in-memory full-text index.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[docKey]*indexedDoc
+	postings map[string]map[docKey]float64
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[docKey]*indexedDoc),
+		postings: make(map[string]map[docKey]float64),
+	}
+}
+
+// Put adds or replaces a document.
+func (x *Index) Put(doc Document) {
+	terms := make(map[string]float64)
+	for _, field := range doc.Fields {
+		weight := field.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		for _, token := range Tokenize(field.Text) {
+			terms[token] += weight
+		}
+	}
+
+	key := docKey{doc.Type, doc.ID}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(key)
+	x.docs[key] = &indexedDoc{item: doc.Item, terms: terms}
+	for term, weight := range terms {
+		posting, ok := x.postings[term]
+		if !ok {
+			posting = make(map[docKey]float64)
+			x.postings[term] = posting
+		}
+		posting[key] = weight
+	}
+}
+
+// Remove deletes a document.
+func (x *Index) Remove(typ string, id uuid.UUID) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(docKey{typ, id})
+}
+
+// Len returns the number of indexed documents.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+func (x *Index) remove(key docKey) {
+	doc, ok := x.docs[key]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		posting := x.postings[term]
+		delete(posting, key)
+		if len(posting) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.docs, key)
+}
+
+// Search returns the documents containing every query term. The last term also
+// matches as a prefix so partial input finds results while typing. Hits are
+// ranked by the sum of weighted term frequency times inverse document frequency.
+func (x *Index) Search(q Query) Results {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	results := Results{Hits: []Hit{}, Offset: max(q.Offset, 0), Limit: limit}
+
+	terms := Tokenize(q.Text)
+	if len(terms) == 0 {
+		return results
+	}
+
+	var types map[string]bool
+	if len(q.Types) > 0 {
+		types = make(map[string]bool, len(q.Types))
+		for _, t := range q.Types {
+			types[t] = true
+		}
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	total := float64(len(x.docs))
+	var scores map[docKey]float64
+	for i, term := range terms {
+		matched := make(map[docKey]float64)
+		x.scoreTerm(term, total, matched)
+		if i == len(terms)-1 {
+			for indexed := range x.postings {
+				if indexed != term && strings.HasPrefix(indexed, term) {
+					x.scoreTerm(indexed, total, matched)
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for key := range scores {
+			if score, ok := matched[key]; ok {
+				scores[key] += score
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for key, score := range scores {
+		if types != nil && !types[key.typ] {
+			continue
+		}
+		hits = append(hits, Hit{Type: key.typ, ID: key.id, Score: score, Item: x.docs[key].item})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Type != hits[j].Type {
+			return hits[i].Type < hits[j].Type
+		}
+		return hits[i].ID.String() < hits[j].ID.String()
+	})
+
+	results.Total = len(hits)
+	start := min(results.Offset, len(hits))
+	end := min(start+limit, len(hits))
+	results.Hits = hits[start:end]
+	return results
+}
+
+// scoreTerm adds tf-idf scores of term into scores, keeping the best score per document
+// when several prefix matches hit the same document.
+func (x *Index) scoreTerm(term string, total float64, scores map[docKey]float64) {
+	posting := x.postings[term]
+	if len(posting) == 0 {
+		return
+	}
+	idf := math.Log(1 + total/float64(len(posting)))
+	for key, weight := range posting {
+		score := weight * idf
+		if score > scores[key] {
+			scores[key] = score
+		}
+	}
+}
+
+// Tokenize lower-cases text, unifies Arabic and Persian letter variants and splits it
+// into words of letters and digits.
+func Tokenize(text string) []string {
+	text = persianReplacer.Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// persianReplacer یکسان‌سازی «ي/ی» و «ك/ک» و ارقام فارسی و عربی را انجام می‌دهد.
+var persianReplacer = strings.NewReplacer(
+	"ي", "ی", "ى", "ی", "ك", "ک", "ة", "ه", "\u200c", " ",
+	"۰", "0", "۱", "1", "۲", "2", "۳", "3", "۴", "4", "۵", "5", "۶", "6", "۷", "7", "۸", "8", "۹", "9",
+	"٠", "0", "١", "1", "٢", "2", "٣", "3", "٤", "4", "٥", "5", "٦", "6", "٧", "7", "٨", "8", "٩", "9",
+)
+
+// Field selects a weighted text field of T for Register.
+type Field[T any] struct {
+	Name   string
+	Weight float64
+	Value  func(T) string
+}
+
+// Register indexes every item of manager under typ and keeps the index in sync
+// through the manager's change hooks. The returned function stops syncing; the
+// documents stay in the index until removed.
+func Register[T collection_manager_memory.CollectionItem](x *Index, typ string, manager *collection_manager_memory.Manager[T], fields ...Field[T]) (stop func(), err error) {
+	document := func(item T) Document {
+		doc := Document{Type: typ, ID: item.GetID(), Item: item, Fields: make([]FieldText, len(fields))}
+		for i, field := range fields {
+			doc.Fields[i] = FieldText{Name: field.Name, Text: field.Value(item), Weight: field.Weight}
+		}
+		return doc
+	}
+
+	stop = manager.OnChange(func(change collection_manager_memory.Change[T]) {
+		if change.Type == collection_manager_memory.ChangeDelete {
+			x.Remove(typ, change.ID)
+			return
+		}
+		x.Put(document(change.Item))
+	})
+
+	items, err := manager.ReadAll()
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	for _, item := range items {
+		x.Put(document(item))
+	}
+	return stop, nil
+}
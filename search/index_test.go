@@ -0,0 +1,92 @@
+package search
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type album struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (a *album) SetID(id uuid.UUID)       { a.ID = id }
+func (a *album) GetID() uuid.UUID         { return a.ID }
+func (a *album) GetRecordSize() int       { return 512 }
+func (a *album) SetCreatedAt(t time.Time) { a.CreatedAt = t }
+func (a *album) SetUpdatedAt(t time.Time) { a.UpdatedAt = t }
+
+func TestIndexSearch(t *testing.T) {
+	x := NewIndex()
+	photo := uuid.New()
+	x.Put(Document{Type: "photos", ID: photo, Fields: []FieldText{{Name: "caption", Text: "Sunset over Tehran"}}})
+	x.Put(Document{Type: "photos", ID: uuid.New(), Fields: []FieldText{{Name: "caption", Text: "Tehran metro"}}})
+	x.Put(Document{Type: "people", ID: uuid.New(), Fields: []FieldText{{Name: "name", Text: "علي", Weight: 2}}})
+
+	if r := x.Search(Query{Text: "tehran sun"}); r.Total != 1 || r.Hits[0].ID != photo {
+		t.Fatalf("expected prefix match on last term, got %+v", r)
+	}
+	if r := x.Search(Query{Text: "tehran", Types: []string{"people"}}); r.Total != 0 {
+		t.Fatalf("type filter ignored: %+v", r)
+	}
+	if r := x.Search(Query{Text: "tehran", Limit: 1, Offset: 1}); r.Total != 2 || len(r.Hits) != 1 {
+		t.Fatalf("bad page: %+v", r)
+	}
+	// «ي» عربی و «ی» فارسی یکسان جستجو می‌شوند
+	if r := x.Search(Query{Text: "علی"}); r.Total != 1 {
+		t.Fatalf("expected normalized Persian match, got %+v", r)
+	}
+
+	x.Remove("photos", photo)
+	if r := x.Search(Query{Text: "sunset"}); r.Total != 0 {
+		t.Fatalf("removed document still found: %+v", r)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	manager, err := collection_manager_memory.New[*album](t.TempDir(), "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	existing, _ := manager.Create(&album{Title: "Summer", Description: "beach trip"})
+
+	x := NewIndex()
+	stop, err := Register(x, "albums", manager,
+		Field[*album]{Name: "title", Weight: 3, Value: func(a *album) string { return a.Title }},
+		Field[*album]{Name: "description", Value: func(a *album) string { return a.Description }},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	created, _ := manager.Create(&album{Title: "Beach", Description: "summer house"})
+	r := x.Search(Query{Text: "beach"})
+	if r.Total != 2 || r.Hits[0].ID != created.ID {
+		t.Fatalf("expected title match ranked first, got %+v", r)
+	}
+
+	manager.Delete(existing.ID)
+	if r := x.Search(Query{Text: "summer"}); r.Total != 1 || r.Hits[0].ID != created.ID {
+		t.Fatalf("delete not synced: %+v", r)
+	}
+
+	engine := mygin.New()
+	engine.GET("/search", Handler(x, 0))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/search?q=beach&type=albums", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), created.ID.String()) {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body)
+	}
+}
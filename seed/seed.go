@@ -0,0 +1,237 @@
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture is the content of one fixture file.
+//
+//	collection: albums
+//	records:
+//	  - ref: summer
+//	    data: {title: "Summer 2024"}
+//
+// String values of the form "@collection.ref" are replaced with the ID of the
+// record created earlier under that ref, which is how join tables point at their parents:
+//
+//	collection: photo_albums
+//	join: true
+//	records:
+//	  - data: {albumId: "@albums.summer", photoId: "@photos.beach"}
+type Fixture struct {
+	Collection string          `json:"collection" yaml:"collection"`
+	Join       bool            `json:"join" yaml:"join"`
+	Records    []FixtureRecord `json:"records" yaml:"records"`
+}
+
+// FixtureRecord is a single record of a fixture.
+type FixtureRecord struct {
+	Ref  string         `json:"ref" yaml:"ref"`
+	Data map[string]any `json:"data" yaml:"data"`
+}
+
+// Target inserts decoded fixture records into a collection and returns the ID
+// assigned to the record (uuid.Nil for join items).
+type Target interface {
+	Insert(data []byte) (uuid.UUID, error)
+}
+
+// TargetFunc adapts a function to the Target interface.
+type TargetFunc func(data []byte) (uuid.UUID, error)
+
+func (f TargetFunc) Insert(data []byte) (uuid.UUID, error) { return f(data) }
+
+// Loader loads fixture files into registered collections.
+type Loader struct {
+	mu      sync.Mutex
+	targets map[string]Target
+	refs    map[string]uuid.UUID // key: "collection.ref"
+}
+
+// New creates an empty Loader.
+func New() *Loader {
+	return &Loader{
+		targets: make(map[string]Target),
+		refs:    make(map[string]uuid.UUID),
+	}
+}
+
+// Register makes a collection available to fixtures under the given name.
+func (l *Loader) Register(name string, target Target) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.targets[name] = target
+}
+
+// Ref returns the ID of a record created under "collection.ref".
+func (l *Loader) Ref(collection, ref string) (uuid.UUID, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id, ok := l.refs[collection+"."+ref]
+	return id, ok
+}
+
+// LoadDir loads every .json, .yaml and .yml file of dir in lexical order, so
+// files can be prefixed with numbers to load parents before join tables.
+func (l *Loader) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading fixture directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err := l.LoadFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile loads a single fixture file.
+func (l *Loader) LoadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading fixture %s: %w", path, err)
+	}
+
+	fixture, err := Parse(content, filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("error parsing fixture %s: %w", path, err)
+	}
+
+	if err := l.Load(fixture); err != nil {
+		return fmt.Errorf("fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// Parse decodes fixture content; ext selects the format (".json", ".yaml" or ".yml").
+func Parse(content []byte, ext string) (Fixture, error) {
+	var fixture Fixture
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, &fixture); err != nil {
+			return fixture, err
+		}
+	case ".json":
+		if err := json.Unmarshal(content, &fixture); err != nil {
+			return fixture, err
+		}
+	default:
+		return fixture, fmt.Errorf("unsupported fixture format %q", ext)
+	}
+
+	if fixture.Collection == "" {
+		return fixture, errors.New("fixture has no collection name")
+	}
+	return fixture, nil
+}
+
+// Load inserts the records of a parsed fixture.
+func (l *Loader) Load(fixture Fixture) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	target, ok := l.targets[fixture.Collection]
+	if !ok {
+		return fmt.Errorf("collection %q is not registered", fixture.Collection)
+	}
+
+	for i, record := range fixture.Records {
+		resolved, err := l.resolve(record.Data)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+
+		data, err := json.Marshal(resolved)
+		if err != nil {
+			return fmt.Errorf("record %d: error marshaling data: %w", i, err)
+		}
+
+		id, err := target.Insert(data)
+		if err != nil {
+			return fmt.Errorf("record %d: error inserting into %s: %w", i, fixture.Collection, err)
+		}
+
+		if record.Ref != "" {
+			if id == uuid.Nil {
+				return fmt.Errorf("record %d: ref %q set but %s does not assign IDs", i, record.Ref, fixture.Collection)
+			}
+			l.refs[fixture.Collection+"."+record.Ref] = id
+		}
+	}
+	return nil
+}
+
+// resolve replaces "@collection.ref" strings with the referenced IDs.
+func (l *Loader) resolve(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(v, "@") {
+			return v, nil
+		}
+		if strings.HasPrefix(v, "@@") {
+			return v[1:], nil // "@@" escapes a literal "@"
+		}
+		id, ok := l.refs[v[1:]]
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference %s", v)
+		}
+		return id.String(), nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			resolved, err := l.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := l.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// newItem returns a new zero item for T, allocating the struct when T is a pointer type.
+func newItem[T any]() T {
+	var zero T
+	t := reflect.TypeOf(zero)
+	if t != nil && t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface().(T)
+	}
+	return zero
+}
@@ -0,0 +1,110 @@
+package seed
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_join"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+type Album struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (a *Album) SetID(id uuid.UUID)       { a.ID = id }
+func (a *Album) GetID() uuid.UUID         { return a.ID }
+func (a *Album) SetCreatedAt(t time.Time) { a.CreatedAt = t }
+func (a *Album) SetUpdatedAt(t time.Time) { a.UpdatedAt = t }
+func (a *Album) GetRecordSize() int       { return 250 }
+
+type PhotoAlbum struct {
+	AlbumID uuid.UUID `json:"albumId"`
+	PhotoID uuid.UUID `json:"photoId"`
+}
+
+func (pa *PhotoAlbum) GetRecordSize() int { return 100 }
+func (pa *PhotoAlbum) GetCompositeKey() string {
+	return fmt.Sprintf("%s:%s", pa.AlbumID.String(), pa.PhotoID.String())
+}
+
+const albumsYAML = `
+collection: albums
+records:
+  - ref: summer
+    data:
+      title: Summer
+  - ref: winter
+    data: {title: Winter}
+`
+
+const photoAlbumsJSON = `{
+  "collection": "photo_albums",
+  "join": true,
+  "records": [
+    {"data": {"albumId": "@albums.summer", "photoId": "0199c5b8-0000-7000-8000-000000000001"}},
+    {"data": {"albumId": "@albums.winter", "photoId": "0199c5b8-0000-7000-8000-000000000002"}}
+  ]
+}`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	albums, err := collection_manager_memory.New[*Album](dir, "albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer albums.Close()
+
+	photoAlbums, err := collection_manager_join.New[*PhotoAlbum](dir, "photo_albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer photoAlbums.Close()
+
+	loader := New()
+	loader.Register("albums", Collection[*Album](albums))
+	loader.Register("photo_albums", Join[*PhotoAlbum](photoAlbums))
+
+	for _, f := range []struct{ content, ext string }{{albumsYAML, ".yaml"}, {photoAlbumsJSON, ".json"}} {
+		fixture, err := Parse([]byte(f.content), f.ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := loader.Load(fixture); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summerID, ok := loader.Ref("albums", "summer")
+	if !ok {
+		t.Fatal("ref albums.summer was not recorded")
+	}
+	album, err := albums.Read(summerID)
+	if err != nil || album.Title != "Summer" {
+		t.Fatalf("unexpected album %v: %v", album, err)
+	}
+
+	items, err := photoAlbums.GetByParentID(summerID)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one photo in summer album, got %v: %v", items, err)
+	}
+}
+
+func TestUnresolvedReference(t *testing.T) {
+	loader := New()
+	loader.Register("photo_albums", TargetFunc(func([]byte) (uuid.UUID, error) { return uuid.Nil, nil }))
+
+	fixture, err := Parse([]byte(photoAlbumsJSON), ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Load(fixture); err == nil {
+		t.Fatal("expected unresolved reference error")
+	}
+}
@@ -0,0 +1,96 @@
+package seed
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+// IDItem is implemented by items of collections that assign UUIDs.
+type IDItem interface {
+	GetID() uuid.UUID
+}
+
+// Creator is the Create method shared by collection_manager_memory, collection_manager_index
+// and collection_manager_json managers.
+type Creator[T any] interface {
+	Create(item T) (T, error)
+}
+
+// Collection returns a Target that decodes fixture records into T and creates
+// them through the manager, e.g. seed.Collection[*Album](albumManager).
+func Collection[T IDItem](manager Creator[T]) Target {
+	return TargetFunc(func(data []byte) (uuid.UUID, error) {
+		item := newItem[T]()
+		if err := json.Unmarshal(data, item); err != nil {
+			return uuid.Nil, fmt.Errorf("error unmarshaling item: %w", err)
+		}
+		created, err := manager.Create(item)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return created.GetID(), nil
+	})
+}
+
+// Join returns a Target for join collections (collection_manager_join) whose
+// items are identified by a composite key instead of an ID.
+func Join[T any](manager Creator[T]) Target {
+	return TargetFunc(func(data []byte) (uuid.UUID, error) {
+		item := newItem[T]()
+		if err := json.Unmarshal(data, item); err != nil {
+			return uuid.Nil, fmt.Errorf("error unmarshaling item: %w", err)
+		}
+		_, err := manager.Create(item)
+		return uuid.Nil, err
+	})
+}
+
+// FileTarget writes records straight into <dir>/<name>.db in the fixed record
+// format used by collection_manager_memory and collection_manager_join, without
+// knowing the Go type of the items. Unless join is set every record gets an "id"
+// (UUID v7) when it does not have one. The returned close function must be called
+// when seeding is done.
+func FileTarget(dir, name string, recordSize int, join bool) (Target, func() error, error) {
+	fh, err := collection_manager_memory.NewFileHandler(dir, name, recordSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target := TargetFunc(func(data []byte) (uuid.UUID, error) {
+		if join {
+			_, err := fh.WriteRecord(data)
+			return uuid.Nil, err
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return uuid.Nil, fmt.Errorf("error unmarshaling record: %w", err)
+		}
+
+		var id uuid.UUID
+		if raw, ok := fields["id"].(string); ok && raw != "" {
+			if id, err = uuid.Parse(raw); err != nil {
+				return uuid.Nil, fmt.Errorf("invalid id %q: %w", raw, err)
+			}
+		} else {
+			if id, err = uuid.NewV7(); err != nil {
+				return uuid.Nil, fmt.Errorf("error generating UUID v7: %w", err)
+			}
+			fields["id"] = id.String()
+		}
+
+		record, err := json.Marshal(fields)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("error marshaling record: %w", err)
+		}
+		if _, err := fh.WriteRecord(record); err != nil {
+			return uuid.Nil, err
+		}
+		return id, nil
+	})
+
+	return target, fh.Close, nil
+}
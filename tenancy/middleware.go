@@ -0,0 +1,85 @@
+package tenancy
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mahdi-cpp/iris-tools/auth"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Context keys set by Middleware.
+const (
+	TenantKey    = "tenancy.tenant"
+	ResourcesKey = "tenancy.resources"
+)
+
+// Resolver returns the tenant of a request, or "" when there is none.
+type Resolver func(c *mygin.Context) string
+
+// FromHeader resolves the tenant from a request header, e.g. "X-Tenant-ID".
+func FromHeader(name string) Resolver {
+	return func(c *mygin.Context) string { return c.GetHeader(name) }
+}
+
+// FromParam resolves the tenant from a route parameter, e.g. "/t/:tenant/photos".
+func FromParam(name string) Resolver {
+	return func(c *mygin.Context) string { return c.Param(name) }
+}
+
+// FromUser uses the authenticated user's ID as tenant, giving every user its own
+// data directory. It must run after auth's Require middleware.
+func FromUser() Resolver {
+	return func(c *mygin.Context) string {
+		if user, ok := auth.CurrentUser(c); ok {
+			return user.ID.String()
+		}
+		return ""
+	}
+}
+
+// Middleware resolves the tenant, acquires its resources for the rest of the chain
+// and stores them in the Context (see Current). Requests without a tenant get 401,
+// invalid tenant IDs 400.
+func Middleware[R any](m *Manager[R], resolve Resolver) mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		id := resolve(c)
+		if id == "" {
+			c.JSON(http.StatusUnauthorized, mygin.H{"error": "tenant required"})
+			c.Abort()
+			return
+		}
+
+		res, release, err := m.Acquire(id)
+		switch {
+		case errors.Is(err, ErrInvalidTenant):
+			c.JSON(http.StatusBadRequest, mygin.H{"error": err.Error()})
+			c.Abort()
+			return
+		case err != nil:
+			logger.Error("error opening tenant", "tenant", id, "error", err)
+			c.JSON(http.StatusServiceUnavailable, mygin.H{"error": "tenant unavailable"})
+			c.Abort()
+			return
+		}
+		defer release()
+
+		c.Set(TenantKey, id)
+		c.Set(ResourcesKey, res)
+		c.Next()
+	}
+}
+
+// Tenant returns the tenant ID resolved by Middleware.
+func Tenant(c *mygin.Context) string {
+	id, _ := c.Get(TenantKey)
+	s, _ := id.(string)
+	return s
+}
+
+// Current returns the tenant resources stored by Middleware.
+func Current[R any](c *mygin.Context) (R, bool) {
+	value, _ := c.Get(ResourcesKey)
+	res, ok := value.(R)
+	return res, ok
+}
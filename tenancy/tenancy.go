@@ -0,0 +1,249 @@
+package tenancy
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/mahdi-cpp/iris-tools/logging"
+)
+
+// پکیج tenancy برای هر tenant (مثلاً هر کاربر) یک پوشه داده جدا نگه می‌دارد و
+// مدیریت‌کننده‌های کالکشن آن را به صورت تنبل باز می‌کند. تعداد tenantهای باز محدود است و
+// کم‌استفاده‌ترین tenant بسته می‌شود؛ tenantی که هنوز درخواست فعال دارد تا آزاد شدن باز می‌ماند.
+//
+//	type Resources struct {
+//		Photos *collection_manager_memory.Manager[*Photo]
+//	}
+//
+//	func (r *Resources) Close() error { return r.Photos.Close() }
+//
+//	tenants, err := tenancy.New("/var/iris/tenants", 100, func(tenant, dir string) (*Resources, error) {
+//		photos, err := collection_manager_memory.New[*Photo](dir, "photos")
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &Resources{Photos: photos}, nil
+//	})
+
+var logger = logging.For("tenancy")
+
+var (
+	ErrInvalidTenant = errors.New("invalid tenant id")
+	ErrClosed        = errors.New("tenant manager is closed")
+)
+
+var tenantPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidTenant reports whether id is usable as a tenant directory name.
+func ValidTenant(id string) bool {
+	return tenantPattern.MatchString(id)
+}
+
+// OpenFunc opens the resources of a tenant stored in dir.
+type OpenFunc[R any] func(tenant, dir string) (R, error)
+
+type tenant[R any] struct {
+	id      string
+	res     R
+	refs    int
+	evicted bool // close once refs drops to zero
+	element *list.Element
+	ready   chan struct{}
+	openErr error
+}
+
+// Manager opens and caches per-tenant resources. Resources implementing io.Closer
+// are closed when evicted and on Close.
+type Manager[R any] struct {
+	root    string
+	maxOpen int
+	open    OpenFunc[R]
+
+	mu      sync.Mutex
+	tenants map[string]*tenant[R]
+	lru     *list.List // front = most recently used
+	closed  bool
+}
+
+// New returns a Manager storing tenant data under root/<tenant>. At most maxOpen
+// tenants are kept open (unbounded when maxOpen <= 0).
+func New[R any](root string, maxOpen int, open OpenFunc[R]) (*Manager[R], error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("error creating tenants directory: %w", err)
+	}
+	return &Manager[R]{
+		root:    root,
+		maxOpen: maxOpen,
+		open:    open,
+		tenants: make(map[string]*tenant[R]),
+		lru:     list.New(),
+	}, nil
+}
+
+// Dir returns the data directory of a tenant.
+func (m *Manager[R]) Dir(id string) string {
+	return filepath.Join(m.root, id)
+}
+
+// Acquire returns the resources of a tenant, opening them when needed. release must be
+// called once the caller is done; until then the tenant is never closed, so a tenant
+// is only ever open once.
+func (m *Manager[R]) Acquire(id string) (res R, release func(), err error) {
+	var zero R
+	if !ValidTenant(id) {
+		return zero, nil, fmt.Errorf("%w: %q", ErrInvalidTenant, id)
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return zero, nil, ErrClosed
+	}
+	t, ok := m.tenants[id]
+	if ok {
+		t.refs++
+		t.evicted = false
+		m.lru.MoveToFront(t.element)
+		m.mu.Unlock()
+		<-t.ready
+	} else {
+		t = &tenant[R]{id: id, refs: 1, ready: make(chan struct{})}
+		t.element = m.lru.PushFront(t)
+		m.tenants[id] = t
+		idle := m.evictLocked()
+		m.mu.Unlock()
+		closeAll(idle)
+
+		t.res, t.openErr = m.openTenant(id)
+		close(t.ready)
+	}
+
+	if t.openErr != nil {
+		m.mu.Lock()
+		t.refs--
+		if m.tenants[id] == t {
+			m.removeLocked(t)
+		}
+		m.mu.Unlock()
+		return zero, nil, t.openErr
+	}
+
+	var once sync.Once
+	return t.res, func() { once.Do(func() { m.release(t) }) }, nil
+}
+
+// Open returns the number of open tenants.
+func (m *Manager[R]) Open() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.tenants)
+}
+
+// Evict closes a tenant, e.g. before deleting its data. A tenant in use is closed
+// when the last release runs unless it is acquired again in the meantime.
+func (m *Manager[R]) Evict(id string) {
+	m.mu.Lock()
+	t, ok := m.tenants[id]
+	var idle []*tenant[R]
+	if ok {
+		if t.refs == 0 {
+			m.removeLocked(t)
+			idle = append(idle, t)
+		} else {
+			t.evicted = true
+		}
+	}
+	m.mu.Unlock()
+	closeAll(idle)
+}
+
+// Close closes every tenant that is not in use; the others are closed on their last
+// release. Acquire fails with ErrClosed afterwards.
+func (m *Manager[R]) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	var idle []*tenant[R]
+	for _, t := range m.tenants {
+		if t.refs == 0 {
+			m.removeLocked(t)
+			idle = append(idle, t)
+		} else {
+			t.evicted = true
+		}
+	}
+	m.mu.Unlock()
+	closeAll(idle)
+	return nil
+}
+
+func (m *Manager[R]) openTenant(id string) (R, error) {
+	var zero R
+	dir := m.Dir(id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return zero, fmt.Errorf("error creating tenant directory: %w", err)
+	}
+	res, err := m.open(id, dir)
+	if err != nil {
+		return zero, fmt.Errorf("error opening tenant %s: %w", id, err)
+	}
+	logger.Debug("tenant opened", "tenant", id)
+	return res, nil
+}
+
+func (m *Manager[R]) release(t *tenant[R]) {
+	m.mu.Lock()
+	t.refs--
+	var idle []*tenant[R]
+	if t.refs == 0 && t.evicted && m.tenants[t.id] == t {
+		m.removeLocked(t)
+		idle = append(idle, t)
+	} else if t.refs == 0 {
+		idle = m.evictLocked() // ممکن است قبلاً به خاطر استفاده بیش از سقف باز مانده باشیم
+	}
+	m.mu.Unlock()
+	closeAll(idle)
+}
+
+// evictLocked removes least recently used idle tenants above maxOpen and returns them
+// for closing. Tenants in use are skipped, so the limit can be exceeded temporarily.
+func (m *Manager[R]) evictLocked() []*tenant[R] {
+	if m.maxOpen <= 0 {
+		return nil
+	}
+	var idle []*tenant[R]
+	for e := m.lru.Back(); e != nil && m.lru.Len() > m.maxOpen; {
+		prev := e.Prev()
+		if t := e.Value.(*tenant[R]); t.refs == 0 {
+			m.removeLocked(t)
+			idle = append(idle, t)
+		}
+		e = prev
+	}
+	return idle
+}
+
+func (m *Manager[R]) removeLocked(t *tenant[R]) {
+	m.lru.Remove(t.element)
+	delete(m.tenants, t.id)
+}
+
+func closeAll[R any](tenants []*tenant[R]) {
+	for _, t := range tenants {
+		if t.openErr != nil {
+			continue
+		}
+		if closer, ok := any(t.res).(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Error("error closing tenant", "tenant", t.id, "error", err)
+				continue
+			}
+		}
+		logger.Debug("tenant closed", "tenant", t.id)
+	}
+}
@@ -0,0 +1,117 @@
+package tenancy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type resources struct {
+	dir    string
+	closed atomic.Bool
+}
+
+func (r *resources) Close() error {
+	r.closed.Store(true)
+	return nil
+}
+
+func TestManagerLRU(t *testing.T) {
+	var opened []*resources
+	m, err := New(t.TempDir(), 2, func(tenant, dir string) (*resources, error) {
+		r := &resources{dir: dir}
+		opened = append(opened, r)
+		return r, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, releaseA, err := m.Acquire("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(a.dir); err != nil {
+		t.Fatalf("tenant dir not created: %v", err)
+	}
+	_, releaseB, _ := m.Acquire("bob")
+	releaseB()
+
+	// alice در حال استفاده است، پس با باز شدن carol فقط bob بسته می‌شود
+	_, releaseC, _ := m.Acquire("carol")
+	if !opened[1].closed.Load() || a.closed.Load() {
+		t.Fatalf("expected bob closed and alice open")
+	}
+
+	_, releaseD, _ := m.Acquire("dave")
+	if m.Open() != 3 || a.closed.Load() {
+		t.Fatalf("tenants in use must stay open, open=%d", m.Open())
+	}
+	releaseA()
+	if !a.closed.Load() || m.Open() != 2 {
+		t.Fatalf("expected alice closed after release, open=%d", m.Open())
+	}
+
+	again, release, _ := m.Acquire("carol")
+	if again != opened[2] {
+		t.Fatal("expected cached tenant to be reused")
+	}
+	release()
+	releaseC()
+	releaseD()
+
+	if _, _, err := m.Acquire("../etc"); err == nil {
+		t.Fatal("expected invalid tenant error")
+	}
+	m.Close()
+	if !opened[2].closed.Load() || !opened[3].closed.Load() {
+		t.Fatal("Close should close idle tenants")
+	}
+	if _, _, err := m.Acquire("alice"); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	m, err := New(t.TempDir(), 0, func(tenant, dir string) (*resources, error) {
+		return &resources{dir: dir}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	engine := mygin.New()
+	engine.GET("/photos", Middleware(m, FromHeader("X-Tenant-ID")), func(c *mygin.Context) {
+		res, ok := Current[*resources](c)
+		if !ok {
+			c.String(http.StatusInternalServerError, "no resources")
+			return
+		}
+		c.String(http.StatusOK, "%s %s", Tenant(c), res.dir)
+	})
+
+	do := func(tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/photos", nil)
+		if tenant != "" {
+			req.Header.Set("X-Tenant-ID", tenant)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("alice"); w.Code != http.StatusOK || w.Body.String() != "alice "+m.Dir("alice") {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body)
+	}
+	if w := do(""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	if w := do("a/b"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,88 @@
+package tokens
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// Context keys set by Middleware.
+const (
+	KeyContextKey    = "tokens.key"
+	ScopesContextKey = "tokens.scopes"
+)
+
+// Middleware authenticates requests by API key, read from the X-API-Key header
+// or an "Authorization: Bearer irk_..." header, and stores the key and its scopes
+// in the Context. Requests without a valid key get 401.
+func Middleware(store *Store) mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		token := TokenFromRequest(c.Req)
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, mygin.H{"error": "API key required"})
+			c.Abort()
+			return
+		}
+
+		key, err := store.Authenticate(token)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if !errors.Is(err, ErrInvalidToken) && !errors.Is(err, ErrRevoked) && !errors.Is(err, ErrExpired) {
+				status = http.StatusInternalServerError
+			}
+			c.JSON(status, mygin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set(KeyContextKey, key)
+		c.Set(ScopesContextKey, key.Scopes)
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose key does not grant every scope with 403.
+// It must run after Middleware.
+func RequireScope(scopes ...string) mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		granted := Scopes(c)
+		for _, scope := range scopes {
+			if !MatchScope(granted, scope) {
+				c.JSON(http.StatusForbidden, mygin.H{"error": "missing scope " + scope})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// CurrentKey returns the key stored by Middleware.
+func CurrentKey(c *mygin.Context) (*Key, bool) {
+	value, ok := c.Get(KeyContextKey)
+	if !ok {
+		return nil, false
+	}
+	key, ok := value.(*Key)
+	return key, ok
+}
+
+// Scopes returns the scopes stored by Middleware.
+func Scopes(c *mygin.Context) []string {
+	value, _ := c.Get(ScopesContextKey)
+	scopes, _ := value.([]string)
+	return scopes
+}
+
+// TokenFromRequest extracts an API key from the request headers.
+func TokenFromRequest(req *http.Request) string {
+	if key := req.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if header := req.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer "+TokenPrefix) {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return ""
+}
@@ -0,0 +1,314 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+)
+
+// پکیج tokens کلیدهای API را صادر، ذخیره، چرخش و ابطال می‌کند. فقط هش SHA-256 هر کلید
+// ذخیره می‌شود؛ خود کلید فقط یک بار هنگام صدور برگردانده می‌شود.
+
+// TokenPrefix starts every issued key so leaked keys are easy to recognize.
+const TokenPrefix = "irk_"
+
+var (
+	ErrInvalidToken = errors.New("invalid API key")
+	ErrRevoked      = errors.New("API key revoked")
+	ErrExpired      = errors.New("API key expired")
+	ErrNotFound     = errors.New("API key not found")
+)
+
+// Key is a stored API key. Hash is the hex SHA-256 of the token.
+type Key struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	OwnerID    string    `json:"ownerId"`
+	Hint       string    `json:"hint"` // first characters of the token, for display
+	Hash       string    `json:"hash"`
+	Scopes     []string  `json:"scopes"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	RevokedAt  time.Time `json:"revokedAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	RotatedTo  uuid.UUID `json:"rotatedTo"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func (k *Key) SetID(id uuid.UUID)       { k.ID = id }
+func (k *Key) GetID() uuid.UUID         { return k.ID }
+func (k *Key) SetCreatedAt(t time.Time) { k.CreatedAt = t }
+func (k *Key) SetUpdatedAt(t time.Time) { k.UpdatedAt = t }
+func (k *Key) GetRecordSize() int       { return 1024 }
+
+// Active reports whether the key can authenticate at time now.
+func (k *Key) Active(now time.Time) bool {
+	return k.RevokedAt.IsZero() && (k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt))
+}
+
+// HasScope reports whether the key grants scope. A granted "*" matches every
+// scope and "albums:*" matches every scope starting with "albums:".
+func (k *Key) HasScope(scope string) bool {
+	return MatchScope(k.Scopes, scope)
+}
+
+// MatchScope reports whether one of granted allows scope. "*" is a wildcard
+// only as a whole segment after ':' or '/': "albums:*" matches "albums:read"
+// but not "albumsX:read", and "albums*" only matches itself.
+func MatchScope(granted []string, scope string) bool {
+	for _, g := range granted {
+		if g == "*" || g == scope {
+			return true
+		}
+		prefix, ok := strings.CutSuffix(g, "*")
+		if ok && (strings.HasSuffix(prefix, ":") || strings.HasSuffix(prefix, "/")) && strings.HasPrefix(scope, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueOptions describes a new key.
+type IssueOptions struct {
+	Name    string
+	OwnerID string
+	Scopes  []string
+	TTL     time.Duration // 0 = never expires
+}
+
+// Store persists API keys in a collection_manager_memory collection.
+type Store struct {
+	manager *collection_manager_memory.Manager[*Key]
+	now     func() time.Time
+
+	// TouchInterval limits how often LastUsedAt is written for a key.
+	TouchInterval time.Duration
+
+	mu        sync.RWMutex
+	byHash    map[string]uuid.UUID
+	stopIndex func()
+
+	// writeMu serializes the read-modify-update of keys so a LastUsedAt touch
+	// cannot write back a copy read before Revoke or Rotate
+	writeMu sync.Mutex
+}
+
+// NewStore opens the "api_keys" collection in dir.
+func NewStore(dir string) (*Store, error) {
+	manager, err := collection_manager_memory.New[*Key](dir, "api_keys")
+	if err != nil {
+		return nil, fmt.Errorf("error opening api_keys collection: %w", err)
+	}
+	return NewStoreWithManager(manager)
+}
+
+// NewStoreWithManager uses an already opened manager.
+func NewStoreWithManager(manager *collection_manager_memory.Manager[*Key]) (*Store, error) {
+	s := &Store{manager: manager, now: time.Now, TouchInterval: time.Minute, byHash: make(map[string]uuid.UUID)}
+	keys, err := manager.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		s.byHash[k.Hash] = k.ID
+	}
+	s.stopIndex = manager.OnChange(func(change collection_manager_memory.Change[*Key]) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if change.Type == collection_manager_memory.ChangeDelete {
+			delete(s.byHash, change.Item.Hash)
+			return
+		}
+		s.byHash[change.Item.Hash] = change.ID
+	})
+	return s, nil
+}
+
+// Close stops tracking changes and closes the manager.
+func (s *Store) Close() error {
+	s.stopIndex()
+	return s.manager.Close()
+}
+
+// Issue creates a key and returns its token. The token cannot be recovered later.
+func (s *Store) Issue(opts IssueOptions) (string, *Key, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+	key := &Key{
+		Name:    opts.Name,
+		OwnerID: opts.OwnerID,
+		Hint:    token[:len(TokenPrefix)+6],
+		Hash:    HashToken(token),
+		Scopes:  slices.Clone(opts.Scopes),
+	}
+	if opts.TTL > 0 {
+		key.ExpiresAt = s.now().Add(opts.TTL)
+	}
+	created, err := s.manager.Create(key)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, created, nil
+}
+
+// Authenticate returns the active key of token.
+func (s *Store) Authenticate(token string) (*Key, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	hash := HashToken(token)
+
+	s.mu.RLock()
+	id, ok := s.byHash[hash]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	now := s.now()
+	key, err := s.check(id, hash, now)
+	if err != nil || now.Sub(key.LastUsedAt) < s.TouchInterval {
+		return key, err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	// Revoke یا Rotate ممکن است پس از خواندن بالا کلید را تغییر داده باشند
+	if key, err = s.check(id, hash, now); err != nil || now.Sub(key.LastUsedAt) < s.TouchInterval {
+		return key, err
+	}
+	touched := *key
+	touched.LastUsedAt = now
+	if updated, err := s.manager.Update(&touched); err == nil {
+		key = updated
+	}
+	return key, nil
+}
+
+// check reads the key with id and returns it when it has hash and is active.
+func (s *Store) check(id uuid.UUID, hash string, now time.Time) (*Key, error) {
+	key, err := s.manager.Read(id)
+	if err != nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) != 1 {
+		return nil, ErrInvalidToken
+	}
+	if !key.RevokedAt.IsZero() {
+		return nil, ErrRevoked
+	}
+	if !key.Active(now) {
+		return nil, ErrExpired
+	}
+	return key, nil
+}
+
+// Get returns a key by ID.
+func (s *Store) Get(id uuid.UUID) (*Key, error) {
+	key, err := s.manager.Read(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return key, nil
+}
+
+// List returns the keys of an owner (all keys when ownerID is empty), newest first.
+func (s *Store) List(ownerID string) ([]*Key, error) {
+	keys, err := s.manager.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	result := keys[:0]
+	for _, k := range keys {
+		if ownerID == "" || k.OwnerID == ownerID {
+			result = append(result, k)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+// Revoke disables a key immediately.
+func (s *Store) Revoke(id uuid.UUID) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	key, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if !key.RevokedAt.IsZero() {
+		return nil
+	}
+	revoked := *key
+	revoked.RevokedAt = s.now()
+	_, err = s.manager.Update(&revoked)
+	return err
+}
+
+// Rotate issues a replacement with the same name, owner, scopes and lifetime.
+// The old key keeps working for grace (0 = revoked immediately), so clients
+// can switch without downtime.
+func (s *Store) Rotate(id uuid.UUID, grace time.Duration) (string, *Key, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	old, err := s.Get(id)
+	if err != nil {
+		return "", nil, err
+	}
+	now := s.now()
+	if !old.Active(now) {
+		return "", nil, ErrRevoked
+	}
+
+	opts := IssueOptions{Name: old.Name, OwnerID: old.OwnerID, Scopes: old.Scopes}
+	if !old.ExpiresAt.IsZero() {
+		opts.TTL = old.ExpiresAt.Sub(old.CreatedAt)
+	}
+	token, replacement, err := s.Issue(opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	retired := *old
+	retired.RotatedTo = replacement.ID
+	if grace > 0 {
+		if retired.ExpiresAt.IsZero() || now.Add(grace).Before(retired.ExpiresAt) {
+			retired.ExpiresAt = now.Add(grace)
+		}
+	} else {
+		retired.RevokedAt = now
+	}
+	if _, err := s.manager.Update(&retired); err != nil {
+		return "", nil, err
+	}
+	return token, replacement, nil
+}
+
+// Delete removes a key record.
+func (s *Store) Delete(id uuid.UUID) error {
+	return s.manager.Delete(id)
+}
+
+// HashToken returns the hex SHA-256 of a token as stored in Key.Hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("error generating API key: %w", err)
+	}
+	return TokenPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
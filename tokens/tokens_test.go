@@ -0,0 +1,134 @@
+package tokens
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+func TestIssueRotateRevoke(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	token, key, err := store.Issue(IssueOptions{Name: "ci", OwnerID: "u1", Scopes: []string{"albums:*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Hash == token || key.Hash != HashToken(token) {
+		t.Fatal("expected only the token hash to be stored")
+	}
+	if got, err := store.Authenticate(token); err != nil || got.ID != key.ID || !got.HasScope("albums:write") || got.HasScope("photos:read") {
+		t.Fatalf("unexpected authentication result %v %v", got, err)
+	}
+
+	newToken, replacement, err := store.Rotate(key.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Authenticate(token); err != nil {
+		t.Fatalf("old key should work during grace period: %v", err)
+	}
+	if _, err := store.Authenticate(newToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Revoke(replacement.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Authenticate(newToken); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("expected ErrRevoked, got %v", err)
+	}
+	if _, err := store.Authenticate("irk_unknown"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestRevokeWhileAuthenticating(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	token, key, _ := store.Issue(IssueOptions{Name: "ci"})
+
+	// the key is revoked while Authenticate is running, before it touches LastUsedAt
+	revoking := false
+	store.now = func() time.Time {
+		if !revoking {
+			revoking = true
+			if err := store.Revoke(key.ID); err != nil {
+				t.Error(err)
+			}
+		}
+		return time.Now()
+	}
+	store.Authenticate(token)
+
+	if got, _ := store.Get(key.ID); got.RevokedAt.IsZero() {
+		t.Fatal("the LastUsedAt update overwrote the revocation")
+	}
+	if _, err := store.Authenticate(token); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("expected ErrRevoked, got %v", err)
+	}
+}
+
+func TestMatchScope(t *testing.T) {
+	cases := []struct {
+		granted, scope string
+		want           bool
+	}{
+		{"*", "albums:delete", true},
+		{"albums:*", "albums:delete", true},
+		{"albums/*", "albums/2024:read", true},
+		{"albums:*", "albumsX:delete", false},
+		{"albums*", "albumsX:delete", false},
+		{"albums*", "albums*", true},
+		{"albums:*", "albums", false},
+	}
+	for _, tc := range cases {
+		if got := MatchScope([]string{tc.granted}, tc.scope); got != tc.want {
+			t.Errorf("MatchScope(%q, %q) = %v, want %v", tc.granted, tc.scope, got, tc.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	token, _, _ := store.Issue(IssueOptions{Name: "reader", Scopes: []string{"albums:read"}})
+
+	engine := mygin.New()
+	ok := func(c *mygin.Context) { c.String(http.StatusOK, "%s", "ok") }
+	engine.GET("/albums", Middleware(store), RequireScope("albums:read"), ok)
+	engine.POST("/albums", Middleware(store), RequireScope("albums:write"), ok)
+
+	cases := []struct {
+		method, token string
+		want          int
+	}{
+		{"GET", "", http.StatusUnauthorized},
+		{"GET", token, http.StatusOK},
+		{"POST", token, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/albums", nil)
+		if tc.token != "" {
+			req.Header.Set("X-API-Key", tc.token)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s with token %v: got %d, want %d", tc.method, tc.token != "", w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/metrics"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// پکیج tracing اسپن‌های OpenTelemetry را برای درخواست‌های HTTP و عملیات مدیریت‌کننده‌های
+// کالکشن می‌سازد. میان‌افزار، context درخواست را با اسپن سرور جایگزین می‌کند و وقتی هندلرها
+// متدهای ...Context مدیریت‌کننده را با c.Req.Context() صدا بزنند (rest این کار را خودکار انجام
+// می‌دهد) اسپن‌های ذخیره‌سازی فرزند اسپن HTTP می‌شوند.
+
+const instrumentationName = "github.com/mahdi-cpp/iris-tools/tracing"
+
+func tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
+
+// Middleware starts a server span for every request, continuing a trace propagated
+// in the request headers. A nil provider uses the global one.
+func Middleware(tp trace.TracerProvider) mygin.HandlerFunc {
+	tr := tracer(tp)
+	return func(c *mygin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Req.Context(), propagation.HeaderCarrier(c.Req.Header))
+		route := metrics.Route(c)
+		ctx, span := tr.Start(ctx, c.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Path),
+			),
+		)
+		defer span.End()
+		c.Req = c.Req.WithContext(ctx)
+
+		c.Next()
+
+		status := c.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// InstrumentManager records a span for every operation of a collection whose context
+// carries a span, i.e. operations called through the ...Context methods inside a
+// traced request or job. Operations without a parent span are not traced so that
+// internal bookkeeping does not create root traces. The returned function stops tracing.
+func InstrumentManager[T collection_manager_memory.CollectionItem](tp trace.TracerProvider, collection string, manager *collection_manager_memory.Manager[T]) (remove func()) {
+	tr := tracer(tp)
+	return manager.Observe(func(op collection_manager_memory.Op) {
+		if op.Context == nil || !trace.SpanContextFromContext(op.Context).IsValid() {
+			return
+		}
+
+		attrs := []attribute.KeyValue{
+			attribute.String("db.system.name", "iris"),
+			attribute.String("db.collection.name", collection),
+			attribute.String("db.operation.name", op.Name),
+			attribute.Int("iris.record_size", op.RecordSize),
+		}
+		if op.ID != uuid.Nil {
+			attrs = append(attrs, attribute.String("iris.item_id", op.ID.String()))
+		}
+		switch op.Name {
+		case "read":
+			attrs = append(attrs, attribute.Bool("iris.cache_hit", op.CacheHit))
+		case "read_all", "compact":
+			attrs = append(attrs, attribute.Int("iris.items", op.Items))
+		case "create", "update", "copy":
+			attrs = append(attrs, attribute.Int("iris.payload_bytes", op.Bytes))
+		}
+
+		// عملیات تمام شده است؛ اسپن با زمان واقعی شروع و پایان ساخته می‌شود
+		_, span := tr.Start(op.Context, collection+"."+op.Name,
+			trace.WithTimestamp(op.Start),
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(attrs...),
+		)
+		if op.Err != nil {
+			span.RecordError(op.Err)
+			span.SetStatus(codes.Error, op.Err.Error())
+		}
+		span.End(trace.WithTimestamp(op.Start.Add(op.Duration)))
+	})
+}
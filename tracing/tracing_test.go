@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type note struct {
+	ID   uuid.UUID `json:"id"`
+	Text string    `json:"text"`
+}
+
+func (n *note) SetID(id uuid.UUID) { n.ID = id }
+func (n *note) GetID() uuid.UUID   { return n.ID }
+func (n *note) GetRecordSize() int { return 128 }
+
+func TestStorageSpansJoinRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	notes, err := collection_manager_memory.New[*note](t.TempDir(), "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notes.Close()
+	defer InstrumentManager(tp, "notes", notes)()
+
+	// بدون اسپن والد هیچ اسپنی ساخته نمی‌شود
+	created, _ := notes.Create(&note{Text: "hello"})
+
+	engine := mygin.New()
+	engine.Use(Middleware(tp))
+	engine.GET("/notes/:id", func(c *mygin.Context) {
+		if _, err := notes.ReadContext(c.Req.Context(), created.ID); err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/notes/"+created.ID.String(), nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected storage and server span, got %d", len(spans))
+	}
+	storage, server := spans[0], spans[1]
+	if server.Name() != "GET /notes/:id" || storage.Name() != "notes.read" {
+		t.Fatalf("unexpected span names %q, %q", server.Name(), storage.Name())
+	}
+	if storage.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Fatal("storage span is not a child of the server span")
+	}
+
+	attrs := attribute.NewSet(storage.Attributes()...)
+	if v, ok := attrs.Value("iris.cache_hit"); !ok || !v.AsBool() {
+		t.Fatalf("missing cache hit attribute: %v", storage.Attributes())
+	}
+	if v, ok := attrs.Value("iris.record_size"); !ok || v.AsInt64() != 128 {
+		t.Fatalf("missing record size attribute: %v", storage.Attributes())
+	}
+}
@@ -0,0 +1,137 @@
+package uuidutil
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// پکیج uuidutil توابع کمکی برای شناسه‌های UUID دارد: استخراج زمان ایجاد از UUID v7،
+// کدگذاری کوتاه و امن برای URL و افزودن/حذف پیشوند نوع (مثل alb_ و pho_).
+
+var (
+	// ErrNotV7 is returned by Time for UUIDs that are not version 7.
+	ErrNotV7 = errors.New("uuid is not version 7")
+	// ErrInvalidShort is returned when a short ID cannot be decoded.
+	ErrInvalidShort = errors.New("invalid short id")
+	// ErrPrefixMismatch is returned when an ID has an unexpected prefix.
+	ErrPrefixMismatch = errors.New("id prefix mismatch")
+)
+
+// Time returns the creation time embedded in a UUID v7 (millisecond precision).
+func Time(id uuid.UUID) (time.Time, error) {
+	if id.Version() != 7 {
+		return time.Time{}, ErrNotV7
+	}
+	ms := int64(id[0])<<40 | int64(id[1])<<32 | int64(id[2])<<24 |
+		int64(id[3])<<16 | int64(id[4])<<8 | int64(id[5])
+	return time.UnixMilli(ms), nil
+}
+
+// MinV7 returns the smallest UUID v7 for t. Comparing IDs against MinV7 of two
+// times selects the items created in that range without parsing each ID.
+func MinV7(t time.Time) uuid.UUID {
+	var id uuid.UUID
+	ms := t.UnixMilli()
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	id[6] = 0x70 // version 7
+	id[8] = 0x80 // variant RFC 4122
+	return id
+}
+
+// base62 keeps the alphabet in ASCII order so that short IDs sort like the UUIDs
+// they encode; short IDs of v7 UUIDs are therefore time ordered.
+const (
+	alphabet    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	ShortLength = 22
+)
+
+var base = big.NewInt(62)
+
+// Short encodes id as a fixed-length 22 character base62 string.
+func Short(id uuid.UUID) string {
+	n := new(big.Int).SetBytes(id[:])
+	out := make([]byte, ShortLength)
+	rem := new(big.Int)
+	for i := ShortLength - 1; i >= 0; i-- {
+		n.DivMod(n, base, rem)
+		out[i] = alphabet[rem.Int64()]
+	}
+	return string(out)
+}
+
+// ParseShort decodes a string produced by Short.
+func ParseShort(s string) (uuid.UUID, error) {
+	if len(s) != ShortLength {
+		return uuid.Nil, fmt.Errorf("%w: length %d", ErrInvalidShort, len(s))
+	}
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(alphabet, s[i])
+		if d < 0 {
+			return uuid.Nil, fmt.Errorf("%w: character %q", ErrInvalidShort, s[i])
+		}
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	if n.BitLen() > 128 {
+		return uuid.Nil, fmt.Errorf("%w: value out of range", ErrInvalidShort)
+	}
+
+	var id uuid.UUID
+	n.FillBytes(id[:])
+	return id, nil
+}
+
+// Parse accepts a canonical UUID string or a short ID.
+func Parse(s string) (uuid.UUID, error) {
+	if len(s) == ShortLength {
+		return ParseShort(s)
+	}
+	return uuid.Parse(s)
+}
+
+// Prefix is a typed ID prefix such as "alb". Formatted IDs look like alb_<short>.
+type Prefix string
+
+// Separator joins a prefix and the encoded ID.
+const Separator = "_"
+
+// Format returns the prefixed short form of id.
+func (p Prefix) Format(id uuid.UUID) string {
+	return string(p) + Separator + Short(id)
+}
+
+// Parse decodes a prefixed ID. Unprefixed canonical UUIDs and short IDs are
+// accepted too, so clients can send either form.
+func (p Prefix) Parse(s string) (uuid.UUID, error) {
+	if prefix, rest, ok := Split(s); ok {
+		if prefix != p {
+			return uuid.Nil, fmt.Errorf("%w: expected %s, got %s", ErrPrefixMismatch, p, prefix)
+		}
+		return Parse(rest)
+	}
+	return Parse(s)
+}
+
+// Split separates a prefixed ID into its prefix and encoded part. ok is false
+// when s has no prefix.
+func Split(s string) (prefix Prefix, rest string, ok bool) {
+	i := strings.LastIndex(s, Separator)
+	if i <= 0 || i == len(s)-1 {
+		return "", s, false
+	}
+	return Prefix(s[:i]), s[i+1:], true
+}
+
+// Strip removes any prefix from s and returns the decoded UUID.
+func Strip(s string) (uuid.UUID, error) {
+	_, rest, _ := Split(s)
+	return Parse(rest)
+}
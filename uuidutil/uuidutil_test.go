@@ -0,0 +1,63 @@
+package uuidutil
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestTime(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	id := uuid.Must(uuid.NewV7())
+	got, err := Time(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Before(before) || got.After(time.Now()) {
+		t.Fatalf("unexpected time %s", got)
+	}
+	if _, err := Time(uuid.New()); !errors.Is(err, ErrNotV7) {
+		t.Fatalf("expected ErrNotV7, got %v", err)
+	}
+	if min := MinV7(got); min.String() > id.String() {
+		t.Fatalf("MinV7 %s should not be greater than %s", min, id)
+	}
+}
+
+func TestShortAndPrefix(t *testing.T) {
+	ids := []uuid.UUID{uuid.Nil, uuid.Max}
+	for i := 0; i < 50; i++ {
+		ids = append(ids, uuid.Must(uuid.NewV7()))
+	}
+
+	shorts := make([]string, len(ids))
+	for i, id := range ids {
+		shorts[i] = Short(id)
+		back, err := ParseShort(shorts[i])
+		if err != nil || back != id {
+			t.Fatalf("round trip of %s failed: %s %v", id, back, err)
+		}
+	}
+	if !sort.StringsAreSorted(shorts[2:]) {
+		t.Fatal("short ids of v7 uuids should sort by time")
+	}
+
+	const album Prefix = "alb"
+	id := ids[5]
+	formatted := album.Format(id)
+	if got, err := album.Parse(formatted); err != nil || got != id {
+		t.Fatalf("prefix round trip failed: %s %v", got, err)
+	}
+	if got, err := album.Parse(id.String()); err != nil || got != id {
+		t.Fatalf("plain uuid should be accepted: %v", err)
+	}
+	if _, err := Prefix("pho").Parse(formatted); !errors.Is(err, ErrPrefixMismatch) {
+		t.Fatalf("expected prefix mismatch, got %v", err)
+	}
+	if _, err := ParseShort("zzzzzzzzzzzzzzzzzzzzzz"); err == nil {
+		t.Fatal("expected out of range error")
+	}
+}
@@ -0,0 +1,160 @@
+package validation
+
+import (
+	"cmp"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Required fails on the zero value of T.
+func Required[T comparable]() Rule[T] {
+	return func(value T) error {
+		var zero T
+		if value == zero {
+			return errorf("is required")
+		}
+		return nil
+	}
+}
+
+// NotEmpty fails on an empty slice or map.
+func NotEmpty[T any]() Rule[T] {
+	return func(value T) error {
+		rv := reflect.ValueOf(value)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+			if rv.Len() == 0 {
+				return errorf("must not be empty")
+			}
+		}
+		return nil
+	}
+}
+
+// MinLen fails when a string has fewer than n characters.
+func MinLen(n int) Rule[string] {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) < n {
+			return errorf("must be at least %d characters", n)
+		}
+		return nil
+	}
+}
+
+// MaxLen fails when a string has more than n characters.
+func MaxLen(n int) Rule[string] {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) > n {
+			return errorf("must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+// Length fails when a string length is outside [min, max].
+func Length(min, max int) Rule[string] {
+	return func(value string) error {
+		if n := utf8.RuneCountInString(value); n < min || n > max {
+			return errorf("must be between %d and %d characters", min, max)
+		}
+		return nil
+	}
+}
+
+// Min fails when value is lower than min.
+func Min[T cmp.Ordered](min T) Rule[T] {
+	return func(value T) error {
+		if value < min {
+			return errorf("must be at least %v", min)
+		}
+		return nil
+	}
+}
+
+// Max fails when value is greater than max.
+func Max[T cmp.Ordered](max T) Rule[T] {
+	return func(value T) error {
+		if value > max {
+			return errorf("must be at most %v", max)
+		}
+		return nil
+	}
+}
+
+// Between fails when value is outside [min, max].
+func Between[T cmp.Ordered](min, max T) Rule[T] {
+	return func(value T) error {
+		if value < min || value > max {
+			return errorf("must be between %v and %v", min, max)
+		}
+		return nil
+	}
+}
+
+// OneOf fails when value is not one of the allowed values.
+func OneOf[T comparable](allowed ...T) Rule[T] {
+	return func(value T) error {
+		if !slices.Contains(allowed, value) {
+			return errorf("must be one of %v", allowed)
+		}
+		return nil
+	}
+}
+
+// Email fails when a non-empty string is not a plain email address.
+func Email() Rule[string] {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value || !strings.Contains(value[strings.LastIndex(value, "@"):], ".") {
+			return errorf("must be a valid email address")
+		}
+		return nil
+	}
+}
+
+// UUID fails when a non-empty string is not a UUID.
+func UUID() Rule[string] {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			return errorf("must be a valid UUID")
+		}
+		return nil
+	}
+}
+
+// NotNilUUID fails on uuid.Nil.
+func NotNilUUID() Rule[uuid.UUID] {
+	return func(value uuid.UUID) error {
+		if value == uuid.Nil {
+			return errorf("is required")
+		}
+		return nil
+	}
+}
+
+// Match fails when a non-empty string does not match pattern.
+func Match(pattern *regexp.Regexp) Rule[string] {
+	return func(value string) error {
+		if value != "" && !pattern.MatchString(value) {
+			return errorf("has an invalid format")
+		}
+		return nil
+	}
+}
+
+// By turns a plain function into a rule.
+func By[T any](fn func(T) error) Rule[T] {
+	return Rule[T](fn)
+}
@@ -0,0 +1,300 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Struct validates the exported fields of a struct (or pointer to struct) using
+// their validate tags. Field names in the errors come from the json tag.
+//
+//	type Album struct {
+//		Title   string    `json:"title" validate:"required,max=100"`
+//		OwnerID string    `json:"owner_id" validate:"uuid"`
+//		Email   string    `json:"email" validate:"omitempty,email"`
+//		Kind    string    `json:"kind" validate:"oneof=photo video"`
+//		Tags    []string  `json:"tags" validate:"max=10"`
+//	}
+//
+// Supported rules: required, omitempty, min=N, max=N, len=N (length for strings,
+// slices and maps, value for numbers), email, uuid, oneof=a b c and the rules
+// added with RegisterRule. Nested structs are validated recursively with
+// "parent.child" field names.
+func Struct(value any) error {
+	err := StructTag(value, "validate")
+	if fields, ok := err.(FieldErrors); ok {
+		return fields.Errors()
+	}
+	return err
+}
+
+// StructTag is Struct for rules in the struct tag named tag, e.g. "binding". The
+// failures are returned as FieldErrors, which also satisfy Fields.
+func StructTag(value any, tag string) error {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: expected struct, got %s", rv.Kind())
+	}
+
+	v := New()
+	if err := validateStruct(v, rv, "", tag); err != nil {
+		return err
+	}
+	if v.Valid() {
+		return nil
+	}
+	return v.details
+}
+
+// TagRule checks a field value against the argument of its tag rule, e.g. "3"
+// for `validate:"maxwords=3"`. It is called with the dereferenced field value;
+// nil pointers only reach required.
+type TagRule func(value any, arg string) error
+
+var (
+	customRulesMu sync.RWMutex
+	customRules   = map[string]TagRule{}
+)
+
+// RegisterRule adds a tag rule usable in validate (and binding) tags:
+//
+//	validation.RegisterRule("maxwords", func(value any, arg string) error {
+//		limit, _ := strconv.Atoi(arg)
+//		if s, _ := value.(string); len(strings.Fields(s)) > limit {
+//			return fmt.Errorf("must have at most %s words", arg)
+//		}
+//		return nil
+//	})
+//
+// Rules must be registered before the structs that use them are validated.
+func RegisterRule(name string, rule TagRule) {
+	customRulesMu.Lock()
+	defer customRulesMu.Unlock()
+	customRules[name] = rule
+}
+
+func customRule(name string) (TagRule, bool) {
+	customRulesMu.RLock()
+	defer customRulesMu.RUnlock()
+	rule, ok := customRules[name]
+	return rule, ok
+}
+
+type tagRule struct {
+	name string
+	arg  string
+}
+
+type tagKey struct {
+	t     reflect.Type
+	index int
+	tag   string
+}
+
+var tagCache sync.Map // tagKey -> []tagRule
+
+var (
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+func validateStruct(v *Validator, rv reflect.Value, prefix, tagName string) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + fieldName(field)
+		fv := rv.Field(i)
+
+		tag := field.Tag.Get(tagName)
+		if tag == "-" {
+			continue
+		}
+		if tag != "" {
+			rules, err := parseTag(t, i, tagName, tag)
+			if err != nil {
+				return err
+			}
+			if err := applyRules(v, name, fv, rules); err != nil {
+				return err
+			}
+		}
+
+		nested := fv
+		for nested.Kind() == reflect.Ptr && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type() != uuidType && nested.Type() != timeType {
+			if err := validateStruct(v, nested, name+".", tagName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("json"); tag != "" && tag != "-" {
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func parseTag(t reflect.Type, index int, tagName, tag string) ([]tagRule, error) {
+	key := tagKey{t: t, index: index, tag: tagName}
+	if cached, ok := tagCache.Load(key); ok {
+		return cached.([]tagRule), nil
+	}
+
+	var rules []tagRule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		switch name {
+		case "required", "omitempty", "email", "uuid":
+		case "min", "max", "len":
+			if _, err := strconv.ParseFloat(arg, 64); err != nil {
+				return nil, fmt.Errorf("validation: invalid argument for %s on %s: %q", name, t.Field(index).Name, arg)
+			}
+		case "oneof":
+			if arg == "" {
+				return nil, fmt.Errorf("validation: oneof without values on %s", t.Field(index).Name)
+			}
+		default:
+			if _, ok := customRule(name); !ok {
+				return nil, fmt.Errorf("validation: unknown rule %q on %s", name, t.Field(index).Name)
+			}
+		}
+		rules = append(rules, tagRule{name: name, arg: arg})
+	}
+	tagCache.Store(key, rules)
+	return rules, nil
+}
+
+func applyRules(v *Validator, name string, fv reflect.Value, rules []tagRule) error {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			for _, rule := range rules {
+				if rule.name == "required" {
+					v.addRule(name, rule, "is required")
+				}
+			}
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	for _, rule := range rules {
+		if rule.name == "omitempty" && fv.IsZero() {
+			return nil
+		}
+	}
+
+	for _, rule := range rules {
+		if err := checkRule(rule, fv); err != nil {
+			v.addRule(name, rule, err.Error())
+			return nil
+		}
+	}
+	return nil
+}
+
+func checkRule(rule tagRule, fv reflect.Value) error {
+	switch rule.name {
+	case "required":
+		if fv.IsZero() {
+			return errorf("is required")
+		}
+	case "email":
+		if fv.Kind() == reflect.String {
+			return Email()(fv.String())
+		}
+	case "uuid":
+		if fv.Type() == uuidType {
+			return nil
+		}
+		if fv.Kind() == reflect.String {
+			return UUID()(fv.String())
+		}
+	case "oneof":
+		allowed := strings.Fields(rule.arg)
+		value := fmt.Sprint(fv.Interface())
+		for _, a := range allowed {
+			if a == value {
+				return nil
+			}
+		}
+		return errorf("must be one of %v", allowed)
+	case "min", "max", "len":
+		return checkBound(rule, fv)
+	case "omitempty":
+	default:
+		if check, ok := customRule(rule.name); ok {
+			return check(fv.Interface(), rule.arg)
+		}
+	}
+	return nil
+}
+
+func checkBound(rule tagRule, fv reflect.Value) error {
+	limit, _ := strconv.ParseFloat(rule.arg, 64)
+
+	var actual float64
+	isLength := false
+	switch fv.Kind() {
+	case reflect.String:
+		actual, isLength = float64(len([]rune(fv.String()))), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		actual, isLength = float64(fv.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = fv.Float()
+	default:
+		return nil
+	}
+
+	unit := ""
+	if isLength {
+		unit = " in length"
+		if fv.Kind() == reflect.String {
+			unit = " characters"
+		}
+	}
+	switch rule.name {
+	case "min":
+		if actual < limit {
+			return errorf("must be at least %s%s", rule.arg, unit)
+		}
+	case "max":
+		if actual > limit {
+			return errorf("must be at most %s%s", rule.arg, unit)
+		}
+	case "len":
+		if actual != limit {
+			return errorf("must be exactly %s%s", rule.arg, unit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// پکیج validation قوانین اعتبارسنجی مشترک را فراهم می‌کند تا همان قوانین هم در لبه
+// (bind کردن درخواست‌های HTTP) و هم در لایه ذخیره‌سازی (هوک Validatable مدیریت‌کننده‌ها)
+// اعمال شوند. قوانین را می‌توان با کد (Field) یا با تگ validate روی struct (Struct) تعریف کرد.
+
+// Validatable is implemented by items that check themselves. The collection
+// managers call Validate before Create and Update.
+type Validatable interface {
+	Validate() error
+}
+
+// Errors maps field names to the message of the first rule they failed.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+": "+e[field])
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Fields returns the field errors of err if it is (or wraps) an Errors value.
+func Fields(err error) (Errors, bool) {
+	var errs Errors
+	if errors.As(err, &errs) {
+		return errs, true
+	}
+	return nil, false
+}
+
+// FieldError describes one failed field: the rule it failed, e.g. "max", with
+// its argument, e.g. "100", and the message also found in Errors.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldErrors are the failed fields in struct order, returned by StructTag. They
+// satisfy Fields, so callers that only need the messages can treat them as Errors.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	return e.Errors().Error()
+}
+
+// Errors returns the messages by field name.
+func (e FieldErrors) Errors() Errors {
+	errs := make(Errors, len(e))
+	for _, fe := range e {
+		errs[fe.Field] = fe.Message
+	}
+	return errs
+}
+
+// As lets errors.As (and Fields) convert FieldErrors into Errors.
+func (e FieldErrors) As(target any) bool {
+	if errs, ok := target.(*Errors); ok {
+		*errs = e.Errors()
+		return true
+	}
+	return false
+}
+
+// Details returns the field errors of err with their rules. Errors without rule
+// information are returned with an empty Rule, sorted by field.
+func Details(err error) (FieldErrors, bool) {
+	var details FieldErrors
+	if errors.As(err, &details) {
+		return details, true
+	}
+	errs, ok := Fields(err)
+	if !ok {
+		return nil, false
+	}
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		details = append(details, FieldError{Field: field, Message: errs[field]})
+	}
+	return details, true
+}
+
+// Rule checks a value and returns an error describing the problem.
+type Rule[T any] func(value T) error
+
+// Validator collects field errors:
+//
+//	v := validation.New()
+//	validation.Field(v, "name", a.Name, validation.Required[string](), validation.MaxLen(100))
+//	validation.Field(v, "owner_id", a.OwnerID.String(), validation.UUID())
+//	return v.Err()
+type Validator struct {
+	errs    Errors
+	details FieldErrors
+}
+
+// New returns an empty validator.
+func New() *Validator {
+	return &Validator{errs: Errors{}}
+}
+
+// Field runs rules against value in order and records the first failure under name.
+func Field[T any](v *Validator, name string, value T, rules ...Rule[T]) *Validator {
+	if _, failed := v.errs[name]; failed {
+		return v
+	}
+	for _, rule := range rules {
+		if err := rule(value); err != nil {
+			v.Add(name, err.Error())
+			break
+		}
+	}
+	return v
+}
+
+// Add records a custom error for a field.
+func (v *Validator) Add(name, message string) *Validator {
+	if _, failed := v.errs[name]; !failed {
+		v.errs[name] = message
+		v.details = append(v.details, FieldError{Field: name, Message: message})
+	}
+	return v
+}
+
+// addRule records the failure of a tag rule.
+func (v *Validator) addRule(name string, rule tagRule, message string) {
+	if _, failed := v.errs[name]; !failed {
+		v.errs[name] = message
+		v.details = append(v.details, FieldError{Field: name, Rule: rule.name, Param: rule.arg, Message: message})
+	}
+}
+
+// Merge adds the field errors of err under prefix (e.g. "address."). Errors
+// that are not field errors are recorded under prefix without the dot.
+func (v *Validator) Merge(prefix string, err error) *Validator {
+	if err == nil {
+		return v
+	}
+	if errs, ok := Fields(err); ok {
+		for field, message := range errs {
+			v.Add(prefix+field, message)
+		}
+		return v
+	}
+	return v.Add(strings.TrimSuffix(prefix, "."), err.Error())
+}
+
+// Valid reports whether no errors were recorded.
+func (v *Validator) Valid() bool {
+	return len(v.errs) == 0
+}
+
+// Err returns the recorded errors, or nil when valid.
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return v.errs
+}
+
+func errorf(format string, args ...any) error {
+	return fmt.Errorf(format, args...)
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type album struct {
+	Title   string    `json:"title" validate:"required,max=10"`
+	OwnerID string    `json:"owner_id" validate:"uuid"`
+	Email   string    `json:"email" validate:"omitempty,email"`
+	Kind    string    `json:"kind" validate:"oneof=photo video"`
+	Tags    []string  `json:"tags" validate:"max=2"`
+	Count   int       `json:"count" validate:"min=1"`
+	ID      uuid.UUID `json:"id" validate:"required"`
+	Address address   `json:"address"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := album{
+		Title: "trip", OwnerID: uuid.NewString(), Kind: "photo", Count: 1,
+		ID: uuid.New(), Address: address{City: "Tehran"},
+	}
+	if err := Struct(&valid); err != nil {
+		t.Fatalf("expected valid album, got %v", err)
+	}
+
+	invalid := album{Title: "a very long title", OwnerID: "nope", Email: "x@", Kind: "audio", Tags: []string{"a", "b", "c"}}
+	errs, ok := Fields(Struct(invalid))
+	if !ok {
+		t.Fatal("expected field errors")
+	}
+	for _, field := range []string{"title", "owner_id", "email", "kind", "tags", "count", "id", "address.city"} {
+		if _, ok := errs[field]; !ok {
+			t.Errorf("expected error for %s, got %v", field, errs)
+		}
+	}
+}
+
+func TestField(t *testing.T) {
+	v := New()
+	Field(v, "name", "", Required[string](), MinLen(3))
+	Field(v, "age", 200, Between(0, 150))
+	Field(v, "email", "a@b.com", Email())
+	errs, _ := Fields(v.Err())
+	if len(errs) != 2 || errs["name"] != "is required" || errs["age"] == "" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
+
+func TestStructTagAndCustomRules(t *testing.T) {
+	RegisterRule("even", func(value any, _ string) error {
+		if n, ok := value.(int); ok && n%2 != 0 {
+			return errorf("must be even")
+		}
+		return nil
+	})
+	type form struct {
+		Count *int   `json:"count" binding:"required,even"`
+		Name  string `json:"name" binding:"max=3"`
+	}
+	three := 3
+	details, ok := Details(StructTag(form{Count: &three, Name: "long"}, "binding"))
+	if !ok || len(details) != 2 || details[0] != (FieldError{Field: "count", Rule: "even", Message: "must be even"}) ||
+		details[1].Rule != "max" || details[1].Param != "3" {
+		t.Fatalf("details = %+v", details)
+	}
+	if errs, ok := Fields(StructTag(form{}, "binding")); !ok || errs["count"] != "is required" {
+		t.Fatalf("fields = %v", errs)
+	}
+}
@@ -0,0 +1,394 @@
+package ws_service
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/logging"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+// پکیج ws_service تغییرات کالکشن‌ها را از طریق WebSocket به کلاینت‌ها پوش می‌کند.
+// کلاینت بعد از اتصال به /ws/changes با پیام subscribe مشخص می‌کند کدام کالکشن،
+// کدام والد (مثلاً عکس‌های یک آلبوم) و کدام نوع تغییر را می‌خواهد:
+//
+//	→ {"action":"subscribe","id":"a1","collection":"photos","parent":"<album id>","types":["create","delete"]}
+//	← {"type":"subscribed","id":"a1"}
+//	← {"type":"event","id":"a1","event":{"collection":"photos","type":"create","id":"...","item":{...}}}
+//	→ {"action":"unsubscribe","id":"a1"}
+//
+// هر کلاینت یک صف ارسال محدود دارد. اگر کلاینت کند باشد رویدادها دور ریخته می‌شوند و
+// پیام {"type":"overflow","dropped":n} ارسال می‌شود تا کلاینت با delta_sync دوباره همگام شود.
+
+var logger = logging.For("ws_service")
+
+// Event is a collection change pushed to clients.
+type Event struct {
+	Collection string    `json:"collection"`
+	Type       string    `json:"type"` // create, update or delete
+	ID         uuid.UUID `json:"id"`
+	Parent     string    `json:"parent,omitempty"`
+	Item       any       `json:"item,omitempty"`
+}
+
+// Subscription is a client filter. Empty Parent and Types match everything.
+type Subscription struct {
+	ID         string   `json:"id"`
+	Collection string   `json:"collection"`
+	Parent     string   `json:"parent,omitempty"`
+	Types      []string `json:"types,omitempty"`
+}
+
+func (s *Subscription) matches(ev *Event) bool {
+	if s.Collection != ev.Collection || (s.Parent != "" && s.Parent != ev.Parent) {
+		return false
+	}
+	if len(s.Types) == 0 {
+		return true
+	}
+	for _, t := range s.Types {
+		if t == ev.Type {
+			return true
+		}
+	}
+	return false
+}
+
+type clientMessage struct {
+	Action string `json:"action"` // subscribe or unsubscribe
+	Subscription
+}
+
+type serverMessage struct {
+	Type    string `json:"type"` // subscribed, unsubscribed, event, overflow, error
+	ID      string `json:"id,omitempty"`
+	Event   *Event `json:"event,omitempty"`
+	Dropped uint64 `json:"dropped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Hub fans collection events out to WebSocket clients.
+type Hub struct {
+	// Authorize is called for every subscribe request; an error rejects it.
+	Authorize func(c *mygin.Context, sub Subscription) error
+	// SendBuffer is the per-client queue length (default 256).
+	SendBuffer int
+	// MaxDropped disconnects clients that dropped more events in a row (default 10000).
+	MaxDropped uint64
+	// MaxSubscriptions per client (default 32).
+	MaxSubscriptions int
+	// PingInterval between keepalive pings (default 30s). Clients not answering
+	// within twice the interval are disconnected.
+	PingInterval time.Duration
+	// Upgrader may be replaced, e.g. to restrict CheckOrigin.
+	Upgrader websocket.Upgrader
+
+	mu      sync.RWMutex
+	clients map[*client]struct{}
+	closed  bool
+}
+
+// NewHub returns a hub with default settings.
+func NewHub() *Hub {
+	return &Hub{clients: make(map[*client]struct{})}
+}
+
+// Len returns the number of connected clients.
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// Publish delivers ev to every matching subscription without blocking.
+func (h *Hub) Publish(ev Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		c.deliver(&ev)
+	}
+}
+
+// overflow reports events of collection lost before they reached the hub.
+func (h *Hub) overflow(collection string, dropped uint64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clients {
+		c.overflow(collection, dropped)
+	}
+}
+
+// Close disconnects all clients.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	clients := h.clients
+	h.clients = make(map[*client]struct{})
+	h.mu.Unlock()
+	for c := range clients {
+		c.close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+// Attach streams the changes of manager to the hub as collection events, using the
+// manager's Watch API. parent extracts the parent key used by subscription filters
+// and may be nil. When Watch drops changes, the subscriptions of the collection
+// receive an overflow message. The returned function stops streaming.
+func Attach[T collection_manager_memory.CollectionItem](h *Hub, collection string, manager *collection_manager_memory.Manager[T], parent func(T) string) (stop func()) {
+	changes, cancel := manager.Watch(1024)
+	go func() {
+		for change := range changes {
+			if change.Type == collection_manager_memory.ChangeOverflow {
+				h.overflow(collection, change.Dropped)
+				continue
+			}
+			ev := Event{Collection: collection, Type: string(change.Type), ID: change.ID}
+			if change.Type != collection_manager_memory.ChangeDelete {
+				ev.Item = change.Item
+			}
+			if parent != nil {
+				ev.Parent = parent(change.Item)
+			}
+			h.Publish(ev)
+		}
+	}()
+	return cancel
+}
+
+// Handler upgrades the request to a WebSocket and serves subscriptions until the
+// client disconnects.
+func (h *Hub) Handler() mygin.HandlerFunc {
+	return func(c *mygin.Context) {
+		conn, err := h.Upgrader.Upgrade(c.Writer, c.Req, nil)
+		if err != nil {
+			return // Upgrade خودش پاسخ خطا را نوشته است
+		}
+		cl := &client{
+			hub:  h,
+			conn: conn,
+			ctx:  c,
+			subs: make(map[string]*Subscription),
+			send: make(chan serverMessage, h.sendBuffer()),
+			done: make(chan struct{}),
+		}
+
+		h.mu.Lock()
+		if h.closed {
+			h.mu.Unlock()
+			cl.close(websocket.CloseGoingAway, "server shutting down")
+			return
+		}
+		h.clients[cl] = struct{}{}
+		h.mu.Unlock()
+
+		go cl.writeLoop()
+		cl.readLoop()
+
+		h.mu.Lock()
+		delete(h.clients, cl)
+		h.mu.Unlock()
+		cl.close(websocket.CloseNormalClosure, "")
+	}
+}
+
+// Mount registers Handler on group as GET path.
+func (h *Hub) Mount(group *mygin.RouterGroup, path string, middleware ...mygin.HandlerFunc) {
+	handlers := append(append([]mygin.HandlerFunc{}, middleware...), h.Handler())
+	group.GET(path, handlers...)
+}
+
+func (h *Hub) sendBuffer() int {
+	if h.SendBuffer > 0 {
+		return h.SendBuffer
+	}
+	return 256
+}
+
+func (h *Hub) pingInterval() time.Duration {
+	if h.PingInterval > 0 {
+		return h.PingInterval
+	}
+	return 30 * time.Second
+}
+
+type client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	ctx  *mygin.Context
+
+	mu     sync.RWMutex
+	subs   map[string]*Subscription
+	nextID int
+
+	send    chan serverMessage
+	dropped atomic.Uint64
+	done    chan struct{}
+	once    sync.Once
+}
+
+// deliver queues ev for every matching subscription; full queues drop the event.
+func (c *client) deliver(ev *Event) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for id, sub := range c.subs {
+		if !sub.matches(ev) {
+			continue
+		}
+		select {
+		case c.send <- serverMessage{Type: "event", ID: id, Event: ev}:
+		default:
+			if n := c.dropped.Add(1); n > c.maxDropped() {
+				go c.close(websocket.ClosePolicyViolation, "client too slow")
+			}
+		}
+	}
+}
+
+// overflow tells the subscriptions of collection that dropped of its events
+// were lost; if the queue is full they are reported with the next message.
+func (c *client) overflow(collection string, dropped uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for id, sub := range c.subs {
+		if sub.Collection != collection {
+			continue
+		}
+		select {
+		case c.send <- serverMessage{Type: "overflow", ID: id, Dropped: dropped}:
+		default:
+			c.dropped.Add(dropped)
+		}
+	}
+}
+
+func (c *client) maxDropped() uint64 {
+	if c.hub.MaxDropped > 0 {
+		return c.hub.MaxDropped
+	}
+	return 10000
+}
+
+// reply queues a control message; it never blocks the read loop.
+func (c *client) reply(msg serverMessage) {
+	select {
+	case c.send <- msg:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+func (c *client) readLoop() {
+	wait := 2 * c.hub.pingInterval()
+	c.conn.SetReadLimit(64 << 10)
+	c.conn.SetReadDeadline(time.Now().Add(wait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wait))
+	})
+
+	for {
+		var msg clientMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				logger.Debug("websocket read ended", "error", err)
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(wait))
+		c.handle(msg)
+	}
+}
+
+func (c *client) handle(msg clientMessage) {
+	sub := msg.Subscription
+	switch msg.Action {
+	case "subscribe":
+		if sub.Collection == "" {
+			c.reply(serverMessage{Type: "error", ID: sub.ID, Error: "collection is required"})
+			return
+		}
+		if c.hub.Authorize != nil {
+			if err := c.hub.Authorize(c.ctx, sub); err != nil {
+				c.reply(serverMessage{Type: "error", ID: sub.ID, Error: err.Error()})
+				return
+			}
+		}
+
+		c.mu.Lock()
+		limit := c.hub.MaxSubscriptions
+		if limit <= 0 {
+			limit = 32
+		}
+		if _, exists := c.subs[sub.ID]; !exists && len(c.subs) >= limit {
+			c.mu.Unlock()
+			c.reply(serverMessage{Type: "error", ID: sub.ID, Error: "too many subscriptions"})
+			return
+		}
+		for sub.ID == "" || (msg.ID == "" && c.subs[sub.ID] != nil) {
+			c.nextID++
+			sub.ID = "s" + strconv.Itoa(c.nextID)
+		}
+		c.subs[sub.ID] = &sub
+		c.mu.Unlock()
+		c.reply(serverMessage{Type: "subscribed", ID: sub.ID})
+
+	case "unsubscribe":
+		c.mu.Lock()
+		delete(c.subs, sub.ID)
+		c.mu.Unlock()
+		c.reply(serverMessage{Type: "unsubscribed", ID: sub.ID})
+
+	default:
+		c.reply(serverMessage{Type: "error", ID: sub.ID, Error: "unknown action " + strconv.Quote(msg.Action)})
+	}
+}
+
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(c.hub.pingInterval())
+	defer ticker.Stop()
+
+	write := func(msg serverMessage) bool {
+		c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return c.conn.WriteJSON(msg) == nil
+	}
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			// قبل از رویداد بعدی به کلاینت اطلاع داده می‌شود که رویدادهایی از دست رفته‌اند
+			if n := c.dropped.Swap(0); n > 0 && !write(serverMessage{Type: "overflow", Dropped: n}) {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+			if !write(msg) {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			deadline := time.Now().Add(10 * time.Second)
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+func (c *client) close(code int, reason string) {
+	c.once.Do(func() {
+		close(c.done)
+		if code != websocket.CloseAbnormalClosure {
+			msg := websocket.FormatCloseMessage(code, reason)
+			c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		}
+		c.conn.Close()
+	})
+}
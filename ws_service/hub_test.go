@@ -0,0 +1,112 @@
+package ws_service
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/mahdi-cpp/iris-tools/collection_manager_memory"
+	"github.com/mahdi-cpp/iris-tools/mygin"
+)
+
+type photo struct {
+	ID      uuid.UUID `json:"id"`
+	AlbumID string    `json:"albumId"`
+}
+
+func (p *photo) SetID(id uuid.UUID) { p.ID = id }
+func (p *photo) GetID() uuid.UUID   { return p.ID }
+func (p *photo) GetRecordSize() int { return 256 }
+
+func TestChangesOverWebSocket(t *testing.T) {
+	photos, err := collection_manager_memory.New[*photo](t.TempDir(), "photos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer photos.Close()
+
+	hub := NewHub()
+	hub.Authorize = func(c *mygin.Context, sub Subscription) error {
+		if sub.Collection == "secret" {
+			return errors.New("forbidden")
+		}
+		return nil
+	}
+	defer hub.Close()
+	stop := Attach(hub, "photos", photos, func(p *photo) string { return p.AlbumID })
+	defer stop()
+
+	engine := mygin.New()
+	hub.Mount(engine.Group("/ws"), "/changes")
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/changes", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	read := func() serverMessage {
+		t.Helper()
+		var msg serverMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	conn.WriteJSON(map[string]any{"action": "subscribe", "collection": "secret"})
+	if msg := read(); msg.Type != "error" {
+		t.Fatalf("expected authorization error, got %+v", msg)
+	}
+	conn.WriteJSON(map[string]any{"action": "subscribe", "id": "album1", "collection": "photos", "parent": "a1", "types": []string{"create", "delete"}})
+	if msg := read(); msg.Type != "subscribed" || msg.ID != "album1" {
+		t.Fatalf("unexpected reply: %+v", msg)
+	}
+
+	photos.Create(&photo{AlbumID: "other"})
+	p, _ := photos.Create(&photo{AlbumID: "a1"})
+	photos.Update(p) // نوع update فیلتر شده است
+	photos.Delete(p.ID)
+
+	if msg := read(); msg.Type != "event" || msg.Event.Type != "create" || msg.Event.ID != p.ID {
+		t.Fatalf("expected create of album photo, got %+v", msg)
+	}
+	if msg := read(); msg.Type != "event" || msg.Event.Type != "delete" || msg.Event.ID != p.ID {
+		t.Fatalf("expected delete, got %+v", msg)
+	}
+	if hub.Len() != 1 {
+		t.Fatalf("expected one client, got %d", hub.Len())
+	}
+}
+
+func TestSlowClientOverflow(t *testing.T) {
+	hub := NewHub()
+	c := &client{hub: hub, subs: map[string]*Subscription{"s": {Collection: "photos"}}, send: make(chan serverMessage, 1)}
+	for i := 0; i < 3; i++ {
+		c.deliver(&Event{Collection: "photos", Type: "create"})
+	}
+	if len(c.send) != 1 || c.dropped.Load() != 2 {
+		t.Fatalf("expected 1 queued and 2 dropped, got %d/%d", len(c.send), c.dropped.Load())
+	}
+
+	// رویدادهایی که پیش از رسیدن به hub در Watch دور ریخته شده‌اند
+	hub.clients[c] = struct{}{}
+	<-c.send
+	hub.overflow("albums", 1)
+	hub.overflow("photos", 5)
+	if msg := <-c.send; msg.Type != "overflow" || msg.ID != "s" || msg.Dropped != 5 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	c.deliver(&Event{Collection: "photos", Type: "create"})
+	hub.overflow("photos", 4)
+	if c.dropped.Load() != 6 {
+		t.Fatalf("expected the overflow to be reported later, got %d dropped", c.dropped.Load())
+	}
+}